@@ -84,7 +84,7 @@ func (in *ConnectionSecretMetadata) GetOwnerUID() string {
 }
 
 // SecretStoreType represents a secret store type.
-// +kubebuilder:validation:Enum=Kubernetes;Vault;Plugin
+// +kubebuilder:validation:Enum=Kubernetes;Vault;Plugin;Webhook
 type SecretStoreType string
 
 const (
@@ -93,8 +93,18 @@ const (
 	// Secrets.
 	SecretStoreKubernetes SecretStoreType = "Kubernetes"
 
+	// SecretStoreVault indicates that secret store type is Vault. In other
+	// words, connection secrets will be stored in a HashiCorp Vault KV
+	// secrets engine.
+	SecretStoreVault SecretStoreType = "Vault"
+
 	// SecretStorePlugin indicates that secret store type is Plugin and will be used with external secret stores.
 	SecretStorePlugin SecretStoreType = "Plugin"
+
+	// SecretStoreWebhook indicates that secret store type is Webhook. In
+	// other words, connection secrets will be written to, read from and
+	// deleted from an HTTP endpoint.
+	SecretStoreWebhook SecretStoreType = "Webhook"
 )
 
 // SecretStoreConfig represents configuration of a Secret Store.
@@ -119,9 +129,55 @@ type SecretStoreConfig struct {
 	// +optional
 	Kubernetes *KubernetesSecretStoreConfig `json:"kubernetes,omitempty"`
 
+	// Vault configures a secret store backed by a HashiCorp Vault KV secrets
+	// engine.
+	// +optional
+	Vault *VaultSecretStoreConfig `json:"vault,omitempty"`
+
 	// Plugin configures External secret store as a plugin.
 	// +optional
 	Plugin *PluginStoreConfig `json:"plugin,omitempty"`
+
+	// Webhook configures a secret store backed by an HTTP webhook.
+	// +optional
+	Webhook *WebhookSecretStoreConfig `json:"webhook,omitempty"`
+
+	// Encryption configures client-side encryption of the values written to
+	// the store, so that they are never stored in plaintext. Supported by
+	// all secret store types.
+	// +optional
+	Encryption *SecretStoreEncryptionConfig `json:"encryption,omitempty"`
+
+	// DefaultLabels are added to every secret written to the store. Labels
+	// of a secret's own metadata take precedence over default labels. Only
+	// supported by the Kubernetes secret store.
+	// +optional
+	DefaultLabels map[string]string `json:"defaultLabels,omitempty"`
+
+	// DefaultAnnotations are added to every secret written to the store.
+	// Annotations of a secret's own metadata take precedence over default
+	// annotations. Only supported by the Kubernetes secret store.
+	// +optional
+	DefaultAnnotations map[string]string `json:"defaultAnnotations,omitempty"`
+}
+
+// SecretStoreEncryptionConfig configures client-side encryption of the values
+// written to a secret store. Values are encrypted using data keys, which are
+// in turn encrypted using versioned key encryption keys. Keys are rotated by
+// adding a new key version and making it the primary version. Values
+// encrypted using older key versions remain readable as long as their key
+// version is kept.
+type SecretStoreEncryptionConfig struct {
+	// PrimaryKeyVersion is the version of the key encryption key used to
+	// encrypt newly written values.
+	PrimaryKeyVersion string `json:"primaryKeyVersion"`
+
+	// KeysSecretRef references a Kubernetes Secret that holds the key
+	// encryption keys. Each key of the Secret is a key version, and its value
+	// is a 16, 24, or 32 byte AES key. Not required if key encryption keys
+	// are provided by an external key management service.
+	// +optional
+	KeysSecretRef *SecretReference `json:"keysSecretRef,omitempty"`
 }
 
 // PluginStoreConfig represents configuration of an External Secret Store.
@@ -142,6 +198,132 @@ type Config struct {
 	Name string `json:"name"`
 }
 
+// WebhookSecretStoreConfig represents the required configuration for a
+// webhook secret store.
+type WebhookSecretStoreConfig struct {
+	// Endpoint is the base URL of the webhook. Secrets are addressed as
+	// <endpoint>/<scope>/<name>.
+	Endpoint string `json:"endpoint"`
+
+	// Signing configures the key used to sign requests to the webhook using
+	// HMAC-SHA256. Requests are not signed if omitted.
+	// +optional
+	Signing *WebhookSigningConfig `json:"signing,omitempty"`
+
+	// Retries is the number of times a request that failed with a transient
+	// error is retried.
+	// +optional
+	// +kubebuilder:default=3
+	Retries *int `json:"retries,omitempty"`
+}
+
+// WebhookSigningConfig configures the key used to sign requests to a webhook.
+type WebhookSigningConfig struct {
+	// Source of the signing key.
+	// +kubebuilder:validation:Enum=None;Secret;Environment;Filesystem
+	Source CredentialsSource `json:"source"`
+
+	// CommonCredentialSelectors provides common selectors for extracting
+	// the signing key.
+	CommonCredentialSelectors `json:",inline"`
+}
+
+// VaultKVVersion represent API version of the Vault KV engine
+// https://www.vaultproject.io/docs/secrets/kv
+type VaultKVVersion string
+
+const (
+	// VaultKVVersionV1 indicates that Secret API is KV Secrets Engine Version 1
+	// https://www.vaultproject.io/docs/secrets/kv/kv-v1
+	VaultKVVersionV1 VaultKVVersion = "v1"
+
+	// VaultKVVersionV2 indicates that Secret API is KV Secrets Engine Version 2
+	// https://www.vaultproject.io/docs/secrets/kv/kv-v2
+	VaultKVVersionV2 VaultKVVersion = "v2"
+)
+
+// VaultAuthMethod represents a Vault authentication method.
+// https://www.vaultproject.io/docs/auth
+type VaultAuthMethod string
+
+const (
+	// VaultAuthToken indicates that "Token Auth" will be used to
+	// authenticate to Vault.
+	// https://www.vaultproject.io/docs/auth/token
+	VaultAuthToken VaultAuthMethod = "Token"
+
+	// VaultAuthKubernetes indicates that "Kubernetes Auth" will be used to
+	// authenticate to Vault, using the token of a Kubernetes service account.
+	// https://www.vaultproject.io/docs/auth/kubernetes
+	VaultAuthKubernetes VaultAuthMethod = "Kubernetes"
+)
+
+// VaultAuthTokenConfig represents configuration for Vault Token Auth Method.
+// https://www.vaultproject.io/docs/auth/token
+type VaultAuthTokenConfig struct {
+	// Source of the credentials.
+	// +kubebuilder:validation:Enum=None;Secret;Environment;Filesystem
+	Source CredentialsSource `json:"source"`
+
+	// CommonCredentialSelectors provides common selectors for extracting
+	// credentials.
+	CommonCredentialSelectors `json:",inline"`
+}
+
+// VaultAuthKubernetesConfig represents configuration for Vault Kubernetes
+// Auth Method.
+// https://www.vaultproject.io/docs/auth/kubernetes
+type VaultAuthKubernetesConfig struct {
+	// MountPath is the mount path of the Kubernetes auth method.
+	// +optional
+	// +kubebuilder:default=kubernetes
+	MountPath string `json:"mountPath,omitempty"`
+
+	// Role is the Vault role to log in as.
+	Role string `json:"role"`
+
+	// TokenPath is the path of the Kubernetes service account token file
+	// used to log in. Defaults to the token of the service account the
+	// controller runs as.
+	// +optional
+	TokenPath string `json:"tokenPath,omitempty"`
+}
+
+// VaultAuthConfig required to authenticate to a Vault API.
+type VaultAuthConfig struct {
+	// Method configures which auth method will be used.
+	// +kubebuilder:validation:Enum=Token;Kubernetes
+	Method VaultAuthMethod `json:"method"`
+
+	// Token configures Token Auth for Vault.
+	// +optional
+	Token *VaultAuthTokenConfig `json:"token,omitempty"`
+
+	// Kubernetes configures Kubernetes Auth for Vault.
+	// +optional
+	Kubernetes *VaultAuthKubernetesConfig `json:"kubernetes,omitempty"`
+}
+
+// VaultSecretStoreConfig represents the required configuration for a Vault
+// secret store.
+type VaultSecretStoreConfig struct {
+	// Server is the url of the Vault server, e.g. "https://vault.acme.org"
+	Server string `json:"server"`
+
+	// MountPath is the mount path of the KV secrets engine.
+	MountPath string `json:"mountPath"`
+
+	// Version of the KV Secrets engine of Vault.
+	// https://www.vaultproject.io/docs/secrets/kv
+	// +optional
+	// +kubebuilder:validation:Enum=v1;v2
+	// +kubebuilder:default=v2
+	Version *VaultKVVersion `json:"version,omitempty"`
+
+	// Auth configures an auth method for Vault.
+	Auth VaultAuthConfig `json:"auth"`
+}
+
 // KubernetesAuthConfig required to authenticate to a K8s API. It expects
 // a "kubeconfig" file to be provided.
 type KubernetesAuthConfig struct {
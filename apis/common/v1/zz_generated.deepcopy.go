@@ -492,11 +492,40 @@ func (in *SecretStoreConfig) DeepCopyInto(out *SecretStoreConfig) {
 		*out = new(KubernetesSecretStoreConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Vault != nil {
+		in, out := &in.Vault, &out.Vault
+		*out = new(VaultSecretStoreConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Plugin != nil {
 		in, out := &in.Plugin, &out.Plugin
 		*out = new(PluginStoreConfig)
 		**out = **in
 	}
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(WebhookSecretStoreConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(SecretStoreEncryptionConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DefaultLabels != nil {
+		in, out := &in.DefaultLabels, &out.DefaultLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.DefaultAnnotations != nil {
+		in, out := &in.DefaultAnnotations, &out.DefaultAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretStoreConfig.
@@ -509,6 +538,26 @@ func (in *SecretStoreConfig) DeepCopy() *SecretStoreConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretStoreEncryptionConfig) DeepCopyInto(out *SecretStoreEncryptionConfig) {
+	*out = *in
+	if in.KeysSecretRef != nil {
+		in, out := &in.KeysSecretRef, &out.KeysSecretRef
+		*out = new(SecretReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretStoreEncryptionConfig.
+func (in *SecretStoreEncryptionConfig) DeepCopy() *SecretStoreEncryptionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretStoreEncryptionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Selector) DeepCopyInto(out *Selector) {
 	*out = *in
@@ -596,3 +645,121 @@ func (in *TypedReference) DeepCopy() *TypedReference {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuthConfig) DeepCopyInto(out *VaultAuthConfig) {
+	*out = *in
+	if in.Token != nil {
+		in, out := &in.Token, &out.Token
+		*out = new(VaultAuthTokenConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Kubernetes != nil {
+		in, out := &in.Kubernetes, &out.Kubernetes
+		*out = new(VaultAuthKubernetesConfig)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthConfig.
+func (in *VaultAuthConfig) DeepCopy() *VaultAuthConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuthConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuthKubernetesConfig) DeepCopyInto(out *VaultAuthKubernetesConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthKubernetesConfig.
+func (in *VaultAuthKubernetesConfig) DeepCopy() *VaultAuthKubernetesConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuthKubernetesConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuthTokenConfig) DeepCopyInto(out *VaultAuthTokenConfig) {
+	*out = *in
+	in.CommonCredentialSelectors.DeepCopyInto(&out.CommonCredentialSelectors)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthTokenConfig.
+func (in *VaultAuthTokenConfig) DeepCopy() *VaultAuthTokenConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuthTokenConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultSecretStoreConfig) DeepCopyInto(out *VaultSecretStoreConfig) {
+	*out = *in
+	if in.Version != nil {
+		in, out := &in.Version, &out.Version
+		*out = new(VaultKVVersion)
+		**out = **in
+	}
+	in.Auth.DeepCopyInto(&out.Auth)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultSecretStoreConfig.
+func (in *VaultSecretStoreConfig) DeepCopy() *VaultSecretStoreConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultSecretStoreConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookSecretStoreConfig) DeepCopyInto(out *WebhookSecretStoreConfig) {
+	*out = *in
+	if in.Signing != nil {
+		in, out := &in.Signing, &out.Signing
+		*out = new(WebhookSigningConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Retries != nil {
+		in, out := &in.Retries, &out.Retries
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookSecretStoreConfig.
+func (in *WebhookSecretStoreConfig) DeepCopy() *WebhookSecretStoreConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookSecretStoreConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookSigningConfig) DeepCopyInto(out *WebhookSigningConfig) {
+	*out = *in
+	in.CommonCredentialSelectors.DeepCopyInto(&out.CommonCredentialSelectors)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookSigningConfig.
+func (in *WebhookSigningConfig) DeepCopy() *WebhookSigningConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookSigningConfig)
+	in.DeepCopyInto(out)
+	return out
+}
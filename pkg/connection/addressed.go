@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errResolveName  = "cannot resolve content addressed secret name"
+	errWritePointer = "cannot write content addressed secret pointer"
+
+	errFmtNoPointer       = "secret %q in scope %q is not a content addressed secret pointer"
+	errFmtPointerNotFound = "content addressed secret pointer %q in scope %q not found"
+)
+
+// ContentAddressedNameKey is the key of a pointer secret whose value is the
+// name of the current content addressed secret.
+const ContentAddressedNameKey = "contentAddressedName"
+
+// contentHashLength is the number of hex encoded characters of the content
+// hash a content addressed name incorporates.
+const contentHashLength = 10
+
+// ContentAddressedName returns the content addressed name of a secret with the
+// supplied name and key values. Secrets with identical key values have the
+// same content addressed name, and changing any key value changes the name.
+func ContentAddressedName(name string, kv store.KeyValues) string {
+	return name + "-" + store.Hash(kv)[:contentHashLength]
+}
+
+// A ContentAddressedStore is a Store that writes each secret under its
+// ContentAddressedName, so that a secret's content never changes once
+// written: changing its content writes a new secret. This allows consumers to
+// pin the exact content they consume. A pointer secret under the secret's own
+// name records the name of the current content addressed secret, so that
+// consumers can resolve it, and reads of the secret's own name are served from
+// the current content addressed secret.
+//
+// Content addressed secrets that are no longer current are not deleted, so
+// that consumers still referencing them are not broken. Callers are
+// responsible for garbage collecting them once they are no longer referenced.
+type ContentAddressedStore struct {
+	store Store
+}
+
+// NewContentAddressedStore returns a ContentAddressedStore that wraps the
+// supplied Store.
+func NewContentAddressedStore(s Store) *ContentAddressedStore {
+	return &ContentAddressedStore{store: s}
+}
+
+// Resolve returns the scoped name of the current content addressed secret of
+// the supplied secret. It returns a NotFound error if the secret has no
+// pointer secret.
+func (c *ContentAddressedStore) Resolve(ctx context.Context, n store.ScopedName) (store.ScopedName, error) {
+	p := &store.Secret{}
+	if err := c.store.ReadKeyValues(ctx, n, p); err != nil {
+		return store.ScopedName{}, errors.Wrap(err, errResolveName)
+	}
+	if len(p.Data) == 0 {
+		return store.ScopedName{}, store.NewNotFound(errors.Errorf(errFmtPointerNotFound, n.Name, n.Scope).Error())
+	}
+	name, ok := p.Data[ContentAddressedNameKey]
+	if !ok {
+		return store.ScopedName{}, errors.Errorf(errFmtNoPointer, n.Name, n.Scope)
+	}
+	return store.ScopedName{Name: string(name), Scope: n.Scope}, nil
+}
+
+// ReadKeyValues reads key values from the current content addressed secret of
+// the supplied secret.
+func (c *ContentAddressedStore) ReadKeyValues(ctx context.Context, n store.ScopedName, s *store.Secret, ro ...store.ReadOption) error {
+	ca, err := c.Resolve(ctx, n)
+	if err != nil {
+		return err
+	}
+	return c.store.ReadKeyValues(ctx, ca, s, ro...)
+}
+
+// WriteKeyValues writes key values to the content addressed secret of the
+// supplied secret, and makes it the current content addressed secret. It
+// returns true if the current content addressed secret changed.
+func (c *ContentAddressedStore) WriteKeyValues(ctx context.Context, s *store.Secret, wo ...store.WriteOption) (bool, error) {
+	cs := s.DeepCopy()
+	cs.Name = ContentAddressedName(s.Name, s.Data)
+	if _, err := c.store.WriteKeyValues(ctx, cs, wo...); err != nil {
+		return false, err
+	}
+
+	p := s.DeepCopy()
+	p.Data = store.KeyValues{ContentAddressedNameKey: []byte(cs.Name)}
+	changed, err := c.store.WriteKeyValues(ctx, p, wo...)
+	return changed, errors.Wrap(err, errWritePointer)
+}
+
+// DeleteKeyValues deletes key values from the current content addressed
+// secret of the supplied secret. Because content addressed secrets never
+// change, deleting some of its keys writes a new content addressed secret
+// without them. Deleting all of its keys deletes the pointer secret and the
+// current content addressed secret.
+func (c *ContentAddressedStore) DeleteKeyValues(ctx context.Context, s *store.Secret, do ...store.DeleteOption) error {
+	ca, err := c.Resolve(ctx, s.ScopedName)
+	if store.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	current := &store.Secret{}
+	if err := c.store.ReadKeyValues(ctx, ca, current); err != nil {
+		return err
+	}
+	for k := range s.Data {
+		delete(current.Data, k)
+	}
+
+	if len(s.Data) > 0 && len(current.Data) > 0 {
+		ws := s.DeepCopy()
+		ws.Data = current.Data
+		_, err := c.WriteKeyValues(ctx, ws)
+		return err
+	}
+
+	cs := s.DeepCopy()
+	cs.ScopedName, cs.Data = ca, nil
+	if err := c.store.DeleteKeyValues(ctx, cs, do...); err != nil {
+		return err
+	}
+	p := s.DeepCopy()
+	p.Data = nil
+	return c.store.DeleteKeyValues(ctx, p, do...)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+)
+
+func TestContentAddressedName(t *testing.T) {
+	a := ContentAddressedName("cool", store.KeyValues{"user": []byte("admin"), "pass": []byte("hunter2")})
+	if b := ContentAddressedName("cool", store.KeyValues{"pass": []byte("hunter2"), "user": []byte("admin")}); a != b {
+		t.Errorf("ContentAddressedName(...): want identical content to yield the same name, got %q and %q", a, b)
+	}
+	if c := ContentAddressedName("cool", store.KeyValues{"user": []byte("admin"), "pass": []byte("hunter3")}); a == c {
+		t.Errorf("ContentAddressedName(...): want changed content to yield a new name, got %q for both", a)
+	}
+}
+
+func TestContentAddressedStore(t *testing.T) {
+	ctx := context.Background()
+	n := store.ScopedName{Name: "cool", Scope: "ns"}
+	m := fake.NewMemoryStore()
+	c := NewContentAddressedStore(m)
+
+	write := func(kv store.KeyValues) store.ScopedName {
+		t.Helper()
+		if _, err := c.WriteKeyValues(ctx, &store.Secret{ScopedName: n, Data: kv}); err != nil {
+			t.Fatalf("c.WriteKeyValues(...): %v", err)
+		}
+		ca, err := c.Resolve(ctx, n)
+		if err != nil {
+			t.Fatalf("c.Resolve(...): %v", err)
+		}
+		return ca
+	}
+
+	first := write(store.KeyValues{"pass": []byte("hunter2")})
+	if want := (store.ScopedName{Name: ContentAddressedName("cool", store.KeyValues{"pass": []byte("hunter2")}), Scope: "ns"}); first != want {
+		t.Errorf("c.Resolve(...): want %v, got %v", want, first)
+	}
+	if again := write(store.KeyValues{"pass": []byte("hunter2")}); again != first {
+		t.Errorf("c.Resolve(...): want identical content to resolve to %v, got %v", first, again)
+	}
+
+	second := write(store.KeyValues{"pass": []byte("hunter3")})
+	if second == first {
+		t.Errorf("c.Resolve(...): want changed content to resolve to a new name, got %v", second)
+	}
+
+	// Reads of the secret's own name should be served by the current content
+	// addressed secret, and the previous one should remain for consumers that
+	// pinned it.
+	s := &store.Secret{}
+	if err := c.ReadKeyValues(ctx, n, s); err != nil {
+		t.Fatalf("c.ReadKeyValues(...): %v", err)
+	}
+	if diff := cmp.Diff(store.KeyValues{"pass": []byte("hunter3")}, s.Data); diff != "" {
+		t.Errorf("c.ReadKeyValues(...): -want, +got:\n%s", diff)
+	}
+	if _, ok := m.Get(first); !ok {
+		t.Errorf("m.Get(...): want the previous content addressed secret %v to remain", first)
+	}
+
+	if err := c.DeleteKeyValues(ctx, &store.Secret{ScopedName: n}); err != nil {
+		t.Fatalf("c.DeleteKeyValues(...): %v", err)
+	}
+	if _, err := c.Resolve(ctx, n); !store.IsNotFound(err) {
+		t.Errorf("c.Resolve(...): want a NotFound error once deleted, got %v", err)
+	}
+	if _, ok := m.Get(second); ok {
+		t.Errorf("m.Get(...): want the current content addressed secret %v to be deleted", second)
+	}
+}
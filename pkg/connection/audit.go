@@ -0,0 +1,248 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// An AuditRecord describes a single secret store operation. It never contains
+// secret values.
+type AuditRecord struct {
+	// Time the operation completed.
+	Time time.Time
+
+	// Operation that was performed, i.e. "read", "write", or "delete".
+	Operation string
+
+	// Secret the operation was performed on.
+	Secret store.ScopedName
+
+	// Keys the operation read, wrote, or deleted, in lexical order. Empty
+	// for a delete of the whole secret.
+	Keys []string
+
+	// Owner is the UID of the owner of the secret, if known.
+	Owner string
+
+	// Controller that performed the operation, if known.
+	Controller string
+
+	// RequestID of the request that caused the operation, if known.
+	RequestID string
+
+	// Error the operation failed with, if any.
+	Error string
+}
+
+// An AuditSink receives the AuditRecords emitted by an AuditingStore, for
+// example to deliver them to a message queue or a file.
+type AuditSink interface {
+	Emit(ctx context.Context, r AuditRecord) error
+}
+
+// An AuditSinkFn is a function that satisfies the AuditSink interface.
+type AuditSinkFn func(ctx context.Context, r AuditRecord) error
+
+// Emit the supplied AuditRecord.
+func (fn AuditSinkFn) Emit(ctx context.Context, r AuditRecord) error {
+	return fn(ctx, r)
+}
+
+// DefaultAuditQueueSize is the default number of records an AuditingStore
+// queues for delivery to its AuditSink.
+const DefaultAuditQueueSize = 1024
+
+// An AuditingStoreOption configures an AuditingStore.
+type AuditingStoreOption func(*AuditingStore)
+
+// WithAuditClock configures the Clock an AuditingStore uses to timestamp the
+// records it emits. store.RealClock is used by default.
+func WithAuditClock(c store.Clock) AuditingStoreOption {
+	return func(s *AuditingStore) {
+		s.clock = c
+	}
+}
+
+// WithAuditQueueSize configures the number of records an AuditingStore queues
+// for delivery to its AuditSink. Records emitted while the queue is full are
+// dropped.
+func WithAuditQueueSize(n int) AuditingStoreOption {
+	return func(s *AuditingStore) {
+		s.queueSize = n
+	}
+}
+
+type auditEntry struct {
+	ctx    context.Context
+	record AuditRecord
+}
+
+// An AuditingStore is a Store that emits an AuditRecord to an AuditSink for
+// each operation. Records are queued and delivered to the AuditSink by a
+// worker, so that a slow AuditSink doesn't slow operations down. Delivery is
+// best-effort: operations don't fail if their record can't be emitted. Records
+// that are emitted while the queue is full, or that the AuditSink fails to
+// receive, are dropped and counted by a metric.
+type AuditingStore struct {
+	store     Store
+	sink      AuditSink
+	clock     store.Clock
+	queueSize int
+
+	mx     sync.RWMutex
+	closed bool
+	queue  chan auditEntry
+	done   chan struct{}
+
+	dropped *prometheus.CounterVec
+}
+
+// NewAuditingStore returns an AuditingStore that wraps the supplied Store and
+// emits records to the supplied AuditSink. Its metrics are registered with
+// the supplied Registerer. The AuditingStore delivers records until it is
+// shut down.
+func NewAuditingStore(s Store, sink AuditSink, r prometheus.Registerer, o ...AuditingStoreOption) (*AuditingStore, error) {
+	as := &AuditingStore{
+		store:     s,
+		sink:      sink,
+		clock:     store.RealClock,
+		queueSize: DefaultAuditQueueSize,
+		done:      make(chan struct{}),
+		dropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Subsystem: metricsSubsystem,
+			Name:      "connection_store_audit_records_dropped_total",
+			Help:      "The number of secret store audit records that could not be delivered to their sink, by operation",
+		}, []string{"operation"}),
+	}
+
+	for _, fn := range o {
+		fn(as)
+	}
+
+	var err error
+	if as.dropped, err = register(r, as.dropped); err != nil {
+		return nil, errors.Wrap(err, errRegisterMetrics)
+	}
+
+	as.queue = make(chan auditEntry, max(0, as.queueSize))
+	go as.deliver()
+	return as, nil
+}
+
+// Unwrap returns the Store the AuditingStore wraps.
+func (s *AuditingStore) Unwrap() Store {
+	return s.store
+}
+
+// ReadKeyValues reads key values from the wrapped Store, and emits a record
+// of the keys that were read.
+func (s *AuditingStore) ReadKeyValues(ctx context.Context, n store.ScopedName, sec *store.Secret, ro ...store.ReadOption) error {
+	err := s.store.ReadKeyValues(ctx, n, sec, ro...)
+	s.emit(ctx, "read", n, sec, err)
+	return err
+}
+
+// WriteKeyValues writes key values to the wrapped Store, and emits a record of
+// the keys that were written.
+func (s *AuditingStore) WriteKeyValues(ctx context.Context, sec *store.Secret, wo ...store.WriteOption) (bool, error) {
+	changed, err := s.store.WriteKeyValues(ctx, sec, wo...)
+	s.emit(ctx, "write", sec.ScopedName, sec, err)
+	return changed, err
+}
+
+// DeleteKeyValues deletes key values from the wrapped Store, and emits a
+// record of the keys that were deleted.
+func (s *AuditingStore) DeleteKeyValues(ctx context.Context, sec *store.Secret, do ...store.DeleteOption) error {
+	err := s.store.DeleteKeyValues(ctx, sec, do...)
+	s.emit(ctx, "delete", sec.ScopedName, sec, err)
+	return err
+}
+
+func (s *AuditingStore) emit(ctx context.Context, op string, n store.ScopedName, sec *store.Secret, err error) {
+	r := AuditRecord{
+		Time:      s.clock.Now(),
+		Operation: op,
+		Secret:    n,
+		Owner:     sec.GetOwner(),
+	}
+	for k := range sec.Data {
+		r.Keys = append(r.Keys, k)
+	}
+	sort.Strings(r.Keys)
+	r.Controller, _ = store.GetControllerName(ctx)
+	r.RequestID, _ = store.GetRequestID(ctx)
+	if err != nil {
+		r.Error = err.Error()
+	}
+
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	if s.closed {
+		s.dropped.WithLabelValues(op).Inc()
+		return
+	}
+
+	// The record is delivered after the operation returns, so delivery must
+	// not be cancelled along with the operation's context.
+	select {
+	case s.queue <- auditEntry{ctx: context.WithoutCancel(ctx), record: r}:
+	default:
+		s.dropped.WithLabelValues(op).Inc()
+	}
+}
+
+// deliver emits queued records to the AuditSink until the queue is closed.
+func (s *AuditingStore) deliver() {
+	defer close(s.done)
+	for e := range s.queue {
+		if err := s.sink.Emit(e.ctx, e.record); err != nil {
+			s.dropped.WithLabelValues(e.record.Operation).Inc()
+		}
+	}
+}
+
+// Shutdown stops queueing records, and blocks until the queued records are
+// delivered or the supplied context is done. It then shuts down the wrapped
+// Store if it is, or wraps, a Shutdowner.
+func (s *AuditingStore) Shutdown(ctx context.Context) error {
+	s.mx.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mx.Unlock()
+
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if sd, ok := Find[Shutdowner](s.store); ok {
+		return sd.Shutdown(ctx)
+	}
+	return nil
+}
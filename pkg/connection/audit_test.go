@@ -0,0 +1,152 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestAuditingStore(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	n := store.ScopedName{Name: "cool", Scope: "ns"}
+	owned := func(kv store.KeyValues) *store.Secret {
+		md := &v1.ConnectionSecretMetadata{}
+		md.SetOwnerUID("owner-uid")
+		return &store.Secret{ScopedName: n, Metadata: md, Data: kv}
+	}
+
+	wrapped := &fake.SecretStore{
+		ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
+			*s = *owned(store.KeyValues{"password": []byte("s3cr3t"), "endpoint": []byte("example.org")})
+			return nil
+		},
+		WriteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.WriteOption) (bool, error) {
+			return true, nil
+		},
+		DeleteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.DeleteOption) error {
+			return errBoom
+		},
+	}
+
+	var got []AuditRecord
+	reg := prometheus.NewRegistry()
+	s, err := NewAuditingStore(wrapped, AuditSinkFn(func(_ context.Context, r AuditRecord) error {
+		got = append(got, r)
+		return nil
+	}), reg, WithAuditClock(fake.NewClock(now)))
+	if err != nil {
+		t.Fatalf("NewAuditingStore(...): %v", err)
+	}
+
+	ctx := store.WithRequestID(store.WithControllerName(context.Background(), "cool-controller"), "req-1")
+	_ = s.ReadKeyValues(ctx, n, &store.Secret{})
+	_, _ = s.WriteKeyValues(ctx, owned(store.KeyValues{"token": []byte("t0ken")}))
+	_ = s.DeleteKeyValues(ctx, owned(nil))
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatalf("s.Shutdown(...): %v", err)
+	}
+
+	want := []AuditRecord{
+		{Time: now, Operation: "read", Secret: n, Keys: []string{"endpoint", "password"}, Owner: "owner-uid", Controller: "cool-controller", RequestID: "req-1"},
+		{Time: now, Operation: "write", Secret: n, Keys: []string{"token"}, Owner: "owner-uid", Controller: "cool-controller", RequestID: "req-1"},
+		{Time: now, Operation: "delete", Secret: n, Owner: "owner-uid", Controller: "cool-controller", RequestID: "req-1", Error: errBoom.Error()},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("AuditingStore: -want records, +got records:\n%s", diff)
+	}
+}
+
+func TestAuditingStoreDropped(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	s, err := NewAuditingStore(&fake.SecretStore{
+		WriteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.WriteOption) (bool, error) {
+			return true, nil
+		},
+	}, AuditSinkFn(func(_ context.Context, _ AuditRecord) error {
+		return errBoom
+	}), reg)
+	if err != nil {
+		t.Fatalf("NewAuditingStore(...): %v", err)
+	}
+
+	changed, err := s.WriteKeyValues(context.Background(), &store.Secret{})
+	if diff := cmp.Diff(nil, err, test.EquateErrors()); diff != "" {
+		t.Errorf("s.WriteKeyValues(...): -want error, +got error:\n%s", diff)
+	}
+	if !changed {
+		t.Errorf("s.WriteKeyValues(...): want changed, got unchanged")
+	}
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatalf("s.Shutdown(...): %v", err)
+	}
+
+	want := map[string]float64{"write": 1}
+	if diff := cmp.Diff(want, gathered(t, reg, "crossplane_connection_store_audit_records_dropped_total", "operation")); diff != "" {
+		t.Errorf("dropped records: -want, +got:\n%s", diff)
+	}
+}
+
+func TestAuditingStoreQueueFull(t *testing.T) {
+	received := make(chan struct{})
+	release := make(chan struct{})
+	delivered := 0
+	reg := prometheus.NewRegistry()
+	s, err := NewAuditingStore(&fake.SecretStore{
+		WriteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.WriteOption) (bool, error) {
+			return true, nil
+		},
+	}, AuditSinkFn(func(_ context.Context, _ AuditRecord) error {
+		if delivered == 0 {
+			close(received)
+			<-release
+		}
+		delivered++
+		return nil
+	}), reg, WithAuditQueueSize(1))
+	if err != nil {
+		t.Fatalf("NewAuditingStore(...): %v", err)
+	}
+
+	// The first record blocks the sink, the second fills the queue, and the
+	// third should be dropped rather than block the write.
+	_, _ = s.WriteKeyValues(context.Background(), &store.Secret{})
+	<-received
+	_, _ = s.WriteKeyValues(context.Background(), &store.Secret{})
+	_, _ = s.WriteKeyValues(context.Background(), &store.Secret{})
+	close(release)
+
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatalf("s.Shutdown(...): %v", err)
+	}
+	if delivered != 2 {
+		t.Errorf("s.Shutdown(...): want 2 delivered records, got %d", delivered)
+	}
+	want := map[string]float64{"write": 1}
+	if diff := cmp.Diff(want, gathered(t, reg, "crossplane_connection_store_audit_records_dropped_total", "operation")); diff != "" {
+		t.Errorf("dropped records: -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,338 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errFlush             = "cannot flush buffered writes"
+	errFmtFlushSecret    = "cannot flush buffered write of secret %s/%s"
+	errFmtVerifySecret   = "cannot verify buffered write of secret %s/%s"
+	errFmtVerifyMismatch = "secret %s/%s does not contain the buffered key values"
+)
+
+const (
+	// DefaultFlushInterval is the default interval at which a BufferedStore
+	// flushes its pending writes when it is run.
+	DefaultFlushInterval = 10 * time.Second
+
+	// DefaultShutdownTimeout is the default duration for which a
+	// BufferedStore that is run waits for its pending writes to be flushed
+	// when it is stopped.
+	DefaultShutdownTimeout = 30 * time.Second
+)
+
+// A BufferedStoreOption configures a BufferedStore.
+type BufferedStoreOption func(*BufferedStore)
+
+// WithFlushInterval configures the interval at which a BufferedStore flushes
+// its pending writes when it is run.
+func WithFlushInterval(d time.Duration) BufferedStoreOption {
+	return func(b *BufferedStore) {
+		b.interval = d
+	}
+}
+
+// WithShutdownTimeout configures the duration for which a BufferedStore that
+// is run waits for its pending writes to be flushed when it is stopped.
+func WithShutdownTimeout(d time.Duration) BufferedStoreOption {
+	return func(b *BufferedStore) {
+		b.timeout = d
+	}
+}
+
+// WithVerifyOnShutdown configures a BufferedStore to read back each secret it
+// flushes on shutdown, and return an error if the secret does not contain the
+// key values that were written.
+func WithVerifyOnShutdown() BufferedStoreOption {
+	return func(b *BufferedStore) {
+		b.verify = true
+	}
+}
+
+type pendingWrite struct {
+	secret *store.Secret
+	wo     []store.WriteOption
+}
+
+// A BufferedStore is a Store that buffers writes in memory, and writes them to
+// the Store it wraps when it is flushed. Only the most recent write of each
+// secret is buffered. Reads are served from the buffer if a write of the
+// secret is pending.
+type BufferedStore struct {
+	store    Store
+	interval time.Duration
+	timeout  time.Duration
+	verify   bool
+
+	mx      sync.Mutex
+	pending map[store.ScopedName]pendingWrite
+	locks   map[store.ScopedName]*secretLock
+}
+
+// A secretLock serialises flushes and deletes of a secret. It is discarded
+// once no flush or delete of the secret holds or awaits it.
+type secretLock struct {
+	sync.Mutex
+	refs int
+}
+
+// NewBufferedStore returns a BufferedStore that wraps the supplied Store.
+func NewBufferedStore(s Store, o ...BufferedStoreOption) *BufferedStore {
+	b := &BufferedStore{
+		store:    s,
+		interval: DefaultFlushInterval,
+		timeout:  DefaultShutdownTimeout,
+		pending:  make(map[store.ScopedName]pendingWrite),
+		locks:    make(map[store.ScopedName]*secretLock),
+	}
+	for _, fn := range o {
+		fn(b)
+	}
+	return b
+}
+
+// Unwrap returns the Store the BufferedStore wraps.
+func (b *BufferedStore) Unwrap() Store {
+	return b.store
+}
+
+// ReadKeyValues reads key values from the buffer if a write of the secret is
+// pending, and from the wrapped Store otherwise.
+func (b *BufferedStore) ReadKeyValues(ctx context.Context, n store.ScopedName, s *store.Secret, ro ...store.ReadOption) error {
+	b.mx.Lock()
+	p, ok := b.pending[n]
+	b.mx.Unlock()
+	if !ok {
+		return b.store.ReadKeyValues(ctx, n, s, ro...)
+	}
+	*s = *p.secret.DeepCopy()
+	return store.ApplyReadOptions(ctx, s, ro...)
+}
+
+// WriteKeyValues buffers a write of the supplied secret. The write options are
+// applied when the write is flushed. It always reports that the secret
+// changed, because whether it did is not known until the write is flushed.
+func (b *BufferedStore) WriteKeyValues(_ context.Context, s *store.Secret, wo ...store.WriteOption) (bool, error) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	b.pending[s.ScopedName] = pendingWrite{secret: s.DeepCopy(), wo: wo}
+	return true, nil
+}
+
+// DeleteKeyValues removes the deleted keys from any pending write of the
+// secret, and deletes key values from the wrapped Store. A pending write is
+// discarded if the whole secret, or every key it would write, is deleted. A
+// delete is never sent to the wrapped Store while the secret is being flushed.
+func (b *BufferedStore) DeleteKeyValues(ctx context.Context, s *store.Secret, do ...store.DeleteOption) error {
+	unlock := b.lock(s.ScopedName)
+	defer unlock()
+
+	b.mx.Lock()
+	if p, ok := b.pending[s.ScopedName]; ok {
+		b.discardKeys(p, s)
+	}
+	b.mx.Unlock()
+	return b.store.DeleteKeyValues(ctx, s, do...)
+}
+
+// discardKeys removes the keys of the supplied deleted secret from the
+// supplied pending write. It must be called with the BufferedStore locked.
+func (b *BufferedStore) discardKeys(p pendingWrite, deleted *store.Secret) {
+	if deleted.Data == nil {
+		delete(b.pending, deleted.ScopedName)
+		return
+	}
+	if len(deleted.Data) == 0 {
+		// Deleting no keys is a no-op.
+		return
+	}
+	remaining := p.secret.DeepCopy()
+	for k := range deleted.Data {
+		delete(remaining.Data, k)
+	}
+	if len(remaining.Data) == 0 {
+		delete(b.pending, deleted.ScopedName)
+		return
+	}
+	b.pending[deleted.ScopedName] = pendingWrite{secret: remaining, wo: p.wo}
+}
+
+// lock the supplied secret against concurrent flushes and deletes, and return
+// a function that unlocks it.
+func (b *BufferedStore) lock(n store.ScopedName) func() {
+	b.mx.Lock()
+	l, ok := b.locks[n]
+	if !ok {
+		l = &secretLock{}
+		b.locks[n] = l
+	}
+	l.refs++
+	b.mx.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		b.mx.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(b.locks, n)
+		}
+		b.mx.Unlock()
+	}
+}
+
+// Flush writes all pending writes to the wrapped Store. Writes that fail
+// remain pending, and don't prevent other pending writes from being flushed.
+// The errors of all writes that fail are returned.
+func (b *BufferedStore) Flush(ctx context.Context) error {
+	_, err := b.flush(ctx)
+	return errors.Wrap(err, errFlush)
+}
+
+// Shutdown flushes all pending writes to the wrapped Store, and optionally
+// verifies that they were persisted. It blocks until all pending writes are
+// flushed, or the supplied context is done. Once flushed, the wrapped Store is
+// shut down if it is, or wraps, a Shutdowner.
+func (b *BufferedStore) Shutdown(ctx context.Context) error {
+	flushed, err := b.flush(ctx)
+	if err != nil {
+		return errors.Wrap(err, errFlush)
+	}
+	if err := b.verifyFlushed(ctx, flushed); err != nil {
+		return err
+	}
+	if sd, ok := Find[Shutdowner](b.store); ok {
+		return sd.Shutdown(ctx)
+	}
+	return nil
+}
+
+func (b *BufferedStore) verifyFlushed(ctx context.Context, flushed []*store.Secret) error {
+	if !b.verify {
+		return nil
+	}
+	for _, s := range flushed {
+		got := &store.Secret{}
+		if err := b.store.ReadKeyValues(ctx, s.ScopedName, got); err != nil {
+			return errors.Wrapf(err, errFmtVerifySecret, s.Scope, s.Name)
+		}
+		// Writes merge into a secret, so only the flushed keys must match.
+		written := make(store.KeyValues, len(s.Data))
+		for k := range s.Data {
+			if v, ok := got.Data[k]; ok {
+				written[k] = v
+			}
+		}
+		if !store.Equal(s.Data, written) {
+			return errors.Errorf(errFmtVerifyMismatch, s.Scope, s.Name)
+		}
+	}
+	return nil
+}
+
+// Start flushes pending writes at the configured interval until the supplied
+// context is done, then shuts the BufferedStore down. It allows a
+// BufferedStore to be added to a controller manager, so that its pending
+// writes are flushed on the manager's stop path.
+func (b *BufferedStore) Start(ctx context.Context) error {
+	t := time.NewTicker(b.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// The supplied context is done, so we need a new one to flush.
+			sctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+			defer cancel()
+			return b.Shutdown(sctx)
+		case <-t.C:
+			// Writes that fail remain pending, and are retried on the next
+			// flush.
+			_ = b.Flush(ctx)
+		}
+	}
+}
+
+func (b *BufferedStore) flush(ctx context.Context) ([]*store.Secret, error) {
+	b.mx.Lock()
+	names := make([]store.ScopedName, 0, len(b.pending))
+	for n := range b.pending {
+		names = append(names, n)
+	}
+	b.mx.Unlock()
+
+	// Flush in a deterministic order.
+	sort.Slice(names, func(i, j int) bool {
+		if names[i].Scope != names[j].Scope {
+			return names[i].Scope < names[j].Scope
+		}
+		return names[i].Name < names[j].Name
+	})
+
+	flushed := make([]*store.Secret, 0, len(names))
+	var errs []error
+	for _, n := range names {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		p, ok, err := b.flushSecret(ctx, n)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, errFmtFlushSecret, n.Scope, n.Name))
+			continue
+		}
+		if ok {
+			flushed = append(flushed, p.secret)
+		}
+	}
+	return flushed, errors.Join(errs...)
+}
+
+// flushSecret writes the pending write of the supplied secret, if any, to the
+// wrapped Store. The secret is locked while it is flushed, so that a
+// concurrent delete can't reach the wrapped Store before the write does.
+func (b *BufferedStore) flushSecret(ctx context.Context, n store.ScopedName) (pendingWrite, bool, error) {
+	unlock := b.lock(n)
+	defer unlock()
+
+	b.mx.Lock()
+	p, ok := b.pending[n]
+	b.mx.Unlock()
+	if !ok {
+		return pendingWrite{}, false, nil
+	}
+
+	if _, err := b.store.WriteKeyValues(ctx, p.secret.DeepCopy(), p.wo...); err != nil {
+		return pendingWrite{}, false, err
+	}
+
+	b.mx.Lock()
+	// Don't discard a write that was buffered while we were flushing.
+	if b.pending[n].secret == p.secret {
+		delete(b.pending, n)
+	}
+	b.mx.Unlock()
+	return p, true, nil
+}
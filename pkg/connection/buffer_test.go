@@ -0,0 +1,298 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+// memoryStore returns a fake Store that persists writes to the supplied map,
+// unless written is false.
+func memoryStore(m map[store.ScopedName]store.KeyValues, written bool) *fake.SecretStore {
+	return &fake.SecretStore{
+		ReadKeyValuesFn: func(_ context.Context, n store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
+			s.ScopedName = n
+			s.Data = m[n]
+			return nil
+		},
+		WriteKeyValuesFn: func(_ context.Context, s *store.Secret, _ ...store.WriteOption) (bool, error) {
+			if written {
+				m[s.ScopedName] = s.Data
+			}
+			return true, nil
+		},
+	}
+}
+
+func TestBufferedStoreShutdown(t *testing.T) {
+	a := &store.Secret{ScopedName: store.ScopedName{Name: "a", Scope: "ns"}, Data: store.KeyValues{"key": []byte("a")}}
+	b := &store.Secret{ScopedName: store.ScopedName{Name: "b", Scope: "ns"}, Data: store.KeyValues{"key": []byte("b")}}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	type args struct {
+		ctx     context.Context
+		written bool
+		o       []BufferedStoreOption
+	}
+	type want struct {
+		err       error
+		persisted map[store.ScopedName]store.KeyValues
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Flushed": {
+			reason: "We should persist all pending writes on shutdown.",
+			args: args{
+				ctx:     context.Background(),
+				written: true,
+			},
+			want: want{
+				persisted: map[store.ScopedName]store.KeyValues{
+					a.ScopedName: a.Data,
+					b.ScopedName: b.Data,
+				},
+			},
+		},
+		"Verified": {
+			reason: "We should not return an error if all flushed writes were verified to be persisted.",
+			args: args{
+				ctx:     context.Background(),
+				written: true,
+				o:       []BufferedStoreOption{WithVerifyOnShutdown()},
+			},
+			want: want{
+				persisted: map[store.ScopedName]store.KeyValues{
+					a.ScopedName: a.Data,
+					b.ScopedName: b.Data,
+				},
+			},
+		},
+		"VerifyFailed": {
+			reason: "We should return an error if a flushed write was not persisted.",
+			args: args{
+				ctx: context.Background(),
+				o:   []BufferedStoreOption{WithVerifyOnShutdown()},
+			},
+			want: want{
+				err:       errors.Errorf(errFmtVerifyMismatch, "ns", "a"),
+				persisted: map[store.ScopedName]store.KeyValues{},
+			},
+		},
+		"ContextDone": {
+			reason: "We should stop flushing and return an error if the context is done.",
+			args: args{
+				ctx:     cancelled,
+				written: true,
+			},
+			want: want{
+				err:       errors.Wrap(context.Canceled, errFlush),
+				persisted: map[store.ScopedName]store.KeyValues{},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			persisted := map[store.ScopedName]store.KeyValues{}
+			bs := NewBufferedStore(memoryStore(persisted, tc.args.written), tc.args.o...)
+			_, _ = bs.WriteKeyValues(context.Background(), a)
+			_, _ = bs.WriteKeyValues(context.Background(), b)
+
+			if len(persisted) != 0 {
+				t.Errorf("\n%s\nbs.WriteKeyValues(...): writes should be buffered until flushed", tc.reason)
+			}
+
+			err := bs.Shutdown(tc.args.ctx)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nbs.Shutdown(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.persisted, persisted); diff != "" {
+				t.Errorf("\n%s\nbs.Shutdown(...): -want persisted, +got persisted:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestBufferedStoreReadKeyValues(t *testing.T) {
+	persisted := map[store.ScopedName]store.KeyValues{}
+	bs := NewBufferedStore(memoryStore(persisted, true))
+
+	n := store.ScopedName{Name: "cool", Scope: "ns"}
+	_, _ = bs.WriteKeyValues(context.Background(), &store.Secret{ScopedName: n, Data: store.KeyValues{"key": []byte("v")}})
+
+	got := &store.Secret{}
+	if err := bs.ReadKeyValues(context.Background(), n, got); err != nil {
+		t.Fatalf("bs.ReadKeyValues(...): %v", err)
+	}
+	if diff := cmp.Diff(store.KeyValues{"key": []byte("v")}, got.Data); diff != "" {
+		t.Errorf("bs.ReadKeyValues(...): reads should be served from pending writes: -want, +got:\n%s", diff)
+	}
+}
+
+func TestBufferedStoreDeleteKeyValues(t *testing.T) {
+	n := store.ScopedName{Name: "cool", Scope: "ns"}
+
+	type want struct {
+		persisted map[store.ScopedName]store.KeyValues
+	}
+
+	cases := map[string]struct {
+		reason string
+		delete *store.Secret
+		want   want
+	}{
+		"SomeKeys": {
+			reason: "We should only discard the deleted keys from a pending write.",
+			delete: &store.Secret{ScopedName: n, Data: store.KeyValues{"a": nil}},
+			want: want{
+				persisted: map[store.ScopedName]store.KeyValues{n: {"b": []byte("b")}},
+			},
+		},
+		"NoKeys": {
+			reason: "We should not discard anything from a pending write when no keys are deleted.",
+			delete: &store.Secret{ScopedName: n, Data: store.KeyValues{}},
+			want: want{
+				persisted: map[store.ScopedName]store.KeyValues{n: {"a": []byte("a"), "b": []byte("b")}},
+			},
+		},
+		"AllKeys": {
+			reason: "We should discard a pending write when every key it would write is deleted.",
+			delete: &store.Secret{ScopedName: n, Data: store.KeyValues{"a": nil, "b": nil}},
+			want: want{
+				persisted: map[store.ScopedName]store.KeyValues{},
+			},
+		},
+		"Secret": {
+			reason: "We should discard a pending write when the whole secret is deleted.",
+			delete: &store.Secret{ScopedName: n},
+			want: want{
+				persisted: map[store.ScopedName]store.KeyValues{},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			persisted := map[store.ScopedName]store.KeyValues{}
+			s := memoryStore(persisted, true)
+			s.DeleteKeyValuesFn = func(_ context.Context, _ *store.Secret, _ ...store.DeleteOption) error { return nil }
+			bs := NewBufferedStore(s)
+
+			_, _ = bs.WriteKeyValues(context.Background(), &store.Secret{ScopedName: n, Data: store.KeyValues{"a": []byte("a"), "b": []byte("b")}})
+			if err := bs.DeleteKeyValues(context.Background(), tc.delete); err != nil {
+				t.Fatalf("\n%s\nbs.DeleteKeyValues(...): %v", tc.reason, err)
+			}
+			if err := bs.Flush(context.Background()); err != nil {
+				t.Fatalf("\n%s\nbs.Flush(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.persisted, persisted); diff != "" {
+				t.Errorf("\n%s\nbs.Flush(...): -want persisted, +got persisted:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestBufferedStoreFlushFailure(t *testing.T) {
+	a := &store.Secret{ScopedName: store.ScopedName{Name: "a", Scope: "ns"}, Data: store.KeyValues{"key": []byte("a")}}
+	b := &store.Secret{ScopedName: store.ScopedName{Name: "b", Scope: "ns"}, Data: store.KeyValues{"key": []byte("b")}}
+
+	persisted := map[store.ScopedName]store.KeyValues{}
+	s := memoryStore(persisted, true)
+	write := s.WriteKeyValuesFn
+	s.WriteKeyValuesFn = func(ctx context.Context, sec *store.Secret, wo ...store.WriteOption) (bool, error) {
+		if sec.ScopedName == a.ScopedName {
+			return false, errBoom
+		}
+		return write(ctx, sec, wo...)
+	}
+	bs := NewBufferedStore(s)
+	_, _ = bs.WriteKeyValues(context.Background(), a)
+	_, _ = bs.WriteKeyValues(context.Background(), b)
+
+	err := bs.Flush(context.Background())
+	want := errors.Wrap(errors.Join(errors.Wrapf(errBoom, errFmtFlushSecret, "ns", "a")), errFlush)
+	if diff := cmp.Diff(want, err, test.EquateErrors()); diff != "" {
+		t.Errorf("bs.Flush(...): -want error, +got error:\n%s", diff)
+	}
+	if diff := cmp.Diff(map[store.ScopedName]store.KeyValues{b.ScopedName: b.Data}, persisted); diff != "" {
+		t.Errorf("bs.Flush(...): a failed write should not prevent later writes from being flushed: -want persisted, +got persisted:\n%s", diff)
+	}
+}
+
+func TestBufferedStoreFlushThenDelete(t *testing.T) {
+	n := store.ScopedName{Name: "cool", Scope: "ns"}
+
+	var mx sync.Mutex
+	var ops []string
+	record := func(op string) {
+		mx.Lock()
+		defer mx.Unlock()
+		ops = append(ops, op)
+	}
+
+	writing, release := make(chan struct{}), make(chan struct{})
+	bs := NewBufferedStore(&fake.SecretStore{
+		WriteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.WriteOption) (bool, error) {
+			close(writing)
+			<-release
+			record("write")
+			return true, nil
+		},
+		DeleteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.DeleteOption) error {
+			record("delete")
+			return nil
+		},
+	})
+	_, _ = bs.WriteKeyValues(context.Background(), &store.Secret{ScopedName: n, Data: store.KeyValues{"key": []byte("v")}})
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_ = bs.Flush(context.Background())
+	}()
+	<-writing
+	go func() {
+		defer wg.Done()
+		_ = bs.DeleteKeyValues(context.Background(), &store.Secret{ScopedName: n})
+	}()
+
+	// Give the delete a chance to overtake the write, if it can.
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if diff := cmp.Diff([]string{"write", "delete"}, ops); diff != "" {
+		t.Errorf("bs.DeleteKeyValues(...): a delete should not reach the wrapped store while the secret is flushed: -want, +got:\n%s", diff)
+	}
+}
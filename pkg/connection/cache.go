@@ -0,0 +1,270 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+)
+
+// DefaultCacheTTL is the default duration for which a CachingStore serves a
+// cached read.
+const DefaultCacheTTL = 30 * time.Second
+
+// DefaultCacheMaxEntries is the default number of secrets a CachingStore
+// caches.
+const DefaultCacheMaxEntries = 1024
+
+// A CachingStoreOption configures a CachingStore.
+type CachingStoreOption func(*CachingStore)
+
+// WithCacheTTL configures the duration for which a CachingStore serves a
+// cached read before reading through to the Store it wraps.
+func WithCacheTTL(ttl time.Duration) CachingStoreOption {
+	return func(c *CachingStore) {
+		c.ttl = ttl
+	}
+}
+
+// WithCacheMaxEntries configures the number of secrets a CachingStore caches.
+// Once the cache is full, caching another secret evicts the secrets whose TTL
+// has passed, or, if there are none, the secret that was cached first.
+func WithCacheMaxEntries(n int) CachingStoreOption {
+	return func(c *CachingStore) {
+		c.maxEntries = n
+	}
+}
+
+// WithCacheClock configures the Clock a CachingStore uses to tell the age of
+// cached reads. store.RealClock is used by default.
+func WithCacheClock(c store.Clock) CachingStoreOption {
+	return func(cs *CachingStore) {
+		cs.clock = c
+	}
+}
+
+type cacheEntry struct {
+	secret   *store.Secret
+	observed time.Time
+}
+
+// A CachingStore is a Store that caches the secrets it reads from the Store it
+// wraps. Cached secrets are invalidated when they are written or deleted via
+// the CachingStore. A read may bound the staleness of the secret it is served
+// using the store.MaxStaleness ReadOption. Immutable secrets can never be
+// stale, so they are cached until they are deleted via the CachingStore or
+// evicted to make room for other secrets.
+type CachingStore struct {
+	store      Store
+	ttl        time.Duration
+	maxEntries int
+	clock      store.Clock
+
+	mx      sync.RWMutex
+	entries map[store.ScopedName]cacheEntry
+
+	// reads tracks the reads in flight for each secret, so that a read that
+	// started before a secret was invalidated doesn't cache it.
+	reads map[store.ScopedName]*cacheReads
+}
+
+// cacheReads tracks the reads in flight for a secret. Its generation counts how
+// often the secret was invalidated while they were in flight.
+type cacheReads struct {
+	generation uint64
+	inflight   int
+}
+
+// NewCachingStore returns a CachingStore that wraps the supplied Store.
+func NewCachingStore(s Store, o ...CachingStoreOption) *CachingStore {
+	c := &CachingStore{
+		store:      s,
+		ttl:        DefaultCacheTTL,
+		maxEntries: DefaultCacheMaxEntries,
+		clock:      store.RealClock,
+		entries:    make(map[store.ScopedName]cacheEntry),
+		reads:      make(map[store.ScopedName]*cacheReads),
+	}
+	for _, fn := range o {
+		fn(c)
+	}
+	return c
+}
+
+// Unwrap returns the Store the CachingStore wraps.
+func (c *CachingStore) Unwrap() Store {
+	return c.store
+}
+
+// ReadKeyValues reads key values from the cache if a sufficiently fresh copy
+// of the secret is cached, and from the wrapped Store otherwise. Strongly
+// consistent reads of mutable secrets always read from the wrapped Store, as
+// do reads of a cached secret that a store.MaxStaleness ReadOption finds stale.
+func (c *CachingStore) ReadKeyValues(ctx context.Context, n store.ScopedName, s *store.Secret, ro ...store.ReadOption) error {
+	if cached, age, ok := c.get(ctx, n); ok {
+		err := store.ApplyReadOptions(store.WithObservedAge(ctx, age), cached, ro...)
+		if !store.IsStale(err) {
+			*s = *cached
+			return err
+		}
+	}
+
+	gen := c.startRead(n)
+	live := &store.Secret{}
+	if err := c.store.ReadKeyValues(ctx, n, live); err != nil {
+		c.endRead(n, nil, gen)
+		return err
+	}
+	c.endRead(n, live, gen)
+
+	*s = *live.DeepCopy()
+	return store.ApplyReadOptions(ctx, s, ro...)
+}
+
+// WriteKeyValues writes key values to the wrapped Store, and invalidates any
+// cached copy of the secret unless it is immutable.
+func (c *CachingStore) WriteKeyValues(ctx context.Context, s *store.Secret, wo ...store.WriteOption) (bool, error) {
+	defer c.invalidateMutable(s.ScopedName)
+	return c.store.WriteKeyValues(ctx, s, wo...)
+}
+
+// DeleteKeyValues deletes key values from the wrapped Store, and invalidates
+// any cached copy of the secret.
+func (c *CachingStore) DeleteKeyValues(ctx context.Context, s *store.Secret, do ...store.DeleteOption) error {
+	defer c.invalidate(s.ScopedName)
+	return c.store.DeleteKeyValues(ctx, s, do...)
+}
+
+// Shutdown discards all cached secrets, and shuts down the wrapped Store if it
+// is, or wraps, a Shutdowner.
+func (c *CachingStore) Shutdown(ctx context.Context) error {
+	c.mx.Lock()
+	c.entries = make(map[store.ScopedName]cacheEntry)
+	c.mx.Unlock()
+
+	if sd, ok := Find[Shutdowner](c.store); ok {
+		return sd.Shutdown(ctx)
+	}
+	return nil
+}
+
+// get returns a copy of the cached secret, and how long ago it was observed.
+// Immutable secrets are never stale, so they are returned with no age.
+func (c *CachingStore) get(ctx context.Context, n store.ScopedName) (*store.Secret, time.Duration, bool) {
+	c.mx.RLock()
+	e, ok := c.entries[n]
+	c.mx.RUnlock()
+	if !ok {
+		return nil, 0, false
+	}
+
+	if e.secret.Immutable {
+		return e.secret.DeepCopy(), 0, true
+	}
+
+	// A strongly consistent read must reflect writes made by other clients of
+	// the wrapped Store, so it can't be served from the cache.
+	if store.GetConsistency(ctx) == store.ConsistencyStrong {
+		return nil, 0, false
+	}
+
+	age := c.clock.Now().Sub(e.observed)
+	if age > c.ttl {
+		return nil, 0, false
+	}
+	return e.secret.DeepCopy(), age, true
+}
+
+// startRead records a read of the supplied secret as in flight, and returns
+// the generation it started at.
+func (c *CachingStore) startRead(n store.ScopedName) uint64 {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	r, ok := c.reads[n]
+	if !ok {
+		r = &cacheReads{}
+		c.reads[n] = r
+	}
+	r.inflight++
+	return r.generation
+}
+
+// endRead records a read of the supplied secret as done, and caches the
+// supplied secret unless it is nil or was invalidated since the supplied
+// generation. The secret may then predate the write that invalidated it.
+func (c *CachingStore) endRead(n store.ScopedName, s *store.Secret, gen uint64) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	r := c.reads[n]
+	if s != nil && r.generation == gen {
+		if _, cached := c.entries[n]; !cached {
+			c.evict()
+		}
+		c.entries[n] = cacheEntry{secret: s.DeepCopy(), observed: c.clock.Now()}
+	}
+	if r.inflight--; r.inflight == 0 {
+		delete(c.reads, n)
+	}
+}
+
+// evict makes room for another cached secret if the cache is full, by evicting
+// the secrets whose TTL has passed, or else the secret that was cached first.
+// It must be called with the lock held.
+func (c *CachingStore) evict() {
+	if c.maxEntries <= 0 || len(c.entries) < c.maxEntries {
+		return
+	}
+	now := c.clock.Now()
+	var oldest store.ScopedName
+	var oldestObserved time.Time
+	for n, e := range c.entries {
+		if !e.secret.Immutable && now.Sub(e.observed) > c.ttl {
+			delete(c.entries, n)
+			continue
+		}
+		if oldestObserved.IsZero() || e.observed.Before(oldestObserved) {
+			oldest, oldestObserved = n, e.observed
+		}
+	}
+	if len(c.entries) >= c.maxEntries {
+		delete(c.entries, oldest)
+	}
+}
+
+func (c *CachingStore) invalidate(n store.ScopedName) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if r, ok := c.reads[n]; ok {
+		r.generation++
+	}
+	delete(c.entries, n)
+}
+
+func (c *CachingStore) invalidateMutable(n store.ScopedName) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if e, ok := c.entries[n]; ok && e.secret.Immutable {
+		return
+	}
+	if r, ok := c.reads[n]; ok {
+		r.generation++
+	}
+	delete(c.entries, n)
+}
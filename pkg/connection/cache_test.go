@@ -0,0 +1,285 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+)
+
+func TestCachingStoreReadKeyValues(t *testing.T) {
+	n := store.ScopedName{Name: "cool", Scope: "ns"}
+	now := time.Now()
+
+	type args struct {
+		age time.Duration
+		ctx context.Context
+		ro  []store.ReadOption
+	}
+	type want struct {
+		reads int
+		data  store.KeyValues
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Fresh": {
+			reason: "We should serve a cached read that is younger than the maximum staleness.",
+			args: args{
+				age: 5 * time.Second,
+				ctx: context.Background(),
+				ro:  []store.ReadOption{store.MaxStaleness(10 * time.Second)},
+			},
+			want: want{
+				reads: 1,
+				data:  store.KeyValues{"key": []byte("1")},
+			},
+		},
+		"Stale": {
+			reason: "We should read through if a cached read is older than the maximum staleness.",
+			args: args{
+				age: 15 * time.Second,
+				ctx: context.Background(),
+				ro:  []store.ReadOption{store.MaxStaleness(10 * time.Second)},
+			},
+			want: want{
+				reads: 2,
+				data:  store.KeyValues{"key": []byte("2")},
+			},
+		},
+		"WithinTTL": {
+			reason: "We should serve a cached read that is younger than the TTL if no maximum staleness is supplied.",
+			args: args{
+				age: 15 * time.Second,
+				ctx: context.Background(),
+			},
+			want: want{
+				reads: 1,
+				data:  store.KeyValues{"key": []byte("1")},
+			},
+		},
+		"StrongConsistency": {
+			reason: "We should read through if a strongly consistent read is requested.",
+			args: args{
+				age: time.Second,
+				ctx: store.WithConsistency(context.Background(), store.ConsistencyStrong),
+			},
+			want: want{
+				reads: 2,
+				data:  store.KeyValues{"key": []byte("2")},
+			},
+		},
+		"Expired": {
+			reason: "We should read through if a cached read is older than the TTL.",
+			args: args{
+				age: DefaultCacheTTL + time.Second,
+				ctx: context.Background(),
+			},
+			want: want{
+				reads: 2,
+				data:  store.KeyValues{"key": []byte("2")},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			reads := 0
+			clock := fake.NewClock(now)
+			c := NewCachingStore(&fake.SecretStore{
+				ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
+					reads++
+					s.Data = store.KeyValues{"key": []byte{byte('0' + reads)}}
+					return nil
+				},
+			}, WithCacheClock(clock))
+
+			if err := c.ReadKeyValues(context.Background(), n, &store.Secret{}); err != nil {
+				t.Fatalf("c.ReadKeyValues(...): %v", err)
+			}
+
+			clock.Step(tc.args.age)
+			s := &store.Secret{}
+			if err := c.ReadKeyValues(tc.args.ctx, n, s, tc.args.ro...); err != nil {
+				t.Fatalf("c.ReadKeyValues(...): %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want.reads, reads); diff != "" {
+				t.Errorf("\n%s\nc.ReadKeyValues(...): -want reads, +got reads:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.data, s.Data); diff != "" {
+				t.Errorf("\n%s\nc.ReadKeyValues(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCachingStoreInvalidation(t *testing.T) {
+	n := store.ScopedName{Name: "cool", Scope: "ns"}
+	reads := 0
+	c := NewCachingStore(&fake.SecretStore{
+		ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, _ *store.Secret, _ ...store.ReadOption) error {
+			reads++
+			return nil
+		},
+		WriteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.WriteOption) (bool, error) {
+			return true, nil
+		},
+		DeleteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.DeleteOption) error {
+			return nil
+		},
+	})
+
+	_ = c.ReadKeyValues(context.Background(), n, &store.Secret{})
+	_, _ = c.WriteKeyValues(context.Background(), &store.Secret{ScopedName: n})
+	_ = c.ReadKeyValues(context.Background(), n, &store.Secret{})
+	_ = c.DeleteKeyValues(context.Background(), &store.Secret{ScopedName: n})
+	_ = c.ReadKeyValues(context.Background(), n, &store.Secret{})
+
+	if reads != 3 {
+		t.Errorf("c.ReadKeyValues(...): want 3 reads through to the wrapped store, got %d", reads)
+	}
+}
+
+func TestCachingStoreConcurrentWrite(t *testing.T) {
+	n := store.ScopedName{Name: "cool", Scope: "ns"}
+	var c *CachingStore
+	reads := 0
+	c = NewCachingStore(&fake.SecretStore{
+		ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
+			reads++
+			s.Data = store.KeyValues{"key": []byte{byte('0' + reads)}}
+			if reads == 1 {
+				// Write the secret after the first read read it, but before
+				// it returned, as a concurrent write could.
+				_, _ = c.WriteKeyValues(context.Background(), &store.Secret{ScopedName: n})
+			}
+			return nil
+		},
+		WriteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.WriteOption) (bool, error) {
+			return true, nil
+		},
+	})
+
+	_ = c.ReadKeyValues(context.Background(), n, &store.Secret{})
+
+	// The first read predates the write, so it must not have been cached.
+	s := &store.Secret{}
+	_ = c.ReadKeyValues(context.Background(), n, s)
+	if reads != 2 {
+		t.Errorf("c.ReadKeyValues(...): want a read that raced a write not to be cached, got %d reads", reads)
+	}
+	if diff := cmp.Diff(store.KeyValues{"key": []byte("2")}, s.Data); diff != "" {
+		t.Errorf("c.ReadKeyValues(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestCachingStoreImmutable(t *testing.T) {
+	n := store.ScopedName{Name: "cool", Scope: "ns"}
+	clock := fake.NewClock(time.Now())
+	reads := 0
+	c := NewCachingStore(&fake.SecretStore{
+		ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
+			reads++
+			s.Data = store.KeyValues{"key": []byte("v")}
+			s.Immutable = true
+			return nil
+		},
+		WriteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.WriteOption) (bool, error) {
+			return false, nil
+		},
+		DeleteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.DeleteOption) error {
+			return nil
+		},
+	}, WithCacheClock(clock))
+
+	_ = c.ReadKeyValues(context.Background(), n, &store.Secret{})
+
+	// Neither the TTL, the maximum staleness, nor a write should cause an
+	// immutable secret to be read through.
+	clock.Step(10 * DefaultCacheTTL)
+	_ = c.ReadKeyValues(context.Background(), n, &store.Secret{}, store.MaxStaleness(time.Second))
+	_, _ = c.WriteKeyValues(context.Background(), &store.Secret{ScopedName: n})
+
+	s := &store.Secret{}
+	_ = c.ReadKeyValues(context.Background(), n, s)
+	if reads != 1 {
+		t.Errorf("c.ReadKeyValues(...): want immutable secret to be read through once, got %d reads", reads)
+	}
+	if diff := cmp.Diff(store.KeyValues{"key": []byte("v")}, s.Data); diff != "" {
+		t.Errorf("c.ReadKeyValues(...): -want, +got:\n%s", diff)
+	}
+
+	_ = c.DeleteKeyValues(context.Background(), &store.Secret{ScopedName: n})
+	_ = c.ReadKeyValues(context.Background(), n, &store.Secret{})
+	if reads != 2 {
+		t.Errorf("c.ReadKeyValues(...): want a delete to invalidate an immutable secret, got %d reads", reads)
+	}
+}
+
+func TestCachingStoreEviction(t *testing.T) {
+	clock := fake.NewClock(time.Now())
+	reads := map[string]int{}
+	c := NewCachingStore(&fake.SecretStore{
+		ReadKeyValuesFn: func(_ context.Context, n store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
+			reads[n.Name]++
+			s.Immutable = n.Name != "mutable"
+			return nil
+		},
+	}, WithCacheClock(clock), WithCacheMaxEntries(2))
+
+	read := func(name string) {
+		_ = c.ReadKeyValues(context.Background(), store.ScopedName{Name: name, Scope: "ns"}, &store.Secret{})
+		clock.Step(time.Second)
+	}
+
+	// Caching a third secret should evict the secret that was cached first,
+	// even though it is immutable.
+	read("a")
+	read("b")
+	read("c")
+	read("b")
+	read("a")
+	if diff := cmp.Diff(map[string]int{"a": 2, "b": 1, "c": 1}, reads); diff != "" {
+		t.Errorf("c.ReadKeyValues(...): -want reads, +got reads:\n%s", diff)
+	}
+
+	// A secret whose TTL has passed should be evicted before any other.
+	clear(reads)
+	c = NewCachingStore(c.store, WithCacheClock(clock), WithCacheMaxEntries(2))
+	read("a")
+	read("mutable")
+	clock.Step(DefaultCacheTTL)
+	read("b")
+	read("a")
+	if diff := cmp.Diff(map[string]int{"a": 1, "b": 1, "mutable": 1}, reads); diff != "" {
+		t.Errorf("c.ReadKeyValues(...): -want reads, +got reads:\n%s", diff)
+	}
+
+	if len(c.reads) != 0 {
+		t.Errorf("c.ReadKeyValues(...): want no reads tracked once none are in flight, got %d", len(c.reads))
+	}
+}
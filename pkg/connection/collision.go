@@ -0,0 +1,171 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"sync"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+)
+
+// Error strings.
+const (
+	errFmtCollision = "secret %q in scope %q is already written for owner %q, refusing to write it for owner %q"
+)
+
+// A CollisionPolicy determines what a CollisionDetectingStore does when two
+// owners write the same secret.
+type CollisionPolicy string
+
+// Collision policies.
+const (
+	// CollisionPolicyWarn logs a warning and writes the secret.
+	CollisionPolicyWarn CollisionPolicy = "Warn"
+
+	// CollisionPolicyError returns an error and does not write the secret.
+	CollisionPolicyError CollisionPolicy = "Error"
+)
+
+// A CollisionDetectingStoreOption configures a CollisionDetectingStore.
+type CollisionDetectingStoreOption func(*CollisionDetectingStore)
+
+// WithCollisionPolicy configures the CollisionPolicy of a
+// CollisionDetectingStore. CollisionPolicyWarn is used by default.
+func WithCollisionPolicy(p CollisionPolicy) CollisionDetectingStoreOption {
+	return func(s *CollisionDetectingStore) {
+		s.policy = p
+	}
+}
+
+// WithCollisionLogger configures the logger a CollisionDetectingStore warns
+// about collisions with.
+func WithCollisionLogger(l logging.Logger) CollisionDetectingStoreOption {
+	return func(s *CollisionDetectingStore) {
+		s.log = l
+	}
+}
+
+// A CollisionDetectingStore is a Store that detects when the secrets of two
+// distinct owners resolve to the same secret, for example because of a name
+// factory or a scope fallback, so that they would silently clobber each
+// other. Each secret is claimed by the first owner that successfully writes
+// it, and released when the whole secret is deleted. Collisions are only
+// detected between writes made through the same CollisionDetectingStore, i.e.
+// within a process. Secrets without an owner are never considered to collide.
+type CollisionDetectingStore struct {
+	store  Store
+	policy CollisionPolicy
+	log    logging.Logger
+
+	mu     sync.Mutex
+	owners map[store.ScopedName]string
+}
+
+// NewCollisionDetectingStore returns a CollisionDetectingStore that wraps the
+// supplied Store.
+func NewCollisionDetectingStore(s Store, o ...CollisionDetectingStoreOption) *CollisionDetectingStore {
+	cs := &CollisionDetectingStore{
+		store:  s,
+		policy: CollisionPolicyWarn,
+		log:    logging.NewNopLogger(),
+		owners: make(map[store.ScopedName]string),
+	}
+	for _, fn := range o {
+		fn(cs)
+	}
+	return cs
+}
+
+// Unwrap returns the Store the CollisionDetectingStore wraps.
+func (s *CollisionDetectingStore) Unwrap() Store {
+	return s.store
+}
+
+// ReadKeyValues reads key values from the wrapped Store.
+func (s *CollisionDetectingStore) ReadKeyValues(ctx context.Context, n store.ScopedName, sec *store.Secret, ro ...store.ReadOption) error {
+	return s.store.ReadKeyValues(ctx, n, sec, ro...)
+}
+
+// WriteKeyValues writes key values to the wrapped Store, unless the secret is
+// already claimed by another owner and the CollisionPolicy is
+// CollisionPolicyError.
+func (s *CollisionDetectingStore) WriteKeyValues(ctx context.Context, sec *store.Secret, wo ...store.WriteOption) (bool, error) {
+	claimed, err := s.claim(sec)
+	if err != nil {
+		return false, err
+	}
+	changed, err := s.store.WriteKeyValues(ctx, sec, wo...)
+	if err != nil && claimed {
+		// An owner whose write failed, for example because it doesn't own
+		// the secret, mustn't keep the claim from the secret's real owner.
+		s.release(sec.ScopedName, sec.GetOwner())
+	}
+	return changed, err
+}
+
+// DeleteKeyValues deletes key values from the wrapped Store. Deleting a whole
+// secret, i.e. nil key values, releases its claim.
+func (s *CollisionDetectingStore) DeleteKeyValues(ctx context.Context, sec *store.Secret, do ...store.DeleteOption) error {
+	if err := s.store.DeleteKeyValues(ctx, sec, do...); err != nil {
+		return err
+	}
+	if sec.Data == nil {
+		s.mu.Lock()
+		delete(s.owners, sec.ScopedName)
+		s.mu.Unlock()
+	}
+	return nil
+}
+
+// claim the supplied secret for its owner. It returns true if the secret was
+// newly claimed.
+func (s *CollisionDetectingStore) claim(sec *store.Secret) (bool, error) {
+	owner := sec.GetOwner()
+	if owner == "" {
+		return false, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	claimed, ok := s.owners[sec.ScopedName]
+	if !ok {
+		s.owners[sec.ScopedName] = owner
+		return true, nil
+	}
+	if claimed == owner {
+		return false, nil
+	}
+	if s.policy == CollisionPolicyError {
+		return false, errors.Errorf(errFmtCollision, sec.Name, sec.Scope, claimed, owner)
+	}
+	s.log.Info("Secrets of two owners resolve to the same secret, and will clobber each other", "secret-name", sec.Name, "secret-scope", sec.Scope, "owner", claimed, "other-owner", owner)
+	return false, nil
+}
+
+// release the claim of the supplied owner on the supplied secret, if it still
+// holds it.
+func (s *CollisionDetectingStore) release(n store.ScopedName, owner string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.owners[n] == owner {
+		delete(s.owners, n)
+	}
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/types"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func ownedSecret(n store.ScopedName, uid string) *store.Secret {
+	meta := &v1.ConnectionSecretMetadata{}
+	meta.SetOwnerUID(types.UID(uid))
+	return &store.Secret{ScopedName: n, Metadata: meta, Data: store.KeyValues{"key": []byte("value")}}
+}
+
+func TestCollisionDetectingStoreWriteKeyValues(t *testing.T) {
+	n := store.ScopedName{Name: "cool", Scope: "ns"}
+
+	type args struct {
+		policy CollisionPolicy
+		writes []*store.Secret
+
+		// deleteAfterFirst deletes the supplied key values of the secret,
+		// or the whole secret if deleteData is nil, after the first write.
+		deleteAfterFirst bool
+		deleteData       store.KeyValues
+
+		// failFirst fails the first write.
+		failFirst bool
+	}
+	type want struct {
+		written int
+		warned  int
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"SameOwner": {
+			reason: "We should not report a collision when the same owner writes a secret again.",
+			args: args{
+				policy: CollisionPolicyError,
+				writes: []*store.Secret{ownedSecret(n, "a"), ownedSecret(n, "a")},
+			},
+			want: want{
+				written: 2,
+			},
+		},
+		"DistinctSecrets": {
+			reason: "We should not report a collision when two owners write distinct secrets.",
+			args: args{
+				policy: CollisionPolicyError,
+				writes: []*store.Secret{ownedSecret(n, "a"), ownedSecret(store.ScopedName{Name: "other", Scope: "ns"}, "b")},
+			},
+			want: want{
+				written: 2,
+			},
+		},
+		"CollisionWarn": {
+			reason: "We should warn about, but write, a secret another owner already wrote when using the warn policy.",
+			args: args{
+				writes: []*store.Secret{ownedSecret(n, "a"), ownedSecret(n, "b")},
+			},
+			want: want{
+				written: 2,
+				warned:  1,
+			},
+		},
+		"CollisionError": {
+			reason: "We should refuse to write a secret another owner already wrote when using the error policy.",
+			args: args{
+				policy: CollisionPolicyError,
+				writes: []*store.Secret{ownedSecret(n, "a"), ownedSecret(n, "b")},
+			},
+			want: want{
+				written: 1,
+				err:     errors.Errorf(errFmtCollision, "cool", "ns", "a", "b"),
+			},
+		},
+		"ReleasedByDelete": {
+			reason: "We should not report a collision when another owner writes a secret that was deleted.",
+			args: args{
+				policy:           CollisionPolicyError,
+				writes:           []*store.Secret{ownedSecret(n, "a"), ownedSecret(n, "b")},
+				deleteAfterFirst: true,
+			},
+			want: want{
+				written: 2,
+			},
+		},
+		"NotReleasedByNoopDelete": {
+			reason: "We should report a collision when another owner writes a secret whose keys were deleted, but not the whole secret.",
+			args: args{
+				policy:           CollisionPolicyError,
+				writes:           []*store.Secret{ownedSecret(n, "a"), ownedSecret(n, "b")},
+				deleteAfterFirst: true,
+				deleteData:       store.KeyValues{},
+			},
+			want: want{
+				written: 1,
+				err:     errors.Errorf(errFmtCollision, "cool", "ns", "a", "b"),
+			},
+		},
+		"NotClaimedByFailedWrite": {
+			reason: "We should not report a collision when another owner writes a secret whose first write failed.",
+			args: args{
+				policy:    CollisionPolicyError,
+				writes:    []*store.Secret{ownedSecret(n, "a"), ownedSecret(n, "b")},
+				failFirst: true,
+			},
+			want: want{
+				written: 2,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			written := 0
+			logged := [][]any{}
+			o := []CollisionDetectingStoreOption{WithCollisionLogger(capturingLogger{out: &logged})}
+			if tc.args.policy != "" {
+				o = append(o, WithCollisionPolicy(tc.args.policy))
+			}
+			s := NewCollisionDetectingStore(&fake.SecretStore{
+				WriteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.WriteOption) (bool, error) {
+					written++
+					if written == 1 && tc.args.failFirst {
+						return false, errBoom
+					}
+					return true, nil
+				},
+				DeleteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.DeleteOption) error {
+					return nil
+				},
+			}, o...)
+
+			var err error
+			for i, sec := range tc.args.writes {
+				_, err = s.WriteKeyValues(context.Background(), sec)
+				if i == 0 && tc.args.deleteAfterFirst {
+					if err := s.DeleteKeyValues(context.Background(), &store.Secret{ScopedName: sec.ScopedName, Data: tc.args.deleteData}); err != nil {
+						t.Fatalf("s.DeleteKeyValues(...): %v", err)
+					}
+				}
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ns.WriteKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.written, written); diff != "" {
+				t.Errorf("\n%s\ns.WriteKeyValues(...): -want writes, +got writes:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.warned, len(logged)); diff != "" {
+				t.Errorf("\n%s\ns.WriteKeyValues(...): -want warnings, +got warnings:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
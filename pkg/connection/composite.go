@@ -0,0 +1,285 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errNoStores = "composite store has no stores"
+)
+
+// A NamedStore is a Store identified by a name, typically the name of the
+// StoreConfig it was built from.
+type NamedStore struct {
+	Name  string
+	Store Store
+}
+
+// A StoreError is an error returned by a named Store.
+type StoreError struct {
+	Store string
+	Err   error
+}
+
+// A PartialError reports the outcome of an operation that failed for some of
+// the Stores of a CompositeStore. Callers may use it to retry the operation
+// against only the Stores that failed.
+type PartialError struct {
+	// Operation that failed, e.g. "write" or "delete".
+	Operation string
+
+	// Succeeded lists the names of the Stores the operation succeeded for.
+	Succeeded []string
+
+	// Failed lists the Stores the operation failed for, and why.
+	Failed []StoreError
+
+	// NotAttempted lists the names of the Stores the operation was not
+	// attempted for, because it failed for an earlier Store.
+	NotAttempted []string
+
+	// Tolerated is true if the operation counts as succeeded despite failing
+	// for some Stores, per the CompositeWritePolicy.
+	Tolerated bool
+}
+
+func (e *PartialError) Error() string {
+	msgs := make([]string, len(e.Failed))
+	for i, f := range e.Failed {
+		msgs[i] = fmt.Sprintf("%s: %s", f.Store, f.Err)
+	}
+	total := len(e.Failed) + len(e.Succeeded) + len(e.NotAttempted)
+	if len(e.NotAttempted) > 0 {
+		return fmt.Sprintf("cannot %s %d of %d stores (%d not attempted): [%s]", e.Operation, len(e.Failed), total, len(e.NotAttempted), strings.Join(msgs, ", "))
+	}
+	return fmt.Sprintf("cannot %s %d of %d stores: [%s]", e.Operation, len(e.Failed), total, strings.Join(msgs, ", "))
+}
+
+// Unwrap returns the errors returned by the Stores that failed.
+func (e *PartialError) Unwrap() []error {
+	errs := make([]error, len(e.Failed))
+	for i, f := range e.Failed {
+		errs[i] = f.Err
+	}
+	return errs
+}
+
+// IgnoreTolerated returns nil if the supplied error is a *PartialError whose
+// failures are tolerated, and the supplied error otherwise.
+func IgnoreTolerated(err error) error {
+	pe := &PartialError{}
+	if errors.As(err, &pe) && pe.Tolerated {
+		return nil
+	}
+	return err
+}
+
+// A CompositeWritePolicy determines whether a write or delete operation
+// against a CompositeStore must succeed for all of its Stores. A PartialError
+// is returned whenever an operation fails for any Store, so that callers may
+// retry it against the Stores that failed. The policy only determines whether
+// the PartialError is Tolerated, i.e. whether the operation counts as failed.
+type CompositeWritePolicy string
+
+// Composite write policies.
+const (
+	// CompositeWriteRequireAll requires an operation to succeed for all
+	// Stores.
+	CompositeWriteRequireAll CompositeWritePolicy = "RequireAll"
+
+	// CompositeWriteBestEffort requires an operation to succeed for at least
+	// one Store. The PartialError of an operation that fails for some but not
+	// all Stores is Tolerated.
+	CompositeWriteBestEffort CompositeWritePolicy = "BestEffort"
+)
+
+// A CompositeStoreOption configures a CompositeStore.
+type CompositeStoreOption func(*CompositeStore)
+
+// WithCompositeWritePolicy configures the CompositeWritePolicy of a
+// CompositeStore. CompositeWriteRequireAll is used by default.
+func WithCompositeWritePolicy(p CompositeWritePolicy) CompositeStoreOption {
+	return func(c *CompositeStore) {
+		c.policy = p
+	}
+}
+
+// WithCompositeConcurrency configures how many Stores a CompositeStore writes
+// to or deletes from concurrently. Stores are written to one at a time by
+// default.
+func WithCompositeConcurrency(n int) CompositeStoreOption {
+	return func(c *CompositeStore) {
+		c.concurrency = n
+	}
+}
+
+// WithCompositeDeleteOrder configures a CompositeStore to delete from its
+// Stores one at a time, in the supplied order of Store names, for example to
+// delete from the Store being migrated to before the Store being migrated
+// from. Stores that are not named are deleted from last, in Store order.
+// Deleting stops at the first Store that fails; the Stores that follow it are
+// reported as not attempted. By default deletes respect
+// WithCompositeConcurrency.
+func WithCompositeDeleteOrder(names ...string) CompositeStoreOption {
+	return func(c *CompositeStore) {
+		c.deleteOrder = names
+	}
+}
+
+// A CompositeStore is a Store that fans writes and deletes out to multiple
+// Stores. Reads are served by the first Store.
+type CompositeStore struct {
+	stores      []NamedStore
+	policy      CompositeWritePolicy
+	concurrency int
+	deleteOrder []string
+}
+
+// NewCompositeStore returns a CompositeStore that fans out to the supplied
+// Stores.
+func NewCompositeStore(stores []NamedStore, o ...CompositeStoreOption) *CompositeStore {
+	c := &CompositeStore{stores: stores, policy: CompositeWriteRequireAll, concurrency: 1}
+	for _, fn := range o {
+		fn(c)
+	}
+	return c
+}
+
+// ReadKeyValues reads key values from the first Store.
+func (c *CompositeStore) ReadKeyValues(ctx context.Context, n store.ScopedName, s *store.Secret, ro ...store.ReadOption) error {
+	if len(c.stores) == 0 {
+		return errors.New(errNoStores)
+	}
+	return c.stores[0].Store.ReadKeyValues(ctx, n, s, ro...)
+}
+
+// WriteKeyValues writes key values to all Stores. It returns true if the
+// secret was changed in any Store.
+func (c *CompositeStore) WriteKeyValues(ctx context.Context, s *store.Secret, wo ...store.WriteOption) (bool, error) {
+	var changed atomic.Bool
+	err := c.each("write", func(ns NamedStore) error {
+		// Each Store gets its own copy, in case a write option mutates it.
+		ch, err := ns.Store.WriteKeyValues(ctx, s.DeepCopy(), wo...)
+		if ch {
+			changed.Store(true)
+		}
+		return err
+	})
+	return changed.Load(), err
+}
+
+// DeleteKeyValues deletes key values from all Stores, in the configured delete
+// order if any.
+func (c *CompositeStore) DeleteKeyValues(ctx context.Context, s *store.Secret, do ...store.DeleteOption) error {
+	fn := func(ns NamedStore) error {
+		return ns.Store.DeleteKeyValues(ctx, s.DeepCopy(), do...)
+	}
+	if len(c.deleteOrder) == 0 {
+		return c.each("delete", fn)
+	}
+	return c.inOrder("delete", orderStores(c.stores, c.deleteOrder), fn)
+}
+
+// each calls the supplied function for each Store, for up to the configured
+// number of Stores concurrently. Outcomes are reported in Store order.
+func (c *CompositeStore) each(op string, fn func(ns NamedStore) error) error {
+	return c.eachOf(op, c.stores, c.concurrency, fn)
+}
+
+// eachOf calls the supplied function for each of the supplied Stores, for up
+// to the supplied number of Stores concurrently. Outcomes are reported in the
+// order of the supplied Stores.
+func (c *CompositeStore) eachOf(op string, stores []NamedStore, concurrency int, fn func(ns NamedStore) error) error {
+	errs := make([]error, len(stores))
+	sem := make(chan struct{}, max(1, concurrency))
+	wg := sync.WaitGroup{}
+	for i, ns := range stores {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			errs[i] = fn(ns)
+		}()
+	}
+	wg.Wait()
+
+	pe := &PartialError{Operation: op}
+	for i, ns := range stores {
+		if errs[i] != nil {
+			pe.Failed = append(pe.Failed, StoreError{Store: ns.Name, Err: errs[i]})
+			continue
+		}
+		pe.Succeeded = append(pe.Succeeded, ns.Name)
+	}
+	return c.result(pe)
+}
+
+// inOrder calls the supplied function for each of the supplied Stores, one at
+// a time and in order, stopping at the first Store it fails for.
+func (c *CompositeStore) inOrder(op string, stores []NamedStore, fn func(ns NamedStore) error) error {
+	pe := &PartialError{Operation: op}
+	for i, ns := range stores {
+		if err := fn(ns); err != nil {
+			pe.Failed = append(pe.Failed, StoreError{Store: ns.Name, Err: err})
+			for _, rest := range stores[i+1:] {
+				pe.NotAttempted = append(pe.NotAttempted, rest.Name)
+			}
+			break
+		}
+		pe.Succeeded = append(pe.Succeeded, ns.Name)
+	}
+	return c.result(pe)
+}
+
+// orderStores returns the supplied Stores in the supplied order of Store names.
+// Stores that are not named follow, in their original order.
+func orderStores(stores []NamedStore, names []string) []NamedStore {
+	out := make([]NamedStore, 0, len(stores))
+	ordered := make(map[int]bool, len(names))
+	for _, n := range names {
+		for i, ns := range stores {
+			if ns.Name == n && !ordered[i] {
+				out = append(out, ns)
+				ordered[i] = true
+			}
+		}
+	}
+	for i, ns := range stores {
+		if !ordered[i] {
+			out = append(out, ns)
+		}
+	}
+	return out
+}
+
+func (c *CompositeStore) result(pe *PartialError) error {
+	if len(pe.Failed) == 0 {
+		return nil
+	}
+	pe.Tolerated = c.policy == CompositeWriteBestEffort && len(pe.Succeeded) > 0
+	return pe
+}
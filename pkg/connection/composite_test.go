@@ -0,0 +1,277 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func deleting(err error) NamedStore {
+	return NamedStore{Store: &fake.SecretStore{
+		DeleteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.DeleteOption) error { return err },
+	}}
+}
+
+func writing(changed bool, err error) NamedStore {
+	return NamedStore{Store: &fake.SecretStore{
+		WriteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.WriteOption) (bool, error) { return changed, err },
+	}}
+}
+
+func named(name string, ns NamedStore) NamedStore {
+	ns.Name = name
+	return ns
+}
+
+func TestCompositeStoreDeleteKeyValues(t *testing.T) {
+	errOther := errors.New("other")
+
+	cases := map[string]struct {
+		reason string
+		stores []NamedStore
+		o      []CompositeStoreOption
+		want   error
+	}{
+		"AllSucceeded": {
+			reason: "We should return no error if the delete succeeded for all stores.",
+			stores: []NamedStore{named("a", deleting(nil)), named("b", deleting(nil))},
+		},
+		"PartialFailure": {
+			reason: "We should report which stores succeeded and which failed.",
+			stores: []NamedStore{named("a", deleting(nil)), named("b", deleting(errBoom)), named("c", deleting(nil)), named("d", deleting(errOther))},
+			want: &PartialError{
+				Operation: "delete",
+				Succeeded: []string{"a", "c"},
+				Failed:    []StoreError{{Store: "b", Err: errBoom}, {Store: "d", Err: errOther}},
+			},
+		},
+		"BestEffortPartialFailure": {
+			reason: "We should report, but tolerate, a partial failure when using the best effort policy.",
+			stores: []NamedStore{named("a", deleting(nil)), named("b", deleting(errBoom))},
+			o:      []CompositeStoreOption{WithCompositeWritePolicy(CompositeWriteBestEffort)},
+			want: &PartialError{
+				Operation: "delete",
+				Succeeded: []string{"a"},
+				Failed:    []StoreError{{Store: "b", Err: errBoom}},
+				Tolerated: true,
+			},
+		},
+		"BestEffortTotalFailure": {
+			reason: "We should return an error if the delete failed for all stores when using the best effort policy.",
+			stores: []NamedStore{named("a", deleting(errBoom)), named("b", deleting(errOther))},
+			o:      []CompositeStoreOption{WithCompositeWritePolicy(CompositeWriteBestEffort)},
+			want: &PartialError{
+				Operation: "delete",
+				Failed:    []StoreError{{Store: "a", Err: errBoom}, {Store: "b", Err: errOther}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := NewCompositeStore(tc.stores, tc.o...)
+			err := c.DeleteKeyValues(context.Background(), &store.Secret{})
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nc.DeleteKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+
+			pe := &PartialError{}
+			if tc.want != nil && errors.As(err, &pe) {
+				if diff := cmp.Diff(tc.want, pe, test.EquateErrors()); diff != "" {
+					t.Errorf("\n%s\nc.DeleteKeyValues(...): -want partial error, +got partial error:\n%s", tc.reason, diff)
+				}
+				if !errors.Is(err, errBoom) {
+					t.Errorf("\n%s\nc.DeleteKeyValues(...): expected the partial error to wrap the store errors", tc.reason)
+				}
+				if (IgnoreTolerated(err) == nil) != pe.Tolerated {
+					t.Errorf("\n%s\nIgnoreTolerated(...): want nil only for a tolerated partial error", tc.reason)
+				}
+			}
+		})
+	}
+}
+
+func TestCompositeStoreWriteKeyValues(t *testing.T) {
+	type want struct {
+		changed bool
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		stores []NamedStore
+		want   want
+	}{
+		"AnyChanged": {
+			reason: "We should report a change if the secret changed in any store.",
+			stores: []NamedStore{named("a", writing(false, nil)), named("b", writing(true, nil))},
+			want: want{
+				changed: true,
+			},
+		},
+		"PartialFailure": {
+			reason: "We should report which stores succeeded and which failed.",
+			stores: []NamedStore{named("a", writing(true, nil)), named("b", writing(false, errBoom))},
+			want: want{
+				changed: true,
+				err: &PartialError{
+					Operation: "write",
+					Succeeded: []string{"a"},
+					Failed:    []StoreError{{Store: "b", Err: errBoom}},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := NewCompositeStore(tc.stores)
+			changed, err := c.WriteKeyValues(context.Background(), &store.Secret{})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nc.WriteKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.changed, changed); diff != "" {
+				t.Errorf("\n%s\nc.WriteKeyValues(...): -want changed, +got changed:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCompositeStoreConcurrency(t *testing.T) {
+	const n = 3
+
+	// Each store blocks until all stores are being written to concurrently.
+	started := make(chan struct{}, n)
+	barrier := func(err error) NamedStore {
+		return NamedStore{Store: &fake.SecretStore{
+			WriteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.WriteOption) (bool, error) {
+				started <- struct{}{}
+				deadline := time.After(5 * time.Second)
+				for len(started) < n {
+					select {
+					case <-deadline:
+						return false, errors.New("stores were not written to concurrently")
+					case <-time.After(time.Millisecond):
+					}
+				}
+				return err == nil, err
+			},
+		}}
+	}
+
+	c := NewCompositeStore([]NamedStore{
+		named("a", barrier(nil)),
+		named("b", barrier(errBoom)),
+		named("c", barrier(nil)),
+	}, WithCompositeConcurrency(n))
+
+	changed, err := c.WriteKeyValues(context.Background(), &store.Secret{})
+	want := &PartialError{
+		Operation: "write",
+		Succeeded: []string{"a", "c"},
+		Failed:    []StoreError{{Store: "b", Err: errBoom}},
+	}
+	if diff := cmp.Diff(want, err, test.EquateErrors()); diff != "" {
+		t.Errorf("c.WriteKeyValues(...): -want error, +got error:\n%s", diff)
+	}
+	if !changed {
+		t.Errorf("c.WriteKeyValues(...): want changed, got unchanged")
+	}
+}
+
+func TestCompositeStoreDeleteOrder(t *testing.T) {
+	var order []string
+	recording := func(name string, err error) NamedStore {
+		return NamedStore{Name: name, Store: &fake.SecretStore{
+			DeleteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.DeleteOption) error {
+				order = append(order, name)
+				return err
+			},
+		}}
+	}
+
+	type want struct {
+		order []string
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		stores []NamedStore
+		o      []CompositeStoreOption
+		want   want
+	}{
+		"StoreOrder": {
+			reason: "We should delete from stores in store order by default.",
+			stores: []NamedStore{recording("old", nil), recording("new", nil)},
+			want: want{
+				order: []string{"old", "new"},
+			},
+		},
+		"ConfiguredOrder": {
+			reason: "We should delete from stores in the configured order, followed by any stores it doesn't name.",
+			stores: []NamedStore{recording("old", nil), recording("other", nil), recording("new", nil)},
+			o:      []CompositeStoreOption{WithCompositeDeleteOrder("new", "old")},
+			want: want{
+				order: []string{"new", "old", "other"},
+			},
+		},
+		"ConfiguredOrderPartialFailure": {
+			reason: "We should stop deleting at the first failure, and report the stores that follow it as not attempted.",
+			stores: []NamedStore{recording("old", nil), recording("new", nil), recording("other", errBoom)},
+			o:      []CompositeStoreOption{WithCompositeDeleteOrder("new", "other", "old"), WithCompositeConcurrency(3)},
+			want: want{
+				order: []string{"new", "other"},
+				err: &PartialError{
+					Operation:    "delete",
+					Succeeded:    []string{"new"},
+					Failed:       []StoreError{{Store: "other", Err: errBoom}},
+					NotAttempted: []string{"old"},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			order = nil
+			c := NewCompositeStore(tc.stores, tc.o...)
+			err := c.DeleteKeyValues(context.Background(), &store.Secret{})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nc.DeleteKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			pe := &PartialError{}
+			if tc.want.err != nil && errors.As(err, &pe) {
+				if diff := cmp.Diff(tc.want.err, pe, test.EquateErrors()); diff != "" {
+					t.Errorf("\n%s\nc.DeleteKeyValues(...): -want partial error, +got partial error:\n%s", tc.reason, diff)
+				}
+			}
+			if diff := cmp.Diff(tc.want.order, order); diff != "" {
+				t.Errorf("\n%s\nc.DeleteKeyValues(...): -want delete order, +got delete order:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
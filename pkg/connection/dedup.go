@@ -0,0 +1,210 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+)
+
+const (
+	// AnnotationKeyDeduplicatedKeys is the annotation a DedupStore uses to
+	// record which keys of a secret reference a deduplicated value.
+	AnnotationKeyDeduplicatedKeys = "connection.crossplane.io/deduplicated-keys"
+
+	// DefaultDedupMinSize is the default minimum size of a value that a
+	// DedupStore deduplicates.
+	DefaultDedupMinSize = 1024
+
+	dedupKeyPrefix = "_dedup-"
+)
+
+// A DedupStoreOption configures a DedupStore.
+type DedupStoreOption func(*DedupStore)
+
+// WithDedupMinSize configures the minimum size in bytes of a value that a
+// DedupStore deduplicates. Smaller values are always stored as is.
+func WithDedupMinSize(n int) DedupStoreOption {
+	return func(s *DedupStore) {
+		s.minSize = n
+	}
+}
+
+// A DedupStore is a Store that stores values shared by multiple keys of a
+// secret once, for example the same CA certificate. Each shared value is
+// stored under a hidden key, and the keys that share it store the name of the
+// hidden key instead. Deduplicated values are transparently expanded on read.
+type DedupStore struct {
+	store   Store
+	minSize int
+}
+
+// NewDedupStore returns a DedupStore that wraps the supplied Store.
+func NewDedupStore(s Store, o ...DedupStoreOption) *DedupStore {
+	d := &DedupStore{store: s, minSize: DefaultDedupMinSize}
+	for _, fn := range o {
+		fn(d)
+	}
+	return d
+}
+
+// Unwrap returns the Store the DedupStore wraps.
+func (s *DedupStore) Unwrap() Store {
+	return s.store
+}
+
+// ReadKeyValues reads key values from the wrapped Store, expanding any
+// deduplicated values.
+func (s *DedupStore) ReadKeyValues(ctx context.Context, n store.ScopedName, sec *store.Secret, ro ...store.ReadOption) error {
+	if err := s.store.ReadKeyValues(ctx, n, sec); err != nil {
+		return err
+	}
+	expand(sec)
+	return store.ApplyReadOptions(ctx, sec, ro...)
+}
+
+// WriteKeyValues writes key values to the wrapped Store, deduplicating values
+// shared by multiple keys. The wrapped Store may keep keys that aren't
+// written, so keys that were deduplicated by an earlier write and aren't
+// written remain recorded as deduplicated.
+func (s *DedupStore) WriteKeyValues(ctx context.Context, sec *store.Secret, wo ...store.WriteOption) (bool, error) {
+	current := &store.Secret{}
+	if err := s.store.ReadKeyValues(ctx, sec.ScopedName, current); err != nil && !store.IsNotFound(err) {
+		return false, err
+	}
+	return s.store.WriteKeyValues(ctx, s.dedup(sec, current), wo...)
+}
+
+// DeleteKeyValues deletes key values from the wrapped Store, and any
+// deduplicated values that are no longer referenced by the remaining keys.
+func (s *DedupStore) DeleteKeyValues(ctx context.Context, sec *store.Secret, do ...store.DeleteOption) error {
+	if len(sec.Data) == 0 {
+		// The whole secret is being deleted.
+		return s.store.DeleteKeyValues(ctx, sec, do...)
+	}
+
+	current := &store.Secret{}
+	if err := s.store.ReadKeyValues(ctx, sec.ScopedName, current); err != nil {
+		return err
+	}
+
+	del := sec.DeepCopy()
+	remaining := map[string]bool{}
+	for _, k := range dedupedKeys(current) {
+		if _, ok := del.Data[k]; !ok {
+			remaining[string(current.Data[k])] = true
+		}
+	}
+	for k := range current.Data {
+		if strings.HasPrefix(k, dedupKeyPrefix) && !remaining[k] {
+			del.Data[k] = nil
+		}
+	}
+	return s.store.DeleteKeyValues(ctx, del, do...)
+}
+
+// dedup returns a copy of the supplied secret with shared values deduplicated.
+// Keys of the supplied current secret that were deduplicated and aren't
+// written remain recorded as deduplicated.
+func (s *DedupStore) dedup(sec, current *store.Secret) *store.Secret {
+	keys := make([]string, 0, len(sec.Data))
+	for _, k := range dedupedKeys(current) {
+		if _, ok := sec.Data[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	byHash := map[string][]string{}
+	for k, v := range sec.Data {
+		if len(v) < s.minSize {
+			continue
+		}
+		h := sha256.Sum256(v)
+		hk := dedupKeyPrefix + hex.EncodeToString(h[:8])
+		byHash[hk] = append(byHash[hk], k)
+	}
+
+	out := sec.DeepCopy()
+	for hk, ks := range byHash {
+		if len(ks) < 2 {
+			continue
+		}
+		out.Data[hk] = sec.Data[ks[0]]
+		for _, k := range ks {
+			out.Data[k] = []byte(hk)
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 && !annotated(current) {
+		return out
+	}
+
+	sort.Strings(keys)
+	if out.Metadata == nil {
+		out.Metadata = &v1.ConnectionSecretMetadata{}
+	}
+	if out.Metadata.Annotations == nil {
+		out.Metadata.Annotations = map[string]string{}
+	}
+	out.Metadata.Annotations[AnnotationKeyDeduplicatedKeys] = strings.Join(keys, ",")
+	return out
+}
+
+// expand replaces the values of deduplicated keys with the values they
+// reference, and removes the hidden keys that store deduplicated values.
+func expand(sec *store.Secret) {
+	for _, k := range dedupedKeys(sec) {
+		if v, ok := sec.Data[string(sec.Data[k])]; ok {
+			sec.Data[k] = v
+		}
+	}
+	for k := range sec.Data {
+		if strings.HasPrefix(k, dedupKeyPrefix) {
+			delete(sec.Data, k)
+		}
+	}
+	if sec.Metadata != nil {
+		delete(sec.Metadata.Annotations, AnnotationKeyDeduplicatedKeys)
+	}
+}
+
+func annotated(sec *store.Secret) bool {
+	if sec.Metadata == nil {
+		return false
+	}
+	_, ok := sec.Metadata.Annotations[AnnotationKeyDeduplicatedKeys]
+	return ok
+}
+
+func dedupedKeys(sec *store.Secret) []string {
+	if sec.Metadata == nil || sec.Metadata.Annotations[AnnotationKeyDeduplicatedKeys] == "" {
+		return nil
+	}
+	var keys []string
+	for _, k := range strings.Split(sec.Metadata.Annotations[AnnotationKeyDeduplicatedKeys], ",") {
+		// Only keys that still reference a deduplicated value are expanded.
+		if v, ok := sec.Data[k]; ok && strings.HasPrefix(string(v), dedupKeyPrefix) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"sort"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errFmtCompareRead = "cannot read secret from store %s"
+)
+
+// Diff reads the supplied secret from the supplied Store, and returns the keys
+// that writing the supplied desired key values would add to, change in, and
+// remove from it, each in sorted order. It does not write the secret. All keys
+// are added if the secret does not exist.
+func Diff(ctx context.Context, s Store, n store.ScopedName, desired store.KeyValues) (added, changed, removed []string, err error) {
+	current := &store.Secret{}
+	if err := s.ReadKeyValues(ctx, n, current); err != nil && !store.IsNotFound(err) {
+		return nil, nil, nil, errors.Wrap(err, errReadStore)
+	}
+	added, changed, removed = store.DiffKeyValues(current.Data, desired)
+	return added, changed, removed, nil
+}
+
+// Compare reads the supplied secret from the two supplied Stores, and returns
+// the keys whose values differ between them, in sorted order. Keys that only
+// one of the Stores has differ. A secret that does not exist in a Store has
+// no keys. Compare may be used to detect Stores that should hold the same
+// secrets, e.g. while migrating between Stores, disagreeing.
+func Compare(ctx context.Context, n store.ScopedName, a, b Store) ([]string, error) {
+	as := &store.Secret{}
+	if err := a.ReadKeyValues(ctx, n, as); err != nil && !store.IsNotFound(err) {
+		return nil, errors.Wrapf(err, errFmtCompareRead, "a")
+	}
+	bs := &store.Secret{}
+	if err := b.ReadKeyValues(ctx, n, bs); err != nil && !store.IsNotFound(err) {
+		return nil, errors.Wrapf(err, errFmtCompareRead, "b")
+	}
+	added, changed, removed := store.DiffKeyValues(as.Data, bs.Data)
+	differ := append(append(added, changed...), removed...)
+	sort.Strings(differ)
+	return differ, nil
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestDiff(t *testing.T) {
+	n := store.ScopedName{Name: "cool", Scope: "ns"}
+	current := store.KeyValues{"same": []byte("a"), "changed": []byte("b"), "removed": []byte("c"), "empty": nil}
+
+	type args struct {
+		current store.KeyValues
+		err     error
+		desired store.KeyValues
+	}
+	type want struct {
+		added   []string
+		changed []string
+		removed []string
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Create": {
+			reason: "We should report all keys as added if the secret does not exist.",
+			args: args{
+				err:     store.NewNotFound("secret not found"),
+				desired: store.KeyValues{"b": []byte("2"), "a": []byte("1")},
+			},
+			want: want{
+				added: []string{"a", "b"},
+			},
+		},
+		"Update": {
+			reason: "We should report added, changed, and removed keys.",
+			args: args{
+				current: current,
+				desired: store.KeyValues{"same": []byte("a"), "changed": []byte("d"), "added": []byte("e"), "empty": []byte{}},
+			},
+			want: want{
+				added:   []string{"added"},
+				changed: []string{"changed"},
+				removed: []string{"removed"},
+			},
+		},
+		"NoOp": {
+			reason: "We should report no keys if the desired key values are identical to the current ones.",
+			args: args{
+				current: current,
+				desired: store.KeyValues{"same": []byte("a"), "changed": []byte("b"), "removed": []byte("c"), "empty": nil},
+			},
+		},
+		"ReadError": {
+			reason: "We should return an error if the secret can't be read.",
+			args: args{
+				err: errBoom,
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errReadStore),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := &fake.SecretStore{
+				ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
+					s.Data = tc.args.current
+					return tc.args.err
+				},
+				WriteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.WriteOption) (bool, error) {
+					t.Errorf("\n%s\nDiff(...): unexpected write", tc.reason)
+					return false, nil
+				},
+			}
+			added, changed, removed, err := Diff(context.Background(), s, n, tc.args.desired)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nDiff(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.added, added); diff != "" {
+				t.Errorf("\n%s\nDiff(...): -want added, +got added:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.changed, changed); diff != "" {
+				t.Errorf("\n%s\nDiff(...): -want changed, +got changed:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.removed, removed); diff != "" {
+				t.Errorf("\n%s\nDiff(...): -want removed, +got removed:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	n := store.ScopedName{Name: "cool", Scope: "ns"}
+
+	reading := func(kv store.KeyValues, err error) Store {
+		return &fake.SecretStore{
+			ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
+				s.Data = kv
+				return err
+			},
+		}
+	}
+
+	type want struct {
+		differ []string
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		a      Store
+		b      Store
+		want   want
+	}{
+		"Identical": {
+			reason: "We should report no keys if both stores agree.",
+			a:      reading(store.KeyValues{"a": []byte("1"), "b": []byte("2"), "empty": nil}, nil),
+			b:      reading(store.KeyValues{"a": []byte("1"), "b": []byte("2"), "empty": []byte{}}, nil),
+		},
+		"Divergent": {
+			reason: "We should report keys whose values differ, and keys only one store has.",
+			a:      reading(store.KeyValues{"same": []byte("1"), "split": []byte("a"), "onlyA": []byte("x")}, nil),
+			b:      reading(store.KeyValues{"same": []byte("1"), "split": []byte("b"), "onlyB": []byte("y")}, nil),
+			want: want{
+				differ: []string{"onlyA", "onlyB", "split"},
+			},
+		},
+		"MissingFromOne": {
+			reason: "We should report all keys if the secret only exists in one store.",
+			a:      reading(nil, store.NewNotFound("secret not found")),
+			b:      reading(store.KeyValues{"b": []byte("2"), "a": []byte("1")}, nil),
+			want: want{
+				differ: []string{"a", "b"},
+			},
+		},
+		"ReadError": {
+			reason: "We should return an error if the secret can't be read from a store.",
+			a:      reading(nil, nil),
+			b:      reading(nil, errBoom),
+			want: want{
+				err: errors.Wrapf(errBoom, errFmtCompareRead, "b"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			differ, err := Compare(context.Background(), n, tc.a, tc.b)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nCompare(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.differ, differ); diff != "" {
+				t.Errorf("\n%s\nCompare(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
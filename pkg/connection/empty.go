@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errFmtEmptySecret = "secret %q in scope %q is still empty"
+)
+
+// An EmptyRetryStore is a Store that retries reading a secret until it has
+// data. Secrets often exist but are momentarily empty while the resource they
+// belong to is being provisioned, so consumers that read them too early would
+// otherwise see no connection details.
+type EmptyRetryStore struct {
+	store   Store
+	backoff wait.Backoff
+}
+
+// NewEmptyRetryStore returns an EmptyRetryStore that wraps the supplied Store,
+// retrying reads of empty secrets per the supplied backoff.
+func NewEmptyRetryStore(s Store, b wait.Backoff) *EmptyRetryStore {
+	return &EmptyRetryStore{store: s, backoff: b}
+}
+
+// Unwrap returns the Store the EmptyRetryStore wraps.
+func (s *EmptyRetryStore) Unwrap() Store {
+	return s.store
+}
+
+// ReadKeyValues reads key values from the wrapped Store, retrying until the
+// secret has data. It returns an error if the secret is still empty when the
+// backoff is exhausted or the supplied context is done. Errors reading the
+// secret are not retried. Read options are applied once the secret has data.
+func (s *EmptyRetryStore) ReadKeyValues(ctx context.Context, n store.ScopedName, sec *store.Secret, ro ...store.ReadOption) error {
+	err := wait.ExponentialBackoffWithContext(ctx, s.backoff, func(ctx context.Context) (bool, error) {
+		*sec = store.Secret{}
+		if err := s.store.ReadKeyValues(ctx, n, sec); err != nil {
+			return false, err
+		}
+		return len(sec.Data) > 0, nil
+	})
+	if wait.Interrupted(err) {
+		return errors.Wrapf(err, errFmtEmptySecret, n.Name, n.Scope)
+	}
+	if err != nil {
+		return err
+	}
+	return store.ApplyReadOptions(ctx, sec, ro...)
+}
+
+// WriteKeyValues writes key values to the wrapped Store.
+func (s *EmptyRetryStore) WriteKeyValues(ctx context.Context, sec *store.Secret, wo ...store.WriteOption) (bool, error) {
+	return s.store.WriteKeyValues(ctx, sec, wo...)
+}
+
+// DeleteKeyValues deletes key values from the wrapped Store.
+func (s *EmptyRetryStore) DeleteKeyValues(ctx context.Context, sec *store.Secret, do ...store.DeleteOption) error {
+	return s.store.DeleteKeyValues(ctx, sec, do...)
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestEmptyRetryStoreReadKeyValues(t *testing.T) {
+	n := store.ScopedName{Name: "cool", Scope: "ns"}
+
+	type args struct {
+		// populatedAfter is the number of reads after which the secret has
+		// data. The secret never has data if it is zero.
+		populatedAfter int
+		err            error
+	}
+	type want struct {
+		data  store.KeyValues
+		reads int
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Populated": {
+			reason: "We should not retry reading a secret that has data.",
+			args: args{
+				populatedAfter: 1,
+			},
+			want: want{
+				data:  store.KeyValues{"key": []byte("value")},
+				reads: 1,
+			},
+		},
+		"PopulatedAfterRetry": {
+			reason: "We should retry reading an empty secret until it has data.",
+			args: args{
+				populatedAfter: 3,
+			},
+			want: want{
+				data:  store.KeyValues{"key": []byte("value")},
+				reads: 3,
+			},
+		},
+		"NeverPopulated": {
+			reason: "We should return an error if the secret is still empty when the backoff is exhausted.",
+			want: want{
+				reads: 5,
+				err:   errors.Wrapf(wait.ErrorInterrupted(nil), errFmtEmptySecret, n.Name, n.Scope),
+			},
+		},
+		"ReadError": {
+			reason: "We should not retry if the secret can't be read.",
+			args: args{
+				err: errBoom,
+			},
+			want: want{
+				reads: 1,
+				err:   errBoom,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			reads := 0
+			s := NewEmptyRetryStore(&fake.SecretStore{
+				ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
+					reads++
+					if reads == tc.args.populatedAfter {
+						s.Data = store.KeyValues{"key": []byte("value")}
+					}
+					return tc.args.err
+				},
+			}, wait.Backoff{Steps: 5})
+
+			sec := &store.Secret{}
+			err := s.ReadKeyValues(context.Background(), n, sec)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ns.ReadKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.reads, reads); diff != "" {
+				t.Errorf("\n%s\ns.ReadKeyValues(...): -want reads, +got reads:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.data, sec.Data); diff != "" {
+				t.Errorf("\n%s\ns.ReadKeyValues(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errGenerateDataKey = "cannot generate data key"
+	errWrapDataKey     = "cannot wrap data key"
+	errUnwrapDataKey   = "cannot unwrap data key"
+	errDecodeEnvelope  = "cannot decode encrypted value"
+	errReEncrypt       = "cannot re-encrypt secret"
+	errShortCiphertext = "ciphertext is too short"
+
+	errFmtEncryptKeyVal     = "cannot encrypt value of key %q"
+	errFmtDecryptKeyVal     = "cannot decrypt value of key %q"
+	errFmtUnknownKeyVersion = "unknown key version %q"
+)
+
+// encryptedValuePrefix prefixes the values an EncryptingStore writes.
+const encryptedValuePrefix = "crossplane-encrypted:"
+
+const dataKeySize = 32
+
+// A KeyProvider wraps and unwraps the data keys an EncryptingStore encrypts
+// values with, using versioned key encryption keys. A KeyProvider may for
+// example be backed by an external KMS.
+type KeyProvider interface {
+	// WrapKey encrypts the supplied data key using the primary key
+	// encryption key, and returns the version of that key.
+	WrapKey(ctx context.Context, dek []byte) (version string, wrapped []byte, err error)
+
+	// UnwrapKey decrypts the supplied data key using the key encryption key
+	// with the supplied version.
+	UnwrapKey(ctx context.Context, version string, wrapped []byte) ([]byte, error)
+}
+
+// A StaticKeyProvider is a KeyProvider backed by a static set of AES key
+// encryption keys.
+type StaticKeyProvider struct {
+	primary string
+	keys    map[string][]byte
+}
+
+// NewStaticKeyProvider returns a StaticKeyProvider that wraps data keys using
+// the key with the supplied primary version, and unwraps them using any of the
+// supplied keys. Keys must be 16, 24, or 32 bytes long. Keys are rotated by
+// adding a new key, making it the primary key, and keeping the old key until
+// all values encrypted with it have been re-encrypted.
+func NewStaticKeyProvider(primary string, keys map[string][]byte) *StaticKeyProvider {
+	return &StaticKeyProvider{primary: primary, keys: keys}
+}
+
+// WrapKey encrypts the supplied data key using the primary key.
+func (p *StaticKeyProvider) WrapKey(_ context.Context, dek []byte) (string, []byte, error) {
+	kek, ok := p.keys[p.primary]
+	if !ok {
+		return "", nil, errors.Errorf(errFmtUnknownKeyVersion, p.primary)
+	}
+	wrapped, err := seal(kek, dek, nil)
+	return p.primary, wrapped, err
+}
+
+// UnwrapKey decrypts the supplied data key using the key with the supplied
+// version.
+func (p *StaticKeyProvider) UnwrapKey(_ context.Context, version string, wrapped []byte) ([]byte, error) {
+	kek, ok := p.keys[version]
+	if !ok {
+		return nil, errors.Errorf(errFmtUnknownKeyVersion, version)
+	}
+	return open(kek, wrapped, nil)
+}
+
+// An envelope is an encrypted value, along with the wrapped data key it was
+// encrypted with and the version of the key that wrapped the data key. Values
+// carry their own key version, so values encrypted with different keys may
+// coexist in the same secret while keys are being rotated.
+type envelope struct {
+	KeyVersion string `json:"v"`
+	DataKey    []byte `json:"k"`
+	Ciphertext []byte `json:"c"`
+}
+
+// An EncryptingStore is a Store that encrypts values before writing them to
+// the Store it wraps, and decrypts them after reading them. Each write
+// encrypts changed values using a fresh data key, which is wrapped by a
+// KeyProvider and stored alongside each value. Each value is bound to its key,
+// so encrypted values can't be moved between keys.
+//
+// Values that were not written by an EncryptingStore are read as is, so that
+// secrets written before encryption was enabled remain readable until they are
+// re-encrypted. Write options passed to the wrapped Store see encrypted
+// values.
+type EncryptingStore struct {
+	store Store
+	keys  KeyProvider
+}
+
+// NewEncryptingStore returns an EncryptingStore that wraps the supplied Store,
+// using the supplied KeyProvider.
+func NewEncryptingStore(s Store, kp KeyProvider) *EncryptingStore {
+	return &EncryptingStore{store: s, keys: kp}
+}
+
+// Unwrap returns the Store the EncryptingStore wraps.
+func (e *EncryptingStore) Unwrap() Store {
+	return e.store
+}
+
+// ReadKeyValues reads and decrypts key values from the wrapped Store.
+func (e *EncryptingStore) ReadKeyValues(ctx context.Context, n store.ScopedName, s *store.Secret, ro ...store.ReadOption) error {
+	if err := e.store.ReadKeyValues(ctx, n, s); err != nil {
+		return err
+	}
+	if s.Data != nil {
+		kv := make(store.KeyValues, len(s.Data))
+		for k, v := range s.Data {
+			pt, err := e.decrypt(ctx, k, v)
+			if err != nil {
+				return errors.Wrapf(err, errFmtDecryptKeyVal, k)
+			}
+			kv[k] = pt
+		}
+		s.Data = kv
+	}
+	return store.ApplyReadOptions(ctx, s, ro...)
+}
+
+// WriteKeyValues encrypts and writes key values to the wrapped Store. Every
+// write encrypts using a fresh data key and nonce, so encrypting the same
+// value twice produces different ciphertexts. To keep writes that don't change
+// any values no-ops, values whose current ciphertext decrypts to the desired
+// value using the primary key version are written as their current
+// ciphertext.
+func (e *EncryptingStore) WriteKeyValues(ctx context.Context, s *store.Secret, wo ...store.WriteOption) (bool, error) {
+	if len(s.Data) == 0 {
+		return e.store.WriteKeyValues(ctx, s, wo...)
+	}
+
+	dek := make([]byte, dataKeySize)
+	if _, err := rand.Read(dek); err != nil {
+		return false, errors.Wrap(err, errGenerateDataKey)
+	}
+	version, wrapped, err := e.keys.WrapKey(ctx, dek)
+	if err != nil {
+		return false, errors.Wrap(err, errWrapDataKey)
+	}
+
+	current := e.current(ctx, s.ScopedName)
+	es := s.DeepCopy()
+	for k, v := range s.Data {
+		if ct, ok := current[k]; ok && e.encrypts(ctx, k, ct, v, version) {
+			es.Data[k] = ct
+			continue
+		}
+		ct, err := seal(dek, v, []byte(k))
+		if err != nil {
+			return false, errors.Wrapf(err, errFmtEncryptKeyVal, k)
+		}
+		b, err := json.Marshal(envelope{KeyVersion: version, DataKey: wrapped, Ciphertext: ct})
+		if err != nil {
+			return false, errors.Wrapf(err, errFmtEncryptKeyVal, k)
+		}
+		es.Data[k] = append([]byte(encryptedValuePrefix), b...)
+	}
+	return e.store.WriteKeyValues(ctx, es, wo...)
+}
+
+// current returns the current, encrypted key values of the supplied secret.
+// It returns no key values if they can't be read, in which case all values
+// are encrypted afresh.
+func (e *EncryptingStore) current(ctx context.Context, n store.ScopedName) store.KeyValues {
+	s := &store.Secret{}
+	if err := e.store.ReadKeyValues(ctx, n, s); err != nil {
+		return nil
+	}
+	return s.Data
+}
+
+// encrypts returns true if the supplied ciphertext of the supplied key was
+// encrypted using the supplied key version, and decrypts to the supplied
+// plaintext.
+func (e *EncryptingStore) encrypts(ctx context.Context, k string, ciphertext, plaintext []byte, version string) bool {
+	b, ok := bytes.CutPrefix(ciphertext, []byte(encryptedValuePrefix))
+	if !ok {
+		return false
+	}
+	env := envelope{}
+	if err := json.Unmarshal(b, &env); err != nil || env.KeyVersion != version {
+		return false
+	}
+	pt, err := e.decrypt(ctx, k, ciphertext)
+	return err == nil && bytes.Equal(pt, plaintext)
+}
+
+// DeleteKeyValues deletes key values from the wrapped Store.
+func (e *EncryptingStore) DeleteKeyValues(ctx context.Context, s *store.Secret, do ...store.DeleteOption) error {
+	return e.store.DeleteKeyValues(ctx, s, do...)
+}
+
+// ReEncrypt re-encrypts all values of the supplied secret using the primary
+// key encryption key of the EncryptingStore's KeyProvider. Values that were
+// not yet encrypted are encrypted. ReEncrypt is used to rotate keys: once all
+// secrets have been re-encrypted, old key encryption keys may be retired.
+func (e *EncryptingStore) ReEncrypt(ctx context.Context, n store.ScopedName) error {
+	s := &store.Secret{}
+	if err := e.ReadKeyValues(ctx, n, s); err != nil {
+		return errors.Wrap(err, errReEncrypt)
+	}
+	if len(s.Data) == 0 {
+		return nil
+	}
+	s.ScopedName = n
+	_, err := e.WriteKeyValues(ctx, s)
+	return errors.Wrap(err, errReEncrypt)
+}
+
+func (e *EncryptingStore) decrypt(ctx context.Context, k string, v []byte) ([]byte, error) {
+	b, ok := bytes.CutPrefix(v, []byte(encryptedValuePrefix))
+	if !ok {
+		return v, nil
+	}
+	env := envelope{}
+	if err := json.Unmarshal(b, &env); err != nil {
+		return nil, errors.Wrap(err, errDecodeEnvelope)
+	}
+	dek, err := e.keys.UnwrapKey(ctx, env.KeyVersion, env.DataKey)
+	if err != nil {
+		return nil, errors.Wrap(err, errUnwrapDataKey)
+	}
+	return open(dek, env.Ciphertext, []byte(k))
+}
+
+// seal encrypts the supplied plaintext using AES-GCM, and returns the
+// ciphertext prefixed with its nonce.
+func seal(key, plaintext, ad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, ad), nil
+}
+
+// open decrypts ciphertext produced by seal.
+func open(key, ciphertext, ad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.New(errShortCiphertext)
+	}
+	nonce, ct := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ct, ad)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	b, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(b)
+}
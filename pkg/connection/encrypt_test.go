@@ -0,0 +1,183 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+var (
+	kek1 = bytes.Repeat([]byte{1}, 32)
+	kek2 = bytes.Repeat([]byte{2}, 32)
+)
+
+// mixedVersions returns a secret with a value encrypted using key version v1,
+// a value encrypted using key version v2, and a plaintext value.
+func mixedVersions(t *testing.T, n store.ScopedName) map[store.ScopedName]store.KeyValues {
+	t.Helper()
+
+	write := func(kp KeyProvider, k, v string) []byte {
+		m := map[store.ScopedName]store.KeyValues{}
+		if _, err := NewEncryptingStore(memoryStore(m, true), kp).WriteKeyValues(context.Background(), &store.Secret{ScopedName: n, Data: store.KeyValues{k: []byte(v)}}); err != nil {
+			t.Fatalf("WriteKeyValues(...): %v", err)
+		}
+		return m[n][k]
+	}
+
+	return map[store.ScopedName]store.KeyValues{n: {
+		"old":   write(NewStaticKeyProvider("v1", map[string][]byte{"v1": kek1}), "old", "a"),
+		"new":   write(NewStaticKeyProvider("v2", map[string][]byte{"v2": kek2}), "new", "b"),
+		"plain": []byte("c"),
+	}}
+}
+
+func keyVersion(t *testing.T, v []byte) string {
+	t.Helper()
+
+	b, ok := bytes.CutPrefix(v, []byte(encryptedValuePrefix))
+	if !ok {
+		return ""
+	}
+	env := envelope{}
+	if err := json.Unmarshal(b, &env); err != nil {
+		t.Fatalf("json.Unmarshal(...): %v", err)
+	}
+	return env.KeyVersion
+}
+
+func TestEncryptingStoreReadKeyValues(t *testing.T) {
+	n := store.ScopedName{Name: "cool", Scope: "ns"}
+
+	type want struct {
+		data store.KeyValues
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		kp     KeyProvider
+		want   want
+	}{
+		"MixedVersions": {
+			reason: "We should decrypt values encrypted with different key versions, and read plaintext values as is.",
+			kp:     NewStaticKeyProvider("v2", map[string][]byte{"v1": kek1, "v2": kek2}),
+			want: want{
+				data: store.KeyValues{"old": []byte("a"), "new": []byte("b"), "plain": []byte("c")},
+			},
+		},
+		"RetiredVersion": {
+			reason: "We should return an error if a value was encrypted with a key version that is no longer known.",
+			kp:     NewStaticKeyProvider("v2", map[string][]byte{"v2": kek2}),
+			want: want{
+				err: errors.Wrapf(errors.Wrap(errors.Errorf(errFmtUnknownKeyVersion, "v1"), errUnwrapDataKey), errFmtDecryptKeyVal, "old"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := NewEncryptingStore(memoryStore(mixedVersions(t, n), false), tc.kp)
+			s := &store.Secret{}
+			err := e.ReadKeyValues(context.Background(), n, s)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.ReadKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.data, s.Data); diff != "" {
+				t.Errorf("\n%s\ne.ReadKeyValues(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestEncryptingStoreReEncrypt(t *testing.T) {
+	n := store.ScopedName{Name: "cool", Scope: "ns"}
+	m := mixedVersions(t, n)
+
+	e := NewEncryptingStore(memoryStore(m, true), NewStaticKeyProvider("v2", map[string][]byte{"v1": kek1, "v2": kek2}))
+	if err := e.ReEncrypt(context.Background(), n); err != nil {
+		t.Fatalf("e.ReEncrypt(...): %v", err)
+	}
+
+	got := map[string]string{}
+	for k, v := range m[n] {
+		got[k] = keyVersion(t, v)
+	}
+	want := map[string]string{"old": "v2", "new": "v2", "plain": "v2"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("e.ReEncrypt(...): -want key versions, +got key versions:\n%s", diff)
+	}
+
+	// Once re-encrypted, the secret should be readable without the old key.
+	retired := NewEncryptingStore(memoryStore(m, false), NewStaticKeyProvider("v2", map[string][]byte{"v2": kek2}))
+	s := &store.Secret{}
+	if err := retired.ReadKeyValues(context.Background(), n, s); err != nil {
+		t.Fatalf("retired.ReadKeyValues(...): %v", err)
+	}
+	if diff := cmp.Diff(store.KeyValues{"old": []byte("a"), "new": []byte("b"), "plain": []byte("c")}, s.Data); diff != "" {
+		t.Errorf("retired.ReadKeyValues(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestEncryptingStoreWriteKeyValuesUnchanged(t *testing.T) {
+	n := store.ScopedName{Name: "cool", Scope: "ns"}
+	kp := NewStaticKeyProvider("v1", map[string][]byte{"v1": kek1, "v2": kek2})
+	ms := fake.NewMemoryStore()
+	e := NewEncryptingStore(ms, kp)
+
+	write := func(kv store.KeyValues) bool {
+		t.Helper()
+		changed, err := e.WriteKeyValues(context.Background(), &store.Secret{ScopedName: n, Data: kv})
+		if err != nil {
+			t.Fatalf("e.WriteKeyValues(...): %v", err)
+		}
+		return changed
+	}
+
+	if !write(store.KeyValues{"key": []byte("a")}) {
+		t.Errorf("e.WriteKeyValues(...): want changed writing a new secret, got unchanged")
+	}
+	if write(store.KeyValues{"key": []byte("a")}) {
+		t.Errorf("e.WriteKeyValues(...): want unchanged writing the same data twice, got changed")
+	}
+	if !write(store.KeyValues{"key": []byte("b")}) {
+		t.Errorf("e.WriteKeyValues(...): want changed writing different data, got unchanged")
+	}
+
+	// Values encrypted using a key version other than the primary one are
+	// re-encrypted, even if they are unchanged.
+	e = NewEncryptingStore(ms, NewStaticKeyProvider("v2", map[string][]byte{"v1": kek1, "v2": kek2}))
+	if !write(store.KeyValues{"key": []byte("b")}) {
+		t.Errorf("e.WriteKeyValues(...): want changed writing data encrypted using an old key version, got unchanged")
+	}
+	s, _ := ms.Get(n)
+	if diff := cmp.Diff("v2", keyVersion(t, s.Data["key"])); diff != "" {
+		t.Errorf("e.WriteKeyValues(...): -want key version, +got key version:\n%s", diff)
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errFmtNotFound = "secret %q in scope %q not found"
+)
+
+// A MemoryStore is a secret store backed by an in-memory map of secrets,
+// keyed by their scoped name. It is useful to test controllers that publish
+// connection details without reasoning about how a particular store persists
+// them. A MemoryStore is safe for concurrent use.
+type MemoryStore struct {
+	mx      sync.RWMutex
+	secrets map[store.ScopedName]*store.Secret
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{secrets: map[store.ScopedName]*store.Secret{}}
+}
+
+// ReadKeyValues reads key values from the supplied secret. It returns a
+// NotFound error if the secret does not exist.
+func (m *MemoryStore) ReadKeyValues(ctx context.Context, n store.ScopedName, s *store.Secret, ro ...store.ReadOption) error {
+	m.mx.RLock()
+	stored, ok := m.secrets[n]
+	m.mx.RUnlock()
+	if !ok {
+		return store.NewNotFound(errors.Errorf(errFmtNotFound, n.Name, n.Scope).Error())
+	}
+
+	c := stored.DeepCopy()
+	s.ScopedName = n
+	s.Data = c.Data
+	s.Metadata = c.Metadata
+	for _, o := range ro {
+		if err := o(ctx, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteKeyValues creates the supplied secret, or replaces its key values and
+// metadata if it exists. It returns true if the secret was changed.
+func (m *MemoryStore) WriteKeyValues(ctx context.Context, s *store.Secret, wo ...store.WriteOption) (bool, error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	desired := s.DeepCopy()
+	desired.Owner = nil
+	current, ok := m.secrets[s.ScopedName]
+	if !ok {
+		current = &store.Secret{ScopedName: s.ScopedName}
+	}
+	for _, o := range wo {
+		if err := o(ctx, current.DeepCopy(), desired); err != nil {
+			return false, err
+		}
+	}
+
+	if ok && store.Equal(current.Data, desired.Data) && cmp.Equal(current.Metadata, desired.Metadata) {
+		return false, nil
+	}
+	m.secrets[s.ScopedName] = desired
+	return true, nil
+}
+
+// DeleteKeyValues deletes the supplied keys from the supplied secret. The
+// secret is deleted if no keys are supplied, or if no keys are left. Deleting
+// a secret that doesn't exist is not an error.
+func (m *MemoryStore) DeleteKeyValues(ctx context.Context, s *store.Secret, do ...store.DeleteOption) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	stored, ok := m.secrets[s.ScopedName]
+	if !ok {
+		return nil
+	}
+	for _, o := range do {
+		if err := o(ctx, s); err != nil {
+			return err
+		}
+	}
+	for k := range s.Data {
+		delete(stored.Data, k)
+	}
+	if len(s.Data) == 0 || len(stored.Data) == 0 {
+		delete(m.secrets, s.ScopedName)
+	}
+	return nil
+}
+
+// Get returns a copy of the supplied secret as stored, and whether it exists.
+// It lets tests assert what was written without affecting what is stored.
+func (m *MemoryStore) Get(n store.ScopedName) (*store.Secret, bool) {
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+	s, ok := m.secrets[n]
+	return s.DeepCopy(), ok
+}
+
+// Len returns the number of stored secrets.
+func (m *MemoryStore) Len() int {
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+	return len(m.secrets)
+}
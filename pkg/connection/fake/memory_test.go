@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	n := store.ScopedName{Name: "cool", Scope: "ns"}
+	m := NewMemoryStore()
+
+	if err := m.ReadKeyValues(ctx, n, &store.Secret{}); !store.IsNotFound(err) {
+		t.Errorf("m.ReadKeyValues(...): want a NotFound error reading an absent secret, got %v", err)
+	}
+
+	write := func(kv store.KeyValues) bool {
+		t.Helper()
+		changed, err := m.WriteKeyValues(ctx, &store.Secret{ScopedName: n, Data: kv})
+		if err != nil {
+			t.Fatalf("m.WriteKeyValues(...): %v", err)
+		}
+		return changed
+	}
+	if !write(store.KeyValues{"a": []byte("1"), "b": []byte("2")}) {
+		t.Errorf("m.WriteKeyValues(...): want a new secret to be changed")
+	}
+	if write(store.KeyValues{"a": []byte("1"), "b": []byte("2")}) {
+		t.Errorf("m.WriteKeyValues(...): want an identical write to be unchanged")
+	}
+
+	s := &store.Secret{}
+	if err := m.ReadKeyValues(ctx, n, s); err != nil {
+		t.Fatalf("m.ReadKeyValues(...): %v", err)
+	}
+	if diff := cmp.Diff(store.KeyValues{"a": []byte("1"), "b": []byte("2")}, s.Data); diff != "" {
+		t.Errorf("m.ReadKeyValues(...): -want, +got:\n%s", diff)
+	}
+
+	// Mutating what was read must not affect what is stored.
+	s.Data["a"] = []byte("mutated")
+
+	if err := m.DeleteKeyValues(ctx, &store.Secret{ScopedName: n, Data: store.KeyValues{"b": nil}}); err != nil {
+		t.Fatalf("m.DeleteKeyValues(...): %v", err)
+	}
+	got, ok := m.Get(n)
+	if !ok {
+		t.Fatalf("m.Get(...): want the secret to exist after deleting some of its keys")
+	}
+	if diff := cmp.Diff(store.KeyValues{"a": []byte("1")}, got.Data); diff != "" {
+		t.Errorf("m.Get(...): -want, +got:\n%s", diff)
+	}
+
+	if err := m.DeleteKeyValues(ctx, &store.Secret{ScopedName: n, Data: store.KeyValues{"a": nil}}); err != nil {
+		t.Fatalf("m.DeleteKeyValues(...): %v", err)
+	}
+	if _, ok := m.Get(n); ok {
+		t.Errorf("m.Get(...): want the secret to be deleted once no keys are left")
+	}
+}
+
+func TestMemoryStoreConcurrency(t *testing.T) {
+	m := NewMemoryStore()
+	wg := sync.WaitGroup{}
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n := store.ScopedName{Name: fmt.Sprintf("secret-%d", i)}
+			_, _ = m.WriteKeyValues(context.Background(), &store.Secret{ScopedName: n, Data: store.KeyValues{"k": []byte("v")}})
+			_ = m.ReadKeyValues(context.Background(), n, &store.Secret{})
+		}()
+	}
+	wg.Wait()
+	if m.Len() != 10 {
+		t.Errorf("m.Len(): want 10, got %d", m.Len())
+	}
+}
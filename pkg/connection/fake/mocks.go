@@ -33,14 +33,14 @@ import (
 
 // SecretStore is a fake SecretStore.
 type SecretStore struct {
-	ReadKeyValuesFn   func(ctx context.Context, n store.ScopedName, s *store.Secret) error
+	ReadKeyValuesFn   func(ctx context.Context, n store.ScopedName, s *store.Secret, ro ...store.ReadOption) error
 	WriteKeyValuesFn  func(ctx context.Context, s *store.Secret, wo ...store.WriteOption) (bool, error)
 	DeleteKeyValuesFn func(ctx context.Context, s *store.Secret, do ...store.DeleteOption) error
 }
 
 // ReadKeyValues reads key values.
-func (ss *SecretStore) ReadKeyValues(ctx context.Context, n store.ScopedName, s *store.Secret) error {
-	return ss.ReadKeyValuesFn(ctx, n, s)
+func (ss *SecretStore) ReadKeyValues(ctx context.Context, n store.ScopedName, s *store.Secret, ro ...store.ReadOption) error {
+	return ss.ReadKeyValuesFn(ctx, n, s, ro...)
 }
 
 // WriteKeyValues writes key values.
@@ -0,0 +1,60 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// Error strings.
+const (
+	errUnpublishConnection = "cannot unpublish connection details"
+)
+
+// A SecretFinalizer is a resource.Finalizer that unpublishes the connection
+// secret of a resource before removing its finalizer. Secrets that can't be
+// garbage collected using owner references, for example because they were
+// written to a remote Kubernetes API server, are thus deleted, or orphaned,
+// along with their owner. Reconcilers that don't otherwise unpublish the
+// connection details of the resources they delete may use it.
+type SecretFinalizer struct {
+	resource.Finalizer
+	publisher managed.ConnectionPublisher
+}
+
+// NewSecretFinalizer returns a SecretFinalizer that wraps the supplied
+// Finalizer, and unpublishes connection secrets using the supplied
+// ConnectionPublisher, typically a DetailsManager.
+func NewSecretFinalizer(f resource.Finalizer, p managed.ConnectionPublisher) *SecretFinalizer {
+	return &SecretFinalizer{Finalizer: f, publisher: p}
+}
+
+// RemoveFinalizer unpublishes the connection secret of the supplied resource,
+// if it is a ConnectionSecretOwner, then removes its finalizer. The finalizer
+// is not removed if the secret can't be unpublished.
+func (f *SecretFinalizer) RemoveFinalizer(ctx context.Context, obj resource.Object) error {
+	if so, ok := obj.(resource.ConnectionSecretOwner); ok {
+		if err := f.publisher.UnpublishConnection(ctx, so, managed.ConnectionDetails{}); err != nil {
+			return errors.Wrap(err, errUnpublishConnection)
+		}
+	}
+	return f.Finalizer.RemoveFinalizer(ctx, obj)
+}
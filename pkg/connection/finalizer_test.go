@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	resourcefake "github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestSecretFinalizerRemoveFinalizer(t *testing.T) {
+	type want struct {
+		unpublished bool
+		removed     bool
+		err         error
+	}
+
+	cases := map[string]struct {
+		reason string
+		obj    resource.Object
+		err    error
+		want   want
+	}{
+		"Unpublished": {
+			reason: "We should unpublish the connection secret of a connection secret owner, then remove its finalizer.",
+			obj:    &resourcefake.MockConnectionSecretOwner{},
+			want: want{
+				unpublished: true,
+				removed:     true,
+			},
+		},
+		"NotConnectionSecretOwner": {
+			reason: "We should only remove the finalizer of a resource that isn't a connection secret owner.",
+			obj:    &resourcefake.Object{},
+			want: want{
+				removed: true,
+			},
+		},
+		"CannotUnpublish": {
+			reason: "We should not remove the finalizer if the connection secret can't be unpublished.",
+			obj:    &resourcefake.MockConnectionSecretOwner{},
+			err:    errBoom,
+			want: want{
+				unpublished: true,
+				err:         errors.Wrap(errBoom, errUnpublishConnection),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := want{}
+			f := NewSecretFinalizer(resource.FinalizerFns{
+				RemoveFinalizerFn: func(_ context.Context, _ resource.Object) error {
+					got.removed = true
+					return nil
+				},
+			}, managed.ConnectionPublisherFns{
+				UnpublishConnectionFn: func(_ context.Context, _ resource.ConnectionSecretOwner, _ managed.ConnectionDetails) error {
+					got.unpublished = true
+					return tc.err
+				},
+			})
+			got.err = f.RemoveFinalizer(context.Background(), tc.obj)
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{}), test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nf.RemoveFinalizer(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+)
+
+// An FS is a read-only fs.FS backed by a Store. The value of the key of a
+// secret is exposed as a file at path scope/name/key, and the keys of a secret
+// are exposed as the entries of a directory at path scope/name. Secrets that
+// don't exist or have no keys don't exist in the FS.
+type FS struct {
+	ctx   context.Context //nolint:containedctx // fs.FS methods don't accept a context.
+	store Store
+}
+
+var (
+	_ fs.ReadFileFS = &FS{}
+	_ fs.ReadDirFS  = &FS{}
+)
+
+// NewFS returns an FS backed by the supplied Store. All reads from the Store
+// use the supplied context.
+func NewFS(ctx context.Context, s Store) *FS {
+	return &FS{ctx: ctx, store: s}
+}
+
+// Open opens the named file or directory.
+func (f *FS) Open(name string) (fs.File, error) {
+	n, key, err := f.parse("open", name)
+	if err != nil {
+		return nil, err
+	}
+	kv, err := f.read("open", name, n)
+	if err != nil {
+		return nil, err
+	}
+	if key == "" {
+		return &dir{name: n.Name, entries: entries(kv)}, nil
+	}
+	v, ok := kv[key]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &file{info: fileInfo{name: key, size: int64(len(v))}, r: bytes.NewReader(v)}, nil
+}
+
+// ReadFile returns the value of the key at the named path.
+func (f *FS) ReadFile(name string) ([]byte, error) {
+	n, key, err := f.parse("readfile", name)
+	if err != nil {
+		return nil, err
+	}
+	if key == "" {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: fs.ErrInvalid}
+	}
+	kv, err := f.read("readfile", name, n)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := kv[key]
+	if !ok {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]byte(nil), v...), nil
+}
+
+// ReadDir returns the keys of the secret at the named path, sorted by key.
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	n, key, err := f.parse("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if key != "" {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	kv, err := f.read("readdir", name, n)
+	if err != nil {
+		return nil, err
+	}
+	return entries(kv), nil
+}
+
+// parse parses a path of the form scope/name or scope/name/key.
+func (f *FS) parse(op, name string) (store.ScopedName, string, error) {
+	if !fs.ValidPath(name) {
+		return store.ScopedName{}, "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	parts := strings.Split(name, "/")
+	switch len(parts) {
+	case 2:
+		return store.ScopedName{Scope: parts[0], Name: parts[1]}, "", nil
+	case 3:
+		return store.ScopedName{Scope: parts[0], Name: parts[1]}, parts[2], nil
+	default:
+		// The Store interface can't list scopes or secrets.
+		return store.ScopedName{}, "", &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+}
+
+func (f *FS) read(op, name string, n store.ScopedName) (store.KeyValues, error) {
+	s := &store.Secret{}
+	err := f.store.ReadKeyValues(f.ctx, n, s)
+	if store.IsNotFound(err) || (err == nil && len(s.Data) == 0) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	return s.Data, nil
+}
+
+func entries(kv store.KeyValues) []fs.DirEntry {
+	out := make([]fs.DirEntry, 0, len(kv))
+	for k, v := range kv {
+		out = append(out, fs.FileInfoToDirEntry(fileInfo{name: k, size: int64(len(v))}))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name() < out[j].Name() })
+	return out
+}
+
+type fileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i fileInfo) Name() string       { return i.name }
+func (i fileInfo) Size() int64        { return i.size }
+func (i fileInfo) ModTime() time.Time { return time.Time{} }
+func (i fileInfo) IsDir() bool        { return i.dir }
+func (i fileInfo) Sys() any           { return nil }
+
+func (i fileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
+
+type file struct {
+	info fileInfo
+	r    *bytes.Reader
+}
+
+func (f *file) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *file) Read(b []byte) (int, error) { return f.r.Read(b) }
+func (f *file) Close() error               { return nil }
+
+type dir struct {
+	name    string
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *dir) Stat() (fs.FileInfo, error) { return fileInfo{name: d.name, dir: true}, nil }
+func (d *dir) Close() error               { return nil }
+
+func (d *dir) Read(_ []byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: fs.ErrInvalid}
+}
+
+// ReadDir implements fs.ReadDirFile.
+func (d *dir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(remaining))
+	d.offset += n
+	return remaining[:n], nil
+}
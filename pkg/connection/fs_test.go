@@ -0,0 +1,173 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+)
+
+func fsStore() Store {
+	secrets := map[store.ScopedName]store.KeyValues{
+		{Scope: "ns", Name: "db"}: {"username": []byte("admin"), "password": []byte("hunter2")},
+	}
+	return &fake.SecretStore{
+		ReadKeyValuesFn: func(_ context.Context, n store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
+			kv, ok := secrets[n]
+			if !ok {
+				return store.NewNotFound("secret not found")
+			}
+			s.Data = kv
+			return nil
+		},
+	}
+}
+
+func TestFSReadFile(t *testing.T) {
+	type want struct {
+		data []byte
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		path   string
+		want   want
+	}{
+		"Key": {
+			reason: "We should read the value of the key at the supplied path.",
+			path:   "ns/db/password",
+			want: want{
+				data: []byte("hunter2"),
+			},
+		},
+		"MissingKey": {
+			reason: "We should return fs.ErrNotExist if the key does not exist.",
+			path:   "ns/db/port",
+			want: want{
+				err: fs.ErrNotExist,
+			},
+		},
+		"MissingSecret": {
+			reason: "We should return fs.ErrNotExist if the secret does not exist.",
+			path:   "ns/cache/password",
+			want: want{
+				err: fs.ErrNotExist,
+			},
+		},
+		"NotAKey": {
+			reason: "We should return fs.ErrNotExist for paths that are neither secrets nor keys.",
+			path:   "ns/db/password/more",
+			want: want{
+				err: fs.ErrNotExist,
+			},
+		},
+		"InvalidPath": {
+			reason: "We should return fs.ErrInvalid for invalid paths.",
+			path:   "/ns/db/password",
+			want: want{
+				err: fs.ErrInvalid,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := fs.ReadFile(NewFS(context.Background(), fsStore()), tc.path)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nfs.ReadFile(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.data, got); diff != "" {
+				t.Errorf("\n%s\nfs.ReadFile(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestFSReadDir(t *testing.T) {
+	type want struct {
+		names []string
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		path   string
+		want   want
+	}{
+		"Secret": {
+			reason: "We should list the keys of the secret at the supplied path.",
+			path:   "ns/db",
+			want: want{
+				names: []string{"password", "username"},
+			},
+		},
+		"MissingSecret": {
+			reason: "We should return fs.ErrNotExist if the secret does not exist.",
+			path:   "ns/cache",
+			want: want{
+				err: fs.ErrNotExist,
+			},
+		},
+		"Scope": {
+			reason: "We should return fs.ErrNotExist for scopes, which can't be listed.",
+			path:   "ns",
+			want: want{
+				err: fs.ErrNotExist,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			entries, err := fs.ReadDir(NewFS(context.Background(), fsStore()), tc.path)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nfs.ReadDir(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			var names []string
+			for _, e := range entries {
+				names = append(names, e.Name())
+			}
+			if diff := cmp.Diff(tc.want.names, names); diff != "" {
+				t.Errorf("\n%s\nfs.ReadDir(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestFSOpen(t *testing.T) {
+	f, err := NewFS(context.Background(), fsStore()).Open("ns/db/username")
+	if err != nil {
+		t.Fatalf("f.Open(...): %v", err)
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		t.Fatalf("f.Stat(): %v", err)
+	}
+	if fi.Name() != "username" || fi.Size() != 5 || fi.IsDir() {
+		t.Errorf("f.Stat(): want a 5 byte file named username, got %q of %d bytes (dir: %t)", fi.Name(), fi.Size(), fi.IsDir())
+	}
+}
@@ -33,7 +33,74 @@ type StoreConfig interface {
 
 // A Store stores sensitive key values in Secret.
 type Store interface {
-	ReadKeyValues(ctx context.Context, n store.ScopedName, s *store.Secret) error
+	ReadKeyValues(ctx context.Context, n store.ScopedName, s *store.Secret, ro ...store.ReadOption) error
 	WriteKeyValues(ctx context.Context, s *store.Secret, wo ...store.WriteOption) (changed bool, err error)
 	DeleteKeyValues(ctx context.Context, s *store.Secret, do ...store.DeleteOption) error
 }
+
+// A Wrapper is a Store that wraps another Store, for example to decorate its
+// operations. Stores that change which secrets back a secret, like a
+// NamingStore, are not Wrappers, because operations on the Store they wrap
+// would affect other secrets.
+type Wrapper interface {
+	// Unwrap returns the Store this Store wraps.
+	Unwrap() Store
+}
+
+// Find returns the supplied Store if it is a T, or else the first Store it
+// wraps that is a T, following the chain of Wrappers. Use Find rather than a
+// type assertion to use optional interfaces like Orphaner or SecretLister of
+// a Store that may be wrapped.
+func Find[T any](s Store) (T, bool) {
+	for s != nil {
+		if t, ok := s.(T); ok {
+			return t, true
+		}
+		w, ok := s.(Wrapper)
+		if !ok {
+			break
+		}
+		s = w.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
+
+// A Shutdowner is a Store that must be shut down before the process exits,
+// for example because it buffers writes or caches reads.
+type Shutdowner interface {
+	// Shutdown flushes any pending writes, blocking until they are complete
+	// or the supplied context is done.
+	Shutdown(ctx context.Context) error
+}
+
+// An Orphaner is a Store that can release a secret from its owner, so that the
+// secret is no longer deleted along with its owner.
+type Orphaner interface {
+	// OrphanKeyValues releases the supplied secret from the supplied
+	// secret's owner, leaving its key values as is.
+	OrphanKeyValues(ctx context.Context, s *store.Secret) error
+}
+
+// A SecretLister lists the secrets managed by Crossplane in a scope.
+type SecretLister interface {
+	// ListSecrets returns the names of the secrets managed by Crossplane in
+	// the supplied scope.
+	ListSecrets(ctx context.Context, scope string) ([]string, error)
+}
+
+// A Rotator is a Store that can rotate the credentials it stores out-of-band,
+// for example a Vault store that issues dynamic secrets.
+type Rotator interface {
+	// Rotate triggers rotation of the supplied secret by the Store's backend,
+	// and returns its new key values.
+	Rotate(ctx context.Context, n store.ScopedName) (store.KeyValues, error)
+}
+
+// A StreamReader is a Store that can read the key values of a secret one at a
+// time, without materializing them all at once. See ReadKeyValuesStream.
+type StreamReader interface {
+	// ReadKeyValuesStream calls the supplied function for each key value of
+	// the supplied secret, and stops at the first error it returns.
+	ReadKeyValuesStream(ctx context.Context, n store.ScopedName, fn func(key string, value []byte) error) error
+}
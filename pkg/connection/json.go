@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errFmtMissingKey     = "secret does not have key %q"
+	errFmtMissingAliases = "secret does not have key %q or any of its aliases %v"
+)
+
+// ReadJSONKey reads the supplied secret from the supplied Store, and
+// unmarshals the JSON document stored as the value of the supplied key into
+// the supplied destination, which must be a pointer. Like a typed read, it
+// returns a *store.ReadError if the secret can't be read, and a
+// *store.DecodeError if the value isn't valid JSON for the destination.
+func ReadJSONKey(ctx context.Context, s Store, n store.ScopedName, key string, dst any) error {
+	sec := &store.Secret{}
+	if err := s.ReadKeyValues(ctx, n, sec); err != nil {
+		return &store.ReadError{Err: err}
+	}
+	v, ok := sec.Data[key]
+	if !ok {
+		return errors.Errorf(errFmtMissingKey, key)
+	}
+	if err := json.Unmarshal(v, dst); err != nil {
+		return &store.DecodeError{Key: key, Field: fmt.Sprintf("%T", dst), Err: err}
+	}
+	return nil
+}
+
+// ReadKeyWithAliases reads the supplied secret from the supplied Store, and
+// returns the value of the supplied key or, if it is absent, the value of the
+// first of the supplied aliases that is present. This is useful while a key is
+// being renamed, when a secret may carry either its new or its old name.
+func ReadKeyWithAliases(ctx context.Context, s Store, n store.ScopedName, key string, aliases ...string) ([]byte, error) {
+	sec := &store.Secret{}
+	if err := s.ReadKeyValues(ctx, n, sec); err != nil {
+		return nil, errors.Wrap(err, errReadStore)
+	}
+	v, ok := sec.Data.Lookup(key, aliases...)
+	if !ok {
+		return nil, errors.Errorf(errFmtMissingAliases, key, aliases)
+	}
+	return v, nil
+}
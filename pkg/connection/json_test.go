@@ -0,0 +1,175 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestReadJSONKey(t *testing.T) {
+	type endpoint struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+
+	invalid := []byte(`{"host": `)
+	errInvalid := json.Unmarshal(invalid, &endpoint{})
+	wrongType := []byte(`{"port": "5432"}`)
+	errWrongType := json.Unmarshal(wrongType, &endpoint{})
+
+	type args struct {
+		data    store.KeyValues
+		readErr error
+	}
+	type want struct {
+		dst endpoint
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ValidJSON": {
+			reason: "We should unmarshal the JSON value of the key.",
+			args: args{
+				data: store.KeyValues{"endpoint": []byte(`{"host": "db.example.org", "port": 5432}`)},
+			},
+			want: want{
+				dst: endpoint{Host: "db.example.org", Port: 5432},
+			},
+		},
+		"InvalidJSON": {
+			reason: "We should return a DecodeError if the value of the key is not valid JSON.",
+			args: args{
+				data: store.KeyValues{"endpoint": invalid},
+			},
+			want: want{
+				err: &store.DecodeError{Key: "endpoint", Field: "*connection.endpoint", Err: errInvalid},
+			},
+		},
+		"WrongType": {
+			reason: "We should return a DecodeError if the value of the key is valid JSON of the wrong type.",
+			args: args{
+				data: store.KeyValues{"endpoint": wrongType},
+			},
+			want: want{
+				err: &store.DecodeError{Key: "endpoint", Field: "*connection.endpoint", Err: errWrongType},
+			},
+		},
+		"MissingKey": {
+			reason: "We should return an error if the secret does not have the key.",
+			args: args{
+				data: store.KeyValues{"other": []byte(`{}`)},
+			},
+			want: want{
+				err: errors.Errorf(errFmtMissingKey, "endpoint"),
+			},
+		},
+		"ReadFailed": {
+			reason: "We should return a ReadError if we can't read the secret.",
+			args: args{
+				readErr: errBoom,
+			},
+			want: want{
+				err: &store.ReadError{Err: errBoom},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := &fake.SecretStore{
+				ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
+					s.Data = tc.args.data
+					return tc.args.readErr
+				},
+			}
+			got := endpoint{}
+			err := ReadJSONKey(context.Background(), s, store.ScopedName{Name: "cool", Scope: "ns"}, "endpoint", &got)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nReadJSONKey(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.dst, got); diff != "" {
+				t.Errorf("\n%s\nReadJSONKey(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestReadKeyWithAliases(t *testing.T) {
+	type want struct {
+		value []byte
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		data   store.KeyValues
+		want   want
+	}{
+		"PrimaryPresent": {
+			reason: "We should return the value of the primary key if it is present.",
+			data:   store.KeyValues{"endpoint": []byte("new"), "host": []byte("old"), "address": []byte("older")},
+			want: want{
+				value: []byte("new"),
+			},
+		},
+		"AliasFallback": {
+			reason: "We should return the value of the first alias that is present if the primary key is absent.",
+			data:   store.KeyValues{"address": []byte("older"), "host": []byte("old")},
+			want: want{
+				value: []byte("old"),
+			},
+		},
+		"AllAbsent": {
+			reason: "We should return an error if neither the primary key nor any alias is present.",
+			data:   store.KeyValues{"other": []byte("other")},
+			want: want{
+				err: errors.Errorf(errFmtMissingAliases, "endpoint", []string{"host", "address"}),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := &fake.SecretStore{
+				ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
+					s.Data = tc.data
+					return nil
+				},
+			}
+			got, err := ReadKeyWithAliases(context.Background(), s, store.ScopedName{Name: "cool", Scope: "ns"}, "endpoint", "host", "address")
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nReadKeyWithAliases(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.value, got); diff != "" {
+				t.Errorf("\n%s\nReadKeyWithAliases(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
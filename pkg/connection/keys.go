@@ -0,0 +1,128 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"crypto/tls"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errGetKeysSecret       = "cannot get secret holding key encryption keys"
+	errNoKeysSecretRef     = "encryption requires a reference to a secret holding key encryption keys"
+	errBuildKeyProvider    = "cannot build key provider"
+	errNoPrimaryKeyVersion = "encryption requires a primary key version"
+)
+
+// A SecretKeyProvider is a KeyProvider backed by AES key encryption keys held
+// by a Kubernetes Secret. Each key of the Secret is a key version, and its
+// value is the key encryption key with that version. The Secret is read once,
+// when it is first needed, so that a SecretKeyProvider sees a consistent set
+// of keys for its whole lifetime.
+type SecretKeyProvider struct {
+	client  client.Reader
+	ref     v1.SecretReference
+	primary string
+
+	mu   sync.Mutex
+	keys *StaticKeyProvider
+}
+
+// NewSecretKeyProvider returns a SecretKeyProvider that wraps data keys using
+// the key with the supplied primary version, and unwraps them using any of the
+// keys held by the referenced Secret.
+func NewSecretKeyProvider(c client.Reader, ref v1.SecretReference, primary string) *SecretKeyProvider {
+	return &SecretKeyProvider{client: c, ref: ref, primary: primary}
+}
+
+// WrapKey encrypts the supplied data key using the primary key.
+func (p *SecretKeyProvider) WrapKey(ctx context.Context, dek []byte) (string, []byte, error) {
+	kp, err := p.load(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	return kp.WrapKey(ctx, dek)
+}
+
+// UnwrapKey decrypts the supplied data key using the key with the supplied
+// version.
+func (p *SecretKeyProvider) UnwrapKey(ctx context.Context, version string, wrapped []byte) ([]byte, error) {
+	kp, err := p.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return kp.UnwrapKey(ctx, version, wrapped)
+}
+
+func (p *SecretKeyProvider) load(ctx context.Context) (*StaticKeyProvider, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.keys != nil {
+		return p.keys, nil
+	}
+	s := &corev1.Secret{}
+	if err := p.client.Get(ctx, types.NamespacedName{Name: p.ref.Name, Namespace: p.ref.Namespace}, s); err != nil {
+		return nil, errors.Wrap(err, errGetKeysSecret)
+	}
+	p.keys = NewStaticKeyProvider(p.primary, s.Data)
+	return p.keys, nil
+}
+
+// A KeyProviderBuilderFn builds and returns a KeyProvider for the supplied
+// encryption config. A KeyProviderBuilderFn may for example return a
+// KeyProvider backed by an external key management service.
+type KeyProviderBuilderFn func(ctx context.Context, local client.Client, cfg v1.SecretStoreEncryptionConfig) (KeyProvider, error)
+
+// SecretKeyProviderBuilder builds a SecretKeyProvider backed by the Secret the
+// supplied encryption config references.
+func SecretKeyProviderBuilder(_ context.Context, local client.Client, cfg v1.SecretStoreEncryptionConfig) (KeyProvider, error) {
+	if cfg.KeysSecretRef == nil {
+		return nil, errors.New(errNoKeysSecretRef)
+	}
+	return NewSecretKeyProvider(local, *cfg.KeysSecretRef, cfg.PrimaryKeyVersion), nil
+}
+
+// EncryptingStoreBuilder returns a StoreBuilderFn that wraps the Stores built
+// by the supplied StoreBuilderFn in an EncryptingStore, if their config
+// enables encryption. Their KeyProvider is built by the supplied
+// KeyProviderBuilderFn. Stores whose config doesn't enable encryption are
+// returned as is.
+func EncryptingStoreBuilder(sb StoreBuilderFn, kb KeyProviderBuilderFn) StoreBuilderFn {
+	return func(ctx context.Context, local client.Client, tcfg *tls.Config, cfg v1.SecretStoreConfig) (Store, error) {
+		s, err := sb(ctx, local, tcfg, cfg)
+		if err != nil || cfg.Encryption == nil {
+			return s, err
+		}
+		if cfg.Encryption.PrimaryKeyVersion == "" {
+			return nil, errors.New(errNoPrimaryKeyVersion)
+		}
+		kp, err := kb(ctx, local, *cfg.Encryption)
+		if err != nil {
+			return nil, errors.Wrap(err, errBuildKeyProvider)
+		}
+		return NewEncryptingStore(s, kp), nil
+	}
+}
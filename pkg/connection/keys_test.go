@@ -0,0 +1,156 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"crypto/tls"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func keysSecret(gets *int, keys map[string][]byte) client.Client {
+	return &test.MockClient{
+		MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+			*gets++
+			obj.(*corev1.Secret).Data = keys
+			return nil
+		},
+	}
+}
+
+func TestSecretKeyProviderRotation(t *testing.T) {
+	n := store.ScopedName{Name: "cool", Scope: "ns"}
+	ref := v1.SecretReference{Name: "keys", Namespace: "crossplane-system"}
+	m := map[store.ScopedName]store.KeyValues{}
+
+	// Write a value using key version v1.
+	gets := 0
+	old := NewSecretKeyProvider(keysSecret(&gets, map[string][]byte{"v1": kek1}), ref, "v1")
+	if _, err := NewEncryptingStore(memoryStore(m, true), old).WriteKeyValues(context.Background(), &store.Secret{ScopedName: n, Data: store.KeyValues{"old": []byte("a")}}); err != nil {
+		t.Fatalf("WriteKeyValues(...): %v", err)
+	}
+
+	// Rotate to key version v2, keeping v1, and write another value to the
+	// same secret. The memory store overwrites secrets, so we keep the value
+	// encrypted using v1.
+	oldValue := m[n]["old"]
+	rotated := NewSecretKeyProvider(keysSecret(&gets, map[string][]byte{"v1": kek1, "v2": kek2}), ref, "v2")
+	e := NewEncryptingStore(memoryStore(m, true), rotated)
+	if _, err := e.WriteKeyValues(context.Background(), &store.Secret{ScopedName: n, Data: store.KeyValues{"new": []byte("b")}}); err != nil {
+		t.Fatalf("WriteKeyValues(...): %v", err)
+	}
+	m[n]["old"] = oldValue
+
+	s := &store.Secret{}
+	if err := e.ReadKeyValues(context.Background(), n, s); err != nil {
+		t.Fatalf("ReadKeyValues(...): %v", err)
+	}
+	if diff := cmp.Diff(store.KeyValues{"old": []byte("a"), "new": []byte("b")}, s.Data); diff != "" {
+		t.Errorf("ReadKeyValues(...): -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff("v2", keyVersion(t, m[n]["new"])); diff != "" {
+		t.Errorf("WriteKeyValues(...): -want key version, +got key version:\n%s", diff)
+	}
+
+	// Each SecretKeyProvider should read its keys only once.
+	if diff := cmp.Diff(2, gets); diff != "" {
+		t.Errorf("SecretKeyProvider: -want gets, +got gets:\n%s", diff)
+	}
+}
+
+func TestEncryptingStoreBuilder(t *testing.T) {
+	inner := &fake.SecretStore{}
+	sb := func(_ context.Context, _ client.Client, _ *tls.Config, _ v1.SecretStoreConfig) (Store, error) {
+		return inner, nil
+	}
+	kp := NewStaticKeyProvider("v1", map[string][]byte{"v1": kek1})
+
+	type args struct {
+		cfg v1.SecretStoreConfig
+		kb  KeyProviderBuilderFn
+	}
+	type want struct {
+		s   Store
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoEncryption": {
+			reason: "We should return the built Store as is if its config doesn't enable encryption.",
+			want: want{
+				s: inner,
+			},
+		},
+		"NoPrimaryKeyVersion": {
+			reason: "We should return an error if encryption is enabled without a primary key version.",
+			args: args{
+				cfg: v1.SecretStoreConfig{Encryption: &v1.SecretStoreEncryptionConfig{}},
+			},
+			want: want{
+				err: errors.New(errNoPrimaryKeyVersion),
+			},
+		},
+		"NoKeysSecretRef": {
+			reason: "We should return an error if a SecretKeyProvider is used without a reference to a secret.",
+			args: args{
+				cfg: v1.SecretStoreConfig{Encryption: &v1.SecretStoreEncryptionConfig{PrimaryKeyVersion: "v1"}},
+				kb:  SecretKeyProviderBuilder,
+			},
+			want: want{
+				err: errors.Wrap(errors.New(errNoKeysSecretRef), errBuildKeyProvider),
+			},
+		},
+		"Encrypting": {
+			reason: "We should wrap the built Store in an EncryptingStore using the built KeyProvider.",
+			args: args{
+				cfg: v1.SecretStoreConfig{Encryption: &v1.SecretStoreEncryptionConfig{PrimaryKeyVersion: "v1"}},
+				kb: func(_ context.Context, _ client.Client, _ v1.SecretStoreEncryptionConfig) (KeyProvider, error) {
+					return kp, nil
+				},
+			},
+			want: want{
+				s: NewEncryptingStore(inner, kp),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s, err := EncryptingStoreBuilder(sb, tc.args.kb)(context.Background(), &test.MockClient{}, nil, tc.args.cfg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nEncryptingStoreBuilder(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.s, s, cmp.AllowUnexported(EncryptingStore{}, StaticKeyProvider{}), cmp.Comparer(func(a, b *fake.SecretStore) bool { return a == b })); diff != "" {
+				t.Errorf("\n%s\nEncryptingStoreBuilder(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errListSecrets       = "cannot list secrets"
+	errFmtTooManySecrets = "cannot create secret %q: scope %q already contains %d secrets, the maximum allowed"
+)
+
+// A CountLimitedStore is a Store that limits how many secrets may be created
+// in each scope, for example to prevent runaway secret creation. Writes that
+// would create a new secret in a scope that already contains the maximum
+// allowed number of secrets are rejected. Writes to existing secrets are
+// always allowed.
+type CountLimitedStore struct {
+	store  Store
+	lister SecretLister
+	max    int
+}
+
+// NewCountLimitedStore returns a CountLimitedStore that wraps the supplied
+// Store, allowing at most the supplied number of secrets per scope as listed
+// by the supplied SecretLister.
+func NewCountLimitedStore(s Store, l SecretLister, maxSecrets int) *CountLimitedStore {
+	return &CountLimitedStore{store: s, lister: l, max: maxSecrets}
+}
+
+// Unwrap returns the Store the CountLimitedStore wraps.
+func (s *CountLimitedStore) Unwrap() Store {
+	return s.store
+}
+
+// ReadKeyValues reads key values from the wrapped Store.
+func (s *CountLimitedStore) ReadKeyValues(ctx context.Context, n store.ScopedName, sec *store.Secret, ro ...store.ReadOption) error {
+	return s.store.ReadKeyValues(ctx, n, sec, ro...)
+}
+
+// WriteKeyValues writes key values to the wrapped Store, unless doing so would
+// create a secret in a scope that contains the maximum allowed number of
+// secrets.
+func (s *CountLimitedStore) WriteKeyValues(ctx context.Context, sec *store.Secret, wo ...store.WriteOption) (bool, error) {
+	names, err := s.lister.ListSecrets(ctx, sec.Scope)
+	if err != nil {
+		return false, errors.Wrap(err, errListSecrets)
+	}
+	for _, n := range names {
+		if n == sec.Name {
+			// This is an update, which doesn't increase the secret count.
+			return s.store.WriteKeyValues(ctx, sec, wo...)
+		}
+	}
+	if len(names) >= s.max {
+		return false, errors.Errorf(errFmtTooManySecrets, sec.Name, sec.Scope, len(names))
+	}
+	return s.store.WriteKeyValues(ctx, sec, wo...)
+}
+
+// DeleteKeyValues deletes key values from the wrapped Store.
+func (s *CountLimitedStore) DeleteKeyValues(ctx context.Context, sec *store.Secret, do ...store.DeleteOption) error {
+	return s.store.DeleteKeyValues(ctx, sec, do...)
+}
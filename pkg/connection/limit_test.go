@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+type secretListerFn func(ctx context.Context, scope string) ([]string, error)
+
+func (fn secretListerFn) ListSecrets(ctx context.Context, scope string) ([]string, error) {
+	return fn(ctx, scope)
+}
+
+func TestCountLimitedStoreWriteKeyValues(t *testing.T) {
+	type args struct {
+		existing []string
+		listErr  error
+		secret   string
+	}
+	type want struct {
+		written bool
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"CreateUnderLimit": {
+			reason: "We should allow creating a secret in a scope under the limit.",
+			args: args{
+				existing: []string{"a"},
+				secret:   "b",
+			},
+			want: want{
+				written: true,
+			},
+		},
+		"UpdateAtLimit": {
+			reason: "We should allow updating an existing secret in a scope at the limit.",
+			args: args{
+				existing: []string{"a", "b"},
+				secret:   "b",
+			},
+			want: want{
+				written: true,
+			},
+		},
+		"CreateAtLimit": {
+			reason: "We should reject creating a secret in a scope at the limit.",
+			args: args{
+				existing: []string{"a", "b"},
+				secret:   "c",
+			},
+			want: want{
+				err: errors.Errorf(errFmtTooManySecrets, "c", "ns", 2),
+			},
+		},
+		"ListFailed": {
+			reason: "We should return an error if we can't list secrets.",
+			args: args{
+				listErr: errBoom,
+				secret:  "c",
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errListSecrets),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			written := false
+			s := NewCountLimitedStore(&fake.SecretStore{
+				WriteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.WriteOption) (bool, error) {
+					written = true
+					return true, nil
+				},
+			}, secretListerFn(func(_ context.Context, _ string) ([]string, error) {
+				return tc.args.existing, tc.args.listErr
+			}), 2)
+
+			_, err := s.WriteKeyValues(context.Background(), &store.Secret{ScopedName: store.ScopedName{Name: tc.args.secret, Scope: "ns"}})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ns.WriteKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.written, written); diff != "" {
+				t.Errorf("\n%s\ns.WriteKeyValues(...): -want written, +got written:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+)
+
+// A LoggingStore is a Store that logs the operations of the Store it wraps.
+// Each message includes the structured values carried by the operation's
+// context, e.g. its request ID and controller name.
+type LoggingStore struct {
+	store Store
+	log   logging.Logger
+}
+
+// NewLoggingStore returns a LoggingStore that wraps the supplied Store.
+func NewLoggingStore(s Store, l logging.Logger) *LoggingStore {
+	return &LoggingStore{store: s, log: l}
+}
+
+// Unwrap returns the Store the LoggingStore wraps.
+func (s *LoggingStore) Unwrap() Store {
+	return s.store
+}
+
+// ReadKeyValues reads key values from the wrapped Store.
+func (s *LoggingStore) ReadKeyValues(ctx context.Context, n store.ScopedName, sec *store.Secret, ro ...store.ReadOption) error {
+	err := s.store.ReadKeyValues(ctx, n, sec, ro...)
+	s.logger(ctx, n).Debug("Read connection secret", "error", err)
+	return err
+}
+
+// WriteKeyValues writes key values to the wrapped Store.
+func (s *LoggingStore) WriteKeyValues(ctx context.Context, sec *store.Secret, wo ...store.WriteOption) (bool, error) {
+	changed, err := s.store.WriteKeyValues(ctx, sec, wo...)
+	s.logger(ctx, sec.ScopedName).Debug("Wrote connection secret", "changed", changed, "error", err)
+	return changed, err
+}
+
+// DeleteKeyValues deletes key values from the wrapped Store.
+func (s *LoggingStore) DeleteKeyValues(ctx context.Context, sec *store.Secret, do ...store.DeleteOption) error {
+	err := s.store.DeleteKeyValues(ctx, sec, do...)
+	s.logger(ctx, sec.ScopedName).Debug("Deleted connection secret", "error", err)
+	return err
+}
+
+func (s *LoggingStore) logger(ctx context.Context, n store.ScopedName) logging.Logger {
+	return s.log.WithValues(append([]any{"secret-name", n.Name, "secret-scope", n.Scope}, store.ContextFields(ctx)...)...)
+}
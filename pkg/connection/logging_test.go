@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+)
+
+// capturingLogger records the structured values of the messages it logs.
+type capturingLogger struct {
+	values []any
+	out    *[][]any
+}
+
+func (l capturingLogger) Info(_ string, kv ...any) {
+	*l.out = append(*l.out, append(append([]any{}, l.values...), kv...))
+}
+
+func (l capturingLogger) Debug(_ string, kv ...any) {
+	*l.out = append(*l.out, append(append([]any{}, l.values...), kv...))
+}
+
+func (l capturingLogger) WithValues(kv ...any) logging.Logger {
+	return capturingLogger{values: append(append([]any{}, l.values...), kv...), out: l.out}
+}
+
+func TestLoggingStoreContextFields(t *testing.T) {
+	n := store.ScopedName{Name: "cool", Scope: "ns"}
+
+	cases := map[string]struct {
+		reason string
+		ctx    context.Context
+		want   []any
+	}{
+		"NoContextValues": {
+			reason: "We should only log the secret if the context carries no structured values.",
+			ctx:    context.Background(),
+			want:   []any{"secret-name", "cool", "secret-scope", "ns", "changed", true, "error", nil},
+		},
+		"ContextValues": {
+			reason: "We should log the request ID and controller name carried by the context.",
+			ctx:    store.WithControllerName(store.WithRequestID(context.Background(), "req-1"), "managed/cool"),
+			want:   []any{"secret-name", "cool", "secret-scope", "ns", "request-id", "req-1", "controller", "managed/cool", "changed", true, "error", nil},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var logged [][]any
+			ls := NewLoggingStore(&fake.SecretStore{
+				WriteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.WriteOption) (bool, error) { return true, nil },
+			}, capturingLogger{out: &logged})
+
+			_, _ = ls.WriteKeyValues(tc.ctx, &store.Secret{ScopedName: n})
+
+			if diff := cmp.Diff([][]any{tc.want}, logged); diff != "" {
+				t.Errorf("\n%s\nls.WriteKeyValues(...): -want fields, +got fields:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -21,14 +21,20 @@ import (
 	"context"
 	"crypto/tls"
 
+	"github.com/prometheus/client_golang/prometheus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 
 	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 )
@@ -39,10 +45,21 @@ const (
 	errWriteStore      = "cannot write to secret store"
 	errReadStore       = "cannot read from secret store"
 	errDeleteFromStore = "cannot delete from secret store"
+	errOrphanInStore   = "cannot orphan secret in secret store"
 	errGetStoreConfig  = "cannot get store config"
 	errSecretConflict  = "cannot establish control of existing connection secret"
+	errInvalidEncoding = "invalid connection details encoding"
+	errInvalidMetadata = "invalid connection secret metadata"
+	errRenderTemplates = "cannot render templated connection details"
 
-	errFmtNotOwnedBy = "existing secret is not owned by UID %q"
+	errFmtNotOwnedBy       = "existing secret is not owned by UID %q"
+	errFmtResolveOwnerKind = "cannot resolve the apiVersion and kind of owner %q: its type must be registered with the owner scheme"
+)
+
+// Event reasons.
+const (
+	reasonCannotPublish   event.Reason = "CannotPublishConnectionDetails"
+	reasonCannotUnpublish event.Reason = "CannotUnpublishConnectionDetails"
 )
 
 // StoreBuilderFn is a function that builds and returns a Store with a given
@@ -52,7 +69,12 @@ type StoreBuilderFn func(ctx context.Context, local client.Client, tcfg *tls.Con
 // A DetailsManagerOption configures a DetailsManager.
 type DetailsManagerOption func(*DetailsManager)
 
-// WithStoreBuilder configures the StoreBuilder to use.
+// WithStoreBuilder configures the StoreBuilder to use. By default Stores are
+// built by a StoreBuilderFn returned by NewRuntimeStoreBuilder, configured with
+// the DetailsManager's logger and event recorder, and are wrapped in an
+// EncryptingStore backed by a SecretKeyProvider if their config enables
+// encryption. Use EncryptingStoreBuilder to encrypt using another KeyProvider,
+// for example one backed by an external key management service.
 func WithStoreBuilder(sb StoreBuilderFn) DetailsManagerOption {
 	return func(m *DetailsManager) {
 		m.storeBuilder = sb
@@ -66,6 +88,112 @@ func WithTLSConfig(tcfg *tls.Config) DetailsManagerOption {
 	}
 }
 
+// WithValueEncoding configures the DetailsManager to refuse to write
+// connection details whose values violate any of the supplied encoding
+// constraints. Values are not validated by default.
+func WithValueEncoding(ec ...store.EncodingConstraint) DetailsManagerOption {
+	return func(m *DetailsManager) {
+		m.encoding = ec
+	}
+}
+
+// WithTemplatedKeys configures the DetailsManager to render the values of the
+// supplied keys as Go templates that may reference other connection details
+// before publishing them, e.g. to derive a url from a host and port. See
+// store.RenderTemplates.
+func WithTemplatedKeys(keys ...string) DetailsManagerOption {
+	return func(m *DetailsManager) {
+		m.templated = keys
+	}
+}
+
+// WithStoreConfigKeyPrefix configures the DetailsManager to prefix the keys it
+// writes with the name of the StoreConfig they're written to, and to only read
+// keys with that prefix. This isolates tenants that use different StoreConfigs
+// to share an external store. See KeyPrefixStore.
+func WithStoreConfigKeyPrefix() DetailsManagerOption {
+	return func(m *DetailsManager) {
+		m.keyPrefix = true
+	}
+}
+
+// WithPropagatedOwnerLabels configures the DetailsManager to copy the supplied
+// labels of a connection secret's owner, e.g. "environment" or "team", to the
+// connection secret whenever it is published. Labels the secret's metadata
+// already sets, including store managed labels like the owner UID label, take
+// precedence over propagated labels. No labels are propagated by default.
+func WithPropagatedOwnerLabels(keys ...string) DetailsManagerOption {
+	return func(m *DetailsManager) {
+		m.ownerLabels = keys
+	}
+}
+
+// WithOwnerScheme configures the DetailsManager to resolve the apiVersion and
+// kind of a connection secret's owner using the supplied scheme, when the owner
+// doesn't carry them. Typed objects read using a controller-runtime client
+// usually don't. Publishing fails if the owner's type isn't registered with
+// the scheme. Owners that don't carry their apiVersion and kind are referenced
+// without them by default.
+func WithOwnerScheme(s *runtime.Scheme) DetailsManagerOption {
+	return func(m *DetailsManager) {
+		m.ownerScheme = s
+	}
+}
+
+// WithSecretDeletionPolicy configures what the DetailsManager does with a
+// connection secret when its owner is deleted. v1.DeletionOrphan leaves the
+// secret in its store instead of deleting it. Stores that are an Orphaner
+// release the secret from its owner, so that it isn't garbage collected along
+// with its owner. v1.DeletionDelete is used by default.
+func WithSecretDeletionPolicy(p v1.DeletionPolicy) DetailsManagerOption {
+	return func(m *DetailsManager) {
+		m.deletionPolicy = p
+	}
+}
+
+// WithStoreMetrics configures the DetailsManager to wrap the Stores it connects
+// to in an InstrumentedStore that registers its metrics with the supplied
+// Registerer, typically metrics.Registry of controller-runtime. Operation
+// metrics are labelled with the type of the Store and the name of its
+// StoreConfig. Stores are not instrumented by default.
+func WithStoreMetrics(r prometheus.Registerer) DetailsManagerOption {
+	return func(m *DetailsManager) {
+		m.metrics = r
+	}
+}
+
+// WithStoreQuotas configures the DetailsManager to wrap the Stores it connects
+// to in a QuotaStore, if the supplied quotas include their StoreConfig. The
+// quotas are the maximum number of bytes that may be written to the Stores of
+// each StoreConfig, by name. Usage is recorded by the supplied QuotaTracker,
+// so that it is shared by all Stores the DetailsManager connects to. Writes
+// are not limited by default.
+func WithStoreQuotas(t *QuotaTracker, quotas map[string]int64) DetailsManagerOption {
+	return func(m *DetailsManager) {
+		m.quotaTracker = t
+		m.quotas = quotas
+	}
+}
+
+// WithEventRecorder configures the DetailsManager to record a warning event on
+// a connection secret's owner when publishing or unpublishing its connection
+// details fails. The Stores built by the default StoreBuilder record their
+// warnings using it too. No events are recorded by default, because
+// reconcilers usually record their own.
+func WithEventRecorder(r event.Recorder) DetailsManagerOption {
+	return func(m *DetailsManager) {
+		m.recorder = r
+	}
+}
+
+// WithLogger configures the logger the Stores built by the default
+// StoreBuilder use. Nothing is logged by default.
+func WithLogger(l logging.Logger) DetailsManagerOption {
+	return func(m *DetailsManager) {
+		m.log = l
+	}
+}
+
 // DetailsManager is a connection details manager that satisfies the required
 // interfaces to work with connection details by managing interaction with
 // different store implementations.
@@ -74,6 +202,19 @@ type DetailsManager struct {
 	newConfig    func() StoreConfig
 	storeBuilder StoreBuilderFn
 	tcfg         *tls.Config
+	encoding     []store.EncodingConstraint
+	templated    []string
+	keyPrefix    bool
+	ownerLabels  []string
+	ownerScheme  *runtime.Scheme
+
+	deletionPolicy v1.DeletionPolicy
+
+	metrics      prometheus.Registerer
+	quotaTracker *QuotaTracker
+	quotas       map[string]int64
+	recorder     event.Recorder
+	log          logging.Logger
 }
 
 // NewDetailsManager returns a new connection DetailsManager.
@@ -88,88 +229,284 @@ func NewDetailsManager(c client.Client, of schema.GroupVersionKind, o ...Details
 	_ = nc()
 
 	m := &DetailsManager{
-		client:       c,
-		newConfig:    nc,
-		storeBuilder: RuntimeStoreBuilder,
+		client:    c,
+		newConfig: nc,
+		recorder:  event.NewNopRecorder(),
+		log:       logging.NewNopLogger(),
 	}
 
 	for _, mo := range o {
 		mo(m)
 	}
 
+	if m.storeBuilder == nil {
+		sb := NewRuntimeStoreBuilder(WithStoreLogger(m.log), WithStoreEventRecorder(m.recorder))
+		m.storeBuilder = EncryptingStoreBuilder(sb, SecretKeyProviderBuilder)
+	}
+
 	return m
 }
 
 // PublishConnection publishes the supplied ConnectionDetails to a secret on
-// the configured connection Store.
+// the configured connection Store. The write carries an idempotency key that
+// Stores supporting idempotent writes may use to skip a write they have
+// already performed, e.g. before the process was restarted. Owners that publish
+// to multiple targets are published to each of them; a *PartialError is
+// returned if publishing to any of them fails.
 func (m *DetailsManager) PublishConnection(ctx context.Context, so resource.ConnectionSecretOwner, conn managed.ConnectionDetails) (bool, error) {
+	published, err := m.publishConnection(ctx, so, conn)
+	return published, m.recordFailure(so, reasonCannotPublish, err)
+}
+
+func (m *DetailsManager) publishConnection(ctx context.Context, so resource.ConnectionSecretOwner, conn managed.ConnectionDetails) (bool, error) {
 	// This resource does not want to expose a connection secret.
-	p := so.GetPublishConnectionDetailsTo()
-	if p == nil {
+	targets := publishTargets(so)
+	if len(targets) == 0 {
 		return false, nil
 	}
 
+	kv, err := store.RenderTemplates(store.KeyValues(conn), m.templated...)
+	if err != nil {
+		return false, errors.Wrap(err, errRenderTemplates)
+	}
+
+	if err := store.ValidateEncoding(kv, m.encoding...); err != nil {
+		return false, errors.Wrap(err, errInvalidEncoding)
+	}
+
+	if len(targets) == 1 {
+		return m.publishTo(ctx, so, targets[0], kv)
+	}
+
+	published := false
+	err = eachTarget("publish", targets, func(p *v1.PublishConnectionDetailsTo) error {
+		changed, err := m.publishTo(ctx, so, p, kv)
+		published = published || changed
+		return err
+	})
+	return published, err
+}
+
+// publishTo writes the supplied key values to the supplied target of the
+// supplied owner. It is used both to publish and to propagate connection
+// details, so that the secret is prepared the same way for either.
+func (m *DetailsManager) publishTo(ctx context.Context, so resource.Object, p *v1.PublishConnectionDetailsTo, kv store.KeyValues) (bool, error) {
+	if err := store.ValidateMetadata(p.Metadata, so.GetUID()); err != nil {
+		return false, errors.Wrap(err, errInvalidMetadata)
+	}
+
 	ss, err := m.connectStore(ctx, p)
 	if err != nil {
 		return false, errors.Wrap(err, errConnectStore)
 	}
 
-	changed, err := ss.WriteKeyValues(ctx, store.NewSecret(so, store.KeyValues(conn)), SecretToWriteMustBeOwnedBy(so))
+	s := store.NewSecretTo(so, p, kv)
+	if err := m.resolveOwnerKind(s, so); err != nil {
+		return false, err
+	}
+	propagateLabels(s, so.GetLabels(), m.ownerLabels...)
+	changed, err := ss.WriteKeyValues(store.WithIdempotencyKey(ctx, store.IdempotencyKey(s)), s, SecretToWriteMustBeOwnedBy(so))
 	return changed, errors.Wrap(err, errWriteStore)
 }
 
+// publishTargets returns the targets the supplied owner publishes its
+// connection details to.
+func publishTargets(so resource.ConnectionDetailsPublisherTo) []*v1.PublishConnectionDetailsTo {
+	if all := so.GetPublishConnectionDetailsToAll(); len(all) > 0 {
+		targets := make([]*v1.PublishConnectionDetailsTo, len(all))
+		for i := range all {
+			targets[i] = &all[i]
+		}
+		return targets
+	}
+	if p := so.GetPublishConnectionDetailsTo(); p != nil {
+		return []*v1.PublishConnectionDetailsTo{p}
+	}
+	return nil
+}
+
+// eachTarget calls the supplied function for each of the supplied targets, in
+// order. Failures are reported per StoreConfig, in a *PartialError.
+func eachTarget(op string, targets []*v1.PublishConnectionDetailsTo, fn func(p *v1.PublishConnectionDetailsTo) error) error {
+	pe := &PartialError{Operation: op}
+	for _, p := range targets {
+		name := targetName(p)
+		if err := fn(p); err != nil {
+			pe.Failed = append(pe.Failed, StoreError{Store: name, Err: err})
+			continue
+		}
+		pe.Succeeded = append(pe.Succeeded, name)
+	}
+	if len(pe.Failed) == 0 {
+		return nil
+	}
+	return pe
+}
+
+// targetName identifies the supplied target by its StoreConfig and secret.
+func targetName(p *v1.PublishConnectionDetailsTo) string {
+	if p.SecretStoreConfigRef == nil {
+		return p.Name
+	}
+	return p.SecretStoreConfigRef.Name + "/" + p.Name
+}
+
+// resolveOwnerKind sets the apiVersion and kind of the supplied secret's owner
+// reference using the owner scheme, if the owner doesn't carry them.
+func (m *DetailsManager) resolveOwnerKind(s *store.Secret, so resource.Object) error {
+	if m.ownerScheme == nil || (s.Owner.APIVersion != "" && s.Owner.Kind != "") {
+		return nil
+	}
+	gvk, err := apiutil.GVKForObject(so, m.ownerScheme)
+	if err != nil {
+		return errors.Wrapf(err, errFmtResolveOwnerKind, so.GetName())
+	}
+	s.Owner.APIVersion, s.Owner.Kind = gvk.GroupVersion().String(), gvk.Kind
+	return nil
+}
+
+// propagateLabels copies the supplied keys of the supplied labels to the
+// supplied secret, unless the secret already sets them.
+func propagateLabels(s *store.Secret, from map[string]string, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+	// The secret's metadata is the owner's, so we must not mutate it.
+	s.Metadata = s.Metadata.DeepCopy()
+	if s.Metadata.Labels == nil {
+		s.Metadata.Labels = map[string]string{}
+	}
+	for _, k := range keys {
+		v, ok := from[k]
+		if !ok {
+			continue
+		}
+		if _, set := s.Metadata.Labels[k]; !set {
+			s.Metadata.Labels[k] = v
+		}
+	}
+}
+
 // UnpublishConnection deletes connection details secret to the configured
-// connection Store.
+// connection Store. If the supplied owner was deleted and the DetailsManager
+// was configured WithSecretDeletionPolicy(v1.DeletionOrphan), the whole secret
+// is orphaned instead. Owners that publish to multiple targets are unpublished
+// from each of them; a *PartialError is returned if unpublishing from any of
+// them fails.
 func (m *DetailsManager) UnpublishConnection(ctx context.Context, so resource.ConnectionSecretOwner, conn managed.ConnectionDetails) error {
+	return m.recordFailure(so, reasonCannotUnpublish, m.unpublishConnection(ctx, so, conn))
+}
+
+func (m *DetailsManager) unpublishConnection(ctx context.Context, so resource.ConnectionSecretOwner, conn managed.ConnectionDetails) error {
 	// This resource didn't expose a connection secret.
-	p := so.GetPublishConnectionDetailsTo()
-	if p == nil {
+	targets := publishTargets(so)
+	switch len(targets) {
+	case 0:
 		return nil
+	case 1:
+		return m.unpublishFrom(ctx, so, targets[0], conn)
 	}
+	return eachTarget("unpublish", targets, func(p *v1.PublishConnectionDetailsTo) error {
+		return m.unpublishFrom(ctx, so, p, conn)
+	})
+}
 
+func (m *DetailsManager) unpublishFrom(ctx context.Context, so resource.ConnectionSecretOwner, p *v1.PublishConnectionDetailsTo, conn managed.ConnectionDetails) error {
 	ss, err := m.connectStore(ctx, p)
 	if err != nil {
 		return errors.Wrap(err, errConnectStore)
 	}
 
-	return errors.Wrap(ss.DeleteKeyValues(ctx, store.NewSecret(so, store.KeyValues(conn)), SecretToDeleteMustBeOwnedBy(so)), errDeleteFromStore)
+	s := store.NewSecretTo(so, p, store.KeyValues(conn))
+	if len(s.Data) == 0 {
+		// Unpublishing without connection details unpublishes the whole
+		// secret. Stores delete the whole secret when asked to delete nil
+		// data, while deleting empty data may be a no-op.
+		s.Data = nil
+	}
+	if s.Data == nil && meta.WasDeleted(so) && m.deletionPolicy == v1.DeletionOrphan {
+		if o, ok := Find[Orphaner](ss); ok {
+			return errors.Wrap(o.OrphanKeyValues(ctx, s), errOrphanInStore)
+		}
+		return nil
+	}
+	return errors.Wrap(ss.DeleteKeyValues(ctx, s, SecretToDeleteMustBeOwnedBy(so)), errDeleteFromStore)
 }
 
 // FetchConnection fetches connection details of a given ConnectionSecretOwner.
+// Owners that publish to multiple targets publish the same connection details
+// to each of them, so they are fetched from the first of their targets that
+// has any. A *PartialError is returned if none of them has connection details
+// and any of them can't be read.
 func (m *DetailsManager) FetchConnection(ctx context.Context, so resource.ConnectionSecretOwner) (managed.ConnectionDetails, error) {
+	s, err := m.fetchSecret(ctx, so)
+	if err != nil || s == nil {
+		return nil, err
+	}
+	return managed.ConnectionDetails(s.Data), nil
+}
+
+// fetchSecret reads the connection secret of the supplied owner from the first
+// of its targets that has any connection details. It returns nil if the owner
+// doesn't publish connection details.
+func (m *DetailsManager) fetchSecret(ctx context.Context, so resource.ConnectionSecretOwner) (*store.Secret, error) {
 	// This resource does not want to expose a connection secret.
-	p := so.GetPublishConnectionDetailsTo()
-	if p == nil {
+	targets := publishTargets(so)
+	switch len(targets) {
+	case 0:
 		return nil, nil
+	case 1:
+		return m.fetchFrom(ctx, so, targets[0])
+	}
+
+	pe := &PartialError{Operation: "fetch"}
+	for _, p := range targets {
+		s, err := m.fetchFrom(ctx, so, p)
+		if err != nil {
+			pe.Failed = append(pe.Failed, StoreError{Store: targetName(p), Err: err})
+			continue
+		}
+		if len(s.Data) > 0 {
+			return s, nil
+		}
+		pe.Succeeded = append(pe.Succeeded, targetName(p))
+	}
+	if len(pe.Failed) > 0 {
+		return nil, pe
 	}
+	return &store.Secret{}, nil
+}
 
+func (m *DetailsManager) fetchFrom(ctx context.Context, so resource.ConnectionSecretOwner, p *v1.PublishConnectionDetailsTo) (*store.Secret, error) {
 	ss, err := m.connectStore(ctx, p)
 	if err != nil {
 		return nil, errors.Wrap(err, errConnectStore)
 	}
 
 	s := &store.Secret{}
-	return managed.ConnectionDetails(s.Data), errors.Wrap(ss.ReadKeyValues(ctx, store.ScopedName{Name: p.Name, Scope: so.GetNamespace()}, s), errReadStore)
+	if err := ss.ReadKeyValues(ctx, store.ScopedName{Name: p.Name, Scope: so.GetNamespace()}, s); err != nil {
+		return nil, errors.Wrap(err, errReadStore)
+	}
+	return s, nil
 }
 
 // PropagateConnection propagate connection details from one resource to another.
+// Connection details are fetched from 'from' like FetchConnection does, and are
+// propagated to each of the targets of 'to'; a *PartialError is returned if
+// propagating to any of them fails.
 func (m *DetailsManager) PropagateConnection(ctx context.Context, to resource.LocalConnectionSecretOwner, from resource.ConnectionSecretOwner) (propagated bool, err error) {
 	// Either from does not expose a connection secret, or to does not want one.
-	if from.GetPublishConnectionDetailsTo() == nil || to.GetPublishConnectionDetailsTo() == nil {
+	if len(publishTargets(from)) == 0 {
 		return false, nil
 	}
-
-	ssFrom, err := m.connectStore(ctx, from.GetPublishConnectionDetailsTo())
-	if err != nil {
-		return false, errors.Wrap(err, errConnectStore)
+	targets := publishTargets(to)
+	if len(targets) == 0 {
+		return false, nil
 	}
 
-	sFrom := &store.Secret{}
-	if err = ssFrom.ReadKeyValues(ctx, store.ScopedName{
-		Name:  from.GetPublishConnectionDetailsTo().Name,
-		Scope: from.GetNamespace(),
-	}, sFrom); err != nil {
-		return false, errors.Wrap(err, errReadStore)
+	sFrom, err := m.fetchSecret(ctx, from)
+	if err != nil {
+		return false, err
 	}
 
 	// Make sure 'from' is the controller of the connection secret it references
@@ -179,13 +516,20 @@ func (m *DetailsManager) PropagateConnection(ctx context.Context, to resource.Lo
 		return false, errors.New(errSecretConflict)
 	}
 
-	ssTo, err := m.connectStore(ctx, to.GetPublishConnectionDetailsTo())
-	if err != nil {
-		return false, errors.Wrap(err, errConnectStore)
+	if err := store.ValidateEncoding(sFrom.Data, m.encoding...); err != nil {
+		return false, errors.Wrap(err, errInvalidEncoding)
 	}
 
-	changed, err := ssTo.WriteKeyValues(ctx, store.NewSecret(to, sFrom.Data), SecretToWriteMustBeOwnedBy(to))
-	return changed, errors.Wrap(err, errWriteStore)
+	if len(targets) == 1 {
+		return m.publishTo(ctx, to, targets[0], sFrom.Data)
+	}
+
+	err = eachTarget("propagate", targets, func(p *v1.PublishConnectionDetailsTo) error {
+		changed, err := m.publishTo(ctx, to, p, sFrom.Data)
+		propagated = propagated || changed
+		return err
+	})
+	return propagated, err
 }
 
 func (m *DetailsManager) connectStore(ctx context.Context, p *v1.PublishConnectionDetailsTo) (Store, error) {
@@ -194,7 +538,39 @@ func (m *DetailsManager) connectStore(ctx context.Context, p *v1.PublishConnecti
 		return nil, errors.Wrap(err, errGetStoreConfig)
 	}
 
-	return m.storeBuilder(ctx, m.client, m.tcfg, sc.GetStoreConfig())
+	cfg := sc.GetStoreConfig()
+	st, err := m.storeBuilder(ctx, m.client, m.tcfg, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if q, ok := m.quotas[sc.GetName()]; ok && m.quotaTracker != nil {
+		st = NewQuotaStore(st, sc.GetName(), q, m.quotaTracker)
+	}
+	if m.metrics != nil {
+		if st, err = NewInstrumentedStore(st, m.metrics, WithStoreIdentity(storeType(cfg), sc.GetName())); err != nil {
+			return nil, err
+		}
+	}
+	if !m.keyPrefix {
+		return st, nil
+	}
+	return NewKeyPrefixStore(st, StoreConfigKeyPrefix(sc.GetName())), nil
+}
+
+// recordFailure records a warning event with the supplied reason on the
+// supplied owner if the supplied error is not nil. It returns the error.
+func (m *DetailsManager) recordFailure(so resource.ConnectionSecretOwner, r event.Reason, err error) error {
+	if err != nil {
+		m.recorder.Event(so, event.Warning(r, err))
+	}
+	return err
+}
+
+func storeType(cfg v1.SecretStoreConfig) string {
+	if cfg.Type == nil {
+		return ""
+	}
+	return string(*cfg.Type)
 }
 
 // SecretToWriteMustBeOwnedBy requires that the current object is a
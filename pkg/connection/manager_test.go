@@ -22,8 +22,10 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -31,6 +33,7 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
 	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	resourcefake "github.com/crossplane/crossplane-runtime/pkg/resource/fake"
@@ -158,6 +161,7 @@ func TestManagerPublishConnection(t *testing.T) {
 	type args struct {
 		c  client.Client
 		sb StoreBuilderFn
+		o  []DetailsManagerOption
 
 		conn managed.ConnectionDetails
 		so   resource.ConnectionSecretOwner
@@ -245,6 +249,67 @@ func TestManagerPublishConnection(t *testing.T) {
 				err: errors.Wrap(errBoom, errWriteStore),
 			},
 		},
+		"InvalidEncoding": {
+			reason: "We should refuse to publish connection details that violate the configured encoding constraints.",
+			args: args{
+				c: &test.MockClient{
+					MockScheme: test.NewMockSchemeFn(resourcefake.SchemeWith(&fake.StoreConfig{})),
+				},
+				o:    []DetailsManagerOption{WithValueEncoding(store.EncodingUTF8)},
+				conn: managed.ConnectionDetails{"key": {0xff}},
+				so: &resourcefake.MockConnectionSecretOwner{
+					To: &v1.PublishConnectionDetailsTo{
+						SecretStoreConfigRef: &v1.Reference{
+							Name: fakeConfig,
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(store.ValidateEncoding(store.KeyValues{"key": {0xff}}, store.EncodingUTF8), errInvalidEncoding),
+			},
+		},
+		"ReservedMetadata": {
+			reason: "We should refuse to publish a connection secret whose metadata overrides reserved labels or annotations.",
+			args: args{
+				c: &test.MockClient{
+					MockScheme: test.NewMockSchemeFn(resourcefake.SchemeWith(&fake.StoreConfig{})),
+				},
+				so: &resourcefake.MockConnectionSecretOwner{
+					To: &v1.PublishConnectionDetailsTo{
+						Metadata: &v1.ConnectionSecretMetadata{
+							Annotations: map[string]string{"connection.crossplane.io/key-order": "a,b"},
+						},
+						SecretStoreConfigRef: &v1.Reference{
+							Name: fakeConfig,
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errors.Join(errors.Errorf("annotation %q is reserved for use by Crossplane", "connection.crossplane.io/key-order")), errInvalidMetadata),
+			},
+		},
+		"CannotRenderTemplates": {
+			reason: "We should return an error if templated connection details can't be rendered.",
+			args: args{
+				c: &test.MockClient{
+					MockScheme: test.NewMockSchemeFn(resourcefake.SchemeWith(&fake.StoreConfig{})),
+				},
+				o:    []DetailsManagerOption{WithTemplatedKeys("a", "b")},
+				conn: managed.ConnectionDetails{"a": []byte("{{ .b }}"), "b": []byte("{{ .a }}")},
+				so: &resourcefake.MockConnectionSecretOwner{
+					To: &v1.PublishConnectionDetailsTo{
+						SecretStoreConfigRef: &v1.Reference{
+							Name: fakeConfig,
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errors.New(`template value of key "a" depends on itself`), errRenderTemplates),
+			},
+		},
 		"SuccessfulPublishWithOwnerUID": {
 			reason: "We should return no error when published successfully.",
 			args: args{
@@ -289,7 +354,7 @@ func TestManagerPublishConnection(t *testing.T) {
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			m := NewDetailsManager(tc.args.c, resourcefake.GVK(&fake.StoreConfig{}), WithStoreBuilder(tc.args.sb))
+			m := NewDetailsManager(tc.args.c, resourcefake.GVK(&fake.StoreConfig{}), append([]DetailsManagerOption{WithStoreBuilder(tc.args.sb)}, tc.args.o...)...)
 
 			published, err := m.PublishConnection(context.Background(), tc.args.so, tc.args.conn)
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
@@ -439,6 +504,47 @@ func TestManagerUnpublishConnection(t *testing.T) {
 				err: errors.Wrap(errors.Errorf(errFmtNotOwnedBy, testUID), errDeleteFromStore),
 			},
 		},
+		"EmptyDetailsUnpublishWholeSecret": {
+			reason: "We should ask the store to delete the whole secret when unpublishing empty connection details.",
+			args: args{
+				c: &test.MockClient{
+					MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+						*obj.(*fake.StoreConfig) = fake.StoreConfig{
+							ObjectMeta: metav1.ObjectMeta{
+								Name: fakeConfig,
+							},
+							Config: v1.SecretStoreConfig{
+								Type: &fakeStore,
+							},
+						}
+						return nil
+					},
+					MockScheme: test.NewMockSchemeFn(resourcefake.SchemeWith(&fake.StoreConfig{})),
+				},
+				sb: fakeStoreBuilderFn(fake.SecretStore{
+					DeleteKeyValuesFn: func(_ context.Context, s *store.Secret, _ ...store.DeleteOption) error {
+						if s.Data != nil {
+							return errBoom
+						}
+						return nil
+					},
+				}),
+				conn: managed.ConnectionDetails{},
+				so: &resourcefake.MockConnectionSecretOwner{
+					ObjectMeta: metav1.ObjectMeta{
+						UID: testUID,
+					},
+					To: &v1.PublishConnectionDetailsTo{
+						SecretStoreConfigRef: &v1.Reference{
+							Name: fakeConfig,
+						},
+					},
+				},
+			},
+			want: want{
+				err: nil,
+			},
+		},
 		"SuccessfulUnpublish": {
 			reason: "We should return no error when unpublished successfully.",
 			args: args{
@@ -499,6 +605,519 @@ func TestManagerUnpublishConnection(t *testing.T) {
 	}
 }
 
+// An orphaningStore is a fake Store that is an Orphaner.
+type orphaningStore struct {
+	fake.SecretStore
+	OrphanKeyValuesFn func(ctx context.Context, s *store.Secret) error
+}
+
+func (s *orphaningStore) OrphanKeyValues(ctx context.Context, sec *store.Secret) error {
+	return s.OrphanKeyValuesFn(ctx, sec)
+}
+
+func TestManagerUnpublishConnectionDeletionPolicy(t *testing.T) {
+	deleted := metav1.Now()
+
+	type want struct {
+		deleted  bool
+		orphaned bool
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason   string
+		policy   v1.DeletionPolicy
+		orphaner bool
+		orphan   error
+		conn     managed.ConnectionDetails
+		deleted  *metav1.Time
+		want     want
+	}{
+		"DeleteByDefault": {
+			reason:   "We should delete the secret of a deleted owner by default.",
+			orphaner: true,
+			deleted:  &deleted,
+			want: want{
+				deleted: true,
+			},
+		},
+		"OrphanDeletedOwner": {
+			reason:   "We should orphan the secret of a deleted owner per the orphan deletion policy.",
+			policy:   v1.DeletionOrphan,
+			orphaner: true,
+			deleted:  &deleted,
+			want: want{
+				orphaned: true,
+			},
+		},
+		"OrphanDeletedOwnerNotOrphaner": {
+			reason:  "We should leave the secret of a deleted owner as is per the orphan deletion policy if the store can't orphan it.",
+			policy:  v1.DeletionOrphan,
+			deleted: &deleted,
+		},
+		"OrphanExistingOwner": {
+			reason:   "We should delete the secret of an owner that wasn't deleted regardless of the orphan deletion policy.",
+			policy:   v1.DeletionOrphan,
+			orphaner: true,
+			want: want{
+				deleted: true,
+			},
+		},
+		"OrphanSomeKeys": {
+			reason:   "We should delete keys from the secret of a deleted owner regardless of the orphan deletion policy.",
+			policy:   v1.DeletionOrphan,
+			orphaner: true,
+			conn:     managed.ConnectionDetails{"key": []byte("value")},
+			deleted:  &deleted,
+			want: want{
+				deleted: true,
+			},
+		},
+		"CannotOrphan": {
+			reason:   "We should return any error encountered while orphaning the secret.",
+			policy:   v1.DeletionOrphan,
+			orphaner: true,
+			orphan:   errBoom,
+			deleted:  &deleted,
+			want: want{
+				orphaned: true,
+				err:      errors.Wrap(errBoom, errOrphanInStore),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := want{}
+			fs := fake.SecretStore{
+				DeleteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.DeleteOption) error {
+					got.deleted = true
+					return nil
+				},
+			}
+			var ss Store = &fs
+			if tc.orphaner {
+				ss = &orphaningStore{SecretStore: fs, OrphanKeyValuesFn: func(_ context.Context, _ *store.Secret) error {
+					got.orphaned = true
+					return tc.orphan
+				}}
+			}
+
+			c := &test.MockClient{
+				MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+					*obj.(*fake.StoreConfig) = fake.StoreConfig{
+						ObjectMeta: metav1.ObjectMeta{Name: fakeConfig},
+						Config:     v1.SecretStoreConfig{Type: &fakeStore},
+					}
+					return nil
+				},
+				MockScheme: test.NewMockSchemeFn(resourcefake.SchemeWith(&fake.StoreConfig{})),
+			}
+			sb := func(_ context.Context, _ client.Client, _ *tls.Config, _ v1.SecretStoreConfig) (Store, error) {
+				return ss, nil
+			}
+			m := NewDetailsManager(c, resourcefake.GVK(&fake.StoreConfig{}), WithStoreBuilder(sb), WithSecretDeletionPolicy(tc.policy))
+
+			so := &resourcefake.MockConnectionSecretOwner{
+				ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: tc.deleted},
+				To:         &v1.PublishConnectionDetailsTo{SecretStoreConfigRef: &v1.Reference{Name: fakeConfig}},
+			}
+			got.err = m.UnpublishConnection(context.Background(), so, tc.conn)
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{}), test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nm.UnpublishConnection(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestManagerUnpublishConnectionOrphanWrapped(t *testing.T) {
+	deleted := metav1.Now()
+	orphaned := false
+	ss := &orphaningStore{
+		SecretStore: fake.SecretStore{
+			DeleteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.DeleteOption) error {
+				return errors.New("should orphan rather than delete the secret")
+			},
+		},
+		OrphanKeyValuesFn: func(_ context.Context, _ *store.Secret) error {
+			orphaned = true
+			return nil
+		},
+	}
+
+	c := &test.MockClient{
+		MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+			*obj.(*fake.StoreConfig) = fake.StoreConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: fakeConfig},
+				Config: v1.SecretStoreConfig{
+					Type:       &fakeStore,
+					Encryption: &v1.SecretStoreEncryptionConfig{PrimaryKeyVersion: "v1"},
+				},
+			}
+			return nil
+		},
+		MockScheme: test.NewMockSchemeFn(resourcefake.SchemeWith(&fake.StoreConfig{})),
+	}
+	sb := func(_ context.Context, _ client.Client, _ *tls.Config, _ v1.SecretStoreConfig) (Store, error) {
+		return ss, nil
+	}
+	kb := func(_ context.Context, _ client.Client, _ v1.SecretStoreEncryptionConfig) (KeyProvider, error) {
+		return NewStaticKeyProvider("v1", map[string][]byte{"v1": kek1}), nil
+	}
+
+	// The store is wrapped in an EncryptingStore, an InstrumentedStore, and
+	// a KeyPrefixStore when connected to.
+	m := NewDetailsManager(c, resourcefake.GVK(&fake.StoreConfig{}),
+		WithStoreBuilder(EncryptingStoreBuilder(sb, kb)),
+		WithStoreMetrics(prometheus.NewRegistry()),
+		WithStoreConfigKeyPrefix(),
+		WithSecretDeletionPolicy(v1.DeletionOrphan),
+	)
+
+	so := &resourcefake.MockConnectionSecretOwner{
+		ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &deleted},
+		To:         &v1.PublishConnectionDetailsTo{SecretStoreConfigRef: &v1.Reference{Name: fakeConfig}},
+	}
+	if err := m.UnpublishConnection(context.Background(), so, nil); err != nil {
+		t.Fatalf("m.UnpublishConnection(...): %v", err)
+	}
+	if !orphaned {
+		t.Errorf("m.UnpublishConnection(...): want the secret of a deleted owner orphaned through the wrapping stores, got not orphaned")
+	}
+}
+
+func TestManagerMultipleTargets(t *testing.T) {
+	so := &resourcefake.MockConnectionSecretOwner{
+		ToAll: []v1.PublishConnectionDetailsTo{
+			{Name: "local-secret", SecretStoreConfigRef: &v1.Reference{Name: "local"}},
+			{Name: "vault-secret", SecretStoreConfigRef: &v1.Reference{Name: "vault"}},
+		},
+	}
+
+	written := map[string]string{}
+	deleted := map[string]string{}
+	stores := map[string]*fake.SecretStore{}
+	for _, name := range []string{"local", "vault"} {
+		stores[name] = &fake.SecretStore{
+			WriteKeyValuesFn: func(_ context.Context, s *store.Secret, _ ...store.WriteOption) (bool, error) {
+				written[name] = s.Name
+				if name == "vault" {
+					return false, errBoom
+				}
+				return true, nil
+			},
+			DeleteKeyValuesFn: func(_ context.Context, s *store.Secret, _ ...store.DeleteOption) error {
+				deleted[name] = s.Name
+				return nil
+			},
+		}
+	}
+
+	c := &test.MockClient{
+		MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+			*obj.(*fake.StoreConfig) = fake.StoreConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name},
+				Config:     v1.SecretStoreConfig{Type: &fakeStore, DefaultScope: key.Name},
+			}
+			return nil
+		},
+		MockScheme: test.NewMockSchemeFn(resourcefake.SchemeWith(&fake.StoreConfig{})),
+	}
+	sb := func(_ context.Context, _ client.Client, _ *tls.Config, cfg v1.SecretStoreConfig) (Store, error) {
+		return stores[cfg.DefaultScope], nil
+	}
+	m := NewDetailsManager(c, resourcefake.GVK(&fake.StoreConfig{}), WithStoreBuilder(sb))
+
+	published, err := m.PublishConnection(context.Background(), so, managed.ConnectionDetails{"key": []byte("value")})
+	want := &PartialError{
+		Operation: "publish",
+		Succeeded: []string{"local/local-secret"},
+		Failed:    []StoreError{{Store: "vault/vault-secret", Err: errors.Wrap(errBoom, errWriteStore)}},
+	}
+	if diff := cmp.Diff(want, err, test.EquateErrors()); diff != "" {
+		t.Errorf("m.PublishConnection(...): -want error, +got error:\n%s", diff)
+	}
+	if !published {
+		t.Errorf("m.PublishConnection(...): want published to a target, got not published")
+	}
+	if diff := cmp.Diff(map[string]string{"local": "local-secret", "vault": "vault-secret"}, written); diff != "" {
+		t.Errorf("m.PublishConnection(...): -want written, +got written:\n%s", diff)
+	}
+
+	if err := m.UnpublishConnection(context.Background(), so, nil); err != nil {
+		t.Errorf("m.UnpublishConnection(...): %v", err)
+	}
+	if diff := cmp.Diff(map[string]string{"local": "local-secret", "vault": "vault-secret"}, deleted); diff != "" {
+		t.Errorf("m.UnpublishConnection(...): -want deleted, +got deleted:\n%s", diff)
+	}
+}
+
+// storesByConfig returns a DetailsManager that connects to the supplied Stores
+// by the name of their StoreConfig.
+func storesByConfig(stores map[string]Store, o ...DetailsManagerOption) *DetailsManager {
+	c := &test.MockClient{
+		MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+			*obj.(*fake.StoreConfig) = fake.StoreConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name},
+				Config:     v1.SecretStoreConfig{Type: &fakeStore, DefaultScope: key.Name},
+			}
+			return nil
+		},
+		MockScheme: test.NewMockSchemeFn(resourcefake.SchemeWith(&fake.StoreConfig{})),
+	}
+	sb := func(_ context.Context, _ client.Client, _ *tls.Config, cfg v1.SecretStoreConfig) (Store, error) {
+		return stores[cfg.DefaultScope], nil
+	}
+	return NewDetailsManager(c, resourcefake.GVK(&fake.StoreConfig{}), append([]DetailsManagerOption{WithStoreBuilder(sb)}, o...)...)
+}
+
+// readsSecret returns a Store that reads a secret with the supplied data owned
+// by the supplied owner, or fails to read with the supplied error.
+func readsSecret(owner string, data store.KeyValues, err error) *fake.SecretStore {
+	return &fake.SecretStore{
+		ReadKeyValuesFn: func(_ context.Context, n store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
+			s.ScopedName = n
+			s.Data = data
+			s.Metadata = &v1.ConnectionSecretMetadata{Labels: map[string]string{v1.LabelKeyOwnerUID: owner}}
+			return err
+		},
+	}
+}
+
+func TestManagerFetchConnectionMultipleTargets(t *testing.T) {
+	targets := []v1.PublishConnectionDetailsTo{
+		{Name: "local-secret", SecretStoreConfigRef: &v1.Reference{Name: "local"}},
+		{Name: "vault-secret", SecretStoreConfigRef: &v1.Reference{Name: "vault"}},
+	}
+
+	type want struct {
+		conn managed.ConnectionDetails
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		stores map[string]Store
+		want   want
+	}{
+		"FirstTarget": {
+			reason: "We should fetch connection details from the first target that has any.",
+			stores: map[string]Store{
+				"local": readsSecret(testUID, store.KeyValues{"key": []byte("local")}, nil),
+				"vault": readsSecret(testUID, store.KeyValues{"key": []byte("vault")}, nil),
+			},
+			want: want{
+				conn: managed.ConnectionDetails{"key": []byte("local")},
+			},
+		},
+		"FirstTargetEmpty": {
+			reason: "We should fetch connection details from a later target if earlier ones have none.",
+			stores: map[string]Store{
+				"local": readsSecret(testUID, nil, nil),
+				"vault": readsSecret(testUID, store.KeyValues{"key": []byte("vault")}, nil),
+			},
+			want: want{
+				conn: managed.ConnectionDetails{"key": []byte("vault")},
+			},
+		},
+		"FirstTargetUnreadable": {
+			reason: "We should fetch connection details from a later target if earlier ones can't be read.",
+			stores: map[string]Store{
+				"local": readsSecret(testUID, nil, errBoom),
+				"vault": readsSecret(testUID, store.KeyValues{"key": []byte("vault")}, nil),
+			},
+			want: want{
+				conn: managed.ConnectionDetails{"key": []byte("vault")},
+			},
+		},
+		"NoTargetReadable": {
+			reason: "We should return a PartialError if no target has connection details and any can't be read.",
+			stores: map[string]Store{
+				"local": readsSecret(testUID, nil, nil),
+				"vault": readsSecret(testUID, nil, errBoom),
+			},
+			want: want{
+				err: &PartialError{
+					Operation: "fetch",
+					Succeeded: []string{"local/local-secret"},
+					Failed:    []StoreError{{Store: "vault/vault-secret", Err: errors.Wrap(errBoom, errReadStore)}},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			so := &resourcefake.MockConnectionSecretOwner{ToAll: targets}
+			conn, err := storesByConfig(tc.stores).FetchConnection(context.Background(), so)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nm.FetchConnection(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.conn, conn); diff != "" {
+				t.Errorf("\n%s\nm.FetchConnection(...): -want connection details, +got connection details:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestManagerPropagateConnectionMultipleTargets(t *testing.T) {
+	written := map[string]store.KeyValues{}
+	writes := func(name string, err error) *fake.SecretStore {
+		return &fake.SecretStore{
+			WriteKeyValuesFn: func(_ context.Context, s *store.Secret, _ ...store.WriteOption) (bool, error) {
+				if err != nil {
+					return false, err
+				}
+				written[name+"/"+s.Name] = s.Data
+				return true, nil
+			},
+		}
+	}
+
+	from := &resourcefake.MockConnectionSecretOwner{
+		ObjectMeta: metav1.ObjectMeta{UID: testUID},
+		ToAll: []v1.PublishConnectionDetailsTo{
+			{Name: "from-secret", SecretStoreConfigRef: &v1.Reference{Name: "empty"}},
+			{Name: "from-secret", SecretStoreConfigRef: &v1.Reference{Name: "source"}},
+		},
+	}
+	to := &resourcefake.MockLocalConnectionSecretOwner{
+		ObjectMeta: metav1.ObjectMeta{UID: "to-uid"},
+		ToAll: []v1.PublishConnectionDetailsTo{
+			{Name: "to-secret", SecretStoreConfigRef: &v1.Reference{Name: "local"}},
+			{Name: "to-secret", SecretStoreConfigRef: &v1.Reference{Name: "vault"}},
+			{Name: "to-secret", SecretStoreConfigRef: &v1.Reference{Name: "broken"}},
+		},
+	}
+	m := storesByConfig(map[string]Store{
+		"empty":  readsSecret(testUID, nil, nil),
+		"source": readsSecret(testUID, store.KeyValues{"key": []byte("value")}, nil),
+		"local":  writes("local", nil),
+		"vault":  writes("vault", nil),
+		"broken": writes("broken", errBoom),
+	})
+
+	propagated, err := m.PropagateConnection(context.Background(), to, from)
+	want := &PartialError{
+		Operation: "propagate",
+		Succeeded: []string{"local/to-secret", "vault/to-secret"},
+		Failed:    []StoreError{{Store: "broken/to-secret", Err: errors.Wrap(errBoom, errWriteStore)}},
+	}
+	if diff := cmp.Diff(want, err, test.EquateErrors()); diff != "" {
+		t.Errorf("m.PropagateConnection(...): -want error, +got error:\n%s", diff)
+	}
+	if !propagated {
+		t.Errorf("m.PropagateConnection(...): want propagated to a target, got not propagated")
+	}
+	wantWritten := map[string]store.KeyValues{
+		"local/to-secret": {"key": []byte("value")},
+		"vault/to-secret": {"key": []byte("value")},
+	}
+	if diff := cmp.Diff(wantWritten, written); diff != "" {
+		t.Errorf("m.PropagateConnection(...): -want written, +got written:\n%s", diff)
+	}
+}
+
+func TestManagerPropagateConnectionPreparesSecret(t *testing.T) {
+	var got *store.Secret
+	var key string
+	target := &fake.SecretStore{
+		WriteKeyValuesFn: func(ctx context.Context, s *store.Secret, _ ...store.WriteOption) (bool, error) {
+			got = s
+			key, _ = store.GetIdempotencyKey(ctx)
+			return true, nil
+		},
+	}
+
+	from := &resourcefake.MockConnectionSecretOwner{
+		ObjectMeta: metav1.ObjectMeta{UID: testUID},
+		To:         &v1.PublishConnectionDetailsTo{Name: "from-secret", SecretStoreConfigRef: &v1.Reference{Name: "source"}},
+	}
+	to := &resourcefake.MockLocalConnectionSecretOwner{
+		ObjectMeta: metav1.ObjectMeta{Name: "to", UID: "to-uid", Labels: map[string]string{"team": "cool"}},
+		To:         &v1.PublishConnectionDetailsTo{Name: "to-secret", SecretStoreConfigRef: &v1.Reference{Name: "target"}},
+	}
+	m := storesByConfig(map[string]Store{
+		"source": readsSecret(testUID, store.KeyValues{"key": []byte("value")}, nil),
+		"target": target,
+	}, WithPropagatedOwnerLabels("team"), WithOwnerScheme(resourcefake.SchemeWith(&resourcefake.MockLocalConnectionSecretOwner{})))
+
+	if _, err := m.PropagateConnection(context.Background(), to, from); err != nil {
+		t.Fatalf("m.PropagateConnection(...): %v", err)
+	}
+
+	// Propagated secrets should be prepared just like published ones.
+	wantOwner := &store.Owner{TypedReference: v1.TypedReference{
+		APIVersion: resourcefake.GV.String(),
+		Kind:       "MockLocalConnectionSecretOwner",
+		Name:       "to",
+		UID:        "to-uid",
+	}}
+	if diff := cmp.Diff(wantOwner, got.Owner); diff != "" {
+		t.Errorf("m.PropagateConnection(...): -want owner, +got owner:\n%s", diff)
+	}
+	if diff := cmp.Diff("cool", got.Metadata.Labels["team"]); diff != "" {
+		t.Errorf("m.PropagateConnection(...): -want propagated label, +got propagated label:\n%s", diff)
+	}
+	if key != store.IdempotencyKey(got) {
+		t.Errorf("m.PropagateConnection(...): want the write to carry the secret's idempotency key %q, got %q", store.IdempotencyKey(got), key)
+	}
+}
+
+type recorder struct {
+	events []event.Event
+}
+
+func (r *recorder) Event(_ runtime.Object, e event.Event)      { r.events = append(r.events, e) }
+func (r *recorder) WithAnnotations(_ ...string) event.Recorder { return r }
+
+func TestManagerStoreMetricsAndEvents(t *testing.T) {
+	so := &resourcefake.MockConnectionSecretOwner{
+		ObjectMeta: metav1.ObjectMeta{UID: testUID},
+		To:         &v1.PublishConnectionDetailsTo{Name: "cool-secret", SecretStoreConfigRef: &v1.Reference{Name: "cool"}},
+	}
+	c := &test.MockClient{
+		MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+			*obj.(*fake.StoreConfig) = fake.StoreConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name},
+				Config:     v1.SecretStoreConfig{Type: &fakeStore},
+			}
+			return nil
+		},
+		MockScheme: test.NewMockSchemeFn(resourcefake.SchemeWith(&fake.StoreConfig{})),
+	}
+	sb := func(_ context.Context, _ client.Client, _ *tls.Config, _ v1.SecretStoreConfig) (Store, error) {
+		return &fake.SecretStore{
+			WriteKeyValuesFn:  func(_ context.Context, _ *store.Secret, _ ...store.WriteOption) (bool, error) { return false, errBoom },
+			DeleteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.DeleteOption) error { return errBoom },
+		}, nil
+	}
+
+	reg := prometheus.NewRegistry()
+	rec := &recorder{}
+	m := NewDetailsManager(c, resourcefake.GVK(&fake.StoreConfig{}), WithStoreBuilder(sb), WithStoreMetrics(reg), WithEventRecorder(rec))
+
+	_, _ = m.PublishConnection(context.Background(), so, managed.ConnectionDetails{"key": []byte("value")})
+	_ = m.UnpublishConnection(context.Background(), so, nil)
+
+	want := []event.Event{
+		event.Warning(reasonCannotPublish, errors.Wrap(errBoom, errWriteStore)),
+		event.Warning(reasonCannotUnpublish, errors.Wrap(errBoom, errDeleteFromStore)),
+	}
+	if diff := cmp.Diff(want, rec.events); diff != "" {
+		t.Errorf("m.PublishConnection(...), m.UnpublishConnection(...): -want events, +got events:\n%s", diff)
+	}
+
+	wantErrs := map[string]float64{string(fakeStore): 2}
+	if diff := cmp.Diff(wantErrs, counters(t, reg, "crossplane_connection_store_operation_errors_total", "store_type")); diff != "" {
+		t.Errorf("m.PublishConnection(...), m.UnpublishConnection(...): -want errors by store type, +got errors by store type:\n%s", diff)
+	}
+	wantOps := map[string]float64{"cool": 2}
+	if diff := cmp.Diff(wantOps, counters(t, reg, "crossplane_connection_store_operations_total", "store_config")); diff != "" {
+		t.Errorf("m.PublishConnection(...), m.UnpublishConnection(...): -want operations by StoreConfig, +got operations by StoreConfig:\n%s", diff)
+	}
+}
+
 func TestManagerFetchConnection(t *testing.T) {
 	type args struct {
 		c  client.Client
@@ -573,7 +1192,7 @@ func TestManagerFetchConnection(t *testing.T) {
 					MockScheme: test.NewMockSchemeFn(resourcefake.SchemeWith(&fake.StoreConfig{})),
 				},
 				sb: fakeStoreBuilderFn(fake.SecretStore{
-					ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, _ *store.Secret) error {
+					ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, _ *store.Secret, _ ...store.ReadOption) error {
 						return errBoom
 					},
 				}),
@@ -607,7 +1226,7 @@ func TestManagerFetchConnection(t *testing.T) {
 					MockScheme: test.NewMockSchemeFn(resourcefake.SchemeWith(&fake.StoreConfig{})),
 				},
 				sb: fakeStoreBuilderFn(fake.SecretStore{
-					ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret) error {
+					ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
 						s.Data = store.KeyValues{
 							"key1": []byte("val1"),
 						}
@@ -734,7 +1353,7 @@ func TestManagerPropagateConnection(t *testing.T) {
 					MockScheme: test.NewMockSchemeFn(resourcefake.SchemeWith(&fake.StoreConfig{})),
 				},
 				sb: fakeStoreBuilderFn(fake.SecretStore{
-					ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, _ *store.Secret) error {
+					ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, _ *store.Secret, _ ...store.ReadOption) error {
 						return errBoom
 					},
 				}),
@@ -773,7 +1392,7 @@ func TestManagerPropagateConnection(t *testing.T) {
 					MockScheme: test.NewMockSchemeFn(resourcefake.SchemeWith(&fake.StoreConfig{})),
 				},
 				sb: fakeStoreBuilderFn(fake.SecretStore{
-					ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret) error {
+					ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
 						s.Metadata = &v1.ConnectionSecretMetadata{}
 						return nil
 					},
@@ -823,7 +1442,7 @@ func TestManagerPropagateConnection(t *testing.T) {
 					MockScheme: test.NewMockSchemeFn(resourcefake.SchemeWith(&fake.StoreConfig{})),
 				},
 				sb: fakeStoreBuilderFn(fake.SecretStore{
-					ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret) error {
+					ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
 						s.Metadata = &v1.ConnectionSecretMetadata{
 							Labels: map[string]string{
 								v1.LabelKeyOwnerUID: "00000000-1111-2222-3333-444444444444",
@@ -877,7 +1496,7 @@ func TestManagerPropagateConnection(t *testing.T) {
 					MockScheme: test.NewMockSchemeFn(resourcefake.SchemeWith(&fake.StoreConfig{})),
 				},
 				sb: fakeStoreBuilderFn(fake.SecretStore{
-					ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret) error {
+					ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
 						s.Metadata = &v1.ConnectionSecretMetadata{
 							Labels: map[string]string{
 								v1.LabelKeyOwnerUID: testUID,
@@ -927,7 +1546,7 @@ func TestManagerPropagateConnection(t *testing.T) {
 					MockScheme: test.NewMockSchemeFn(resourcefake.SchemeWith(&fake.StoreConfig{})),
 				},
 				sb: fakeStoreBuilderFn(fake.SecretStore{
-					ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret) error {
+					ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
 						s.Metadata = &v1.ConnectionSecretMetadata{
 							Labels: map[string]string{
 								v1.LabelKeyOwnerUID: testUID,
@@ -979,7 +1598,7 @@ func TestManagerPropagateConnection(t *testing.T) {
 					MockScheme: test.NewMockSchemeFn(resourcefake.SchemeWith(&fake.StoreConfig{})),
 				},
 				sb: fakeStoreBuilderFn(fake.SecretStore{
-					ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret) error {
+					ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
 						s.Metadata = &v1.ConnectionSecretMetadata{
 							Labels: map[string]string{
 								v1.LabelKeyOwnerUID: testUID,
@@ -1045,7 +1664,7 @@ func TestManagerPropagateConnection(t *testing.T) {
 					MockScheme: test.NewMockSchemeFn(resourcefake.SchemeWith(&fake.StoreConfig{})),
 				},
 				sb: fakeStoreBuilderFn(fake.SecretStore{
-					ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret) error {
+					ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
 						s.Metadata = &v1.ConnectionSecretMetadata{
 							Labels: map[string]string{
 								v1.LabelKeyOwnerUID: testUID,
@@ -1097,7 +1716,7 @@ func TestManagerPropagateConnection(t *testing.T) {
 					MockScheme: test.NewMockSchemeFn(resourcefake.SchemeWith(&fake.StoreConfig{})),
 				},
 				sb: fakeStoreBuilderFn(fake.SecretStore{
-					ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret) error {
+					ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
 						s.Metadata = &v1.ConnectionSecretMetadata{
 							Labels: map[string]string{
 								v1.LabelKeyOwnerUID: testUID,
@@ -1159,7 +1778,7 @@ func TestManagerPropagateConnection(t *testing.T) {
 					MockScheme: test.NewMockSchemeFn(resourcefake.SchemeWith(&fake.StoreConfig{})),
 				},
 				sb: fakeStoreBuilderFn(fake.SecretStore{
-					ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret) error {
+					ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
 						s.Metadata = &v1.ConnectionSecretMetadata{
 							Labels: map[string]string{
 								v1.LabelKeyOwnerUID: testUID,
@@ -1234,3 +1853,230 @@ func fakeStoreBuilderFn(ss fake.SecretStore) StoreBuilderFn {
 		return nil, errors.Errorf(errFmtUnknownSecretStore, *cfg.Type)
 	}
 }
+
+func TestManagerPublishConnectionIdempotent(t *testing.T) {
+	c := &test.MockClient{
+		MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+			*obj.(*fake.StoreConfig) = fake.StoreConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: fakeConfig,
+				},
+				Config: v1.SecretStoreConfig{
+					Type: &fakeStore,
+				},
+			}
+			return nil
+		},
+		MockScheme: test.NewMockSchemeFn(resourcefake.SchemeWith(&fake.StoreConfig{})),
+	}
+
+	// The keys recorded by our fake store outlive any DetailsManager, much
+	// like they would in an external store.
+	seen := map[string]bool{}
+	writes := 0
+	sb := fakeStoreBuilderFn(fake.SecretStore{
+		WriteKeyValuesFn: func(ctx context.Context, _ *store.Secret, _ ...store.WriteOption) (bool, error) {
+			k, ok := store.GetIdempotencyKey(ctx)
+			if !ok {
+				t.Errorf("m.PublishConnection(...): expected the write to carry an idempotency key")
+			}
+			if seen[k] {
+				return false, nil
+			}
+			seen[k] = true
+			writes++
+			return true, nil
+		},
+	})
+	so := &resourcefake.MockConnectionSecretOwner{
+		ObjectMeta: metav1.ObjectMeta{
+			UID: testUID,
+		},
+		To: &v1.PublishConnectionDetailsTo{
+			Name: "cool-secret",
+			SecretStoreConfigRef: &v1.Reference{
+				Name: fakeConfig,
+			},
+		},
+	}
+	conn := managed.ConnectionDetails{"key": []byte("value")}
+
+	published, err := NewDetailsManager(c, resourcefake.GVK(&fake.StoreConfig{}), WithStoreBuilder(sb)).PublishConnection(context.Background(), so, conn)
+	if err != nil || !published {
+		t.Fatalf("m.PublishConnection(...): want published, got published %t and error %v", published, err)
+	}
+
+	// A retried write, e.g. by a restarted process, should be a no-op.
+	published, err = NewDetailsManager(c, resourcefake.GVK(&fake.StoreConfig{}), WithStoreBuilder(sb)).PublishConnection(context.Background(), so, conn)
+	if err != nil || published {
+		t.Errorf("m.PublishConnection(...): want no-op, got published %t and error %v", published, err)
+	}
+	if writes != 1 {
+		t.Errorf("m.PublishConnection(...): want 1 write, got %d", writes)
+	}
+}
+
+func TestManagerPublishConnectionOwnerLabels(t *testing.T) {
+	c := &test.MockClient{
+		MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+			*obj.(*fake.StoreConfig) = fake.StoreConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: fakeConfig,
+				},
+				Config: v1.SecretStoreConfig{
+					Type: &fakeStore,
+				},
+			}
+			return nil
+		},
+		MockScheme: test.NewMockSchemeFn(resourcefake.SchemeWith(&fake.StoreConfig{})),
+	}
+	ownerLabels := map[string]string{"environment": "prod", "team": "cool", "internal": "true"}
+	uid := string(testUID)
+
+	cases := map[string]struct {
+		reason   string
+		keys     []string
+		metadata *v1.ConnectionSecretMetadata
+		want     map[string]string
+	}{
+		"NoLabels": {
+			reason: "We should not propagate any owner labels by default.",
+			want:   map[string]string{v1.LabelKeyOwnerUID: uid},
+		},
+		"SelectedLabels": {
+			reason: "We should propagate only the selected owner labels.",
+			keys:   []string{"environment", "team", "absent"},
+			want:   map[string]string{v1.LabelKeyOwnerUID: uid, "environment": "prod", "team": "cool"},
+		},
+		"SecretLabelsWin": {
+			reason:   "We should not override labels the secret's metadata already sets.",
+			keys:     []string{"environment", "team"},
+			metadata: &v1.ConnectionSecretMetadata{Labels: map[string]string{"team": "uncool"}},
+			want:     map[string]string{v1.LabelKeyOwnerUID: uid, "environment": "prod", "team": "uncool"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got map[string]string
+			sb := fakeStoreBuilderFn(fake.SecretStore{
+				WriteKeyValuesFn: func(_ context.Context, s *store.Secret, _ ...store.WriteOption) (bool, error) {
+					if s.Metadata != nil {
+						got = s.Metadata.Labels
+					}
+					return true, nil
+				},
+			})
+			so := &resourcefake.MockConnectionSecretOwner{
+				ObjectMeta: metav1.ObjectMeta{
+					UID:    testUID,
+					Labels: ownerLabels,
+				},
+				To: &v1.PublishConnectionDetailsTo{
+					Name:                 "cool-secret",
+					Metadata:             tc.metadata.DeepCopy(),
+					SecretStoreConfigRef: &v1.Reference{Name: fakeConfig},
+				},
+			}
+
+			m := NewDetailsManager(c, resourcefake.GVK(&fake.StoreConfig{}), WithStoreBuilder(sb), WithPropagatedOwnerLabels(tc.keys...))
+			if _, err := m.PublishConnection(context.Background(), so, managed.ConnectionDetails{"key": []byte("value")}); err != nil {
+				t.Fatalf("\n%s\nm.PublishConnection(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nm.PublishConnection(...): -want labels, +got labels:\n%s", tc.reason, diff)
+			}
+			if _, ok := so.GetPublishConnectionDetailsTo().Metadata.Labels["environment"]; ok {
+				t.Errorf("\n%s\nm.PublishConnection(...): expected propagated labels not to be added to the owner's metadata", tc.reason)
+			}
+		})
+	}
+}
+
+func TestManagerPublishConnectionOwnerKind(t *testing.T) {
+	c := &test.MockClient{
+		MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+			*obj.(*fake.StoreConfig) = fake.StoreConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: fakeConfig,
+				},
+				Config: v1.SecretStoreConfig{
+					Type: &fakeStore,
+				},
+			}
+			return nil
+		},
+		MockScheme: test.NewMockSchemeFn(resourcefake.SchemeWith(&fake.StoreConfig{})),
+	}
+	so := &resourcefake.MockConnectionSecretOwner{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "cool-owner",
+			UID:  testUID,
+		},
+		To: &v1.PublishConnectionDetailsTo{
+			Name:                 "cool-secret",
+			SecretStoreConfigRef: &v1.Reference{Name: fakeConfig},
+		},
+	}
+	unregistered := runtime.NewScheme()
+	_, _, errUnregistered := unregistered.ObjectKinds(so)
+
+	type want struct {
+		owner *store.Owner
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		o      []DetailsManagerOption
+		want   want
+	}{
+		"NoScheme": {
+			reason: "We should reference an owner without its apiVersion and kind if it doesn't carry them and there is no owner scheme.",
+			want: want{
+				owner: &store.Owner{TypedReference: v1.TypedReference{Name: "cool-owner", UID: testUID}},
+			},
+		},
+		"Registered": {
+			reason: "We should resolve the apiVersion and kind of an owner whose type is registered with the owner scheme.",
+			o:      []DetailsManagerOption{WithOwnerScheme(resourcefake.SchemeWith(&resourcefake.MockConnectionSecretOwner{}))},
+			want: want{
+				owner: &store.Owner{TypedReference: v1.TypedReference{
+					APIVersion: resourcefake.GV.String(),
+					Kind:       "MockConnectionSecretOwner",
+					Name:       "cool-owner",
+					UID:        testUID,
+				}},
+			},
+		},
+		"Unregistered": {
+			reason: "We should return an error if the owner's type isn't registered with the owner scheme.",
+			o:      []DetailsManagerOption{WithOwnerScheme(unregistered)},
+			want: want{
+				err: errors.Wrapf(errUnregistered, errFmtResolveOwnerKind, "cool-owner"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got *store.Owner
+			sb := fakeStoreBuilderFn(fake.SecretStore{
+				WriteKeyValuesFn: func(_ context.Context, s *store.Secret, _ ...store.WriteOption) (bool, error) {
+					got = s.Owner
+					return true, nil
+				},
+			})
+
+			m := NewDetailsManager(c, resourcefake.GVK(&fake.StoreConfig{}), append([]DetailsManagerOption{WithStoreBuilder(sb)}, tc.o...)...)
+			_, err := m.PublishConnection(context.Background(), so, managed.ConnectionDetails{"key": []byte("value")})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nm.PublishConnection(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.owner, got); diff != "" {
+				t.Errorf("\n%s\nm.PublishConnection(...): -want owner, +got owner:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	metricsSubsystem = "crossplane"
+
+	opRead   = "read"
+	opWrite  = "write"
+	opDelete = "delete"
+
+	// OtherKey is the key label value under which the sizes of values of
+	// keys that are not explicitly allowed are recorded.
+	OtherKey = "_other"
+)
+
+// operationLabels label the operation metrics of an InstrumentedStore.
+var operationLabels = []string{"operation", "store_type", "store_config"}
+
+// Error strings.
+const (
+	errRegisterMetrics = "cannot register connection store metrics"
+)
+
+// An InstrumentedStoreOption configures an InstrumentedStore.
+type InstrumentedStoreOption func(*InstrumentedStore)
+
+// WithValueSizeKeys configures the keys whose value sizes are recorded under
+// their own key label. The value sizes of all other keys are recorded under
+// the OtherKey label, which bounds the cardinality of the key label.
+func WithValueSizeKeys(keys ...string) InstrumentedStoreOption {
+	return func(s *InstrumentedStore) {
+		for _, k := range keys {
+			s.keys[k] = true
+		}
+	}
+}
+
+// WithStoreIdentity configures the store type and StoreConfig name an
+// InstrumentedStore labels its operation metrics with, so that operations
+// against different backends and StoreConfigs can be told apart. Operation
+// metrics are labelled with empty values by default.
+func WithStoreIdentity(storeType, config string) InstrumentedStoreOption {
+	return func(s *InstrumentedStore) {
+		s.storeType = storeType
+		s.config = config
+	}
+}
+
+// WithMetricsClock configures the Clock an InstrumentedStore uses to time
+// operations. store.RealClock is used by default.
+func WithMetricsClock(c store.Clock) InstrumentedStoreOption {
+	return func(s *InstrumentedStore) {
+		s.clock = c
+	}
+}
+
+// An InstrumentedStore is a Store that records metrics about the operations
+// of the Store it wraps.
+type InstrumentedStore struct {
+	store Store
+	keys  map[string]bool
+	clock store.Clock
+
+	storeType string
+	config    string
+
+	valueSize  *prometheus.HistogramVec
+	operations *prometheus.CounterVec
+	errors     *prometheus.CounterVec
+	duration   *prometheus.HistogramVec
+}
+
+// NewInstrumentedStore returns an InstrumentedStore that wraps the supplied
+// Store and registers its metrics with the supplied Registerer.
+func NewInstrumentedStore(s Store, r prometheus.Registerer, o ...InstrumentedStoreOption) (*InstrumentedStore, error) {
+	is := &InstrumentedStore{
+		store: s,
+		keys:  make(map[string]bool),
+		clock: store.RealClock,
+		valueSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Subsystem: metricsSubsystem,
+			Name:      "connection_details_value_size_bytes",
+			Help:      "The size of connection detail values written to a secret store, by key and controller",
+			Buckets:   prometheus.ExponentialBuckets(16, 4, 8),
+		}, []string{"key", "controller"}),
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Subsystem: metricsSubsystem,
+			Name:      "connection_store_operations_total",
+			Help:      "The number of operations against a secret store, by operation, store type, and StoreConfig",
+		}, operationLabels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Subsystem: metricsSubsystem,
+			Name:      "connection_store_operation_errors_total",
+			Help:      "The number of failed operations against a secret store, by operation, store type, and StoreConfig",
+		}, operationLabels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Subsystem: metricsSubsystem,
+			Name:      "connection_store_operation_duration_seconds",
+			Help:      "The time operations against a secret store took, by operation, store type, and StoreConfig",
+			Buckets:   prometheus.DefBuckets,
+		}, operationLabels),
+	}
+	for _, fn := range o {
+		fn(is)
+	}
+
+	var err error
+	if is.valueSize, err = register(r, is.valueSize); err != nil {
+		return nil, errors.Wrap(err, errRegisterMetrics)
+	}
+	if is.operations, err = register(r, is.operations); err != nil {
+		return nil, errors.Wrap(err, errRegisterMetrics)
+	}
+	if is.errors, err = register(r, is.errors); err != nil {
+		return nil, errors.Wrap(err, errRegisterMetrics)
+	}
+	if is.duration, err = register(r, is.duration); err != nil {
+		return nil, errors.Wrap(err, errRegisterMetrics)
+	}
+	return is, nil
+}
+
+// register registers the supplied collector with the supplied Registerer. It
+// returns the already registered collector if an equivalent collector was
+// registered before, e.g. by another InstrumentedStore, so that all
+// InstrumentedStores sharing a Registerer share their collectors.
+func register[T prometheus.Collector](r prometheus.Registerer, c T) (T, error) {
+	err := r.Register(c)
+	are := prometheus.AlreadyRegisteredError{}
+	if errors.As(err, &are) {
+		if existing, ok := are.ExistingCollector.(T); ok {
+			return existing, nil
+		}
+	}
+	return c, err
+}
+
+// observe records the outcome of an operation that started at the supplied
+// time.
+func (s *InstrumentedStore) observe(op string, started time.Time, err error) {
+	s.operations.WithLabelValues(op, s.storeType, s.config).Inc()
+	s.duration.WithLabelValues(op, s.storeType, s.config).Observe(s.clock.Now().Sub(started).Seconds())
+	if err != nil {
+		s.errors.WithLabelValues(op, s.storeType, s.config).Inc()
+	}
+}
+
+// Unwrap returns the Store the InstrumentedStore wraps.
+func (s *InstrumentedStore) Unwrap() Store {
+	return s.store
+}
+
+// ReadKeyValues reads key values from the wrapped Store.
+func (s *InstrumentedStore) ReadKeyValues(ctx context.Context, n store.ScopedName, sec *store.Secret, ro ...store.ReadOption) error {
+	started := s.clock.Now()
+	err := s.store.ReadKeyValues(ctx, n, sec, ro...)
+	s.observe(opRead, started, err)
+	return err
+}
+
+// WriteKeyValues writes key values to the wrapped Store, recording the size
+// of each written value. Sizes are labelled with the controller name carried
+// by the supplied context, if any.
+func (s *InstrumentedStore) WriteKeyValues(ctx context.Context, sec *store.Secret, wo ...store.WriteOption) (bool, error) {
+	started := s.clock.Now()
+	changed, err := s.store.WriteKeyValues(ctx, sec, wo...)
+	s.observe(opWrite, started, err)
+	if err != nil {
+		return changed, err
+	}
+	controller, _ := store.GetControllerName(ctx)
+	for k, v := range sec.Data {
+		if !s.keys[k] {
+			k = OtherKey
+		}
+		s.valueSize.WithLabelValues(k, controller).Observe(float64(len(v)))
+	}
+	return changed, nil
+}
+
+// DeleteKeyValues deletes key values from the wrapped Store.
+func (s *InstrumentedStore) DeleteKeyValues(ctx context.Context, sec *store.Secret, do ...store.DeleteOption) error {
+	started := s.clock.Now()
+	err := s.store.DeleteKeyValues(ctx, sec, do...)
+	s.observe(opDelete, started, err)
+	return err
+}
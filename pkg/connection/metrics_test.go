@@ -0,0 +1,251 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+)
+
+type observed struct {
+	Count uint64
+	Sum   float64
+}
+
+// histograms returns the sample count and sum of each histogram with the
+// supplied name gathered from the supplied registry, by the value of the
+// supplied label.
+func histograms(t *testing.T, g prometheus.Gatherer, name, label string) map[string]observed {
+	t.Helper()
+	mfs, err := g.Gather()
+	if err != nil {
+		t.Fatalf("g.Gather(): %v", err)
+	}
+	out := map[string]observed{}
+	for _, mf := range mfs {
+		if mf.GetName() != name {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			out[labelValue(m, label)] = observed{Count: m.GetHistogram().GetSampleCount(), Sum: m.GetHistogram().GetSampleSum()}
+		}
+	}
+	return out
+}
+
+// counters returns the value of each counter with the supplied name gathered
+// from the supplied registry, by the value of the supplied label.
+func counters(t *testing.T, g prometheus.Gatherer, name, label string) map[string]float64 {
+	t.Helper()
+	mfs, err := g.Gather()
+	if err != nil {
+		t.Fatalf("g.Gather(): %v", err)
+	}
+	out := map[string]float64{}
+	for _, mf := range mfs {
+		if mf.GetName() != name {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			out[labelValue(m, label)] += m.GetCounter().GetValue()
+		}
+	}
+	return out
+}
+
+func labelValue(m *dto.Metric, name string) string {
+	for _, l := range m.GetLabel() {
+		if l.GetName() == name {
+			return l.GetValue()
+		}
+	}
+	return ""
+}
+
+func TestInstrumentedStoreValueSize(t *testing.T) {
+	type args struct {
+		keys  []string
+		data  store.KeyValues
+		wErr  error
+		write int
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   map[string]observed
+	}{
+		"AllowedKeys": {
+			reason: "We should record the value sizes of allowed keys under their own label, and aggregate the rest.",
+			args: args{
+				keys: []string{"username", "password"},
+				data: store.KeyValues{
+					"username": []byte("admin"),
+					"password": []byte("hunter2"),
+					"cert":     []byte("0123456789"),
+					"key":      []byte("01234"),
+				},
+				write: 2,
+			},
+			want: map[string]observed{
+				"username": {Count: 2, Sum: 10},
+				"password": {Count: 2, Sum: 14},
+				OtherKey:   {Count: 4, Sum: 30},
+			},
+		},
+		"WriteFailed": {
+			reason: "We should not record value sizes if the write failed.",
+			args: args{
+				keys:  []string{"username"},
+				data:  store.KeyValues{"username": []byte("admin")},
+				wErr:  errBoom,
+				write: 1,
+			},
+			want: map[string]observed{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+			is, err := NewInstrumentedStore(&fake.SecretStore{
+				WriteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.WriteOption) (bool, error) {
+					return tc.args.wErr == nil, tc.args.wErr
+				},
+			}, reg, WithValueSizeKeys(tc.args.keys...))
+			if err != nil {
+				t.Fatalf("NewInstrumentedStore(...): %v", err)
+			}
+
+			for range tc.args.write {
+				_, _ = is.WriteKeyValues(context.Background(), &store.Secret{Data: tc.args.data})
+			}
+
+			got := histograms(t, reg, "crossplane_connection_details_value_size_bytes", "key")
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nis.WriteKeyValues(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestInstrumentedStoreControllerLabel(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	is, err := NewInstrumentedStore(&fake.SecretStore{
+		WriteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.WriteOption) (bool, error) { return true, nil },
+	}, reg)
+	if err != nil {
+		t.Fatalf("NewInstrumentedStore(...): %v", err)
+	}
+
+	_, _ = is.WriteKeyValues(store.WithControllerName(context.Background(), "managed/cool"), &store.Secret{Data: store.KeyValues{"key": []byte("a")}})
+	_, _ = is.WriteKeyValues(context.Background(), &store.Secret{Data: store.KeyValues{"key": []byte("bb")}})
+
+	want := map[string]observed{"managed/cool": {Count: 1, Sum: 1}, "": {Count: 1, Sum: 2}}
+	got := histograms(t, reg, "crossplane_connection_details_value_size_bytes", "controller")
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("is.WriteKeyValues(...): value sizes should be labelled with the controller name from the context: -want, +got:\n%s", diff)
+	}
+}
+
+func TestNewInstrumentedStoreRegistration(t *testing.T) {
+	write := func(_ context.Context, _ *store.Secret, _ ...store.WriteOption) (bool, error) { return true, nil }
+
+	t.Run("SharedCollectors", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		a, err := NewInstrumentedStore(&fake.SecretStore{WriteKeyValuesFn: write}, reg)
+		if err != nil {
+			t.Fatalf("NewInstrumentedStore(...): %v", err)
+		}
+		b, err := NewInstrumentedStore(&fake.SecretStore{WriteKeyValuesFn: write}, reg)
+		if err != nil {
+			t.Fatalf("NewInstrumentedStore(...): second store against the same registry: %v", err)
+		}
+
+		_, _ = a.WriteKeyValues(context.Background(), &store.Secret{Data: store.KeyValues{"key": []byte("a")}})
+		_, _ = b.WriteKeyValues(context.Background(), &store.Secret{Data: store.KeyValues{"key": []byte("bb")}})
+
+		want := map[string]observed{OtherKey: {Count: 2, Sum: 3}}
+		got := histograms(t, reg, "crossplane_connection_details_value_size_bytes", "key")
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("InstrumentedStores sharing a registry should share collectors: -want, +got:\n%s", diff)
+		}
+	})
+
+	t.Run("ConflictingCollector", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		reg.MustRegister(prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Subsystem: metricsSubsystem,
+			Name:      "connection_details_value_size_bytes",
+			Help:      "A conflicting collector",
+		}, []string{"secret"}))
+
+		if _, err := NewInstrumentedStore(&fake.SecretStore{WriteKeyValuesFn: write}, reg); err == nil {
+			t.Errorf("NewInstrumentedStore(...): expected an error registering a conflicting collector")
+		}
+	})
+}
+
+func TestInstrumentedStoreOperations(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	clock := fake.NewClock(time.Now())
+	is, err := NewInstrumentedStore(&fake.SecretStore{
+		ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, _ *store.Secret, _ ...store.ReadOption) error {
+			clock.Step(2 * time.Second)
+			return nil
+		},
+		WriteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.WriteOption) (bool, error) {
+			clock.Step(time.Second)
+			return false, errBoom
+		},
+		DeleteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.DeleteOption) error { return nil },
+	}, reg, WithStoreIdentity("Vault", "cool"), WithMetricsClock(clock))
+	if err != nil {
+		t.Fatalf("NewInstrumentedStore(...): %v", err)
+	}
+
+	_ = is.ReadKeyValues(context.Background(), store.ScopedName{}, &store.Secret{})
+	_, _ = is.WriteKeyValues(context.Background(), &store.Secret{})
+	_, _ = is.WriteKeyValues(context.Background(), &store.Secret{})
+	_ = is.DeleteKeyValues(context.Background(), &store.Secret{})
+
+	wantOps := map[string]float64{opRead: 1, opWrite: 2, opDelete: 1}
+	if diff := cmp.Diff(wantOps, counters(t, reg, "crossplane_connection_store_operations_total", "operation")); diff != "" {
+		t.Errorf("InstrumentedStore should count operations: -want, +got:\n%s", diff)
+	}
+	wantErrs := map[string]float64{opWrite: 2}
+	if diff := cmp.Diff(wantErrs, counters(t, reg, "crossplane_connection_store_operation_errors_total", "operation")); diff != "" {
+		t.Errorf("InstrumentedStore should count failed operations: -want, +got:\n%s", diff)
+	}
+	wantDuration := map[string]observed{opRead: {Count: 1, Sum: 2}, opWrite: {Count: 2, Sum: 2}, opDelete: {Count: 1, Sum: 0}}
+	if diff := cmp.Diff(wantDuration, histograms(t, reg, "crossplane_connection_store_operation_duration_seconds", "operation")); diff != "" {
+		t.Errorf("InstrumentedStore should time operations: -want, +got:\n%s", diff)
+	}
+	for _, l := range []struct{ name, want string }{{"store_type", "Vault"}, {"store_config", "cool"}} {
+		if diff := cmp.Diff(map[string]float64{l.want: 4}, counters(t, reg, "crossplane_connection_store_operations_total", l.name)); diff != "" {
+			t.Errorf("InstrumentedStore should label operations with its %s: -want, +got:\n%s", l.name, diff)
+		}
+	}
+}
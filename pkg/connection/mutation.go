@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// AnnotationKeyObservedHash is the annotation a MutationTrackingStore uses to
+// record the hash of the key values it wrote to a secret.
+const AnnotationKeyObservedHash = "connection.crossplane.io/observed-hash"
+
+// A MutationTrackingStore is a Store that records the hash of the key values
+// it writes to a secret, so that out-of-band mutations can later be detected.
+// This is most useful for immutable secrets, which should only change when
+// they are deleted and recreated.
+type MutationTrackingStore struct {
+	store Store
+}
+
+// NewMutationTrackingStore returns a MutationTrackingStore that wraps the
+// supplied Store.
+func NewMutationTrackingStore(s Store) *MutationTrackingStore {
+	return &MutationTrackingStore{store: s}
+}
+
+// Unwrap returns the Store the MutationTrackingStore wraps.
+func (s *MutationTrackingStore) Unwrap() Store {
+	return s.store
+}
+
+// ReadKeyValues reads key values from the wrapped Store.
+func (s *MutationTrackingStore) ReadKeyValues(ctx context.Context, n store.ScopedName, sec *store.Secret, ro ...store.ReadOption) error {
+	return s.store.ReadKeyValues(ctx, n, sec, ro...)
+}
+
+// WriteKeyValues writes key values to the wrapped Store, annotating the secret
+// with their hash.
+func (s *MutationTrackingStore) WriteKeyValues(ctx context.Context, sec *store.Secret, wo ...store.WriteOption) (bool, error) {
+	sec = sec.DeepCopy()
+	if sec.Metadata == nil {
+		sec.Metadata = &v1.ConnectionSecretMetadata{}
+	}
+	if sec.Metadata.Annotations == nil {
+		sec.Metadata.Annotations = map[string]string{}
+	}
+	sec.Metadata.Annotations[AnnotationKeyObservedHash] = store.Hash(sec.Data)
+	return s.store.WriteKeyValues(ctx, sec, wo...)
+}
+
+// DeleteKeyValues deletes key values from the wrapped Store.
+func (s *MutationTrackingStore) DeleteKeyValues(ctx context.Context, sec *store.Secret, do ...store.DeleteOption) error {
+	return s.store.DeleteKeyValues(ctx, sec, do...)
+}
+
+// DetectMutation returns true if the key values of the supplied secret were
+// changed after they were last written by a MutationTrackingStore. It returns
+// false for secrets that were never written by a MutationTrackingStore.
+func (s *MutationTrackingStore) DetectMutation(ctx context.Context, n store.ScopedName) (bool, error) {
+	sec := &store.Secret{}
+	if err := s.store.ReadKeyValues(ctx, n, sec); err != nil {
+		return false, errors.Wrap(err, errReadStore)
+	}
+	if sec.Metadata == nil {
+		return false, nil
+	}
+	observed, ok := sec.Metadata.Annotations[AnnotationKeyObservedHash]
+	if !ok {
+		return false, nil
+	}
+	return store.Hash(sec.Data) != observed, nil
+}
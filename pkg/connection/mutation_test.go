@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestMutationTrackingStoreDetectMutation(t *testing.T) {
+	n := store.ScopedName{Name: "cool", Scope: "ns"}
+
+	type want struct {
+		mutated bool
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		write   bool
+		mutate  func(s *store.Secret)
+		readErr error
+		want    want
+	}{
+		"Unmutated": {
+			reason: "We should not detect a mutation of a secret that didn't change after it was written.",
+			write:  true,
+		},
+		"ExternallyMutated": {
+			reason: "We should detect a mutation of a secret that changed after it was written.",
+			write:  true,
+			mutate: func(s *store.Secret) {
+				// E.g. an immutable secret was deleted and recreated.
+				s.Data = store.KeyValues{"password": []byte("changed")}
+			},
+			want: want{
+				mutated: true,
+			},
+		},
+		"NeverWritten": {
+			reason: "We should not detect a mutation of a secret we never wrote.",
+		},
+		"ReadError": {
+			reason:  "We should return any error encountered reading the secret.",
+			readErr: errBoom,
+			want: want{
+				err: errors.Wrap(errBoom, errReadStore),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			stored := &store.Secret{ScopedName: n, Data: store.KeyValues{"password": []byte("s3cr3t")}}
+			s := NewMutationTrackingStore(&fake.SecretStore{
+				ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
+					*s = *stored.DeepCopy()
+					return tc.readErr
+				},
+				WriteKeyValuesFn: func(_ context.Context, s *store.Secret, _ ...store.WriteOption) (bool, error) {
+					stored = s.DeepCopy()
+					return true, nil
+				},
+			})
+
+			if tc.write {
+				if _, err := s.WriteKeyValues(context.Background(), &store.Secret{ScopedName: n, Data: store.KeyValues{"password": []byte("s3cr3t")}, Immutable: true}); err != nil {
+					t.Fatalf("s.WriteKeyValues(...): %v", err)
+				}
+			}
+			if tc.mutate != nil {
+				tc.mutate(stored)
+			}
+
+			mutated, err := s.DetectMutation(context.Background(), n)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ns.DetectMutation(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.mutated, mutated); diff != "" {
+				t.Errorf("\n%s\ns.DetectMutation(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
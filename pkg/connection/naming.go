@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errFmtNonConformingName  = "secret name %q does not match the required pattern %q"
+	errFmtNonConformingScope = "secret scope %q does not match the required pattern %q"
+)
+
+// A NamingStoreOption configures a NamingStore.
+type NamingStoreOption func(*NamingStore)
+
+// WithNamePattern configures the pattern a NamingStore requires secret names
+// to match.
+func WithNamePattern(re *regexp.Regexp) NamingStoreOption {
+	return func(s *NamingStore) {
+		s.name = re
+	}
+}
+
+// WithScopePattern configures the pattern a NamingStore requires secret
+// scopes to match.
+func WithScopePattern(re *regexp.Regexp) NamingStoreOption {
+	return func(s *NamingStore) {
+		s.scope = re
+	}
+}
+
+// A NamingStore is a Store that enforces naming conventions, for example to
+// keep the layout of an external secret store tidy. Operations on secrets
+// whose name or scope don't match the configured patterns are rejected before
+// they reach the wrapped Store. Names and scopes are not constrained unless a
+// pattern is configured.
+type NamingStore struct {
+	store Store
+	name  *regexp.Regexp
+	scope *regexp.Regexp
+}
+
+// NewNamingStore returns a NamingStore that wraps the supplied Store.
+func NewNamingStore(s Store, o ...NamingStoreOption) *NamingStore {
+	ns := &NamingStore{store: s}
+	for _, fn := range o {
+		fn(ns)
+	}
+	return ns
+}
+
+// ReadKeyValues reads key values from the wrapped Store, if the supplied name
+// conforms to the NamingStore's naming conventions.
+func (s *NamingStore) ReadKeyValues(ctx context.Context, n store.ScopedName, sec *store.Secret, ro ...store.ReadOption) error {
+	if err := s.check(n); err != nil {
+		return err
+	}
+	return s.store.ReadKeyValues(ctx, n, sec, ro...)
+}
+
+// WriteKeyValues writes key values to the wrapped Store, if the supplied
+// secret conforms to the NamingStore's naming conventions.
+func (s *NamingStore) WriteKeyValues(ctx context.Context, sec *store.Secret, wo ...store.WriteOption) (bool, error) {
+	if err := s.check(sec.ScopedName); err != nil {
+		return false, err
+	}
+	return s.store.WriteKeyValues(ctx, sec, wo...)
+}
+
+// DeleteKeyValues deletes key values from the wrapped Store, if the supplied
+// secret conforms to the NamingStore's naming conventions.
+func (s *NamingStore) DeleteKeyValues(ctx context.Context, sec *store.Secret, do ...store.DeleteOption) error {
+	if err := s.check(sec.ScopedName); err != nil {
+		return err
+	}
+	return s.store.DeleteKeyValues(ctx, sec, do...)
+}
+
+func (s *NamingStore) check(n store.ScopedName) error {
+	if s.name != nil && !s.name.MatchString(n.Name) {
+		return errors.Errorf(errFmtNonConformingName, n.Name, s.name)
+	}
+	if s.scope != nil && !s.scope.MatchString(n.Scope) {
+		return errors.Errorf(errFmtNonConformingScope, n.Scope, s.scope)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestNamingStore(t *testing.T) {
+	namePattern := regexp.MustCompile(`^app-[a-z]+$`)
+	scopePattern := regexp.MustCompile(`^team-[a-z]+$`)
+
+	cases := map[string]struct {
+		reason string
+		n      store.ScopedName
+		want   error
+	}{
+		"Conforming": {
+			reason: "We should pass operations on conforming secrets to the wrapped store.",
+			n:      store.ScopedName{Name: "app-cool", Scope: "team-cool"},
+		},
+		"NonConformingName": {
+			reason: "We should reject operations on secrets whose name doesn't match the name pattern.",
+			n:      store.ScopedName{Name: "cool", Scope: "team-cool"},
+			want:   errors.Errorf(errFmtNonConformingName, "cool", namePattern),
+		},
+		"NonConformingScope": {
+			reason: "We should reject operations on secrets whose scope doesn't match the scope pattern.",
+			n:      store.ScopedName{Name: "app-cool", Scope: "default"},
+			want:   errors.Errorf(errFmtNonConformingScope, "default", scopePattern),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			calls := 0
+			s := NewNamingStore(&fake.SecretStore{
+				ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, _ *store.Secret, _ ...store.ReadOption) error {
+					calls++
+					return nil
+				},
+				WriteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.WriteOption) (bool, error) {
+					calls++
+					return true, nil
+				},
+				DeleteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.DeleteOption) error {
+					calls++
+					return nil
+				},
+			}, WithNamePattern(namePattern), WithScopePattern(scopePattern))
+
+			err := s.ReadKeyValues(context.Background(), tc.n, &store.Secret{})
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ns.ReadKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			_, err = s.WriteKeyValues(context.Background(), &store.Secret{ScopedName: tc.n})
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ns.WriteKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			err = s.DeleteKeyValues(context.Background(), &store.Secret{ScopedName: tc.n})
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ns.DeleteKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+
+			wantCalls := 3
+			if tc.want != nil {
+				wantCalls = 0
+			}
+			if calls != wantCalls {
+				t.Errorf("\n%s\nwrapped store calls: want %d, got %d", tc.reason, wantCalls, calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errEvaluatePolicy = "cannot evaluate write policy"
+
+	errFmtPolicyDenied = "write of secret %q in scope %q denied by policy: %s"
+)
+
+// A PolicyDecision is the outcome of evaluating a write against a policy.
+type PolicyDecision struct {
+	// Allowed is true if the write may proceed.
+	Allowed bool
+
+	// Reason explains the decision. It is surfaced to callers when a write is
+	// denied, so it should tell them how to make their write conform.
+	Reason string
+}
+
+// A PolicyEvaluator evaluates secrets against a policy before they are
+// written, for example to refuse writing plaintext passwords to stores that
+// aren't approved for them. A PolicyEvaluator may be backed by a policy engine
+// such as OPA.
+type PolicyEvaluator interface {
+	Evaluate(ctx context.Context, s *store.Secret) (PolicyDecision, error)
+}
+
+// A PolicyEvaluatorFn is a function that satisfies PolicyEvaluator.
+type PolicyEvaluatorFn func(ctx context.Context, s *store.Secret) (PolicyDecision, error)
+
+// Evaluate the supplied secret against a policy.
+func (fn PolicyEvaluatorFn) Evaluate(ctx context.Context, s *store.Secret) (PolicyDecision, error) {
+	return fn(ctx, s)
+}
+
+// A PolicyStore is a Store that evaluates secrets against a policy before they
+// are written to the Store it wraps. Writes the policy denies, or that can't
+// be evaluated, never reach the wrapped Store. Reads and deletes are passed
+// through as is.
+type PolicyStore struct {
+	store  Store
+	policy PolicyEvaluator
+}
+
+// NewPolicyStore returns a PolicyStore that wraps the supplied Store, and
+// evaluates writes using the supplied PolicyEvaluator.
+func NewPolicyStore(s Store, pe PolicyEvaluator) *PolicyStore {
+	return &PolicyStore{store: s, policy: pe}
+}
+
+// Unwrap returns the Store the PolicyStore wraps.
+func (p *PolicyStore) Unwrap() Store {
+	return p.store
+}
+
+// ReadKeyValues reads key values from the wrapped Store.
+func (p *PolicyStore) ReadKeyValues(ctx context.Context, n store.ScopedName, s *store.Secret, ro ...store.ReadOption) error {
+	return p.store.ReadKeyValues(ctx, n, s, ro...)
+}
+
+// WriteKeyValues writes key values to the wrapped Store, if the policy allows
+// it.
+func (p *PolicyStore) WriteKeyValues(ctx context.Context, s *store.Secret, wo ...store.WriteOption) (bool, error) {
+	d, err := p.policy.Evaluate(ctx, s)
+	if err != nil {
+		return false, errors.Wrap(err, errEvaluatePolicy)
+	}
+	if !d.Allowed {
+		return false, errors.Errorf(errFmtPolicyDenied, s.Name, s.Scope, d.Reason)
+	}
+	return p.store.WriteKeyValues(ctx, s, wo...)
+}
+
+// DeleteKeyValues deletes key values from the wrapped Store.
+func (p *PolicyStore) DeleteKeyValues(ctx context.Context, s *store.Secret, do ...store.DeleteOption) error {
+	return p.store.DeleteKeyValues(ctx, s, do...)
+}
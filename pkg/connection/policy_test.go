@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestPolicyStoreWriteKeyValues(t *testing.T) {
+	// noPlaintextPasswords denies writing a password key.
+	noPlaintextPasswords := PolicyEvaluatorFn(func(_ context.Context, s *store.Secret) (PolicyDecision, error) {
+		if _, ok := s.Data["password"]; ok {
+			return PolicyDecision{Reason: "plaintext passwords may not be written to this store"}, nil
+		}
+		return PolicyDecision{Allowed: true}, nil
+	})
+
+	type want struct {
+		changed bool
+		writes  int
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		policy PolicyEvaluator
+		data   store.KeyValues
+		want   want
+	}{
+		"Allowed": {
+			reason: "We should write secrets the policy allows.",
+			policy: noPlaintextPasswords,
+			data:   store.KeyValues{"endpoint": []byte("db.example.org")},
+			want: want{
+				changed: true,
+				writes:  1,
+			},
+		},
+		"Denied": {
+			reason: "We should not write secrets the policy denies, and surface why.",
+			policy: noPlaintextPasswords,
+			data:   store.KeyValues{"password": []byte("hunter2")},
+			want: want{
+				err: errors.Errorf(errFmtPolicyDenied, "cool", "ns", "plaintext passwords may not be written to this store"),
+			},
+		},
+		"EvaluationFailed": {
+			reason: "We should not write secrets if the policy can't be evaluated.",
+			policy: PolicyEvaluatorFn(func(_ context.Context, _ *store.Secret) (PolicyDecision, error) {
+				return PolicyDecision{Allowed: true}, errBoom
+			}),
+			want: want{
+				err: errors.Wrap(errBoom, errEvaluatePolicy),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			writes := 0
+			p := NewPolicyStore(&fake.SecretStore{
+				WriteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.WriteOption) (bool, error) {
+					writes++
+					return true, nil
+				},
+			}, tc.policy)
+
+			changed, err := p.WriteKeyValues(context.Background(), &store.Secret{ScopedName: store.ScopedName{Name: "cool", Scope: "ns"}, Data: tc.data})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\np.WriteKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.changed, changed); diff != "" {
+				t.Errorf("\n%s\np.WriteKeyValues(...): -want changed, +got changed:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.writes, writes); diff != "" {
+				t.Errorf("\n%s\np.WriteKeyValues(...): -want writes, +got writes:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+)
+
+// StoreConfigKeyPrefix returns the key prefix of the StoreConfig with the
+// supplied name. StoreConfig names can't contain underscores, so the prefixes
+// of different StoreConfigs never overlap.
+func StoreConfigKeyPrefix(name string) string {
+	return name + "_"
+}
+
+// A KeyPrefixStore is a Store that isolates the keys of multiple tenants
+// sharing a Store. Keys are prefixed before they're written to the wrapped
+// Store, and only keys with the prefix are read, with the prefix stripped.
+// Tenants using different prefixes therefore never see or overwrite each
+// other's keys, even if they use identical keys. Write options passed to the
+// wrapped Store see prefixed keys.
+//
+// Tenants may share a secret only if the wrapped Store merges the key values
+// it writes into a secret, rather than replacing them.
+type KeyPrefixStore struct {
+	store  Store
+	prefix string
+}
+
+// NewKeyPrefixStore returns a KeyPrefixStore that wraps the supplied Store,
+// and prefixes keys with the supplied prefix.
+func NewKeyPrefixStore(s Store, prefix string) *KeyPrefixStore {
+	return &KeyPrefixStore{store: s, prefix: prefix}
+}
+
+// Unwrap returns the Store the KeyPrefixStore wraps.
+func (p *KeyPrefixStore) Unwrap() Store {
+	return p.store
+}
+
+// ReadKeyValues reads the key values with the KeyPrefixStore's prefix from the
+// wrapped Store, and strips the prefix from their keys.
+func (p *KeyPrefixStore) ReadKeyValues(ctx context.Context, n store.ScopedName, s *store.Secret, ro ...store.ReadOption) error {
+	if err := p.store.ReadKeyValues(ctx, n, s); err != nil {
+		return err
+	}
+	if s.Data != nil {
+		kv := make(store.KeyValues, len(s.Data))
+		for k, v := range s.Data {
+			if sk, ok := strings.CutPrefix(k, p.prefix); ok {
+				kv[sk] = v
+			}
+		}
+		s.Data = kv
+	}
+	return store.ApplyReadOptions(ctx, s, ro...)
+}
+
+// WriteKeyValues prefixes the supplied key values and writes them to the
+// wrapped Store.
+func (p *KeyPrefixStore) WriteKeyValues(ctx context.Context, s *store.Secret, wo ...store.WriteOption) (bool, error) {
+	return p.store.WriteKeyValues(ctx, p.prefixed(s), wo...)
+}
+
+// DeleteKeyValues deletes the supplied key values from the wrapped Store. If
+// the secret's data is nil, all key values with the KeyPrefixStore's prefix
+// are deleted, and other tenants' key values are kept. Deleting empty, non-nil
+// data is a no-op.
+func (p *KeyPrefixStore) DeleteKeyValues(ctx context.Context, s *store.Secret, do ...store.DeleteOption) error {
+	if s.Data != nil && len(s.Data) == 0 {
+		return nil
+	}
+	if len(s.Data) > 0 {
+		return p.store.DeleteKeyValues(ctx, p.prefixed(s), do...)
+	}
+
+	current := &store.Secret{}
+	if err := p.ReadKeyValues(ctx, s.ScopedName, current); err != nil {
+		return err
+	}
+	if len(current.Data) == 0 {
+		return nil
+	}
+	ds := s.DeepCopy()
+	ds.Data = current.Data
+	return p.store.DeleteKeyValues(ctx, p.prefixed(ds), do...)
+}
+
+func (p *KeyPrefixStore) prefixed(s *store.Secret) *store.Secret {
+	ps := s.DeepCopy()
+	if s.Data != nil {
+		ps.Data = make(store.KeyValues, len(s.Data))
+		for k, v := range s.Data {
+			ps.Data[p.prefix+k] = v
+		}
+	}
+	return ps
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+)
+
+// mergingStore returns a fake Store that merges writes into, and deletes keys
+// from, the supplied secret data.
+func mergingStore(data store.KeyValues) *fake.SecretStore {
+	return &fake.SecretStore{
+		ReadKeyValuesFn: func(_ context.Context, n store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
+			s.ScopedName = n
+			s.Data = make(store.KeyValues, len(data))
+			for k, v := range data {
+				s.Data[k] = v
+			}
+			return nil
+		},
+		WriteKeyValuesFn: func(_ context.Context, s *store.Secret, _ ...store.WriteOption) (bool, error) {
+			for k, v := range s.Data {
+				data[k] = v
+			}
+			return true, nil
+		},
+		DeleteKeyValuesFn: func(_ context.Context, s *store.Secret, _ ...store.DeleteOption) error {
+			if len(s.Data) == 0 {
+				clear(data)
+			}
+			for k := range s.Data {
+				delete(data, k)
+			}
+			return nil
+		},
+	}
+}
+
+func TestKeyPrefixStore(t *testing.T) {
+	ctx := context.Background()
+	n := store.ScopedName{Name: "shared", Scope: "ns"}
+	data := store.KeyValues{}
+	inner := mergingStore(data)
+
+	a := NewKeyPrefixStore(inner, StoreConfigKeyPrefix("tenant-a"))
+	b := NewKeyPrefixStore(inner, StoreConfigKeyPrefix("tenant-a.b"))
+
+	for _, w := range []struct {
+		s Store
+		v string
+	}{{a, "a"}, {b, "b"}} {
+		if _, err := w.s.WriteKeyValues(ctx, &store.Secret{ScopedName: n, Data: store.KeyValues{"password": []byte(w.v)}}); err != nil {
+			t.Fatalf("WriteKeyValues(...): %v", err)
+		}
+	}
+
+	want := store.KeyValues{"tenant-a_password": []byte("a"), "tenant-a.b_password": []byte("b")}
+	if diff := cmp.Diff(want, data); diff != "" {
+		t.Errorf("WriteKeyValues(...): -want stored, +got stored:\n%s", diff)
+	}
+
+	// Each tenant should read back only its own keys, with the prefix
+	// stripped.
+	for _, r := range []struct {
+		s    Store
+		want store.KeyValues
+	}{{a, store.KeyValues{"password": []byte("a")}}, {b, store.KeyValues{"password": []byte("b")}}} {
+		s := &store.Secret{}
+		if err := r.s.ReadKeyValues(ctx, n, s); err != nil {
+			t.Fatalf("ReadKeyValues(...): %v", err)
+		}
+		if diff := cmp.Diff(r.want, s.Data); diff != "" {
+			t.Errorf("ReadKeyValues(...): -want, +got:\n%s", diff)
+		}
+	}
+
+	// Deleting a tenant's whole secret should keep the other tenant's keys.
+	if err := a.DeleteKeyValues(ctx, &store.Secret{ScopedName: n}); err != nil {
+		t.Fatalf("a.DeleteKeyValues(...): %v", err)
+	}
+	if diff := cmp.Diff(store.KeyValues{"tenant-a.b_password": []byte("b")}, data); diff != "" {
+		t.Errorf("a.DeleteKeyValues(...): -want stored, +got stored:\n%s", diff)
+	}
+
+	// Deleting no keys should delete nothing.
+	if err := b.DeleteKeyValues(ctx, &store.Secret{ScopedName: n, Data: store.KeyValues{}}); err != nil {
+		t.Fatalf("b.DeleteKeyValues(...): %v", err)
+	}
+	if diff := cmp.Diff(store.KeyValues{"tenant-a.b_password": []byte("b")}, data); diff != "" {
+		t.Errorf("b.DeleteKeyValues(...): -want stored, +got stored:\n%s", diff)
+	}
+}
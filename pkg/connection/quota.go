@@ -0,0 +1,163 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errFmtQuotaExceeded = "cannot write %d bytes to secret %q: %d of the %d bytes quota of store config %q are already used"
+)
+
+// A QuotaTracker tracks how many bytes of their quota have been written to the
+// Stores of each StoreConfig. Stores are typically built for each operation,
+// so a QuotaTracker must outlive them, and be shared by all QuotaStores that
+// write to the Stores of a StoreConfig.
+type QuotaTracker struct {
+	mu   sync.Mutex
+	used map[string]int64
+
+	utilization *prometheus.GaugeVec
+}
+
+// NewQuotaTracker returns a QuotaTracker that registers its quota utilization
+// metric with the supplied Registerer, labelled with the name of each
+// StoreConfig.
+func NewQuotaTracker(r prometheus.Registerer) (*QuotaTracker, error) {
+	t := &QuotaTracker{
+		used: make(map[string]int64),
+		utilization: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Subsystem: metricsSubsystem,
+			Name:      "connection_store_quota_utilization_ratio",
+			Help:      "The fraction of the byte quota of a secret store that is used, by store config",
+		}, []string{"store_config"}),
+	}
+
+	var err error
+	if t.utilization, err = register(r, t.utilization); err != nil {
+		return nil, errors.Wrap(err, errRegisterMetrics)
+	}
+	return t, nil
+}
+
+// Used returns how many bytes have been written to the Stores of the supplied
+// StoreConfig.
+func (t *QuotaTracker) Used(storeConfig string) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.used[storeConfig]
+}
+
+// reserve the supplied number of bytes of the supplied quota of the supplied
+// StoreConfig, unless doing so would exceed it. It returns how many bytes were
+// used before reserving.
+func (t *QuotaTracker) reserve(storeConfig string, size, quota int64) (int64, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	used := t.used[storeConfig]
+	if used+size > quota {
+		return used, false
+	}
+	t.used[storeConfig] = used + size
+	t.observe(storeConfig, quota)
+	return used, true
+}
+
+// release the supplied number of bytes previously reserved of the supplied
+// quota of the supplied StoreConfig.
+func (t *QuotaTracker) release(storeConfig string, size, quota int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.used[storeConfig] -= size
+	t.observe(storeConfig, quota)
+}
+
+// observe records the utilization of the supplied quota of the supplied
+// StoreConfig. It must be called with the QuotaTracker locked.
+func (t *QuotaTracker) observe(storeConfig string, quota int64) {
+	if quota > 0 {
+		t.utilization.WithLabelValues(storeConfig).Set(float64(t.used[storeConfig]) / float64(quota))
+	}
+}
+
+// A QuotaStore is a Store that limits the total number of bytes written to the
+// Stores of a StoreConfig, for example to control the cost of an external
+// store. Each write that changes a secret uses as many bytes of the quota as
+// the keys and values it writes. Writes that would exceed the quota are
+// rejected. Usage is tracked by a QuotaTracker for its lifetime, and is not
+// decreased by deletes.
+type QuotaStore struct {
+	store       Store
+	storeConfig string
+	quota       int64
+	tracker     *QuotaTracker
+}
+
+// NewQuotaStore returns a QuotaStore that wraps the supplied Store, allowing
+// at most the supplied number of bytes to be written to the Stores of the
+// supplied StoreConfig. Usage is recorded by the supplied QuotaTracker.
+func NewQuotaStore(s Store, storeConfig string, quota int64, t *QuotaTracker) *QuotaStore {
+	t.mu.Lock()
+	t.observe(storeConfig, quota)
+	t.mu.Unlock()
+	return &QuotaStore{store: s, storeConfig: storeConfig, quota: quota, tracker: t}
+}
+
+// Unwrap returns the Store the QuotaStore wraps.
+func (s *QuotaStore) Unwrap() Store {
+	return s.store
+}
+
+// ReadKeyValues reads key values from the wrapped Store.
+func (s *QuotaStore) ReadKeyValues(ctx context.Context, n store.ScopedName, sec *store.Secret, ro ...store.ReadOption) error {
+	return s.store.ReadKeyValues(ctx, n, sec, ro...)
+}
+
+// WriteKeyValues writes key values to the wrapped Store, unless doing so would
+// exceed the quota.
+func (s *QuotaStore) WriteKeyValues(ctx context.Context, sec *store.Secret, wo ...store.WriteOption) (bool, error) {
+	var size int64
+	for k, v := range sec.Data {
+		size += int64(len(k) + len(v))
+	}
+
+	// We reserve the bytes before writing, so that concurrent writes can't
+	// together exceed the quota, and release them if the write didn't use
+	// them.
+	used, ok := s.tracker.reserve(s.storeConfig, size, s.quota)
+	if !ok {
+		return false, errors.Errorf(errFmtQuotaExceeded, size, sec.Name, used, s.quota, s.storeConfig)
+	}
+	changed, err := s.store.WriteKeyValues(ctx, sec, wo...)
+	if !changed {
+		s.tracker.release(s.storeConfig, size, s.quota)
+	}
+	return changed, err
+}
+
+// DeleteKeyValues deletes key values from the wrapped Store.
+func (s *QuotaStore) DeleteKeyValues(ctx context.Context, sec *store.Secret, do ...store.DeleteOption) error {
+	return s.store.DeleteKeyValues(ctx, sec, do...)
+}
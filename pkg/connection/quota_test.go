@@ -0,0 +1,156 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+// gauge returns the value of the gauge with the supplied name and label value
+// gathered from the supplied registry.
+func gauge(t *testing.T, g prometheus.Gatherer, name, label, value string) float64 {
+	t.Helper()
+	mfs, err := g.Gather()
+	if err != nil {
+		t.Fatalf("g.Gather(): %v", err)
+	}
+	for _, mf := range mfs {
+		if mf.GetName() != name {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			if labelValue(m, label) == value {
+				return m.GetGauge().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func TestQuotaStoreWriteKeyValues(t *testing.T) {
+	// Each of these secrets uses 4 bytes of the quota.
+	small := store.KeyValues{"ab": []byte("cd")}
+
+	type args struct {
+		quota   int64
+		writes  []store.KeyValues
+		changed bool
+		err     error
+	}
+	type want struct {
+		written     int
+		err         error
+		utilization float64
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"UnderQuota": {
+			reason: "We should allow writes that don't exceed the quota, and record its utilization.",
+			args: args{
+				quota:   10,
+				writes:  []store.KeyValues{small, small},
+				changed: true,
+			},
+			want: want{
+				written:     2,
+				utilization: 0.8,
+			},
+		},
+		"OverQuota": {
+			reason: "We should reject a write that would exceed the quota.",
+			args: args{
+				quota:   10,
+				writes:  []store.KeyValues{small, small, small},
+				changed: true,
+			},
+			want: want{
+				written:     2,
+				err:         errors.Errorf(errFmtQuotaExceeded, 4, "cool", 8, 10, "cool-config"),
+				utilization: 0.8,
+			},
+		},
+		"FailedWrites": {
+			reason: "We should not count writes that failed against the quota.",
+			args: args{
+				quota:  10,
+				writes: []store.KeyValues{small, small, small},
+				err:    errBoom,
+			},
+			want: want{
+				written: 3,
+				err:     errBoom,
+			},
+		},
+		"UnchangedWrites": {
+			reason: "We should not count writes that didn't change the secret against the quota.",
+			args: args{
+				quota:  10,
+				writes: []store.KeyValues{small, small, small},
+			},
+			want: want{
+				written: 3,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			written := 0
+			reg := prometheus.NewRegistry()
+			qt, err := NewQuotaTracker(reg)
+			if err != nil {
+				t.Fatalf("NewQuotaTracker(...): %v", err)
+			}
+			s := &fake.SecretStore{
+				WriteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.WriteOption) (bool, error) {
+					written++
+					return tc.args.changed, tc.args.err
+				},
+			}
+
+			for _, kv := range tc.args.writes {
+				// Stores are built for each operation, so usage must be
+				// tracked across QuotaStores.
+				qs := NewQuotaStore(s, "cool-config", tc.args.quota, qt)
+				_, err = qs.WriteKeyValues(context.Background(), &store.Secret{ScopedName: store.ScopedName{Name: "cool"}, Data: kv})
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nqs.WriteKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.written, written); diff != "" {
+				t.Errorf("\n%s\nqs.WriteKeyValues(...): -want writes, +got writes:\n%s", tc.reason, diff)
+			}
+			got := gauge(t, reg, "crossplane_connection_store_quota_utilization_ratio", "store_config", "cool-config")
+			if diff := cmp.Diff(tc.want.utilization, got); diff != "" {
+				t.Errorf("\n%s\nqs.WriteKeyValues(...): -want utilization, +got utilization:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errRateLimit = "cannot wait for secret store rate limit"
+)
+
+// A RateLimitedStore is a Store that limits the total rate of writes and
+// deletes to the Store it wraps, regardless of which owner they are for.
+// Operations that exceed the rate block until they are allowed, or until
+// their context is done. Reads are not limited.
+type RateLimitedStore struct {
+	store   Store
+	limiter *rate.Limiter
+
+	throttled   *prometheus.CounterVec
+	utilization prometheus.Gauge
+}
+
+// NewRateLimitedStore returns a RateLimitedStore that wraps the supplied
+// Store, allowing the supplied number of writes and deletes per second with
+// the supplied burst. Its metrics are registered with the supplied
+// Registerer.
+func NewRateLimitedStore(s Store, limit rate.Limit, burst int, r prometheus.Registerer) (*RateLimitedStore, error) {
+	rs := &RateLimitedStore{
+		store:   s,
+		limiter: rate.NewLimiter(limit, burst),
+		throttled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Subsystem: metricsSubsystem,
+			Name:      "connection_store_throttled_total",
+			Help:      "The number of secret store operations that were throttled by the global rate limit, by operation",
+		}, []string{"operation"}),
+		utilization: prometheus.NewGauge(prometheus.GaugeOpts{
+			Subsystem: metricsSubsystem,
+			Name:      "connection_store_rate_limit_utilization_ratio",
+			Help:      "The fraction of the global secret store rate limit burst that is currently in use",
+		}),
+	}
+
+	var err error
+	if rs.throttled, err = register(r, rs.throttled); err != nil {
+		return nil, errors.Wrap(err, errRegisterMetrics)
+	}
+	if rs.utilization, err = register(r, rs.utilization); err != nil {
+		return nil, errors.Wrap(err, errRegisterMetrics)
+	}
+	return rs, nil
+}
+
+// Unwrap returns the Store the RateLimitedStore wraps.
+func (s *RateLimitedStore) Unwrap() Store {
+	return s.store
+}
+
+// ReadKeyValues reads key values from the wrapped Store.
+func (s *RateLimitedStore) ReadKeyValues(ctx context.Context, n store.ScopedName, sec *store.Secret, ro ...store.ReadOption) error {
+	return s.store.ReadKeyValues(ctx, n, sec, ro...)
+}
+
+// WriteKeyValues writes key values to the wrapped Store once the rate limit
+// allows it.
+func (s *RateLimitedStore) WriteKeyValues(ctx context.Context, sec *store.Secret, wo ...store.WriteOption) (bool, error) {
+	if err := s.wait(ctx, "write"); err != nil {
+		return false, err
+	}
+	return s.store.WriteKeyValues(ctx, sec, wo...)
+}
+
+// DeleteKeyValues deletes key values from the wrapped Store once the rate
+// limit allows it.
+func (s *RateLimitedStore) DeleteKeyValues(ctx context.Context, sec *store.Secret, do ...store.DeleteOption) error {
+	if err := s.wait(ctx, "delete"); err != nil {
+		return err
+	}
+	return s.store.DeleteKeyValues(ctx, sec, do...)
+}
+
+func (s *RateLimitedStore) wait(ctx context.Context, op string) error {
+	defer s.observeUtilization()
+	if s.limiter.Allow() {
+		return nil
+	}
+	s.throttled.WithLabelValues(op).Inc()
+	return errors.Wrap(s.limiter.Wait(ctx), errRateLimit)
+}
+
+func (s *RateLimitedStore) observeUtilization() {
+	b := float64(s.limiter.Burst())
+	if b == 0 {
+		s.utilization.Set(1)
+		return
+	}
+	// Tokens may be negative while operations are waiting.
+	s.utilization.Set(min(1, 1-s.limiter.Tokens()/b))
+}
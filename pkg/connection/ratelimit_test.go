@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+)
+
+// gathered returns the value of each counter or gauge with the supplied name
+// gathered from the supplied registry, by the value of the supplied label.
+func gathered(t *testing.T, g prometheus.Gatherer, name, label string) map[string]float64 {
+	t.Helper()
+	mfs, err := g.Gather()
+	if err != nil {
+		t.Fatalf("g.Gather(): %v", err)
+	}
+	out := map[string]float64{}
+	for _, mf := range mfs {
+		if mf.GetName() != name {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			v := m.GetCounter().GetValue() + m.GetGauge().GetValue()
+			out[labelValue(m, label)] = v
+		}
+	}
+	return out
+}
+
+func TestRateLimitedStore(t *testing.T) {
+	type args struct {
+		writes  int
+		deletes int
+	}
+	type want struct {
+		written     int
+		throttled   map[string]float64
+		utilization map[string]float64
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"WithinBurst": {
+			reason: "We should not throttle operations within the burst.",
+			args: args{
+				writes:  2,
+				deletes: 1,
+			},
+			want: want{
+				written:     3,
+				throttled:   map[string]float64{},
+				utilization: map[string]float64{"": 1},
+			},
+		},
+		"BeyondBurst": {
+			reason: "We should throttle operations beyond the burst, and count them.",
+			args: args{
+				writes:  4,
+				deletes: 2,
+			},
+			want: want{
+				written:     3,
+				throttled:   map[string]float64{"write": 1, "delete": 2},
+				utilization: map[string]float64{"": 1},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			written := 0
+			reg := prometheus.NewRegistry()
+			rs, err := NewRateLimitedStore(&fake.SecretStore{
+				WriteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.WriteOption) (bool, error) {
+					written++
+					return true, nil
+				},
+				DeleteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.DeleteOption) error {
+					written++
+					return nil
+				},
+			}, rate.Every(time.Hour), 3, reg)
+			if err != nil {
+				t.Fatalf("NewRateLimitedStore(...): %v", err)
+			}
+
+			// Waiting for a token would exceed the deadline, so throttled
+			// operations fail fast.
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			for range tc.args.writes {
+				_, _ = rs.WriteKeyValues(ctx, &store.Secret{})
+			}
+			for range tc.args.deletes {
+				_ = rs.DeleteKeyValues(ctx, &store.Secret{})
+			}
+
+			if diff := cmp.Diff(tc.want.written, written); diff != "" {
+				t.Errorf("\n%s\nrs.WriteKeyValues(...): -want operations, +got operations:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.throttled, gathered(t, reg, "crossplane_connection_store_throttled_total", "operation")); diff != "" {
+				t.Errorf("\n%s\nrs.WriteKeyValues(...): -want throttled, +got throttled:\n%s", tc.reason, diff)
+			}
+			got := gathered(t, reg, "crossplane_connection_store_rate_limit_utilization_ratio", "")
+			if diff := cmp.Diff(tc.want.utilization, got, cmpopts.EquateApprox(0, 0.01)); diff != "" {
+				t.Errorf("\n%s\nrs.WriteKeyValues(...): -want utilization, +got utilization:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
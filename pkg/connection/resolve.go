@@ -0,0 +1,165 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// ReferenceScheme prefixes values that reference the value of a key of another
+// secret, e.g. the secret of a resource addressed by its external name. A
+// reference has the form secretref://<scope>/<name>#<key>.
+const ReferenceScheme = "secretref://"
+
+// Error strings.
+const (
+	errFmtInvalidReference = "invalid secret reference %q: references must have the form " + ReferenceScheme + "<scope>/<name>#<key>"
+	errFmtResolveReference = "cannot resolve secret reference %q of key %q"
+	errFmtMissingReference = "referenced key %q of secret %q in scope %q does not exist"
+	errFmtCyclicReference  = "secret reference %q is part of a reference cycle"
+	errFmtReadReference    = "cannot read referenced secret %q in scope %q"
+	errFmtForbiddenScope   = "secret reference %q may not reference a secret in scope %q: only scope %q and allowed scopes may be referenced"
+)
+
+// A SecretResolver reads the secrets that connection details reference.
+type SecretResolver interface {
+	ResolveSecret(ctx context.Context, n store.ScopedName) (store.KeyValues, error)
+}
+
+// A SecretResolverFn is a function that satisfies SecretResolver.
+type SecretResolverFn func(ctx context.Context, n store.ScopedName) (store.KeyValues, error)
+
+// ResolveSecret reads the supplied secret.
+func (fn SecretResolverFn) ResolveSecret(ctx context.Context, n store.ScopedName) (store.KeyValues, error) {
+	return fn(ctx, n)
+}
+
+// NewStoreResolver returns a SecretResolver that reads referenced secrets from
+// the supplied Store.
+func NewStoreResolver(s Store) SecretResolverFn {
+	return func(ctx context.Context, n store.ScopedName) (store.KeyValues, error) {
+		sec := &store.Secret{}
+		if err := s.ReadKeyValues(ctx, n, sec); err != nil {
+			return nil, err
+		}
+		return sec.Data, nil
+	}
+}
+
+// A ResolveOption configures how references are resolved.
+type ResolveOption func(*resolveOptions)
+
+type resolveOptions struct {
+	scopes map[string]bool
+}
+
+// AllowReferenceScopes allows references to secrets in the supplied scopes, in
+// addition to the scope of the secret that is read.
+func AllowReferenceScopes(scopes ...string) ResolveOption {
+	return func(o *resolveOptions) {
+		for _, s := range scopes {
+			o.scopes[s] = true
+		}
+	}
+}
+
+// ResolveReferences returns a ReadOption that substitutes each value that is a
+// reference per the ReferenceScheme with the referenced value, read using the
+// supplied SecretResolver. Referenced values that are themselves references
+// are resolved too. Values that are not references are passed through as is.
+// An error is returned if a reference is malformed, if the referenced secret
+// or key does not exist, or if references form a cycle.
+//
+// Connection details may be controlled by someone other than the controller
+// that reads them, so by default references may only reference secrets in the
+// scope of the secret that is read. Other scopes must be explicitly allowed
+// using AllowReferenceScopes.
+func ResolveReferences(r SecretResolver, o ...ResolveOption) store.ReadOption {
+	opts := &resolveOptions{scopes: map[string]bool{}}
+	for _, fn := range o {
+		fn(opts)
+	}
+	return func(ctx context.Context, s *store.Secret) error {
+		res := &resolver{secrets: r, scope: s.Scope, scopes: opts.scopes}
+		// Resolve in key order so that errors are stable.
+		keys := make([]string, 0, len(s.Data))
+		for k := range s.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			v, err := res.resolve(ctx, s.Data[k], map[string]bool{})
+			if err != nil {
+				return errors.Wrapf(err, errFmtResolveReference, string(s.Data[k]), k)
+			}
+			s.Data[k] = v
+		}
+		return nil
+	}
+}
+
+type resolver struct {
+	secrets SecretResolver
+	scope   string
+	scopes  map[string]bool
+}
+
+func (r *resolver) resolve(ctx context.Context, v []byte, seen map[string]bool) ([]byte, error) {
+	ref := string(v)
+	if !strings.HasPrefix(ref, ReferenceScheme) {
+		return v, nil
+	}
+	if seen[ref] {
+		return nil, errors.Errorf(errFmtCyclicReference, ref)
+	}
+	seen[ref] = true
+
+	n, key, err := parseReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	if n.Scope != r.scope && !r.scopes[n.Scope] {
+		return nil, errors.Errorf(errFmtForbiddenScope, ref, n.Scope, r.scope)
+	}
+	kv, err := r.secrets.ResolveSecret(ctx, n)
+	if err != nil && !store.IsNotFound(err) {
+		return nil, errors.Wrapf(err, errFmtReadReference, n.Name, n.Scope)
+	}
+	rv, ok := kv[key]
+	if !ok {
+		return nil, errors.Errorf(errFmtMissingReference, key, n.Name, n.Scope)
+	}
+	return r.resolve(ctx, rv, seen)
+}
+
+func parseReference(ref string) (store.ScopedName, string, error) {
+	path, key, ok := strings.Cut(strings.TrimPrefix(ref, ReferenceScheme), "#")
+	if !ok || key == "" {
+		return store.ScopedName{}, "", errors.Errorf(errFmtInvalidReference, ref)
+	}
+	scope, name, ok := strings.Cut(path, "/")
+	if !ok || scope == "" || name == "" {
+		return store.ScopedName{}, "", errors.Errorf(errFmtInvalidReference, ref)
+	}
+	return store.ScopedName{Scope: scope, Name: name}, key, nil
+}
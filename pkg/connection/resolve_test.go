@@ -0,0 +1,144 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestResolveReferences(t *testing.T) {
+	db := store.ScopedName{Name: "db", Scope: "ns"}
+	alias := store.ScopedName{Name: "alias", Scope: "ns"}
+	loop := store.ScopedName{Name: "loop", Scope: "ns"}
+	other := store.ScopedName{Name: "db", Scope: "other"}
+	escape := store.ScopedName{Name: "escape", Scope: "ns"}
+
+	secrets := map[store.ScopedName]store.KeyValues{
+		db:     {"password": []byte("s3cr3t")},
+		alias:  {"password": []byte("secretref://ns/db#password")},
+		loop:   {"self": []byte("secretref://ns/loop#self")},
+		other:  {"password": []byte("0th3r")},
+		escape: {"password": []byte("secretref://other/db#password")},
+	}
+
+	type want struct {
+		data store.KeyValues
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		data   store.KeyValues
+		o      []ResolveOption
+		want   want
+	}{
+		"LiteralPassthrough": {
+			reason: "We should pass values that are not references through as is.",
+			data:   store.KeyValues{"endpoint": []byte("db.example.org"), "port": []byte("5432")},
+			want: want{
+				data: store.KeyValues{"endpoint": []byte("db.example.org"), "port": []byte("5432")},
+			},
+		},
+		"ResolvedReference": {
+			reason: "We should substitute references with the referenced value.",
+			data:   store.KeyValues{"endpoint": []byte("db.example.org"), "password": []byte("secretref://ns/db#password")},
+			want: want{
+				data: store.KeyValues{"endpoint": []byte("db.example.org"), "password": []byte("s3cr3t")},
+			},
+		},
+		"ResolvedChainedReference": {
+			reason: "We should resolve referenced values that are themselves references.",
+			data:   store.KeyValues{"password": []byte("secretref://ns/alias#password")},
+			want: want{
+				data: store.KeyValues{"password": []byte("s3cr3t")},
+			},
+		},
+		"MissingReferencedKey": {
+			reason: "We should return an error if the referenced key does not exist.",
+			data:   store.KeyValues{"password": []byte("secretref://ns/db#nope")},
+			want: want{
+				err: errors.Wrapf(errors.Errorf(errFmtMissingReference, "nope", "db", "ns"), errFmtResolveReference, "secretref://ns/db#nope", "password"),
+			},
+		},
+		"MissingReferencedSecret": {
+			reason: "We should return an error if the referenced secret does not exist.",
+			data:   store.KeyValues{"password": []byte("secretref://ns/gone#password")},
+			want: want{
+				err: errors.Wrapf(errors.Errorf(errFmtMissingReference, "password", "gone", "ns"), errFmtResolveReference, "secretref://ns/gone#password", "password"),
+			},
+		},
+		"CyclicReference": {
+			reason: "We should return an error if references form a cycle.",
+			data:   store.KeyValues{"self": []byte("secretref://ns/loop#self")},
+			want: want{
+				err: errors.Wrapf(errors.Errorf(errFmtCyclicReference, "secretref://ns/loop#self"), errFmtResolveReference, "secretref://ns/loop#self", "self"),
+			},
+		},
+		"CrossScopeReference": {
+			reason: "We should return an error if a reference references a secret in another scope.",
+			data:   store.KeyValues{"password": []byte("secretref://other/db#password")},
+			want: want{
+				err: errors.Wrapf(errors.Errorf(errFmtForbiddenScope, "secretref://other/db#password", "other", "ns"), errFmtResolveReference, "secretref://other/db#password", "password"),
+			},
+		},
+		"ChainedCrossScopeReference": {
+			reason: "We should return an error if a referenced value references a secret in another scope.",
+			data:   store.KeyValues{"password": []byte("secretref://ns/escape#password")},
+			want: want{
+				err: errors.Wrapf(errors.Errorf(errFmtForbiddenScope, "secretref://other/db#password", "other", "ns"), errFmtResolveReference, "secretref://ns/escape#password", "password"),
+			},
+		},
+		"AllowedCrossScopeReference": {
+			reason: "We should resolve references to secrets in explicitly allowed scopes.",
+			data:   store.KeyValues{"password": []byte("secretref://other/db#password")},
+			o:      []ResolveOption{AllowReferenceScopes("other")},
+			want: want{
+				data: store.KeyValues{"password": []byte("0th3r")},
+			},
+		},
+		"InvalidReference": {
+			reason: "We should return an error if a reference is malformed.",
+			data:   store.KeyValues{"password": []byte("secretref://db")},
+			want: want{
+				err: errors.Wrapf(errors.Errorf(errFmtInvalidReference, "secretref://db"), errFmtResolveReference, "secretref://db", "password"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := &store.Secret{ScopedName: store.ScopedName{Name: "cool", Scope: "ns"}, Data: tc.data}
+			err := ResolveReferences(NewStoreResolver(memoryStore(secrets, false)), tc.o...)(context.Background(), s)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nResolveReferences(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.data, s.Data); diff != "" {
+				t.Errorf("\n%s\nResolveReferences(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errFmtRotate = "cannot rotate secret %q in scope %q"
+)
+
+// Rotate triggers rotation of the supplied secret by the supplied Store, and
+// returns its new key values. Rotate returns store.ErrNotSupported if the
+// Store neither is nor wraps a Rotator. When the Rotator is wrapped, the new
+// key values are read through the supplied Store, so that they are returned
+// as its wrappers would return them, e.g. decrypted.
+func Rotate(ctx context.Context, s Store, n store.ScopedName) (store.KeyValues, error) {
+	if r, ok := s.(Rotator); ok {
+		kv, err := r.Rotate(ctx, n)
+		return kv, errors.Wrapf(err, errFmtRotate, n.Name, n.Scope)
+	}
+	r, ok := Find[Rotator](s)
+	if !ok {
+		return nil, store.ErrNotSupported
+	}
+	if _, err := r.Rotate(ctx, n); err != nil {
+		return nil, errors.Wrapf(err, errFmtRotate, n.Name, n.Scope)
+	}
+	sec := &store.Secret{}
+	err := s.ReadKeyValues(ctx, n, sec)
+	return sec.Data, errors.Wrapf(err, errFmtRotate, n.Name, n.Scope)
+}
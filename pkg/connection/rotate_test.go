@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store/kubernetes"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+// A rotatingStore is a Store that issues a new password each time it rotates
+// a secret.
+type rotatingStore struct {
+	fake.SecretStore
+
+	rotations int
+	err       error
+}
+
+func (s *rotatingStore) Rotate(_ context.Context, _ store.ScopedName) (store.KeyValues, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.rotations++
+	return store.KeyValues{"password": []byte{byte('0' + s.rotations)}}, nil
+}
+
+func TestRotate(t *testing.T) {
+	n := store.ScopedName{Name: "cool", Scope: "ns"}
+
+	kube, err := kubernetes.NewSecretStore(context.Background(), &test.MockClient{}, nil, v1.SecretStoreConfig{})
+	if err != nil {
+		t.Fatalf("kubernetes.NewSecretStore(...): %v", err)
+	}
+
+	type want struct {
+		kv  store.KeyValues
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		s      Store
+		want   want
+	}{
+		"Rotated": {
+			reason: "We should return the new key values issued by a Store that supports rotation.",
+			s:      &rotatingStore{rotations: 1},
+			want: want{
+				kv: store.KeyValues{"password": []byte("2")},
+			},
+		},
+		"RotateError": {
+			reason: "We should return an error if a Store that supports rotation fails to rotate.",
+			s:      &rotatingStore{err: errBoom},
+			want: want{
+				err: errors.Wrapf(errBoom, errFmtRotate, n.Name, n.Scope),
+			},
+		},
+		"WrappedRotated": {
+			reason: "We should rotate using a wrapped Store that supports rotation, and return the new key values as read through its wrappers.",
+			s: NewKeyPrefixStore(&rotatingStore{SecretStore: fake.SecretStore{
+				ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
+					s.Data = store.KeyValues{"tenant/password": []byte("2")}
+					return nil
+				},
+			}, rotations: 1}, "tenant/"),
+			want: want{
+				kv: store.KeyValues{"password": []byte("2")},
+			},
+		},
+		"KubernetesNotSupported": {
+			reason: "We should return ErrNotSupported for the Kubernetes store, which can't issue new credentials.",
+			s:      kube,
+			want: want{
+				err: store.ErrNotSupported,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kv, err := Rotate(context.Background(), tc.s, n)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRotate(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.kv, kv); diff != "" {
+				t.Errorf("\n%s\nRotate(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
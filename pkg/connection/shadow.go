@@ -0,0 +1,147 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+)
+
+// Reasons a shadow Store may diverge from its primary.
+const (
+	// DivergenceError indicates an operation succeeded against the primary
+	// Store but failed against the shadow Store.
+	DivergenceError = "Error"
+
+	// DivergenceMismatch indicates a read returned different key values from
+	// the primary and shadow Stores.
+	DivergenceMismatch = "Mismatch"
+)
+
+// A ShadowStoreOption configures a ShadowStore.
+type ShadowStoreOption func(*ShadowStore)
+
+// WithShadowLogger configures the logger a ShadowStore uses to log
+// divergences.
+func WithShadowLogger(l logging.Logger) ShadowStoreOption {
+	return func(s *ShadowStore) {
+		s.log = l
+	}
+}
+
+// WithShadowReads configures a ShadowStore to also read from its shadow Store,
+// and record a divergence if the shadow's key values differ from the
+// primary's.
+func WithShadowReads() ShadowStoreOption {
+	return func(s *ShadowStore) {
+		s.reads = true
+	}
+}
+
+// A ShadowStore is a Store that mirrors writes and deletes to a shadow Store,
+// for example to validate a migration to a new Store. The primary Store is
+// authoritative; operations against the shadow are best-effort and never
+// affect the result of an operation. Divergences between the primary and
+// shadow Stores are logged and counted.
+type ShadowStore struct {
+	primary Store
+	shadow  Store
+	reads   bool
+	log     logging.Logger
+
+	divergences *prometheus.CounterVec
+}
+
+// NewShadowStore returns a ShadowStore that mirrors operations against the
+// supplied primary Store to the supplied shadow Store. Its metrics are
+// registered with the supplied Registerer.
+func NewShadowStore(primary, shadow Store, r prometheus.Registerer, o ...ShadowStoreOption) (*ShadowStore, error) {
+	s := &ShadowStore{
+		primary: primary,
+		shadow:  shadow,
+		log:     logging.NewNopLogger(),
+		divergences: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Subsystem: metricsSubsystem,
+			Name:      "connection_store_shadow_divergences_total",
+			Help:      "The number of operations for which a shadow secret store diverged from its primary, by operation and reason",
+		}, []string{"operation", "reason"}),
+	}
+	for _, fn := range o {
+		fn(s)
+	}
+
+	var err error
+	if s.divergences, err = register(r, s.divergences); err != nil {
+		return nil, errors.Wrap(err, errRegisterMetrics)
+	}
+	return s, nil
+}
+
+// ReadKeyValues reads key values from the primary Store.
+func (s *ShadowStore) ReadKeyValues(ctx context.Context, n store.ScopedName, sec *store.Secret, ro ...store.ReadOption) error {
+	if err := s.primary.ReadKeyValues(ctx, n, sec, ro...); err != nil || !s.reads {
+		return err
+	}
+
+	shadow := &store.Secret{}
+	if err := s.shadow.ReadKeyValues(ctx, n, shadow, ro...); err != nil {
+		s.diverged("read", DivergenceError, n, err)
+		return nil
+	}
+	if !store.Equal(sec.Data, shadow.Data) {
+		s.diverged("read", DivergenceMismatch, n, nil)
+	}
+	return nil
+}
+
+// WriteKeyValues writes key values to the primary Store and, if that
+// succeeds, to the shadow Store.
+func (s *ShadowStore) WriteKeyValues(ctx context.Context, sec *store.Secret, wo ...store.WriteOption) (bool, error) {
+	// Write options may mutate the secret they're passed.
+	shadow := sec.DeepCopy()
+	changed, err := s.primary.WriteKeyValues(ctx, sec, wo...)
+	if err != nil {
+		return changed, err
+	}
+	if _, err := s.shadow.WriteKeyValues(ctx, shadow, wo...); err != nil {
+		s.diverged("write", DivergenceError, shadow.ScopedName, err)
+	}
+	return changed, nil
+}
+
+// DeleteKeyValues deletes key values from the primary Store and, if that
+// succeeds, from the shadow Store.
+func (s *ShadowStore) DeleteKeyValues(ctx context.Context, sec *store.Secret, do ...store.DeleteOption) error {
+	shadow := sec.DeepCopy()
+	if err := s.primary.DeleteKeyValues(ctx, sec, do...); err != nil {
+		return err
+	}
+	if err := s.shadow.DeleteKeyValues(ctx, shadow, do...); err != nil {
+		s.diverged("delete", DivergenceError, shadow.ScopedName, err)
+	}
+	return nil
+}
+
+func (s *ShadowStore) diverged(op, reason string, n store.ScopedName, err error) {
+	s.divergences.WithLabelValues(op, reason).Inc()
+	s.log.Info("Shadow secret store diverged from primary", "operation", op, "reason", reason, "secret-name", n.Name, "secret-scope", n.Scope, "error", err)
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func shadowBackend(data store.KeyValues, err error, calls *int) *fake.SecretStore {
+	return &fake.SecretStore{
+		ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
+			*calls++
+			s.Data = data
+			return err
+		},
+		WriteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.WriteOption) (bool, error) {
+			*calls++
+			return err == nil, err
+		},
+		DeleteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.DeleteOption) error {
+			*calls++
+			return err
+		},
+	}
+}
+
+func TestShadowStore(t *testing.T) {
+	kv := store.KeyValues{"key": []byte("v")}
+
+	type args struct {
+		primaryErr  error
+		shadowErr   error
+		shadowData  store.KeyValues
+		shadowReads bool
+		op          func(s *ShadowStore) error
+	}
+	type want struct {
+		err         error
+		shadowCalls int
+		divergences map[string]float64
+	}
+
+	write := func(s *ShadowStore) error {
+		_, err := s.WriteKeyValues(context.Background(), &store.Secret{Data: kv})
+		return err
+	}
+	del := func(s *ShadowStore) error {
+		return s.DeleteKeyValues(context.Background(), &store.Secret{})
+	}
+	read := func(s *ShadowStore) error {
+		return s.ReadKeyValues(context.Background(), store.ScopedName{}, &store.Secret{})
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"WriteBoth": {
+			reason: "We should write to the shadow after writing to the primary.",
+			args: args{
+				op: write,
+			},
+			want: want{
+				shadowCalls: 1,
+				divergences: map[string]float64{},
+			},
+		},
+		"PrimaryWriteFailed": {
+			reason: "We should return the primary's error and not write to the shadow.",
+			args: args{
+				primaryErr: errBoom,
+				op:         write,
+			},
+			want: want{
+				err:         errBoom,
+				divergences: map[string]float64{},
+			},
+		},
+		"ShadowWriteFailed": {
+			reason: "We should record a divergence but succeed if only the shadow write failed.",
+			args: args{
+				shadowErr: errBoom,
+				op:        write,
+			},
+			want: want{
+				shadowCalls: 1,
+				divergences: map[string]float64{"write/Error": 1},
+			},
+		},
+		"ShadowDeleteFailed": {
+			reason: "We should record a divergence but succeed if only the shadow delete failed.",
+			args: args{
+				shadowErr: errBoom,
+				op:        del,
+			},
+			want: want{
+				shadowCalls: 1,
+				divergences: map[string]float64{"delete/Error": 1},
+			},
+		},
+		"ReadPrimaryOnly": {
+			reason: "We should not read from the shadow unless shadow reads are enabled.",
+			args: args{
+				op: read,
+			},
+			want: want{
+				divergences: map[string]float64{},
+			},
+		},
+		"ShadowReadMismatch": {
+			reason: "We should record a divergence if the shadow's key values differ from the primary's.",
+			args: args{
+				shadowData:  store.KeyValues{"key": []byte("other")},
+				shadowReads: true,
+				op:          read,
+			},
+			want: want{
+				shadowCalls: 1,
+				divergences: map[string]float64{"read/Mismatch": 1},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			primaryCalls, shadowCalls := 0, 0
+			reg := prometheus.NewRegistry()
+
+			var o []ShadowStoreOption
+			if tc.args.shadowReads {
+				o = append(o, WithShadowReads())
+			}
+			s, err := NewShadowStore(shadowBackend(kv, tc.args.primaryErr, &primaryCalls), shadowBackend(tc.args.shadowData, tc.args.shadowErr, &shadowCalls), reg, o...)
+			if err != nil {
+				t.Fatalf("NewShadowStore(...): %v", err)
+			}
+
+			err = tc.args.op(s)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nShadowStore: -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if primaryCalls != 1 {
+				t.Errorf("\n%s\nShadowStore: want 1 call to the primary, got %d", tc.reason, primaryCalls)
+			}
+			if diff := cmp.Diff(tc.want.shadowCalls, shadowCalls); diff != "" {
+				t.Errorf("\n%s\nShadowStore: -want shadow calls, +got shadow calls:\n%s", tc.reason, diff)
+			}
+
+			got := map[string]float64{}
+			mfs, _ := reg.Gather()
+			for _, mf := range mfs {
+				for _, m := range mf.GetMetric() {
+					got[labelValue(m, "operation")+"/"+labelValue(m, "reason")] = m.GetCounter().GetValue()
+				}
+			}
+			if diff := cmp.Diff(tc.want.divergences, got); diff != "" {
+				t.Errorf("\n%s\nShadowStore: -want divergences, +got divergences:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
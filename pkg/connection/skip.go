@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+)
+
+// A SkipIdenticalStore is a Store that skips writes that would not change the
+// secret. Some Stores update a secret even when it is written with identical
+// data, for example bumping its resource version or managed fields, which
+// needlessly triggers watchers of the secret. A SkipIdenticalStore reads the
+// current secret before each write, and doesn't write it if its data is
+// identical per the canonical serialization and it already has all of the
+// desired labels and annotations. Write options are not called for skipped
+// writes.
+type SkipIdenticalStore struct {
+	store Store
+}
+
+// NewSkipIdenticalStore returns a SkipIdenticalStore that wraps the supplied
+// Store.
+func NewSkipIdenticalStore(s Store) *SkipIdenticalStore {
+	return &SkipIdenticalStore{store: s}
+}
+
+// Unwrap returns the Store the SkipIdenticalStore wraps.
+func (s *SkipIdenticalStore) Unwrap() Store {
+	return s.store
+}
+
+// ReadKeyValues reads key values from the wrapped Store.
+func (s *SkipIdenticalStore) ReadKeyValues(ctx context.Context, n store.ScopedName, sec *store.Secret, ro ...store.ReadOption) error {
+	return s.store.ReadKeyValues(ctx, n, sec, ro...)
+}
+
+// WriteKeyValues writes key values to the wrapped Store, unless the secret
+// would not change. It returns false without writing if the write was skipped.
+// The secret is written if it can't be read.
+func (s *SkipIdenticalStore) WriteKeyValues(ctx context.Context, sec *store.Secret, wo ...store.WriteOption) (bool, error) {
+	current := &store.Secret{}
+	if err := s.store.ReadKeyValues(ctx, sec.ScopedName, current); err == nil && identical(current, sec) {
+		return false, nil
+	}
+	return s.store.WriteKeyValues(ctx, sec, wo...)
+}
+
+// DeleteKeyValues deletes key values from the wrapped Store.
+func (s *SkipIdenticalStore) DeleteKeyValues(ctx context.Context, sec *store.Secret, do ...store.DeleteOption) error {
+	return s.store.DeleteKeyValues(ctx, sec, do...)
+}
+
+// identical returns true if writing the desired secret would not change the
+// current secret. Stores may add labels and annotations of their own, so the
+// current secret may have labels and annotations the desired secret doesn't.
+func identical(current, desired *store.Secret) bool {
+	if current.Data == nil || !store.Equal(current.Data, desired.Data) {
+		return false
+	}
+	if desired.Metadata == nil {
+		return true
+	}
+	if current.Metadata == nil {
+		return len(desired.Metadata.Labels) == 0 && len(desired.Metadata.Annotations) == 0
+	}
+	return containsAll(current.Metadata.Labels, desired.Metadata.Labels) && containsAll(current.Metadata.Annotations, desired.Metadata.Annotations)
+}
+
+func containsAll(m, want map[string]string) bool {
+	for k, v := range want {
+		if got, ok := m[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+)
+
+func TestSkipIdenticalStoreWriteKeyValues(t *testing.T) {
+	n := store.ScopedName{Name: "cool", Scope: "ns"}
+	current := &store.Secret{
+		Data:     store.KeyValues{"a": []byte("1"), "b": []byte("2")},
+		Metadata: &v1.ConnectionSecretMetadata{Labels: map[string]string{"team": "cool", "added-by-store": "true"}},
+	}
+
+	type args struct {
+		current *store.Secret
+		readErr error
+		desired *store.Secret
+	}
+	type want struct {
+		changed bool
+		written bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Identical": {
+			reason: "We should skip writing a secret that would not change.",
+			args: args{
+				current: current,
+				desired: &store.Secret{
+					ScopedName: n,
+					Data:       store.KeyValues{"b": []byte("2"), "a": []byte("1")},
+					Metadata:   &v1.ConnectionSecretMetadata{Labels: map[string]string{"team": "cool"}},
+				},
+			},
+		},
+		"ChangedData": {
+			reason: "We should write a secret whose data would change.",
+			args: args{
+				current: current,
+				desired: &store.Secret{ScopedName: n, Data: store.KeyValues{"a": []byte("1"), "b": []byte("3")}},
+			},
+			want: want{
+				changed: true,
+				written: true,
+			},
+		},
+		"ChangedLabels": {
+			reason: "We should write a secret whose labels would change.",
+			args: args{
+				current: current,
+				desired: &store.Secret{
+					ScopedName: n,
+					Data:       store.KeyValues{"a": []byte("1"), "b": []byte("2")},
+					Metadata:   &v1.ConnectionSecretMetadata{Labels: map[string]string{"team": "uncool"}},
+				},
+			},
+			want: want{
+				changed: true,
+				written: true,
+			},
+		},
+		"NotFound": {
+			reason: "We should write a secret that does not exist.",
+			args: args{
+				readErr: store.NewNotFound("secret not found"),
+				desired: &store.Secret{ScopedName: n, Data: store.KeyValues{"a": []byte("1")}},
+			},
+			want: want{
+				changed: true,
+				written: true,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			written := false
+			s := NewSkipIdenticalStore(&fake.SecretStore{
+				ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
+					if tc.args.current != nil {
+						*s = *tc.args.current.DeepCopy()
+					}
+					return tc.args.readErr
+				},
+				WriteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.WriteOption) (bool, error) {
+					written = true
+					return true, nil
+				},
+			})
+			changed, err := s.WriteKeyValues(context.Background(), tc.args.desired)
+			if err != nil {
+				t.Fatalf("\n%s\ns.WriteKeyValues(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.changed, changed); diff != "" {
+				t.Errorf("\n%s\ns.WriteKeyValues(...): -want changed, +got changed:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.written, written); diff != "" {
+				t.Errorf("\n%s\ns.WriteKeyValues(...): -want written, +got written:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
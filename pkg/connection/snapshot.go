@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"bytes"
+	"context"
+	"sort"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// A Snapshot is an immutable view of a secret, as it was read at a single
+// point in time. Consumers that read several related keys, e.g. a username
+// and password, may read them from a Snapshot to avoid observing a secret
+// that is being rotated halfway between reads.
+type Snapshot struct {
+	name store.ScopedName
+	data store.KeyValues
+}
+
+// ReadSnapshot reads the supplied secret from the supplied Store once, and
+// returns a Snapshot of it. A secret that does not exist has an empty
+// Snapshot.
+func ReadSnapshot(ctx context.Context, s Store, n store.ScopedName, ro ...store.ReadOption) (*Snapshot, error) {
+	sec := &store.Secret{}
+	if err := s.ReadKeyValues(ctx, n, sec, ro...); err != nil && !store.IsNotFound(err) {
+		return nil, errors.Wrap(err, errReadStore)
+	}
+	// Copy the data, so that neither the Store nor the caller may mutate it.
+	data := make(store.KeyValues, len(sec.Data))
+	for k, v := range sec.Data {
+		data[k] = bytes.Clone(v)
+	}
+	return &Snapshot{name: n, data: data}, nil
+}
+
+// Name returns the name of the secret the Snapshot was read from.
+func (s *Snapshot) Name() store.ScopedName {
+	return s.name
+}
+
+// Get returns a copy of the value of the supplied key, and whether the key
+// exists.
+func (s *Snapshot) Get(key string) ([]byte, bool) {
+	v, ok := s.data[key]
+	return bytes.Clone(v), ok
+}
+
+// Keys returns the keys of the Snapshot, in sorted order.
+func (s *Snapshot) Keys() []string {
+	keys := make([]string, 0, len(s.data))
+	for k := range s.data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// KeyValues returns a copy of the key values of the Snapshot.
+func (s *Snapshot) KeyValues() store.KeyValues {
+	kv := make(store.KeyValues, len(s.data))
+	for k, v := range s.data {
+		kv[k] = bytes.Clone(v)
+	}
+	return kv
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestReadSnapshot(t *testing.T) {
+	n := store.ScopedName{Name: "cool", Scope: "ns"}
+
+	// The secret is rotated after it is read the first time.
+	reads := 0
+	s := &fake.SecretStore{
+		ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
+			reads++
+			if reads == 1 {
+				s.Data = store.KeyValues{"username": []byte("admin"), "password": []byte("old")}
+				return nil
+			}
+			s.Data = store.KeyValues{"username": []byte("root"), "password": []byte("new")}
+			return nil
+		},
+	}
+
+	snap, err := ReadSnapshot(context.Background(), s, n)
+	if err != nil {
+		t.Fatalf("ReadSnapshot(...): %v", err)
+	}
+
+	// The secret changes between accesses of the snapshot.
+	if err := s.ReadKeyValues(context.Background(), n, &store.Secret{}); err != nil {
+		t.Fatalf("s.ReadKeyValues(...): %v", err)
+	}
+	user, _ := snap.Get("username")
+
+	if err := s.ReadKeyValues(context.Background(), n, &store.Secret{}); err != nil {
+		t.Fatalf("s.ReadKeyValues(...): %v", err)
+	}
+	pass, _ := snap.Get("password")
+
+	if diff := cmp.Diff([]string{"admin", "old"}, []string{string(user), string(pass)}); diff != "" {
+		t.Errorf("snap.Get(...): -want, +got:\n%s", diff)
+	}
+
+	// Mutating a returned value must not mutate the snapshot.
+	pass[0] = 'x'
+	if got, _ := snap.Get("password"); string(got) != "old" {
+		t.Errorf("snap.Get(...): want an immutable view, got mutated value %q", got)
+	}
+	if diff := cmp.Diff([]string{"password", "username"}, snap.Keys()); diff != "" {
+		t.Errorf("snap.Keys(): -want, +got:\n%s", diff)
+	}
+}
+
+func TestReadSnapshotErrors(t *testing.T) {
+	n := store.ScopedName{Name: "cool", Scope: "ns"}
+
+	type want struct {
+		keys []string
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		err    error
+		want   want
+	}{
+		"NotFound": {
+			reason: "We should return an empty snapshot if the secret does not exist.",
+			err:    store.NewNotFound("secret not found"),
+			want: want{
+				keys: []string{},
+			},
+		},
+		"ReadError": {
+			reason: "We should return an error if the secret can't be read.",
+			err:    errBoom,
+			want: want{
+				err: errors.Wrap(errBoom, errReadStore),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := &fake.SecretStore{
+				ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, _ *store.Secret, _ ...store.ReadOption) error {
+					return tc.err
+				},
+			}
+			snap, err := ReadSnapshot(context.Background(), s, n)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nReadSnapshot(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.keys, snap.Keys()); diff != "" {
+				t.Errorf("\n%s\nsnap.Keys(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"sort"
+)
+
+// Canonicalize returns the canonical serialization of the supplied key values.
+// Keys are sorted, and each key and value is length prefixed. Key values with
+// identical content thus always have the same canonical serialization, no
+// matter the order in which they were inserted. Nil and empty key values have
+// the same canonical serialization.
+//
+// All features that hash, checksum or otherwise detect changes to key values
+// must use the canonical serialization.
+func Canonicalize(kv KeyValues) []byte {
+	b := &bytes.Buffer{}
+	writeCanonical(b, kv)
+	return b.Bytes()
+}
+
+// Hash returns the hex encoded SHA-256 hash of the canonical serialization of
+// the supplied key values.
+func Hash(kv KeyValues) string {
+	h := sha256.New()
+	writeCanonical(h, kv)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Equal returns true if the supplied key values have identical content.
+func Equal(a, b KeyValues) bool {
+	return bytes.Equal(Canonicalize(a), Canonicalize(b))
+}
+
+// DiffKeyValues returns the keys the supplied desired key values would add to,
+// change in, and remove from the supplied current key values, each in sorted
+// order.
+func DiffKeyValues(current, desired KeyValues) (added, changed, removed []string) {
+	for k, v := range desired {
+		cv, ok := current[k]
+		switch {
+		case !ok:
+			added = append(added, k)
+		case !bytes.Equal(cv, v):
+			changed = append(changed, k)
+		}
+	}
+	for k := range current {
+		if _, ok := desired[k]; !ok {
+			removed = append(removed, k)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(changed)
+	sort.Strings(removed)
+	return added, changed, removed
+}
+
+func writeCanonical(w io.Writer, kv KeyValues) {
+	keys := make([]string, 0, len(kv))
+	for k := range kv {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		writeField(w, []byte(k))
+		writeField(w, kv[k])
+	}
+}
+
+// writeField writes a length prefixed field, so that no two distinct sequences
+// of fields produce the same stream of bytes.
+func writeField(w io.Writer, b []byte) {
+	_ = binary.Write(w, binary.BigEndian, uint64(len(b)))
+	_, _ = w.Write(b)
+}
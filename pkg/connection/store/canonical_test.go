@@ -0,0 +1,80 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"testing"
+)
+
+func TestHash(t *testing.T) {
+	keys := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+
+	forward := KeyValues{}
+	for _, k := range keys {
+		forward[k] = []byte("value-" + k)
+	}
+	reverse := KeyValues{}
+	for i := len(keys) - 1; i >= 0; i-- {
+		reverse[keys[i]] = []byte("value-" + keys[i])
+	}
+
+	cases := map[string]struct {
+		reason string
+		a      KeyValues
+		b      KeyValues
+		equal  bool
+	}{
+		"DifferentInsertionOrder": {
+			reason: "Key values with identical content inserted in a different order should hash identically.",
+			a:      forward,
+			b:      reverse,
+			equal:  true,
+		},
+		"NilAndEmpty": {
+			reason: "Nil and empty key values should hash identically.",
+			a:      nil,
+			b:      KeyValues{},
+			equal:  true,
+		},
+		"DifferentValue": {
+			reason: "Key values with a different value should hash differently.",
+			a:      KeyValues{"a": []byte("1")},
+			b:      KeyValues{"a": []byte("2")},
+		},
+		"DifferentKey": {
+			reason: "Key values with a different key should hash differently.",
+			a:      KeyValues{"a": []byte("1")},
+			b:      KeyValues{"b": []byte("1")},
+		},
+		"AmbiguousBoundaries": {
+			reason: "Moving bytes between a key and its value should hash differently.",
+			a:      KeyValues{"ab": []byte("c")},
+			b:      KeyValues{"a": []byte("bc")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := Hash(tc.a) == Hash(tc.b); got != tc.equal {
+				t.Errorf("\n%s\nHash(a) == Hash(b): want %t, got %t", tc.reason, tc.equal, got)
+			}
+			if got := Equal(tc.a, tc.b); got != tc.equal {
+				t.Errorf("\n%s\nEqual(a, b): want %t, got %t", tc.reason, tc.equal, got)
+			}
+		})
+	}
+}
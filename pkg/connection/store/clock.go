@@ -0,0 +1,39 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"time"
+)
+
+// A Clock tells the time. Stores and their decorators with time based
+// features, like TTLs and timestamps, tell the time using a Clock so that
+// tests may control it.
+type Clock interface {
+	Now() time.Time
+}
+
+// A ClockFn is a function that satisfies the Clock interface.
+type ClockFn func() time.Time
+
+// Now returns the current time.
+func (fn ClockFn) Now() time.Time {
+	return fn()
+}
+
+// RealClock is a Clock that tells the wall-clock time.
+var RealClock Clock = ClockFn(time.Now)
@@ -0,0 +1,53 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+)
+
+// A Consistency level of a read.
+type Consistency string
+
+// Consistency levels.
+const (
+	// ConsistencyEventual reads may return a value that does not reflect the
+	// most recent writes. This is the default.
+	ConsistencyEventual Consistency = "Eventual"
+
+	// ConsistencyStrong reads return a value that reflects all writes that
+	// completed before the read started.
+	ConsistencyStrong Consistency = "Strong"
+)
+
+type consistencyKey struct{}
+
+// WithConsistency returns a copy of the supplied context that requests the
+// supplied consistency level for reads. Stores with tunable consistency honor
+// it. Stores that only support one consistency level ignore it.
+func WithConsistency(ctx context.Context, c Consistency) context.Context {
+	return context.WithValue(ctx, consistencyKey{}, c)
+}
+
+// GetConsistency returns the consistency level requested by the supplied
+// context. It returns ConsistencyEventual if none was requested.
+func GetConsistency(ctx context.Context) Consistency {
+	if c, ok := ctx.Value(consistencyKey{}).(Consistency); ok {
+		return c
+	}
+	return ConsistencyEventual
+}
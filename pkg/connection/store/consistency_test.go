@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// consistentStore is a fake store with tunable consistency. Strong reads
+// return the latest value it was written, eventual reads may return a
+// replicated value that lags behind.
+type consistentStore struct {
+	latest     KeyValues
+	replicated KeyValues
+}
+
+func (s *consistentStore) ReadKeyValues(ctx context.Context, _ ScopedName, sec *Secret, _ ...ReadOption) error {
+	sec.Data = s.replicated
+	if GetConsistency(ctx) == ConsistencyStrong {
+		sec.Data = s.latest
+	}
+	return nil
+}
+
+func TestConsistency(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		ctx    context.Context
+		want   KeyValues
+	}{
+		"Default": {
+			reason: "Reads should be eventually consistent by default.",
+			ctx:    context.Background(),
+			want:   KeyValues{"key": []byte("old")},
+		},
+		"Eventual": {
+			reason: "Eventually consistent reads may return a value that lags behind.",
+			ctx:    WithConsistency(context.Background(), ConsistencyEventual),
+			want:   KeyValues{"key": []byte("old")},
+		},
+		"Strong": {
+			reason: "Strongly consistent reads should return the latest value.",
+			ctx:    WithConsistency(context.Background(), ConsistencyStrong),
+			want:   KeyValues{"key": []byte("new")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := &consistentStore{latest: KeyValues{"key": []byte("new")}, replicated: KeyValues{"key": []byte("old")}}
+			sec := &Secret{}
+			if err := s.ReadKeyValues(tc.ctx, ScopedName{}, sec); err != nil {
+				t.Fatalf("\n%s\ns.ReadKeyValues(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, sec.Data); diff != "" {
+				t.Errorf("\n%s\ns.ReadKeyValues(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+)
+
+type (
+	requestIDKey      struct{}
+	controllerNameKey struct{}
+)
+
+// WithRequestID returns a copy of the supplied context that carries the
+// supplied request ID. Stores that log their operations include it, so that
+// store operations may be correlated with the controller logs of the request
+// that caused them.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// GetRequestID returns the request ID carried by the supplied context, if any.
+func GetRequestID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok
+}
+
+// WithControllerName returns a copy of the supplied context that carries the
+// name of the controller performing a store operation.
+func WithControllerName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, controllerNameKey{}, name)
+}
+
+// GetControllerName returns the controller name carried by the supplied
+// context, if any.
+func GetControllerName(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(controllerNameKey{}).(string)
+	return name, ok
+}
+
+// ContextFields returns the structured values carried by the supplied context
+// as logging key value pairs.
+func ContextFields(ctx context.Context) []any {
+	var fields []any
+	if id, ok := GetRequestID(ctx); ok {
+		fields = append(fields, "request-id", id)
+	}
+	if name, ok := GetControllerName(ctx); ok {
+		fields = append(fields, "controller", name)
+	}
+	return fields
+}
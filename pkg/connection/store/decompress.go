@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errFmtDecompressKey      = "cannot decompress value of key %q using %s"
+	errFmtUnknownCompression = "unknown compression algorithm %q"
+	errFmtDecompressedSize   = "decompressed value exceeds the maximum size of %d bytes"
+)
+
+// DefaultMaxDecompressedSize is the default maximum size, in bytes, of a value
+// decompressed by DecompressKeys.
+const DefaultMaxDecompressedSize = 16 << 20
+
+// A DecompressOption configures DecompressKeys.
+type DecompressOption func(*decompressOptions)
+
+type decompressOptions struct {
+	maxSize int64
+}
+
+// WithMaxDecompressedSize configures the maximum size, in bytes, a value may
+// have once decompressed. Decompressing a larger value returns an error, so a
+// small, maliciously crafted value can't exhaust memory. The default is
+// DefaultMaxDecompressedSize.
+func WithMaxDecompressedSize(n int64) DecompressOption {
+	return func(o *decompressOptions) {
+		o.maxSize = n
+	}
+}
+
+// A Compression algorithm that may have been used to compress a connection
+// detail value.
+type Compression string
+
+// Supported compression algorithms.
+const (
+	CompressionGzip Compression = "gzip"
+	CompressionZstd Compression = "zstd"
+)
+
+// DecompressKeys returns a ReadOption that decompresses the values of the
+// supplied keys using the supplied compression algorithm. Keys that are not
+// present in the secret are ignored. Keys that are not supplied are returned
+// as is. An error is returned if a value exceeds the maximum size once
+// decompressed.
+func DecompressKeys(keys map[string]Compression, o ...DecompressOption) ReadOption {
+	opts := &decompressOptions{maxSize: DefaultMaxDecompressedSize}
+	for _, fn := range o {
+		fn(opts)
+	}
+	return func(_ context.Context, s *Secret) error {
+		for k, c := range keys {
+			v, ok := s.Data[k]
+			if !ok {
+				continue
+			}
+			d, err := decompress(c, v, opts.maxSize)
+			if err != nil {
+				return errors.Wrapf(err, errFmtDecompressKey, k, c)
+			}
+			s.Data[k] = d
+		}
+		return nil
+	}
+}
+
+func decompress(c Compression, v []byte, maxSize int64) ([]byte, error) {
+	switch c {
+	case CompressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(v))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close() //nolint:errcheck // Closing a gzip reader only returns previously encountered read errors.
+		return readAtMost(r, maxSize)
+	case CompressionZstd:
+		r, err := zstd.NewReader(bytes.NewReader(v))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return readAtMost(r, maxSize)
+	}
+	return nil, errors.Errorf(errFmtUnknownCompression, c)
+}
+
+// readAtMost reads all of the supplied reader, or returns an error if it has
+// more than the supplied number of bytes.
+func readAtMost(r io.Reader, n int64) ([]byte, error) {
+	// Read one more byte than allowed to tell whether there are more.
+	b, err := io.ReadAll(io.LimitReader(r, n+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > n {
+		return nil, errors.Errorf(errFmtDecompressedSize, n)
+	}
+	return b, nil
+}
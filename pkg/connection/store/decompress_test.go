@@ -0,0 +1,241 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func gzipped(t *testing.T, v string) []byte {
+	t.Helper()
+	b := &bytes.Buffer{}
+	w := gzip.NewWriter(b)
+	if _, err := w.Write([]byte(v)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func zstded(t *testing.T, v string) []byte {
+	t.Helper()
+	w, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	return w.EncodeAll([]byte(v), nil)
+}
+
+func TestDecompressKeys(t *testing.T) {
+	type args struct {
+		keys map[string]Compression
+		data KeyValues
+	}
+	type want struct {
+		data KeyValues
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Gzip": {
+			reason: "We should decompress gzipped values of designated keys.",
+			args: args{
+				keys: map[string]Compression{"blob": CompressionGzip},
+				data: KeyValues{
+					"blob":  gzipped(t, "decompressed"),
+					"plain": []byte("plain"),
+				},
+			},
+			want: want{
+				data: KeyValues{
+					"blob":  []byte("decompressed"),
+					"plain": []byte("plain"),
+				},
+			},
+		},
+		"Zstd": {
+			reason: "We should decompress zstd compressed values of designated keys.",
+			args: args{
+				keys: map[string]Compression{"blob": CompressionZstd},
+				data: KeyValues{
+					"blob": zstded(t, "decompressed"),
+				},
+			},
+			want: want{
+				data: KeyValues{
+					"blob": []byte("decompressed"),
+				},
+			},
+		},
+		"KeyNotPresent": {
+			reason: "We should ignore designated keys that are not present.",
+			args: args{
+				keys: map[string]Compression{"blob": CompressionGzip},
+				data: KeyValues{
+					"plain": []byte("plain"),
+				},
+			},
+			want: want{
+				data: KeyValues{
+					"plain": []byte("plain"),
+				},
+			},
+		},
+		"CorruptGzip": {
+			reason: "We should return an error if a gzipped value is corrupt.",
+			args: args{
+				keys: map[string]Compression{"blob": CompressionGzip},
+				data: KeyValues{
+					"blob": []byte("not-gzipped"),
+				},
+			},
+			want: want{
+				data: KeyValues{
+					"blob": []byte("not-gzipped"),
+				},
+				err: cmpopts.AnyError,
+			},
+		},
+		"CorruptZstd": {
+			reason: "We should return an error if a zstd compressed value is corrupt.",
+			args: args{
+				keys: map[string]Compression{"blob": CompressionZstd},
+				data: KeyValues{
+					"blob": []byte("not-zstd"),
+				},
+			},
+			want: want{
+				data: KeyValues{
+					"blob": []byte("not-zstd"),
+				},
+				err: cmpopts.AnyError,
+			},
+		},
+		"UnknownCompression": {
+			reason: "We should return an error if the compression algorithm is unknown.",
+			args: args{
+				keys: map[string]Compression{"blob": Compression("lz4")},
+				data: KeyValues{
+					"blob": []byte("blob"),
+				},
+			},
+			want: want{
+				data: KeyValues{
+					"blob": []byte("blob"),
+				},
+				err: cmpopts.AnyError,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := &Secret{Data: tc.args.data}
+			err := DecompressKeys(tc.args.keys)(context.Background(), s)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nDecompressKeys(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.data, s.Data); diff != "" {
+				t.Errorf("\n%s\nDecompressKeys(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDecompressKeysMaxSize(t *testing.T) {
+	type args struct {
+		keys    map[string]Compression
+		maxSize int64
+		data    KeyValues
+	}
+	type want struct {
+		data KeyValues
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"AtMaxSize": {
+			reason: "We should decompress a value as large as the maximum size.",
+			args: args{
+				keys:    map[string]Compression{"blob": CompressionGzip},
+				maxSize: 12,
+				data:    KeyValues{"blob": gzipped(t, "decompressed")},
+			},
+			want: want{
+				data: KeyValues{"blob": []byte("decompressed")},
+			},
+		},
+		"GzipTooLarge": {
+			reason: "We should return an error if a gzipped value exceeds the maximum size once decompressed.",
+			args: args{
+				keys:    map[string]Compression{"blob": CompressionGzip},
+				maxSize: 11,
+				data:    KeyValues{"blob": gzipped(t, "decompressed")},
+			},
+			want: want{
+				data: KeyValues{"blob": gzipped(t, "decompressed")},
+				err:  errors.Wrapf(errors.Errorf(errFmtDecompressedSize, 11), errFmtDecompressKey, "blob", CompressionGzip),
+			},
+		},
+		"ZstdTooLarge": {
+			reason: "We should return an error if a zstd compressed value exceeds the maximum size once decompressed.",
+			args: args{
+				keys:    map[string]Compression{"blob": CompressionZstd},
+				maxSize: 11,
+				data:    KeyValues{"blob": zstded(t, "decompressed")},
+			},
+			want: want{
+				data: KeyValues{"blob": zstded(t, "decompressed")},
+				err:  errors.Wrapf(errors.Errorf(errFmtDecompressedSize, 11), errFmtDecompressKey, "blob", CompressionZstd),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := &Secret{Data: tc.args.data}
+			err := DecompressKeys(tc.args.keys, WithMaxDecompressedSize(tc.args.maxSize))(context.Background(), s)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nDecompressKeys(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.data, s.Data); diff != "" {
+				t.Errorf("\n%s\nDecompressKeys(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
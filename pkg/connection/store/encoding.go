@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"bytes"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errFmtInvalidUTF8   = "value of key %q is not valid UTF-8"
+	errFmtNullByte      = "value of key %q contains a null byte"
+	errFmtUnknownEncode = "unknown encoding constraint %q"
+)
+
+// An EncodingConstraint constrains the encoding of secret values.
+type EncodingConstraint string
+
+// Encoding constraints.
+const (
+	// EncodingUTF8 requires values to be valid UTF-8.
+	EncodingUTF8 EncodingConstraint = "UTF8"
+
+	// EncodingNoNullBytes requires values not to contain null bytes.
+	EncodingNoNullBytes EncodingConstraint = "NoNullBytes"
+)
+
+// ValidateEncoding returns an error for each key whose value violates any of
+// the supplied encoding constraints. Keys are validated in sorted order.
+func ValidateEncoding(kv KeyValues, ec ...EncodingConstraint) error {
+	if len(ec) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(kv))
+	for k := range kv {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, k := range keys {
+		for _, c := range ec {
+			if err := validate(k, kv[k], c); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func validate(k string, v []byte, c EncodingConstraint) error {
+	switch c {
+	case EncodingUTF8:
+		if !utf8.Valid(v) {
+			return errors.Errorf(errFmtInvalidUTF8, k)
+		}
+	case EncodingNoNullBytes:
+		if bytes.IndexByte(v, 0) >= 0 {
+			return errors.Errorf(errFmtNullByte, k)
+		}
+	default:
+		return errors.Errorf(errFmtUnknownEncode, c)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestValidateEncoding(t *testing.T) {
+	type args struct {
+		kv KeyValues
+		ec []EncodingConstraint
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   error
+	}{
+		"NoConstraints": {
+			reason: "We should not validate values if no constraints are supplied.",
+			args: args{
+				kv: KeyValues{"key": {0xff, 0x00}},
+			},
+		},
+		"ValidUTF8": {
+			reason: "We should accept valid UTF-8 values.",
+			args: args{
+				kv: KeyValues{"key": []byte("héllo, 世界")},
+				ec: []EncodingConstraint{EncodingUTF8, EncodingNoNullBytes},
+			},
+		},
+		"InvalidUTF8": {
+			reason: "We should reject each value that is not valid UTF-8.",
+			args: args{
+				kv: KeyValues{"b": {0xff, 0xfe}, "a": []byte("fine"), "c": {'o', 0xc3}},
+				ec: []EncodingConstraint{EncodingUTF8},
+			},
+			want: errors.Join(errors.Errorf(errFmtInvalidUTF8, "b"), errors.Errorf(errFmtInvalidUTF8, "c")),
+		},
+		"NullByte": {
+			reason: "We should reject values that contain a null byte.",
+			args: args{
+				kv: KeyValues{"key": []byte("nul\x00l")},
+				ec: []EncodingConstraint{EncodingUTF8, EncodingNoNullBytes},
+			},
+			want: errors.Join(errors.Errorf(errFmtNullByte, "key")),
+		},
+		"NullByteAllowed": {
+			reason: "Null bytes are valid UTF-8, so we should accept them if only UTF-8 is required.",
+			args: args{
+				kv: KeyValues{"key": []byte("nul\x00l")},
+				ec: []EncodingConstraint{EncodingUTF8},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateEncoding(tc.args.kv, tc.args.ec...)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nValidateEncoding(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
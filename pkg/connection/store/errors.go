@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+type errNotFound struct{ error }
+
+func (e errNotFound) NotFound() bool { return true }
+
+// NewNotFound returns a new NotFound error. Stores return a NotFound error
+// when the secret they were asked to operate on does not exist.
+func NewNotFound(message string) error {
+	return errNotFound{error: errors.New(message)}
+}
+
+// IsNotFound returns true if the supplied error, or any error it wraps,
+// indicates that a secret was not found.
+func IsNotFound(err error) bool {
+	var e interface{ NotFound() bool }
+	return errors.As(err, &e) && e.NotFound()
+}
+
+type errConflict struct{ error }
+
+func (e errConflict) Conflict() bool { return true }
+
+// NewConflict returns a new Conflict error. Stores return a Conflict error
+// when a write conflicts with the current state of the secret.
+func NewConflict(message string) error {
+	return errConflict{error: errors.New(message)}
+}
+
+// IsConflict returns true if the supplied error, or any error it wraps,
+// indicates that a write conflicted with the current state of a secret.
+func IsConflict(err error) bool {
+	var e interface{ Conflict() bool }
+	return errors.As(err, &e) && e.Conflict()
+}
+
+// ErrNotSupported is returned when a Store is asked to perform an optional
+// operation it does not support.
+var ErrNotSupported = errors.New("operation is not supported by this store")
+
+// ErrUnauthenticated is returned when a Store's client is not authenticated,
+// for example because the credentials it was built with have expired.
+// Controllers may refresh the credentials and rebuild the Store.
+var ErrUnauthenticated = errors.New("secret store client is unauthenticated")
+
+// NewUnauthenticated returns an error that wraps both ErrUnauthenticated and
+// the supplied error.
+func NewUnauthenticated(err error) error {
+	return errors.Errorf("%w: %w", ErrUnauthenticated, err)
+}
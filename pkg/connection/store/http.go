@@ -0,0 +1,56 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"crypto/tls"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultHTTPTimeout is the timeout of each request made by the HTTP clients
+// returned by NewHTTPClient.
+const DefaultHTTPTimeout = 30 * time.Second
+
+var transports = &transportCache{byConfig: make(map[*tls.Config]*http.Transport)} //nolint:gochecknoglobals // Transports must outlive the stores that use them.
+
+type transportCache struct {
+	mu       sync.Mutex
+	byConfig map[*tls.Config]*http.Transport
+}
+
+func (c *transportCache) get(tcfg *tls.Config) *http.Transport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t, ok := c.byConfig[tcfg]
+	if !ok {
+		t = http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert // The default transport is always an *http.Transport.
+		t.TLSClientConfig = tcfg
+		c.byConfig[tcfg] = t
+	}
+	return t
+}
+
+// NewHTTPClient returns an HTTP client for stores backed by an HTTP API. Its
+// requests time out after DefaultHTTPTimeout. Stores are typically built for
+// each operation, so all clients returned for the same TLS config share a
+// transport rather than each leaving a pool of idle connections behind. The
+// TLS config must therefore not be modified once it has been supplied.
+func NewHTTPClient(tcfg *tls.Config) *http.Client {
+	return &http.Client{Transport: transports.get(tcfg), Timeout: DefaultHTTPTimeout}
+}
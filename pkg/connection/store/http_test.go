@@ -0,0 +1,36 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"crypto/tls"
+	"testing"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+	a, b := &tls.Config{MinVersion: tls.VersionTLS12}, &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if NewHTTPClient(a).Transport != NewHTTPClient(a).Transport {
+		t.Errorf("NewHTTPClient(...): clients for the same TLS config should share a transport")
+	}
+	if NewHTTPClient(a).Transport == NewHTTPClient(b).Transport {
+		t.Errorf("NewHTTPClient(...): clients for distinct TLS configs should not share a transport")
+	}
+	if NewHTTPClient(nil).Timeout != DefaultHTTPTimeout {
+		t.Errorf("NewHTTPClient(...): want timeout %s, got %s", DefaultHTTPTimeout, NewHTTPClient(nil).Timeout)
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+type idempotencyKey struct{}
+
+// IdempotencyKey returns a key that identifies a write of the supplied secret.
+// The key is derived from the scoped name of the secret, the UID of its owner,
+// and a hash of its metadata and data, and is thus stable across process
+// restarts. Writing the same secret always results in the same key, while
+// changing its owner, labels, annotations, or type results in a different key.
+func IdempotencyKey(s *Secret) string {
+	h := sha256.New()
+	writeField(h, []byte(s.Scope))
+	writeField(h, []byte(s.Name))
+	var uid string
+	if s.Owner != nil {
+		uid = string(s.Owner.UID)
+	}
+	writeField(h, []byte(uid))
+	writeMetadata(h, s.Metadata)
+	writeCanonical(h, s.Data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeMetadata writes the canonical serialization of the supplied metadata.
+// Labels and annotations are prefixed with their number of entries, so that no
+// entry can move between them without changing the serialization.
+func writeMetadata(w io.Writer, md *v1.ConnectionSecretMetadata) {
+	if md == nil {
+		md = &v1.ConnectionSecretMetadata{}
+	}
+	for _, m := range []map[string]string{md.Labels, md.Annotations} {
+		kv := make(KeyValues, len(m))
+		for k, v := range m {
+			kv[k] = []byte(v)
+		}
+		_ = binary.Write(w, binary.BigEndian, uint64(len(kv)))
+		writeCanonical(w, kv)
+	}
+	var t string
+	if md.Type != nil {
+		t = string(*md.Type)
+	}
+	writeField(w, []byte(t))
+}
+
+// WithIdempotencyKey returns a copy of the supplied context that carries the
+// supplied idempotency key. Stores that support idempotent writes may use it
+// to detect and skip a write they have already performed.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// GetIdempotencyKey returns the idempotency key carried by the supplied
+// context, if any.
+func GetIdempotencyKey(ctx context.Context) (string, bool) {
+	k, ok := ctx.Value(idempotencyKey{}).(string)
+	return k, ok
+}
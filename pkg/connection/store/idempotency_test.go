@@ -0,0 +1,145 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	base := func() *Secret {
+		return &Secret{
+			ScopedName: ScopedName{Name: "cool", Scope: "ns"},
+			Metadata: &v1.ConnectionSecretMetadata{
+				Labels:      map[string]string{"l": "1"},
+				Annotations: map[string]string{"a": "1"},
+			},
+			Data:  KeyValues{"a": []byte("1"), "b": []byte("2")},
+			Owner: &Owner{TypedReference: v1.TypedReference{UID: "owner"}},
+		}
+	}
+
+	cases := map[string]struct {
+		reason string
+		other  func() *Secret
+		same   bool
+	}{
+		"SameSecret": {
+			reason: "Writing the same data to the same secret should produce the same key.",
+			other:  base,
+			same:   true,
+		},
+		"DifferentData": {
+			reason: "Writing different data to the same secret should produce a different key.",
+			other: func() *Secret {
+				s := base()
+				s.Data["b"] = []byte("3")
+				return s
+			},
+		},
+		"DifferentName": {
+			reason: "Writing the same data to a different secret should produce a different key.",
+			other: func() *Secret {
+				s := base()
+				s.Name = "uncool"
+				return s
+			},
+		},
+		"DifferentScope": {
+			reason: "Writing the same data to a secret in a different scope should produce a different key.",
+			other: func() *Secret {
+				s := base()
+				s.Scope = "other"
+				return s
+			},
+		},
+		"DifferentLabels": {
+			reason: "Writing the same data with different labels should produce a different key.",
+			other: func() *Secret {
+				s := base()
+				s.Metadata.Labels["l"] = "2"
+				return s
+			},
+		},
+		"DifferentAnnotations": {
+			reason: "Writing the same data with different annotations should produce a different key.",
+			other: func() *Secret {
+				s := base()
+				s.Metadata.Annotations["a"] = "2"
+				return s
+			},
+		},
+		"DifferentType": {
+			reason: "Writing the same data with a different type should produce a different key.",
+			other: func() *Secret {
+				s := base()
+				t := corev1.SecretTypeOpaque
+				s.Metadata.Type = &t
+				return s
+			},
+		},
+		"DifferentOwner": {
+			reason: "Writing the same data on behalf of a different owner should produce a different key.",
+			other: func() *Secret {
+				s := base()
+				s.Owner.UID = "other"
+				return s
+			},
+		},
+		"AmbiguousMetadata": {
+			reason: "Moving an entry between labels and annotations should produce a different key.",
+			other: func() *Secret {
+				s := base()
+				s.Metadata.Labels = map[string]string{"l": "1", "a": "1"}
+				s.Metadata.Annotations = nil
+				return s
+			},
+		},
+		"AmbiguousFields": {
+			reason: "Moving bytes between adjacent fields should produce a different key.",
+			other: func() *Secret {
+				s := base()
+				s.Data = KeyValues{"a1": []byte(""), "b": []byte("2")}
+				return s
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			a, b := IdempotencyKey(base()), IdempotencyKey(tc.other())
+			if (a == b) != tc.same {
+				t.Errorf("\n%s\nIdempotencyKey(...): want same key %t, got keys %q and %q", tc.reason, tc.same, a, b)
+			}
+		})
+	}
+}
+
+func TestIdempotencyKeyContext(t *testing.T) {
+	if _, ok := GetIdempotencyKey(context.Background()); ok {
+		t.Errorf("GetIdempotencyKey(...): expected no key for an empty context")
+	}
+	k, ok := GetIdempotencyKey(WithIdempotencyKey(context.Background(), "key"))
+	if !ok || k != "key" {
+		t.Errorf("GetIdempotencyKey(...): want %q, got %q", "key", k)
+	}
+}
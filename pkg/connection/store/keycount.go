@@ -0,0 +1,42 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errFmtTooManyKeys = "secret has %d keys, more than the maximum of %d"
+)
+
+// MaxKeys returns a ReadOption that returns an error if the secret has more
+// than the supplied number of keys, protecting consumers from maliciously
+// large secrets. The data of a secret that has too many keys is not returned.
+func MaxKeys(n int) ReadOption {
+	return func(_ context.Context, s *Secret) error {
+		if len(s.Data) <= n {
+			return nil
+		}
+		count := len(s.Data)
+		s.Data = nil
+		return errors.Errorf(errFmtTooManyKeys, count, n)
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestMaxKeys(t *testing.T) {
+	type args struct {
+		n    int
+		data KeyValues
+	}
+	type want struct {
+		data KeyValues
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"UnderLimit": {
+			reason: "We should return a secret that has no more than the maximum number of keys.",
+			args: args{
+				n:    2,
+				data: KeyValues{"a": []byte("1"), "b": []byte("2")},
+			},
+			want: want{
+				data: KeyValues{"a": []byte("1"), "b": []byte("2")},
+			},
+		},
+		"OverLimit": {
+			reason: "We should return an error naming the key count and limit if a secret has too many keys, and not return its data.",
+			args: args{
+				n:    1,
+				data: KeyValues{"a": []byte("1"), "b": []byte("2"), "c": []byte("3")},
+			},
+			want: want{
+				err: errors.Errorf(errFmtTooManyKeys, 3, 1),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := &Secret{Data: tc.args.data}
+			err := MaxKeys(tc.args.n)(context.Background(), s)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nMaxKeys(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.data, s.Data); diff != "" {
+				t.Errorf("\n%s\nMaxKeys(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
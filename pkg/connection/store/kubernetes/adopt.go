@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// WithAPIGroupRenames configures a SecretStore to adopt secrets whose owner
+// reference refers to an owner under an API group that has been renamed. The
+// supplied map is keyed by old API group, and its values are the new API
+// groups. A secret that is owned by an owner of the same kind and name under
+// an old API group is considered to be owned by the owner the secret is being
+// written for, and its owner reference is re-stamped to refer to the new API
+// group. Without this, renaming the API group of a CRD, for example during a
+// provider upgrade, would leave its secrets with dangling owner references.
+func WithAPIGroupRenames(renames map[string]string) SecretStoreOption {
+	return func(ss *SecretStore) {
+		ss.groupRenames = renames
+	}
+}
+
+// adoptRenamedOwners is an ApplyOption that treats owner references of the
+// current secret that refer to an owner under a renamed API group as
+// references to the equivalent owner of the desired secret. Write options
+// that check the current secret's controllability therefore consider it to be
+// controlled by the desired owner. The desired secret's owner references are
+// written as is, which re-stamps them.
+func (ss *SecretStore) adoptRenamedOwners(_ context.Context, current, desired runtime.Object) error {
+	if len(ss.groupRenames) == 0 {
+		return nil
+	}
+	cs := current.(*corev1.Secret) //nolint:forcetypeassert // Will always be a secret.
+	ds := desired.(*corev1.Secret) //nolint:forcetypeassert // Will always be a secret.
+
+	// Index the desired owner references so that secrets with many owner
+	// references are adopted in linear time.
+	type groupKindName struct{ group, kind, name string }
+	desiredRefs := map[groupKindName]metav1.OwnerReference{}
+	for _, d := range ds.GetOwnerReferences() {
+		dgv, err := schema.ParseGroupVersion(d.APIVersion)
+		if err != nil {
+			continue
+		}
+		desiredRefs[groupKindName{group: dgv.Group, kind: d.Kind, name: d.Name}] = d
+	}
+
+	refs := cs.GetOwnerReferences()
+	for i, ref := range refs {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil {
+			continue
+		}
+		group, ok := ss.groupRenames[gv.Group]
+		if !ok {
+			continue
+		}
+		if d, ok := desiredRefs[groupKindName{group: group, kind: ref.Kind, name: ref.Name}]; ok {
+			refs[i] = d
+		}
+	}
+	cs.SetOwnerReferences(refs)
+	return nil
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestSecretStoreWriteKeyValuesAPIGroupRenames(t *testing.T) {
+	errNotOwned := errors.New("secret is not owned by the desired owner")
+
+	// mustBeOwned mimics the write options that check a current secret is
+	// controlled by the owner it is being written for.
+	mustBeOwned := func(_ context.Context, current, _ *store.Secret) error {
+		if current.GetOwner() != fakeOwnerID {
+			return errNotOwned
+		}
+		return nil
+	}
+
+	desired := metav1.OwnerReference{
+		APIVersion:         "new.example.org/v1",
+		Kind:               "Cool",
+		Name:               "cool",
+		UID:                types.UID(fakeOwnerID),
+		Controller:         ptr.To(true),
+		BlockOwnerDeletion: ptr.To(true),
+	}
+
+	type want struct {
+		refs []metav1.OwnerReference
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason     string
+		apiVersion string
+		want       want
+	}{
+		"OldGroupAdopted": {
+			reason:     "We should adopt a secret owned by an owner under a renamed API group, and re-stamp its owner reference.",
+			apiVersion: "old.example.org/v1",
+			want: want{
+				refs: []metav1.OwnerReference{desired},
+			},
+		},
+		"UnrelatedGroupRejected": {
+			reason:     "We should not adopt a secret owned by an owner under an API group that was not renamed.",
+			apiVersion: "other.example.org/v1",
+			want: want{
+				err: errors.Wrap(errNotOwned, errApplySecret),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var refs []metav1.OwnerReference
+			ss := &SecretStore{
+				client: resource.ClientApplicator{
+					Applicator: resource.ApplyFn(func(ctx context.Context, obj client.Object, ao ...resource.ApplyOption) error {
+						current := fakeConnectionSecret(withData(fakeKV()))
+						current.SetOwnerReferences([]metav1.OwnerReference{{
+							APIVersion: tc.apiVersion,
+							Kind:       "Cool",
+							Name:       "cool",
+							UID:        "old-uid",
+							Controller: ptr.To(true),
+						}})
+						for _, fn := range ao {
+							if err := fn(ctx, current, obj); err != nil {
+								return err
+							}
+						}
+						refs = obj.(*corev1.Secret).GetOwnerReferences()
+						return nil
+					}),
+				},
+				ownerRefs: OwnerReferencePolicyRequireSameNamespace,
+			}
+			WithAPIGroupRenames(map[string]string{"old.example.org": "new.example.org"})(ss)
+
+			_, err := ss.WriteKeyValues(context.Background(), &store.Secret{
+				ScopedName: store.ScopedName{Name: fakeSecretName, Scope: fakeSecretNamespace},
+				Data:       store.KeyValues{"key": []byte("new")},
+				Owner: &store.Owner{
+					TypedReference: v1.TypedReference{APIVersion: "new.example.org/v1", Kind: "Cool", Name: "cool", UID: types.UID(fakeOwnerID)},
+					Namespace:      fakeSecretNamespace,
+				},
+			}, mustBeOwned)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.refs, refs); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want owner references, +got owner references:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
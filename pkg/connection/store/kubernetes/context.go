@@ -0,0 +1,52 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type clientKey struct{}
+
+// WithClient returns a copy of the supplied context that carries the supplied
+// client. A SecretStore reads secrets using the client carried by the context
+// of a read, rather than the client it was built with. This allows a single
+// SecretStore to read secrets using different credentials per request, for
+// example on behalf of different tenants.
+func WithClient(ctx context.Context, c client.Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, c)
+}
+
+// GetClient returns the client carried by the supplied context, if any.
+func GetClient(ctx context.Context) (client.Client, bool) {
+	c, ok := ctx.Value(clientKey{}).(client.Client)
+	return c, ok && c != nil
+}
+
+// reader returns the client the SecretStore should read with in the supplied
+// context. A client carried by the context wins over the SecretStore's cache.
+func (ss *SecretStore) reader(ctx context.Context) client.Reader {
+	if c, ok := GetClient(ctx); ok {
+		return c
+	}
+	if c, ok := ss.cachedReader(); ok {
+		return c
+	}
+	return ss.client
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestSecretStoreReadKeyValuesContextClient(t *testing.T) {
+	// reading returns a client that reads a secret with the supplied value.
+	reading := func(val string) client.Client {
+		return &test.MockClient{
+			MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+				*obj.(*corev1.Secret) = corev1.Secret{Data: map[string][]byte{"key": []byte(val)}}
+				return nil
+			}),
+		}
+	}
+
+	cases := map[string]struct {
+		reason string
+		ctx    context.Context
+		want   store.KeyValues
+	}{
+		"ContextClient": {
+			reason: "We should read using the client carried by the context, if any.",
+			ctx:    WithClient(context.Background(), reading("tenant")),
+			want:   store.KeyValues{"key": []byte("tenant")},
+		},
+		"DefaultClient": {
+			reason: "We should read using the SecretStore's client if the context carries no client.",
+			ctx:    context.Background(),
+			want:   store.KeyValues{"key": []byte("default")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ss := &SecretStore{
+				client: resource.ClientApplicator{Client: reading("default")},
+			}
+
+			s := &store.Secret{}
+			if err := ss.ReadKeyValues(tc.ctx, store.ScopedName{Name: fakeSecretName, Scope: fakeSecretNamespace}, s); err != nil {
+				t.Fatalf("\n%s\nss.ReadKeyValues(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, s.Data); diff != "" {
+				t.Errorf("\n%s\nss.ReadKeyValues(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// setDefaultMetadata adds the SecretStore's default labels and annotations to
+// the supplied secret, unless the secret already sets them. Secrets are
+// written using a merge patch, so labels and annotations other controllers
+// added to an existing secret are kept.
+func (ss *SecretStore) setDefaultMetadata(ks *corev1.Secret) {
+	ks.SetLabels(withDefaults(ks.GetLabels(), ss.defaultLabels))
+	ks.SetAnnotations(withDefaults(ks.GetAnnotations(), ss.defaultAnnotations))
+}
+
+// missingMetadata returns true if the supplied current secret lacks any of
+// the labels or annotations of the supplied desired secret, or has a different
+// value for them. The desired secret includes the SecretStore's default labels
+// and annotations, as well as any labels propagated from its owner.
+func missingMetadata(current, desired *corev1.Secret) bool {
+	return !hasAll(current.GetLabels(), desired.GetLabels()) || !hasAll(current.GetAnnotations(), desired.GetAnnotations())
+}
+
+// withDefaults returns a copy of the supplied map with the supplied defaults
+// added. The supplied map is returned as is if there are no defaults, so that
+// a SecretStore without defaults writes secrets exactly as before.
+func withDefaults(m, defaults map[string]string) map[string]string {
+	if len(defaults) == 0 {
+		return m
+	}
+	out := make(map[string]string, len(m)+len(defaults))
+	for k, v := range defaults {
+		out[k] = v
+	}
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+func hasAll(m, want map[string]string) bool {
+	for k, v := range want {
+		if got, ok := m[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
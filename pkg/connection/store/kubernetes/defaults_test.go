@@ -0,0 +1,176 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestSecretStoreWriteKeyValuesDefaultMetadata(t *testing.T) {
+	defaults := v1.SecretStoreConfig{
+		DefaultLabels:      map[string]string{"backup": "true", "team": "default"},
+		DefaultAnnotations: map[string]string{"cost-center": "42"},
+	}
+
+	type args struct {
+		cfg     v1.SecretStoreConfig
+		current *corev1.Secret
+	}
+	type want struct {
+		changed bool
+		applied *corev1.Secret
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoDefaults": {
+			reason: "We should write secrets exactly as before if no defaults are configured.",
+			args: args{
+				current: fakeConnectionSecret(),
+			},
+			want: want{
+				changed: true,
+				applied: fakeConnectionSecret(withLabels(map[string]string{"team": "cool"}), withOwner(), withData(fakeKV())),
+			},
+		},
+		"Defaults": {
+			reason: "We should add default metadata, without overriding the secret's own labels, type, or owner references.",
+			args: args{
+				cfg:     defaults,
+				current: fakeConnectionSecret(),
+			},
+			want: want{
+				changed: true,
+				applied: fakeConnectionSecret(
+					withLabels(map[string]string{"backup": "true", "team": "cool"}),
+					withAnnotations(map[string]string{"cost-center": "42"}),
+					withOwner(),
+					withData(fakeKV()),
+				),
+			},
+		},
+		"UnchangedDataMissingDefaults": {
+			reason: "We should update an existing secret whose data is unchanged if it lacks the default metadata.",
+			args: args{
+				cfg:     defaults,
+				current: fakeConnectionSecret(withLabels(map[string]string{"out-of-band": "true"}), withData(fakeKV())),
+			},
+			want: want{
+				changed: true,
+				applied: fakeConnectionSecret(
+					withLabels(map[string]string{"backup": "true", "team": "cool"}),
+					withAnnotations(map[string]string{"cost-center": "42"}),
+					withOwner(),
+					withData(fakeKV()),
+				),
+			},
+		},
+		"UnchangedDataWithDefaults": {
+			reason: "We should not update an existing secret whose data is unchanged if it has the default metadata.",
+			args: args{
+				cfg: defaults,
+				current: fakeConnectionSecret(
+					withLabels(map[string]string{"backup": "true", "team": "cool", "out-of-band": "true"}),
+					withAnnotations(map[string]string{"cost-center": "42"}),
+					withOwner(),
+					withData(fakeKV()),
+				),
+			},
+			want: want{
+				changed: false,
+			},
+		},
+		"UnchangedDataChangedLabels": {
+			reason: "We should update an existing secret whose data is unchanged if any of its labels changed.",
+			args: args{
+				current: fakeConnectionSecret(withLabels(map[string]string{"team": "uncool"}), withData(fakeKV())),
+			},
+			want: want{
+				changed: true,
+				applied: fakeConnectionSecret(withLabels(map[string]string{"team": "cool"}), withOwner(), withData(fakeKV())),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var applied *corev1.Secret
+			ss, err := NewSecretStore(context.Background(), &test.MockClient{}, nil, tc.args.cfg)
+			if err != nil {
+				t.Fatalf("NewSecretStore(...): %v", err)
+			}
+			ss.client = resource.ClientApplicator{
+				Applicator: resource.ApplyFn(func(ctx context.Context, obj client.Object, ao ...resource.ApplyOption) error {
+					for _, fn := range ao {
+						if err := fn(ctx, tc.args.current.DeepCopy(), obj); err != nil {
+							return err
+						}
+					}
+					applied = obj.(*corev1.Secret)
+					return nil
+				}),
+			}
+
+			changed, err := ss.WriteKeyValues(context.Background(), &store.Secret{
+				ScopedName: store.ScopedName{Name: fakeSecretName, Scope: fakeSecretNamespace},
+				Metadata:   &v1.ConnectionSecretMetadata{Labels: map[string]string{"team": "cool"}},
+				Data:       fakeKV(),
+				Owner: &store.Owner{
+					TypedReference: v1.TypedReference{APIVersion: "example.org/v1", Kind: "Cool", Name: "cool", UID: types.UID(fakeOwnerID)},
+				},
+			})
+			if err != nil {
+				t.Fatalf("\n%s\nss.WriteKeyValues(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.changed, changed); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want changed, +got changed:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.applied, applied); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want applied, +got applied:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func withOwner() secretOption {
+	return func(s *corev1.Secret) {
+		s.SetOwnerReferences([]metav1.OwnerReference{{
+			APIVersion:         "example.org/v1",
+			Kind:               "Cool",
+			Name:               "cool",
+			UID:                types.UID(fakeOwnerID),
+			Controller:         ptr.To(true),
+			BlockOwnerDeletion: ptr.To(true),
+		}})
+	}
+}
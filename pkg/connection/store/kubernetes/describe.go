@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const redacted = "<redacted>"
+
+// DescribeConfig returns a description of the SecretStore's effective
+// configuration, suitable for inclusion in support bundles. Credentials are
+// never included; the SecretStore doesn't retain them once it has built its
+// client.
+func (ss *SecretStore) DescribeConfig() string {
+	b := &strings.Builder{}
+	line := func(k string, v any) {
+		fmt.Fprintf(b, "%s: %v\n", k, v)
+	}
+
+	line("type", "Kubernetes")
+	line("defaultNamespace", ss.defaultNamespace)
+	line("remote", ss.remote)
+	if ss.remote {
+		line("server", ss.server)
+		line("credentialsSource", ss.credentialsSource)
+		line("credentials", redacted)
+	}
+	line("requireOwnership", ss.requireOwnership)
+	line("ownerReferencePolicy", ss.ownerRefs)
+	line("secretFinalizer", ss.finalizer)
+	line("writeBackoff", describeBackoff(ss.writeBackoff))
+	line("conflictBackoff", describeBackoff(ss.conflictBackoff))
+	line("deleteBackoff", describeBackoff(ss.deleteBackoff))
+	return b.String()
+}
+
+// String returns a description of the SecretStore's effective configuration.
+// It is equivalent to DescribeConfig, and ensures a SecretStore never prints
+// its client.
+func (ss *SecretStore) String() string {
+	return ss.DescribeConfig()
+}
+
+func describeBackoff(b wait.Backoff) string {
+	return fmt.Sprintf("steps=%d duration=%s factor=%g jitter=%g", b.Steps, b.Duration, b.Factor, b.Jitter)
+}
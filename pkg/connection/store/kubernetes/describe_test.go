@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestSecretStoreDescribeConfig(t *testing.T) {
+	const token = "sup3r-s3cr3t-t0ken"
+	kubeconfig := `
+apiVersion: v1
+clusters:
+- cluster:
+    server: http://127.0.0.1:64695
+  name: remote
+contexts:
+- context:
+    cluster: remote
+    user: remote
+  name: remote
+current-context: remote
+kind: Config
+users:
+- name: remote
+  user:
+    token: ` + token + `
+`
+	local := &test.MockClient{
+		MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+			*obj.(*corev1.Secret) = corev1.Secret{Data: map[string][]byte{"kubeconfig": []byte(kubeconfig)}}
+			return nil
+		}),
+	}
+
+	cases := map[string]struct {
+		reason  string
+		cfg     v1.SecretStoreConfig
+		o       []SecretStoreOption
+		want    []string
+		exclude []string
+	}{
+		"Local": {
+			reason: "We should describe the key settings of a local store.",
+			cfg:    v1.SecretStoreConfig{Type: &storeTypeKubernetes, DefaultScope: "test-ns"},
+			o:      []SecretStoreOption{WithSecretFinalizer(), WithOwnerReferencePolicy(OwnerReferencePolicyLabelOnly)},
+			want: []string{
+				"defaultNamespace: test-ns",
+				"remote: false",
+				"ownerReferencePolicy: LabelOnly",
+				"secretFinalizer: true",
+				"conflictBackoff: steps=5 duration=10ms factor=1 jitter=0.1",
+			},
+			exclude: []string{"credentials"},
+		},
+		"Remote": {
+			reason: "We should describe the key settings of a remote store, without its credentials.",
+			cfg: v1.SecretStoreConfig{
+				Type:         &storeTypeKubernetes,
+				DefaultScope: "test-ns",
+				Kubernetes: &v1.KubernetesSecretStoreConfig{
+					Auth: v1.KubernetesAuthConfig{
+						Source: v1.CredentialsSourceSecret,
+						CommonCredentialSelectors: v1.CommonCredentialSelectors{
+							SecretRef: &v1.SecretKeySelector{
+								SecretReference: v1.SecretReference{Name: "kube-conn", Namespace: "test-ns"},
+								Key:             "kubeconfig",
+							},
+						},
+					},
+				},
+			},
+			o: []SecretStoreOption{WithRequireOwnership()},
+			want: []string{
+				"defaultNamespace: test-ns",
+				"remote: true",
+				"server: http://127.0.0.1:64695",
+				"credentialsSource: Secret",
+				"credentials: " + redacted,
+				"requireOwnership: true",
+			},
+			exclude: []string{token},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ss, err := NewSecretStore(context.Background(), local, nil, tc.cfg, tc.o...)
+			if err != nil {
+				t.Fatalf("\n%s\nNewSecretStore(...): %v", tc.reason, err)
+			}
+			got := ss.DescribeConfig()
+			for _, w := range tc.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("\n%s\nss.DescribeConfig(): want %q in:\n%s", tc.reason, w, got)
+				}
+			}
+			for _, e := range tc.exclude {
+				if strings.Contains(got, e) || strings.Contains(ss.String(), e) {
+					t.Errorf("\n%s\nss.DescribeConfig(): want %q excluded from:\n%s", tc.reason, e, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errFmtForeignOwner = "refusing to adopt secret %q in namespace %q: it is labelled as owned by %q"
+)
+
+// Labels a SecretStore stamps on the secrets it writes to a remote Kubernetes
+// API server, or WithLabelOwnership, which it can't garbage collect using
+// owner references. The owner UID label is v1.LabelKeyOwnerUID.
+const (
+	LabelKeyOwnerGroup     = "secret.crossplane.io/owner-group"
+	LabelKeyOwnerVersion   = "secret.crossplane.io/owner-version"
+	LabelKeyOwnerKind      = "secret.crossplane.io/owner-kind"
+	LabelKeyOwnerNamespace = "secret.crossplane.io/owner-namespace"
+	LabelKeyOwnerName      = "secret.crossplane.io/owner-name"
+)
+
+// Annotations a SecretStore stamps on the secrets whose ownership it tracks by
+// label. They record the kind and name of the owner even when they aren't
+// valid label values, for example because the owner's name is longer than 63
+// characters.
+const (
+	AnnotationKeyOwnerKind = "secret.crossplane.io/owner-kind"
+	AnnotationKeyOwnerName = "secret.crossplane.io/owner-name"
+)
+
+// setOwnerMetadata labels a secret that is written to a remote Kubernetes API
+// server, or whose ownership is tracked WithLabelOwnership, with the UID, GVK,
+// namespace, name, and identity of its owner. Owners that live on another
+// API server can't be referenced by owner references, so these labels are
+// what allows secrets to be found and deleted along with their owner. Labels
+// whose value would not be a valid label value, for example because the
+// owner's name is too long, are omitted. The owner UID label alone is enough
+// to find a secret. The owner's kind and name are also recorded by
+// annotations, which have no such limits.
+func (ss *SecretStore) setOwnerMetadata(ks *corev1.Secret, o *store.Owner) {
+	if !ss.tracksOwnershipByLabel() || o == nil || o.UID == "" {
+		return
+	}
+	gv, _ := schema.ParseGroupVersion(o.APIVersion)
+	labels := map[string]string{
+		v1.LabelKeyOwnerUID:    string(o.UID),
+		LabelKeyOwnerGroup:     gv.Group,
+		LabelKeyOwnerVersion:   gv.Version,
+		LabelKeyOwnerKind:      o.Kind,
+		LabelKeyOwnerNamespace: o.Namespace,
+		LabelKeyOwnerName:      o.Name,
+		LabelKeyOwnerIdentity:  OwnerIdentity(o),
+	}
+
+	// Don't mutate the labels of the supplied secret's metadata.
+	l := make(map[string]string, len(ks.Labels)+len(labels))
+	for k, v := range ks.Labels {
+		l[k] = v
+	}
+	for k, v := range labels {
+		if v == "" || len(validation.IsValidLabelValue(v)) > 0 {
+			continue
+		}
+		l[k] = v
+	}
+	ks.SetLabels(l)
+
+	// Don't mutate the annotations of the supplied secret's metadata either.
+	a := make(map[string]string, len(ks.Annotations)+2)
+	for k, v := range ks.Annotations {
+		a[k] = v
+	}
+	for k, v := range map[string]string{AnnotationKeyOwnerKind: o.Kind, AnnotationKeyOwnerName: o.Name} {
+		if v != "" {
+			a[k] = v
+		}
+	}
+	ks.SetAnnotations(a)
+}
+
+// refuseForeignOwner is an ApplyOption that refuses to write a secret whose
+// ownership is tracked by label if the current secret is labelled as owned by
+// another owner than the desired secret, per IsOwnedBy. Owner references don't
+// protect the controllability of such secrets, so the owner labels do.
+func (ss *SecretStore) refuseForeignOwner(_ context.Context, current, desired runtime.Object) error {
+	if !ss.tracksOwnershipByLabel() {
+		return nil
+	}
+	cs := current.(*corev1.Secret) //nolint:forcetypeassert // Will always be a secret.
+	ds := desired.(*corev1.Secret) //nolint:forcetypeassert // Will always be a secret.
+
+	// Secrets that aren't labelled as owned by anyone may be adopted by any
+	// owner, and a secret without an owner may be written anywhere.
+	if !labelledOwned(cs.GetLabels()) || !labelledOwned(ds.GetLabels()) {
+		return nil
+	}
+	c, owned := labelledOwnedBy(cs.GetLabels(), ds.GetLabels()[v1.LabelKeyOwnerUID], ds.GetLabels()[LabelKeyOwnerIdentity])
+	if !owned {
+		return errors.Errorf(errFmtForeignOwner, cs.GetName(), cs.GetNamespace(), c)
+	}
+	return nil
+}
+
+// labelledOwned returns true if the supplied secret labels record an owner.
+func labelledOwned(labels map[string]string) bool {
+	return labels[v1.LabelKeyOwnerUID] != "" || labels[LabelKeyOwnerIdentity] != ""
+}
+
+// DeleteKeyValuesForOwner deletes key values from the supplied secret like
+// DeleteKeyValues. If the whole secret is deleted from a remote Kubernetes
+// API server, or WithLabelOwnership, every other secret labelled as owned by
+// the secret's owner is deleted too. Such secrets have no owner references,
+// so they would otherwise leak when their owner is deleted, for example after
+// the owner was changed to publish to another secret.
+func (ss *SecretStore) DeleteKeyValuesForOwner(ctx context.Context, s *store.Secret, do ...store.DeleteOption) error {
+	if err := ss.DeleteKeyValues(ctx, s, do...); err != nil {
+		return err
+	}
+	if !ss.tracksOwnershipByLabel() || len(s.Data) > 0 || s.Owner == nil || s.Owner.UID == "" {
+		return nil
+	}
+	// Owner references are never set when ownership is tracked by label,
+	// so only the owner UID label identifies the owner's secrets.
+	return ss.DeleteAllForOwner(ctx, &store.Owner{TypedReference: s.Owner.TypedReference})
+}
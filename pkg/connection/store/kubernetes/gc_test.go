@@ -0,0 +1,284 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestSecretStoreWriteKeyValuesOwnerLabels(t *testing.T) {
+	owner := &store.Owner{
+		TypedReference: v1.TypedReference{APIVersion: "example.org/v1", Kind: "Cool", Name: "cool", UID: types.UID(fakeOwnerID)},
+		Namespace:      fakeSecretNamespace,
+	}
+
+	type args struct {
+		remote  bool
+		current *corev1.Secret
+	}
+	type want struct {
+		labels map[string]string
+		refs   []metav1.OwnerReference
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Remote": {
+			reason: "We should label secrets written to a remote API server with their owner's UID, GVK, namespace, name, and identity.",
+			args: args{
+				remote:  true,
+				current: fakeConnectionSecret(),
+			},
+			want: want{
+				labels: map[string]string{
+					v1.LabelKeyOwnerUID:    fakeOwnerID,
+					LabelKeyOwnerGroup:     "example.org",
+					LabelKeyOwnerVersion:   "v1",
+					LabelKeyOwnerKind:      "Cool",
+					LabelKeyOwnerNamespace: fakeSecretNamespace,
+					LabelKeyOwnerName:      "cool",
+					LabelKeyOwnerIdentity:  OwnerIdentity(owner),
+				},
+			},
+		},
+		"Local": {
+			reason: "We should keep using owner references for secrets written to the local API server.",
+			args: args{
+				current: fakeConnectionSecret(),
+			},
+			want: want{
+				labels: map[string]string{v1.LabelKeyOwnerUID: fakeOwnerID},
+				refs: []metav1.OwnerReference{{
+					APIVersion:         "example.org/v1",
+					Kind:               "Cool",
+					Name:               "cool",
+					UID:                types.UID(fakeOwnerID),
+					Controller:         ptr.To(true),
+					BlockOwnerDeletion: ptr.To(true),
+				}},
+			},
+		},
+		"RemoteSameOwner": {
+			reason: "We should write a remote secret that is labelled as owned by the same owner.",
+			args: args{
+				remote:  true,
+				current: fakeConnectionSecret(withLabels(map[string]string{v1.LabelKeyOwnerUID: fakeOwnerID, LabelKeyOwnerIdentity: OwnerIdentity(owner)})),
+			},
+			want: want{
+				labels: map[string]string{
+					v1.LabelKeyOwnerUID:    fakeOwnerID,
+					LabelKeyOwnerGroup:     "example.org",
+					LabelKeyOwnerVersion:   "v1",
+					LabelKeyOwnerKind:      "Cool",
+					LabelKeyOwnerNamespace: fakeSecretNamespace,
+					LabelKeyOwnerName:      "cool",
+					LabelKeyOwnerIdentity:  OwnerIdentity(owner),
+				},
+			},
+		},
+		"RemoteForeignOwner": {
+			reason: "We should not adopt a remote secret that is labelled as owned by another owner.",
+			args: args{
+				remote:  true,
+				current: fakeConnectionSecret(withLabels(map[string]string{v1.LabelKeyOwnerUID: "other-uid"})),
+			},
+			want: want{
+				err: errors.Wrap(errors.Errorf(errFmtForeignOwner, fakeSecretName, fakeSecretNamespace, "other-uid"), errApplySecret),
+			},
+		},
+		"RemoteSameOwnerOlderIdentity": {
+			reason: "We should write a remote secret that is labelled with its owner's UID, and stamp its owner identity label again, even if the label was stamped by an older encoding.",
+			args: args{
+				remote:  true,
+				current: fakeConnectionSecret(withLabels(map[string]string{v1.LabelKeyOwnerUID: fakeOwnerID, LabelKeyOwnerIdentity: "older-identity"})),
+			},
+			want: want{
+				labels: map[string]string{
+					v1.LabelKeyOwnerUID:    fakeOwnerID,
+					LabelKeyOwnerGroup:     "example.org",
+					LabelKeyOwnerVersion:   "v1",
+					LabelKeyOwnerKind:      "Cool",
+					LabelKeyOwnerNamespace: fakeSecretNamespace,
+					LabelKeyOwnerName:      "cool",
+					LabelKeyOwnerIdentity:  OwnerIdentity(owner),
+				},
+			},
+		},
+		"RemoteForeignOwnerIdentity": {
+			reason: "We should not adopt a remote secret that is only labelled with the identity of another owner.",
+			args: args{
+				remote:  true,
+				current: fakeConnectionSecret(withLabels(map[string]string{LabelKeyOwnerIdentity: "other-identity"})),
+			},
+			want: want{
+				err: errors.Wrap(errors.Errorf(errFmtForeignOwner, fakeSecretName, fakeSecretNamespace, "other-identity"), errApplySecret),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var applied *corev1.Secret
+			ss := &SecretStore{
+				client: resource.ClientApplicator{
+					Applicator: resource.ApplyFn(func(ctx context.Context, obj client.Object, ao ...resource.ApplyOption) error {
+						for _, fn := range ao {
+							if err := fn(ctx, tc.args.current.DeepCopy(), obj); err != nil {
+								return err
+							}
+						}
+						applied = obj.(*corev1.Secret)
+						return nil
+					}),
+				},
+				remote:    tc.args.remote,
+				ownerRefs: OwnerReferencePolicyRequireSameNamespace,
+			}
+
+			meta := &v1.ConnectionSecretMetadata{}
+			meta.SetOwnerUID(owner.UID)
+			_, err := ss.WriteKeyValues(context.Background(), &store.Secret{
+				ScopedName: store.ScopedName{Name: fakeSecretName, Scope: fakeSecretNamespace},
+				Metadata:   meta,
+				Data:       fakeKV(),
+				Owner:      owner,
+			})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.labels, applied.GetLabels()); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want labels, +got labels:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.refs, applied.GetOwnerReferences()); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want owner references, +got owner references:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(map[string]string{v1.LabelKeyOwnerUID: fakeOwnerID}, meta.Labels); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): want the supplied metadata to be unchanged:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSecretStoreDeleteKeyValuesForOwner(t *testing.T) {
+	owner := &store.Owner{
+		TypedReference: v1.TypedReference{APIVersion: "example.org/v1", Kind: "Cool", Name: "cool", UID: types.UID(fakeOwnerID)},
+	}
+	ownerLabels := map[string]string{v1.LabelKeyOwnerUID: fakeOwnerID, LabelKeyOwnerKind: "Cool"}
+
+	type want struct {
+		deleted []string
+		updated *corev1.Secret
+	}
+
+	cases := map[string]struct {
+		reason string
+		remote bool
+		data   store.KeyValues
+		want   want
+	}{
+		"RemoteFullDelete": {
+			reason: "We should delete all of the owner's secrets from a remote API server when its secret is deleted.",
+			remote: true,
+			want: want{
+				deleted: []string{fakeSecretNamespace + "/" + fakeSecretName, "other-ns/renamed"},
+			},
+		},
+		"LocalFullDelete": {
+			reason: "We should rely on owner references to delete the owner's other secrets from the local API server.",
+			want: want{
+				deleted: []string{fakeSecretNamespace + "/" + fakeSecretName},
+			},
+		},
+		"RemotePartialDelete": {
+			reason: "We should keep the owner labels when deleting some keys of a shared secret.",
+			remote: true,
+			data:   store.KeyValues{"key1": nil},
+			want: want{
+				updated: fakeConnectionSecret(withLabels(ownerLabels), withData(map[string][]byte{"key2": []byte("value2"), "key3": []byte("value3")})),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var deleted []string
+			var updated *corev1.Secret
+			ss := &SecretStore{
+				client: resource.ClientApplicator{
+					Client: &test.MockClient{
+						MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+							*obj.(*corev1.Secret) = *fakeConnectionSecret(withLabels(ownerLabels), withData(fakeKV()))
+							return nil
+						},
+						MockList: func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+							obj.(*corev1.SecretList).Items = []corev1.Secret{
+								{ObjectMeta: metav1.ObjectMeta{Namespace: "other-ns", Name: "renamed", Labels: ownerLabels}},
+								{ObjectMeta: metav1.ObjectMeta{Namespace: "other-ns", Name: "unrelated", Labels: map[string]string{v1.LabelKeyOwnerUID: "other-uid"}}},
+							}
+							return nil
+						},
+						MockDelete: func(_ context.Context, obj client.Object, _ ...client.DeleteOption) error {
+							deleted = append(deleted, obj.GetNamespace()+"/"+obj.GetName())
+							return nil
+						},
+						MockUpdate: func(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+							updated = obj.(*corev1.Secret)
+							return nil
+						},
+					},
+				},
+				remote: tc.remote,
+			}
+
+			err := ss.DeleteKeyValuesForOwner(context.Background(), &store.Secret{
+				ScopedName: store.ScopedName{Name: fakeSecretName, Scope: fakeSecretNamespace},
+				Data:       tc.data,
+				Owner:      owner,
+			})
+			if err != nil {
+				t.Fatalf("\n%s\nss.DeleteKeyValuesForOwner(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.deleted, deleted); diff != "" {
+				t.Errorf("\n%s\nss.DeleteKeyValuesForOwner(...): -want deleted, +got deleted:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.updated, updated); diff != "" {
+				t.Errorf("\n%s\nss.DeleteKeyValuesForOwner(...): -want updated, +got updated:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	corev1 "k8s.io/api/core/v1"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+)
+
+// LabelKeyOwnerIdentity is the label a SecretStore stamps on the secrets it
+// writes to a remote Kubernetes API server to record the identity of their
+// owner, as encoded by OwnerIdentity.
+const LabelKeyOwnerIdentity = "secret.crossplane.io/owner-identity"
+
+// ownerIdentityLength is the length of an encoded owner identity. It is well
+// within the 63 character limit of label values.
+const ownerIdentityLength = 40
+
+// OwnerIdentity encodes the identity of the supplied owner as a valid label
+// value. The identity is the first 40 hex characters of the SHA-256 hash of
+// the owner's UID. The owner's API group, version, and kind are not part of
+// its identity, so that it is stable when the owner's kind is resolved, its
+// API group is renamed, or it is served at another API version. It returns an
+// empty string if the owner has no UID.
+func OwnerIdentity(o *store.Owner) string {
+	if o == nil || o.UID == "" {
+		return ""
+	}
+	h := sha256.Sum256([]byte(o.UID))
+	return hex.EncodeToString(h[:])[:ownerIdentityLength]
+}
+
+// IsOwnedBy returns true if the supplied secret is labelled as owned by the
+// supplied owner. Secrets with an owner UID label are owned by the owner whose
+// UID matches it, even if their owner identity label was stamped by an older
+// encoding; the label is stamped again when the secret is next written.
+// Secrets with only an owner identity label are owned by the owner whose
+// identity matches it.
+func IsOwnedBy(ks *corev1.Secret, o *store.Owner) bool {
+	if o == nil || o.UID == "" {
+		return false
+	}
+	_, owned := labelledOwnedBy(ks.GetLabels(), string(o.UID), OwnerIdentity(o))
+	return owned
+}
+
+// labelledOwnedBy returns true if the supplied secret labels record that the
+// secret is owned by the owner with the supplied UID and identity. The owner's
+// UID is authoritative, so it is compared if both the labels and the owner
+// have one, and its identity otherwise. It also returns the label value it
+// compared.
+func labelledOwnedBy(labels map[string]string, uid, identity string) (string, bool) {
+	if u := labels[v1.LabelKeyOwnerUID]; u != "" && uid != "" {
+		return u, u == uid
+	}
+	if id, ok := labels[LabelKeyOwnerIdentity]; ok && identity != "" {
+		return id, id == identity
+	}
+	return labels[v1.LabelKeyOwnerUID], labels[v1.LabelKeyOwnerUID] == uid
+}
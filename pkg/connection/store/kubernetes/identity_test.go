@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+)
+
+func TestIsOwnedBy(t *testing.T) {
+	owner := &store.Owner{TypedReference: v1.TypedReference{APIVersion: "example.org/v1", Kind: "Cool", Name: "cool", UID: types.UID(fakeOwnerID)}}
+
+	type args struct {
+		labels map[string]string
+		o      *store.Owner
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   bool
+	}{
+		"SameOwner": {
+			reason: "A secret labelled with an owner's identity should be owned by that owner.",
+			args: args{
+				labels: map[string]string{LabelKeyOwnerIdentity: OwnerIdentity(owner)},
+				o:      owner,
+			},
+			want: true,
+		},
+		"SameOwnerOtherVersion": {
+			reason: "An owner's identity should be stable across API versions of the owner.",
+			args: args{
+				labels: map[string]string{LabelKeyOwnerIdentity: OwnerIdentity(owner)},
+				o:      &store.Owner{TypedReference: v1.TypedReference{APIVersion: "example.org/v2", Kind: "Cool", Name: "cool", UID: types.UID(fakeOwnerID)}},
+			},
+			want: true,
+		},
+		"OtherOwner": {
+			reason: "A secret labelled with an owner's identity should not be owned by another owner.",
+			args: args{
+				labels: map[string]string{LabelKeyOwnerIdentity: OwnerIdentity(owner)},
+				o:      &store.Owner{TypedReference: v1.TypedReference{APIVersion: "example.org/v1", Kind: "Cool", Name: "cool", UID: "other-uid"}},
+			},
+			want: false,
+		},
+		"SameOwnerOtherKind": {
+			reason: "An owner's identity should be stable when its kind is resolved or its API group is renamed.",
+			args: args{
+				labels: map[string]string{LabelKeyOwnerIdentity: OwnerIdentity(owner)},
+				o:      &store.Owner{TypedReference: v1.TypedReference{APIVersion: "renamed.example.org/v1", Name: "cool", UID: types.UID(fakeOwnerID)}},
+			},
+			want: true,
+		},
+		"SameUIDOtherIdentity": {
+			reason: "A secret labelled with an owner's UID should be owned by that owner, even if its identity label was stamped by an older encoding.",
+			args: args{
+				labels: map[string]string{v1.LabelKeyOwnerUID: fakeOwnerID, LabelKeyOwnerIdentity: "older-identity"},
+				o:      owner,
+			},
+			want: true,
+		},
+		"OtherUIDSameIdentity": {
+			reason: "A secret labelled with another owner's UID should not be owned by an owner, whatever its identity label.",
+			args: args{
+				labels: map[string]string{v1.LabelKeyOwnerUID: "other-uid", LabelKeyOwnerIdentity: OwnerIdentity(owner)},
+				o:      owner,
+			},
+			want: false,
+		},
+		"LegacyOwnerUID": {
+			reason: "A secret labelled only with an owner UID should be owned by the owner with that UID.",
+			args: args{
+				labels: map[string]string{v1.LabelKeyOwnerUID: fakeOwnerID},
+				o:      owner,
+			},
+			want: true,
+		},
+		"Unlabelled": {
+			reason: "A secret without owner labels should not be owned by any owner.",
+			args: args{
+				o: owner,
+			},
+			want: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ks := &corev1.Secret{}
+			ks.SetLabels(tc.args.labels)
+			got := IsOwnedBy(ks, tc.args.o)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nIsOwnedBy(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestOwnerIdentity(t *testing.T) {
+	o := &store.Owner{TypedReference: v1.TypedReference{APIVersion: "example.org/v1", Kind: "Cool", UID: types.UID(fakeOwnerID)}}
+	id := OwnerIdentity(o)
+	if errs := validation.IsValidLabelValue(id); len(errs) > 0 {
+		t.Errorf("OwnerIdentity(...): %q is not a valid label value: %v", id, errs)
+	}
+	if diff := cmp.Diff("", OwnerIdentity(&store.Owner{})); diff != "" {
+		t.Errorf("OwnerIdentity(...): want an empty identity for an owner without a UID:\n%s", diff)
+	}
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errFmtCheckOwner    = "cannot check whether owner %s %q of secret %q in namespace %q exists"
+	errFmtCheckOwnerUID = "cannot check whether owner with UID %q of secret %q in namespace %q exists"
+)
+
+// WithLabelOwnership configures a SecretStore to track the ownership of the
+// secrets it writes to the local Kubernetes API server using labels, like it
+// always does on a remote Kubernetes API server. This is the supported way to
+// write secrets whose owners can't be referenced by owner references, for
+// example because they are in another namespace. It takes precedence over the
+// OwnerReferencePolicy. Each secret is:
+//
+//   - written without an owner reference, labelled with the UID, GVK,
+//     namespace, name, and identity of its owner, and annotated with the kind
+//     and name of its owner.
+//   - never adopted from an owner with another UID or identity.
+//   - deleted along with all other secrets of its owner by
+//     DeleteKeyValuesForOwner.
+//
+// Secrets whose owner was deleted without deleting its secrets, for example
+// because the owner's controller wasn't running, are not garbage collected by
+// Kubernetes. CollectGarbage deletes them.
+func WithLabelOwnership() SecretStoreOption {
+	return func(ss *SecretStore) {
+		ss.labelOwnership = true
+	}
+}
+
+// tracksOwnershipByLabel returns true if the SecretStore tracks the ownership
+// of the secrets it writes using labels rather than owner references.
+func (ss *SecretStore) tracksOwnershipByLabel() bool {
+	return ss.remote || ss.labelOwnership
+}
+
+// An OwnerExistsFn returns true if the supplied owner of a secret exists.
+type OwnerExistsFn func(ctx context.Context, o *store.Owner) (bool, error)
+
+// OwnerExistsIn returns an OwnerExistsFn that gets owners from the supplied
+// reader, typically a client of the local Kubernetes API server. An owner
+// exists if an object of its GVK with its namespace and name exists, and has
+// its UID. An object that was recreated with the same name is not the owner.
+func OwnerExistsIn(c client.Reader) OwnerExistsFn {
+	return func(ctx context.Context, o *store.Owner) (bool, error) {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion(o.APIVersion)
+		u.SetKind(o.Kind)
+		err := c.Get(ctx, types.NamespacedName{Namespace: o.Namespace, Name: o.Name}, u)
+		if kerrors.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return u.GetUID() == o.UID, nil
+	}
+}
+
+// An OwnerUIDExistsFn returns true if the owner with the supplied UID exists.
+type OwnerUIDExistsFn func(ctx context.Context, uid types.UID) (bool, error)
+
+// A CollectGarbageOption configures how CollectGarbage checks owners.
+type CollectGarbageOption func(*collectGarbageOptions)
+
+type collectGarbageOptions struct {
+	uidExists OwnerUIDExistsFn
+}
+
+// WithOwnerUIDExists configures CollectGarbage to check whether the owner of a
+// secret exists by UID using the supplied OwnerUIDExistsFn, when the secret's
+// owner labels and annotations don't record the owner's kind and name, for
+// example because it was written by an owner whose kind wasn't known.
+func WithOwnerUIDExists(fn OwnerUIDExistsFn) CollectGarbageOption {
+	return func(o *collectGarbageOptions) {
+		o.uidExists = fn
+	}
+}
+
+// CollectGarbage deletes the secrets labelled as owned by an owner that no
+// longer exists, per the supplied OwnerExistsFn. It is intended to be called
+// periodically, for example by a controller that reconciles on a timer, and
+// each call:
+//
+//  1. Lists all secrets labelled with an owner UID, in all namespaces.
+//  2. Checks whether the owner of each secret exists. The owner's kind and
+//     name are read from the secret's owner annotations, or its owner labels
+//     if it has none. Owners whose kind or name isn't recorded are checked by
+//     UID if CollectGarbage is called WithOwnerUIDExists. Otherwise they can't
+//     be checked, so they are assumed to be live.
+//  3. Retains secrets whose owner exists.
+//  4. Deletes secrets whose owner doesn't exist. Secrets that were already
+//     deleted are considered deleted.
+//
+// Secrets are processed in a deterministic order. Errors checking whether an
+// owner exists or deleting a secret don't stop the remaining secrets from
+// being processed; they are returned together, and the affected secrets are
+// retained until the next call. Calls are idempotent.
+func (ss *SecretStore) CollectGarbage(ctx context.Context, exists OwnerExistsFn, o ...CollectGarbageOption) error {
+	opts := &collectGarbageOptions{}
+	for _, fn := range o {
+		fn(opts)
+	}
+
+	var secrets []corev1.Secret
+	err := listPages(ctx, ss.client, &corev1.SecretList{}, []client.ListOption{client.HasLabels{v1.LabelKeyOwnerUID}}, func(l *corev1.SecretList) {
+		secrets = append(secrets, l.Items...)
+	})
+	if err != nil {
+		return errors.Wrap(withUnauthenticated(withRBACHint(err)), errListSecrets)
+	}
+	sort.Slice(secrets, func(i, j int) bool {
+		return types.NamespacedName{Namespace: secrets[i].Namespace, Name: secrets[i].Name}.String() < types.NamespacedName{Namespace: secrets[j].Namespace, Name: secrets[j].Name}.String()
+	})
+
+	errs := make([]error, 0)
+	for i := range secrets {
+		s := &secrets[i]
+		ok, err := ownerExists(ctx, s, exists, opts.uidExists)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if ok {
+			continue
+		}
+		if err := ss.client.Delete(ctx, s); err != nil && !kerrors.IsNotFound(err) {
+			errs = append(errs, errors.Wrapf(withUnauthenticated(withRBACHint(err)), errFmtDeleteOwnedSecret, s.GetName(), s.GetNamespace()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ownerExists returns true if the owner of the supplied secret exists, or if
+// it can't be checked.
+func ownerExists(ctx context.Context, s *corev1.Secret, exists OwnerExistsFn, uidExists OwnerUIDExistsFn) (bool, error) {
+	if o := ownerFromMetadata(s); o != nil {
+		ok, err := exists(ctx, o)
+		return ok, errors.Wrapf(err, errFmtCheckOwner, o.Kind, o.Name, s.GetName(), s.GetNamespace())
+	}
+	uid := s.GetLabels()[v1.LabelKeyOwnerUID]
+	if uidExists == nil || uid == "" {
+		return true, nil
+	}
+	ok, err := uidExists(ctx, types.UID(uid))
+	return ok, errors.Wrapf(err, errFmtCheckOwnerUID, uid, s.GetName(), s.GetNamespace())
+}
+
+// ownerFromMetadata returns the owner identified by the owner annotations and
+// labels of the supplied secret, or nil if they don't identify an owner.
+func ownerFromMetadata(s *corev1.Secret) *store.Owner {
+	l, a := s.GetLabels(), s.GetAnnotations()
+	uid, kind, name := l[v1.LabelKeyOwnerUID], a[AnnotationKeyOwnerKind], a[AnnotationKeyOwnerName]
+	if kind == "" {
+		kind = l[LabelKeyOwnerKind]
+	}
+	if name == "" {
+		name = l[LabelKeyOwnerName]
+	}
+	if uid == "" || kind == "" || name == "" || l[LabelKeyOwnerVersion] == "" {
+		return nil
+	}
+	return &store.Owner{
+		TypedReference: v1.TypedReference{
+			APIVersion: schema.GroupVersion{Group: l[LabelKeyOwnerGroup], Version: l[LabelKeyOwnerVersion]}.String(),
+			Kind:       kind,
+			Name:       name,
+			UID:        types.UID(uid),
+		},
+		Namespace: l[LabelKeyOwnerNamespace],
+	}
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestSecretStoreWriteKeyValuesLabelOwnership(t *testing.T) {
+	owner := &store.Owner{
+		TypedReference: v1.TypedReference{APIVersion: "example.org/v1", Kind: "Cool", Name: "cool", UID: types.UID(fakeOwnerID)},
+		Namespace:      "other-ns",
+	}
+
+	var applied *corev1.Secret
+	ss := &SecretStore{
+		client: resource.ClientApplicator{
+			Applicator: resource.ApplyFn(func(_ context.Context, obj client.Object, _ ...resource.ApplyOption) error {
+				applied = obj.(*corev1.Secret)
+				return nil
+			}),
+		},
+		ownerRefs: OwnerReferencePolicyRequireSameNamespace,
+	}
+	WithLabelOwnership()(ss)
+
+	meta := &v1.ConnectionSecretMetadata{}
+	meta.SetOwnerUID(owner.UID)
+	if _, err := ss.WriteKeyValues(context.Background(), &store.Secret{
+		ScopedName: store.ScopedName{Name: fakeSecretName, Scope: fakeSecretNamespace},
+		Metadata:   meta,
+		Data:       fakeKV(),
+		Owner:      owner,
+	}); err != nil {
+		t.Fatalf("ss.WriteKeyValues(...): %v", err)
+	}
+
+	want := map[string]string{
+		v1.LabelKeyOwnerUID:    fakeOwnerID,
+		LabelKeyOwnerGroup:     "example.org",
+		LabelKeyOwnerVersion:   "v1",
+		LabelKeyOwnerKind:      "Cool",
+		LabelKeyOwnerNamespace: "other-ns",
+		LabelKeyOwnerName:      "cool",
+		LabelKeyOwnerIdentity:  OwnerIdentity(owner),
+	}
+	if diff := cmp.Diff(want, applied.GetLabels()); diff != "" {
+		t.Errorf("ss.WriteKeyValues(...): -want labels, +got labels:\n%s", diff)
+	}
+	if refs := applied.GetOwnerReferences(); len(refs) > 0 {
+		t.Errorf("ss.WriteKeyValues(...): want no owner references, got %v", refs)
+	}
+	wantAnnotations := map[string]string{
+		AnnotationKeyOwnerKind: "Cool",
+		AnnotationKeyOwnerName: "cool",
+	}
+	if diff := cmp.Diff(wantAnnotations, applied.GetAnnotations()); diff != "" {
+		t.Errorf("ss.WriteKeyValues(...): -want annotations, +got annotations:\n%s", diff)
+	}
+}
+
+func TestSecretStoreCollectGarbage(t *testing.T) {
+	labels := func(uid, name string) map[string]string {
+		return map[string]string{
+			v1.LabelKeyOwnerUID:    uid,
+			LabelKeyOwnerGroup:     "example.org",
+			LabelKeyOwnerVersion:   "v1",
+			LabelKeyOwnerKind:      "Cool",
+			LabelKeyOwnerNamespace: "owners",
+			LabelKeyOwnerName:      name,
+		}
+	}
+	// An owner name that is too long to be a label value.
+	long := strings.Repeat("cool", 16)
+	longName := labels("long-uid", "")
+	delete(longName, LabelKeyOwnerName)
+
+	secrets := []corev1.Secret{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "orphaned", Labels: labels("orphaned-uid", "orphaned")}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "live", Labels: labels("live-uid", "live")}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "recreated", Labels: labels("old-uid", "recreated")}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "unidentifiable", Labels: map[string]string{v1.LabelKeyOwnerUID: "unknown-uid"}}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "long-name", Labels: longName, Annotations: map[string]string{AnnotationKeyOwnerKind: "Cool", AnnotationKeyOwnerName: long}}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "kindless", Labels: map[string]string{v1.LabelKeyOwnerUID: "kindless-uid", LabelKeyOwnerVersion: "v1"}}},
+	}
+
+	// Owners that exist on the local API server, by name.
+	owners := map[string]types.UID{"live": "live-uid", "recreated": "new-uid"}
+
+	// Owners that exist, by UID.
+	uidExists := func(_ context.Context, uid types.UID) (bool, error) {
+		return uid == "unknown-uid", nil
+	}
+
+	type args struct {
+		getErr    error
+		deleteErr error
+		o         []CollectGarbageOption
+	}
+	type want struct {
+		deleted []string
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"CollectOrphans": {
+			reason: "We should delete secrets whose owner no longer exists, including owners that were recreated or whose name is only recorded by annotation, and retain the rest.",
+			want: want{
+				deleted: []string{"ns/long-name", "ns/orphaned", "ns/recreated"},
+			},
+		},
+		"CollectOrphansByUID": {
+			reason: "We should check whether owners whose kind or name isn't recorded exist by UID, if configured to.",
+			args: args{
+				o: []CollectGarbageOption{WithOwnerUIDExists(uidExists)},
+			},
+			want: want{
+				deleted: []string{"ns/kindless", "ns/long-name", "ns/orphaned", "ns/recreated"},
+			},
+		},
+		"CheckOwnerError": {
+			reason: "We should retain secrets whose owner can't be checked, and return an error.",
+			args: args{
+				getErr: errBoom,
+			},
+			want: want{
+				err: errors.Join(
+					errors.Wrapf(errBoom, errFmtCheckOwner, "Cool", "live", "live", "ns"),
+					errors.Wrapf(errBoom, errFmtCheckOwner, "Cool", long, "long-name", "ns"),
+					errors.Wrapf(errBoom, errFmtCheckOwner, "Cool", "orphaned", "orphaned", "ns"),
+					errors.Wrapf(errBoom, errFmtCheckOwner, "Cool", "recreated", "recreated", "ns"),
+				),
+			},
+		},
+		"DeleteError": {
+			reason: "We should keep collecting garbage if a secret can't be deleted, and return an error.",
+			args: args{
+				deleteErr: errBoom,
+			},
+			want: want{
+				deleted: []string{"ns/long-name", "ns/orphaned", "ns/recreated"},
+				err: errors.Join(
+					errors.Wrapf(errBoom, errFmtDeleteOwnedSecret, "long-name", "ns"),
+					errors.Wrapf(errBoom, errFmtDeleteOwnedSecret, "orphaned", "ns"),
+					errors.Wrapf(errBoom, errFmtDeleteOwnedSecret, "recreated", "ns"),
+				),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var deleted []string
+			c := &test.MockClient{
+				MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+					if tc.args.getErr != nil {
+						return tc.args.getErr
+					}
+					uid, ok := owners[key.Name]
+					if !ok {
+						return kerrors.NewNotFound(schema.GroupResource{Group: "example.org", Resource: "cools"}, key.Name)
+					}
+					obj.(*unstructured.Unstructured).SetUID(uid)
+					return nil
+				},
+				MockList: func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+					obj.(*corev1.SecretList).Items = secrets
+					return nil
+				},
+				MockDelete: func(_ context.Context, obj client.Object, _ ...client.DeleteOption) error {
+					deleted = append(deleted, obj.GetNamespace()+"/"+obj.GetName())
+					return tc.args.deleteErr
+				},
+			}
+			ss := &SecretStore{client: resource.ClientApplicator{Client: c}}
+
+			err := ss.CollectGarbage(context.Background(), OwnerExistsIn(c), tc.args.o...)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nss.CollectGarbage(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.deleted, deleted); diff != "" {
+				t.Errorf("\n%s\nss.CollectGarbage(...): -want deleted, +got deleted:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
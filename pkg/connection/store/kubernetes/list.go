@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+
+	authv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// Error strings.
+const (
+	errReviewListAccess = "cannot review access to list secrets across namespaces"
+	errListSecrets      = "cannot list secrets"
+	errListNamespaces   = "cannot list namespaces"
+
+	errFmtListDenied = "not allowed to list secrets across namespaces: %s"
+)
+
+// listPageSize is the maximum number of objects requested per page when
+// listing secrets or namespaces.
+const listPageSize = 500
+
+// ReadAcrossNamespaces reads the key values of all secrets that match the
+// supplied secret label selector, in all namespaces that match the supplied
+// namespace label selector. A nil selector matches everything. It returns an
+// error if the store is not allowed to list secrets across namespaces.
+func (ss *SecretStore) ReadAcrossNamespaces(ctx context.Context, secretSelector, nsSelector labels.Selector) (map[store.ScopedName]store.KeyValues, error) {
+	if err := ss.mustBeAllowedToListSecrets(ctx); err != nil {
+		return nil, err
+	}
+
+	var namespaces map[string]bool
+	if nsSelector != nil && !nsSelector.Empty() {
+		namespaces = map[string]bool{}
+		err := listPages(ctx, ss.client, &corev1.NamespaceList{}, []client.ListOption{client.MatchingLabelsSelector{Selector: nsSelector}}, func(l *corev1.NamespaceList) {
+			for _, ns := range l.Items {
+				namespaces[ns.GetName()] = true
+			}
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, errListNamespaces)
+		}
+	}
+
+	var lo []client.ListOption
+	if secretSelector != nil {
+		lo = append(lo, client.MatchingLabelsSelector{Selector: secretSelector})
+	}
+
+	out := map[store.ScopedName]store.KeyValues{}
+	err := listPages(ctx, ss.client, &corev1.SecretList{}, lo, func(l *corev1.SecretList) {
+		for _, s := range l.Items {
+			if namespaces != nil && !namespaces[s.GetNamespace()] {
+				continue
+			}
+			out[store.ScopedName{Name: s.GetName(), Scope: s.GetNamespace()}] = s.Data
+		}
+	})
+	return out, errors.Wrap(err, errListSecrets)
+}
+
+func (ss *SecretStore) mustBeAllowedToListSecrets(ctx context.Context) error {
+	r := &authv1.SelfSubjectAccessReview{
+		Spec: authv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authv1.ResourceAttributes{
+				Verb:     "list",
+				Resource: "secrets",
+			},
+		},
+	}
+	if err := ss.client.Create(ctx, r); err != nil {
+		return errors.Wrap(err, errReviewListAccess)
+	}
+	if !r.Status.Allowed {
+		return errors.Errorf(errFmtListDenied, r.Status.Reason)
+	}
+	return nil
+}
+
+// listPages lists all pages of the supplied list type, calling the supplied
+// function with each page.
+func listPages[T client.ObjectList](ctx context.Context, c client.Reader, l T, lo []client.ListOption, fn func(T)) error {
+	cont := ""
+	for {
+		opts := append([]client.ListOption{client.Limit(listPageSize), client.Continue(cont)}, lo...)
+		if err := c.List(ctx, l, opts...); err != nil {
+			return err
+		}
+		fn(l)
+		if cont = l.GetContinue(); cont == "" {
+			return nil
+		}
+	}
+}
+
+// ListSecrets returns the names of the connection secrets in the supplied
+// namespace, or in the default namespace if none is supplied.
+func (ss *SecretStore) ListSecrets(ctx context.Context, namespace string) ([]string, error) {
+	lo := []client.ListOption{
+		client.InNamespace(ss.namespaceForSecret(store.ScopedName{Scope: namespace})),
+		client.MatchingFields{"type": string(resource.SecretTypeConnection)},
+	}
+	var names []string
+	err := listPages(ctx, ss.client, &corev1.SecretList{}, lo, func(l *corev1.SecretList) {
+		for _, s := range l.Items {
+			names = append(names, s.GetName())
+		}
+	})
+	return names, errors.Wrap(err, errListSecrets)
+}
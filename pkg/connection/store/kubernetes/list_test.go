@@ -0,0 +1,212 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	authv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func secretIn(ns, name string, l map[string]string) corev1.Secret {
+	return corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name, Labels: l},
+		Data:       map[string][]byte{"key": []byte(ns + "/" + name)},
+	}
+}
+
+// listClient returns a client that serves the supplied secrets and namespaces,
+// one object per page, honoring label selectors.
+func listClient(allowed bool, secrets []corev1.Secret, namespaces []corev1.Namespace) *test.MockClient {
+	return &test.MockClient{
+		MockCreate: func(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+			obj.(*authv1.SelfSubjectAccessReview).Status = authv1.SubjectAccessReviewStatus{Allowed: allowed, Reason: "rbac"}
+			return nil
+		},
+		MockList: func(_ context.Context, obj client.ObjectList, opts ...client.ListOption) error {
+			lo := &client.ListOptions{}
+			lo.ApplyOptions(opts)
+			sel := lo.LabelSelector
+			if sel == nil {
+				sel = labels.Everything()
+			}
+
+			var objs []metav1.Object
+			switch l := obj.(type) {
+			case *corev1.SecretList:
+				l.Items = nil
+				for i := range secrets {
+					objs = append(objs, &secrets[i])
+				}
+			case *corev1.NamespaceList:
+				l.Items = nil
+				for i := range namespaces {
+					objs = append(objs, &namespaces[i])
+				}
+			}
+
+			// Continue tokens are the index of the next object.
+			start := 0
+			if lo.Continue != "" {
+				start = int(lo.Continue[0] - '0')
+			}
+			for i := start; i < len(objs); i++ {
+				if !sel.Matches(labels.Set(objs[i].GetLabels())) {
+					continue
+				}
+				next := ""
+				if i+1 < len(objs) {
+					next = string(rune('0' + i + 1))
+				}
+				switch l := obj.(type) {
+				case *corev1.SecretList:
+					l.Items = append(l.Items, *objs[i].(*corev1.Secret))
+					l.Continue = next
+				case *corev1.NamespaceList:
+					l.Items = append(l.Items, *objs[i].(*corev1.Namespace))
+					l.Continue = next
+				}
+				return nil
+			}
+			obj.(metav1.ListInterface).SetContinue("")
+			return nil
+		},
+	}
+}
+
+func TestSecretStoreReadAcrossNamespaces(t *testing.T) {
+	app := map[string]string{"app": "cool"}
+	prod := map[string]string{"env": "prod"}
+
+	secrets := []corev1.Secret{
+		secretIn("a", "db", app),
+		secretIn("a", "other", nil),
+		secretIn("b", "db", app),
+		secretIn("c", "db", app),
+	}
+	namespaces := []corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "a", Labels: prod}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "b"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "c", Labels: prod}},
+	}
+
+	type args struct {
+		allowed        bool
+		secretSelector labels.Selector
+		nsSelector     labels.Selector
+	}
+	type want struct {
+		out map[store.ScopedName]store.KeyValues
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NotAllowed": {
+			reason: "We should return an error if we're not allowed to list secrets across namespaces.",
+			args: args{
+				allowed: false,
+			},
+			want: want{
+				err: errors.Errorf(errFmtListDenied, "rbac"),
+			},
+		},
+		"SecretSelector": {
+			reason: "We should return the matching secrets from all namespaces, across all pages.",
+			args: args{
+				allowed:        true,
+				secretSelector: labels.SelectorFromSet(app),
+			},
+			want: want{
+				out: map[store.ScopedName]store.KeyValues{
+					{Scope: "a", Name: "db"}: {"key": []byte("a/db")},
+					{Scope: "b", Name: "db"}: {"key": []byte("b/db")},
+					{Scope: "c", Name: "db"}: {"key": []byte("c/db")},
+				},
+			},
+		},
+		"SecretAndNamespaceSelector": {
+			reason: "We should return the matching secrets from the matching namespaces only.",
+			args: args{
+				allowed:        true,
+				secretSelector: labels.SelectorFromSet(app),
+				nsSelector:     labels.SelectorFromSet(prod),
+			},
+			want: want{
+				out: map[store.ScopedName]store.KeyValues{
+					{Scope: "a", Name: "db"}: {"key": []byte("a/db")},
+					{Scope: "c", Name: "db"}: {"key": []byte("c/db")},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ss := &SecretStore{client: resource.ClientApplicator{Client: listClient(tc.args.allowed, secrets, namespaces)}}
+			out, err := ss.ReadAcrossNamespaces(context.Background(), tc.args.secretSelector, tc.args.nsSelector)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nss.ReadAcrossNamespaces(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.out, out); diff != "" {
+				t.Errorf("\n%s\nss.ReadAcrossNamespaces(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSecretStoreListSecrets(t *testing.T) {
+	ss := &SecretStore{
+		defaultNamespace: "default",
+		client: resource.ClientApplicator{Client: &test.MockClient{
+			MockList: func(_ context.Context, obj client.ObjectList, opts ...client.ListOption) error {
+				lo := &client.ListOptions{}
+				lo.ApplyOptions(opts)
+				if lo.Namespace != "default" {
+					t.Errorf("ss.ListSecrets(...): want secrets listed in the default namespace, got %q", lo.Namespace)
+				}
+				if got := lo.FieldSelector.String(); got != "type="+string(resource.SecretTypeConnection) {
+					t.Errorf("ss.ListSecrets(...): want only connection secrets listed, got field selector %q", got)
+				}
+				obj.(*corev1.SecretList).Items = []corev1.Secret{secretIn("default", "a", nil), secretIn("default", "b", nil)}
+				return nil
+			},
+		}},
+	}
+
+	got, err := ss.ListSecrets(context.Background(), "")
+	if err != nil {
+		t.Fatalf("ss.ListSecrets(...): %v", err)
+	}
+	if diff := cmp.Diff([]string{"a", "b"}, got); diff != "" {
+		t.Errorf("ss.ListSecrets(...): -want, +got:\n%s", diff)
+	}
+}
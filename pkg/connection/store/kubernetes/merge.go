@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// WithMergeWrites configures a SecretStore to merge the key values it writes
+// into the existing data of a secret, rather than replacing it. This allows
+// several writers to publish distinct keys to the same secret, for example to
+// aggregate the connection details of the resources of a composite, without
+// one writer's keys clobbering another's. Writing keys that are already
+// present updates their values, and writes are considered no-ops if the
+// merged data is identical to the existing data.
+//
+// Merging never removes keys. Writers that stop publishing a key must delete
+// it using DeleteKeyValues. Secrets are replaced by default.
+func WithMergeWrites() SecretStoreOption {
+	return func(ss *SecretStore) {
+		ss.merge = true
+	}
+}
+
+// WithProtectedKeys configures a SecretStore to never overwrite the supplied
+// keys of an existing secret. Writes may add a protected key to a secret that
+// doesn't have it yet, for example to seed a password, but once present its
+// value can only be changed out-of-band or by deleting it. Protected keys
+// always win over incoming values, including when merging writes, so an
+// incoming value for a protected key is ignored while other keys are merged
+// or replaced as usual. Replacing a secret's data keeps its protected keys.
+func WithProtectedKeys(keys ...string) SecretStoreOption {
+	return func(ss *SecretStore) {
+		ss.protected = make(map[string]bool, len(keys))
+		for _, k := range keys {
+			ss.protected[k] = true
+		}
+	}
+}
+
+// mergeData is an ApplyOption that merges the data of the desired secret into
+// the data of the current secret, when the SecretStore is configured
+// WithMergeWrites.
+func (ss *SecretStore) mergeData(_ context.Context, current, desired runtime.Object) error {
+	if !ss.merge {
+		return nil
+	}
+	cs := current.(*corev1.Secret) //nolint:forcetypeassert // Will always be a secret.
+	ds := desired.(*corev1.Secret) //nolint:forcetypeassert // Will always be a secret.
+
+	data := make(map[string][]byte, len(cs.Data)+len(ds.Data))
+	for k, v := range cs.Data {
+		data[k] = v
+	}
+	for k, v := range ds.Data {
+		data[k] = v
+	}
+	ds.Data = data
+	return nil
+}
+
+// protectKeys is an ApplyOption that keeps the current values of any protected
+// keys of the current secret. It must run after mergeData, so that protected
+// keys win over merged values.
+func (ss *SecretStore) protectKeys(_ context.Context, current, desired runtime.Object) error {
+	if len(ss.protected) == 0 {
+		return nil
+	}
+	cs := current.(*corev1.Secret) //nolint:forcetypeassert // Will always be a secret.
+	ds := desired.(*corev1.Secret) //nolint:forcetypeassert // Will always be a secret.
+
+	for k := range ss.protected {
+		v, ok := cs.Data[k]
+		if !ok {
+			continue
+		}
+		if ds.Data == nil {
+			ds.Data = map[string][]byte{}
+		}
+		ds.Data[k] = v
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+func TestSecretStoreWriteKeyValuesMerge(t *testing.T) {
+	current := map[string][]byte{"theirs": []byte("a"), "mine": []byte("b")}
+
+	type args struct {
+		merge     bool
+		protected []string
+		data      store.KeyValues
+	}
+	type want struct {
+		changed bool
+		data    map[string][]byte
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ReplaceByDefault": {
+			reason: "We should replace the data of the secret by default.",
+			args: args{
+				data: store.KeyValues{"mine": []byte("c")},
+			},
+			want: want{
+				changed: true,
+				data:    map[string][]byte{"mine": []byte("c")},
+			},
+		},
+		"MergeNewKey": {
+			reason: "We should keep other writers' keys when merging a new key.",
+			args: args{
+				merge: true,
+				data:  store.KeyValues{"new": []byte("c")},
+			},
+			want: want{
+				changed: true,
+				data:    map[string][]byte{"theirs": []byte("a"), "mine": []byte("b"), "new": []byte("c")},
+			},
+		},
+		"MergeChangedValue": {
+			reason: "We should update the value of a key that changed when merging.",
+			args: args{
+				merge: true,
+				data:  store.KeyValues{"mine": []byte("c")},
+			},
+			want: want{
+				changed: true,
+				data:    map[string][]byte{"theirs": []byte("a"), "mine": []byte("c")},
+			},
+		},
+		"MergeIdentical": {
+			reason: "We should not write the secret if the merged data is identical to the existing data.",
+			args: args{
+				merge: true,
+				data:  store.KeyValues{"mine": []byte("b")},
+			},
+			want: want{
+				changed: false,
+			},
+		},
+		"MergeDoesNotRemoveKeys": {
+			reason: "We should not remove keys the writer no longer publishes when merging.",
+			args: args{
+				merge: true,
+				data:  store.KeyValues{"other": []byte("c")},
+			},
+			want: want{
+				changed: true,
+				data:    map[string][]byte{"theirs": []byte("a"), "mine": []byte("b"), "other": []byte("c")},
+			},
+		},
+		"MergeProtectedKey": {
+			reason: "We should ignore an incoming value for a protected key, while merging other keys as usual.",
+			args: args{
+				merge:     true,
+				protected: []string{"theirs"},
+				data:      store.KeyValues{"theirs": []byte("c"), "mine": []byte("d")},
+			},
+			want: want{
+				changed: true,
+				data:    map[string][]byte{"theirs": []byte("a"), "mine": []byte("d")},
+			},
+		},
+		"MergeOnlyProtectedKey": {
+			reason: "We should not write the secret if only the value of a protected key changed.",
+			args: args{
+				merge:     true,
+				protected: []string{"theirs"},
+				data:      store.KeyValues{"theirs": []byte("c")},
+			},
+			want: want{
+				changed: false,
+			},
+		},
+		"MergeNewProtectedKey": {
+			reason: "We should write a protected key that the secret doesn't have yet.",
+			args: args{
+				merge:     true,
+				protected: []string{"seed"},
+				data:      store.KeyValues{"seed": []byte("c")},
+			},
+			want: want{
+				changed: true,
+				data:    map[string][]byte{"theirs": []byte("a"), "mine": []byte("b"), "seed": []byte("c")},
+			},
+		},
+		"ReplaceKeepsProtectedKey": {
+			reason: "We should keep protected keys when replacing the data of the secret.",
+			args: args{
+				protected: []string{"theirs"},
+				data:      store.KeyValues{"mine": []byte("c")},
+			},
+			want: want{
+				changed: true,
+				data:    map[string][]byte{"theirs": []byte("a"), "mine": []byte("c")},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var written map[string][]byte
+			ss := &SecretStore{
+				client: resource.ClientApplicator{
+					Applicator: resource.ApplyFn(func(ctx context.Context, obj client.Object, ao ...resource.ApplyOption) error {
+						c := fakeConnectionSecret(withData(current))
+						for _, fn := range ao {
+							if err := fn(ctx, c, obj); err != nil {
+								return err
+							}
+						}
+						written = obj.(*corev1.Secret).Data
+						return nil
+					}),
+				},
+			}
+			if tc.args.merge {
+				WithMergeWrites()(ss)
+			}
+			if tc.args.protected != nil {
+				WithProtectedKeys(tc.args.protected...)(ss)
+			}
+
+			changed, err := ss.WriteKeyValues(context.Background(), &store.Secret{
+				ScopedName: store.ScopedName{Name: fakeSecretName, Scope: fakeSecretNamespace},
+				Data:       tc.args.data,
+			})
+			if err != nil {
+				t.Fatalf("\n%s\nss.WriteKeyValues(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.changed, changed); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want changed, +got changed:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.data, written); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want written data, +got written data:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// WithCacheReader configures a SecretStore that stores secrets on the local
+// Kubernetes API server to read secrets using the supplied reader, typically
+// the cache of a controller-runtime manager, rather than getting them from the
+// API server. Writes that the cache shows would be no-ops return early without
+// making any API calls, so that reconciling many owners whose secrets are up
+// to date doesn't produce constant API traffic. Reads may be stale until the
+// cache catches up with a write. The reader is ignored by a SecretStore that
+// stores secrets on a remote Kubernetes API server, which it doesn't cache.
+func WithCacheReader(c client.Reader) SecretStoreOption {
+	return func(ss *SecretStore) {
+		ss.cache = c
+	}
+}
+
+// cachedReader returns the cache reader of the SecretStore, if any.
+func (ss *SecretStore) cachedReader() (client.Reader, bool) {
+	return ss.cache, ss.cache != nil && !ss.remote
+}
+
+// upToDate returns true if the cache of the SecretStore shows the supplied
+// desired secret is already up to date, in which case writing it would be a
+// no-op. The supplied write options are applied like they would be by the
+// write, so that options that change the desired secret are accounted for. It
+// returns false if the SecretStore has no cache, if it can't tell, or if any
+// option would reject the write, in which case the secret should be written as
+// usual.
+func (ss *SecretStore) upToDate(ctx context.Context, desired *corev1.Secret, wo ...store.WriteOption) bool {
+	c, ok := ss.cachedReader()
+	if !ok {
+		return false
+	}
+	current := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Name: desired.GetName(), Namespace: desired.GetNamespace()}, current); err != nil {
+		return false
+	}
+	if current.GetDeletionTimestamp() != nil {
+		return false
+	}
+
+	// The cache owns the current secret, so we must not mutate it. Write
+	// options may mutate either secret, so they're applied to copies.
+	cc, d := current.DeepCopy(), desired.DeepCopy()
+	ao := append([]resource.ApplyOption{ss.adoptRenamedOwners, ss.refuseForeignOwner}, applyOptions(wo...)...)
+	ao = append(ao, ss.mergeData, ss.protectKeys)
+	for _, fn := range ao {
+		if err := fn(ctx, cc, d); err != nil {
+			return false
+		}
+	}
+	return !ss.needsUpdate(current, d)
+}
+
+// needsUpdate returns true if the supplied current secret differs from the
+// supplied desired secret. An update is a no-op unless the current secret's
+// data or type differs, or it is missing our finalizer or any of our owner
+// references, labels, or annotations.
+func (ss *SecretStore) needsUpdate(current, desired runtime.Object) bool {
+	c := current.(*corev1.Secret) //nolint:forcetypeassert // Will always be a secret.
+	d := desired.(*corev1.Secret) //nolint:forcetypeassert // Will always be a secret.
+	if ss.finalizer && !controllerutil.ContainsFinalizer(c, SecretFinalizer) {
+		return true
+	}
+	if missingMetadata(c, d) || missingOwnerReferences(c, d) {
+		return true
+	}
+	if c.Type != d.Type {
+		return true
+	}
+	return !store.Equal(c.Data, d.Data)
+}
+
+// missingOwnerReferences returns true if the supplied current secret lacks
+// any of the owner references of the supplied desired secret, or has a
+// different reference to any of their owners.
+func missingOwnerReferences(current, desired *corev1.Secret) bool {
+	for _, want := range desired.GetOwnerReferences() {
+		if !hasOwnerReference(current.GetOwnerReferences(), want) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasOwnerReference(refs []metav1.OwnerReference, want metav1.OwnerReference) bool {
+	for _, ref := range refs {
+		if ref.UID != want.UID {
+			continue
+		}
+		return ref.APIVersion == want.APIVersion &&
+			ref.Kind == want.Kind &&
+			ref.Name == want.Name &&
+			ptr.Deref(ref.Controller, false) == ptr.Deref(want.Controller, false) &&
+			ptr.Deref(ref.BlockOwnerDeletion, false) == ptr.Deref(want.BlockOwnerDeletion, false)
+	}
+	return false
+}
@@ -0,0 +1,267 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestSecretStoreNeedsUpdate(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		current *corev1.Secret
+		want    bool
+	}{
+		"UpToDate": {
+			reason:  "We should not update a secret whose data, type, owner references, and metadata match.",
+			current: fakeConnectionSecret(withOwner(), withData(fakeKV())),
+			want:    false,
+		},
+		"ExtraOwnerReference": {
+			reason: "We should not update a secret that has other owner references in addition to ours.",
+			current: func() *corev1.Secret {
+				s := fakeConnectionSecret(withOwner(), withData(fakeKV()))
+				s.SetOwnerReferences(append(s.GetOwnerReferences(), metav1.OwnerReference{APIVersion: "v1", Kind: "ConfigMap", Name: "other", UID: "other"}))
+				return s
+			}(),
+			want: false,
+		},
+		"MissingOwnerReference": {
+			reason:  "We should update a secret that lacks our owner reference.",
+			current: fakeConnectionSecret(withData(fakeKV())),
+			want:    true,
+		},
+		"DifferentOwnerReference": {
+			reason: "We should update a secret whose reference to its owner differs from ours.",
+			current: func() *corev1.Secret {
+				s := fakeConnectionSecret(withOwner(), withData(fakeKV()))
+				s.OwnerReferences[0].Controller = ptr.To(false)
+				return s
+			}(),
+			want: true,
+		},
+		"DifferentType": {
+			reason: "We should update a secret whose type differs.",
+			current: func() *corev1.Secret {
+				s := fakeConnectionSecret(withOwner(), withData(fakeKV()))
+				s.Type = corev1.SecretTypeOpaque
+				return s
+			}(),
+			want: true,
+		},
+		"DifferentData": {
+			reason:  "We should update a secret whose data differs.",
+			current: fakeConnectionSecret(withOwner(), withData(map[string][]byte{"key": []byte("old")})),
+			want:    true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ss := &SecretStore{}
+			got := ss.needsUpdate(tc.current, fakeConnectionSecret(withOwner(), withData(fakeKV())))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nss.needsUpdate(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSecretStoreWriteKeyValuesCached(t *testing.T) {
+	errNotFound := kerrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, fakeSecretName)
+
+	type args struct {
+		cached *corev1.Secret
+		remote bool
+		wo     []store.WriteOption
+	}
+	type want struct {
+		changed bool
+		applied bool
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"CachedUpToDateMutatingOption": {
+			reason: "We should apply the secret if a write option changes the desired secret so that the cache shows it is out of date.",
+			args: args{
+				cached: fakeConnectionSecret(withOwner(), withData(fakeKV())),
+				wo: []store.WriteOption{func(_ context.Context, _, desired *store.Secret) error {
+					desired.Metadata.Annotations = map[string]string{"cool": "very"}
+					return nil
+				}},
+			},
+			want: want{
+				changed: true,
+				applied: true,
+			},
+		},
+		"CachedUpToDateRejectingOption": {
+			reason: "We should apply the secret, so that the write returns the error, if a write option rejects it.",
+			args: args{
+				cached: fakeConnectionSecret(withOwner(), withData(fakeKV())),
+				wo: []store.WriteOption{func(_ context.Context, _, _ *store.Secret) error {
+					return errBoom
+				}},
+			},
+			want: want{
+				changed: true,
+				applied: true,
+			},
+		},
+		"CachedUpToDate": {
+			reason: "We should return early without applying the secret if the cache shows it is up to date.",
+			args: args{
+				cached: fakeConnectionSecret(withOwner(), withData(fakeKV())),
+			},
+			want: want{
+				changed: false,
+			},
+		},
+		"CachedOutOfDate": {
+			reason: "We should apply the secret if the cache shows it is out of date.",
+			args: args{
+				cached: fakeConnectionSecret(withOwner(), withData(map[string][]byte{"key": []byte("old")})),
+			},
+			want: want{
+				changed: true,
+				applied: true,
+			},
+		},
+		"NotCached": {
+			reason: "We should apply the secret if it is not in the cache.",
+			want: want{
+				changed: true,
+				applied: true,
+			},
+		},
+		"CachedTerminating": {
+			reason: "We should apply the secret if the cache shows it is being deleted, so that the write handles the deletion.",
+			args: args{
+				cached: func() *corev1.Secret {
+					now := metav1.Now()
+					s := fakeConnectionSecret(withOwner(), withData(fakeKV()))
+					s.SetDeletionTimestamp(&now)
+					return s
+				}(),
+			},
+			want: want{
+				changed: true,
+				applied: true,
+			},
+		},
+		"Remote": {
+			reason: "We should ignore the cache of a SecretStore that stores secrets on a remote API server.",
+			args: args{
+				cached: fakeConnectionSecret(withOwner(), withData(fakeKV())),
+				remote: true,
+			},
+			want: want{
+				changed: true,
+				applied: true,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cache := &test.MockClient{
+				MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+					if tc.args.cached == nil {
+						return errNotFound
+					}
+					*obj.(*corev1.Secret) = *tc.args.cached.DeepCopy()
+					return nil
+				},
+			}
+			applied := false
+			ss := &SecretStore{
+				client: resource.ClientApplicator{
+					Applicator: resource.ApplyFn(func(_ context.Context, _ client.Object, _ ...resource.ApplyOption) error {
+						applied = true
+						return nil
+					}),
+				},
+				remote:    tc.args.remote,
+				ownerRefs: OwnerReferencePolicyRequireSameNamespace,
+				log:       logging.NewNopLogger(),
+				recorder:  event.NewNopRecorder(),
+				unowned:   newRemoteTargets(),
+			}
+			WithCacheReader(cache)(ss)
+
+			changed, err := ss.WriteKeyValues(context.Background(), &store.Secret{
+				ScopedName: store.ScopedName{Name: fakeSecretName, Scope: fakeSecretNamespace},
+				Data:       fakeKV(),
+				Owner: &store.Owner{
+					TypedReference: v1.TypedReference{APIVersion: "example.org/v1", Kind: "Cool", Name: "cool", UID: types.UID(fakeOwnerID)},
+				},
+			}, tc.args.wo...)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.changed, changed); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want changed, +got changed:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.applied, applied); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want applied, +got applied:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSecretStoreReadKeyValuesCached(t *testing.T) {
+	live := &test.MockClient{MockGet: test.NewMockGetFn(errors.New("should read from the cache"))}
+	cache := &test.MockClient{
+		MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+			*obj.(*corev1.Secret) = *fakeConnectionSecret(withData(fakeKV()))
+			return nil
+		},
+	}
+	ss := &SecretStore{client: resource.ClientApplicator{Client: live}}
+	WithCacheReader(cache)(ss)
+
+	s := &store.Secret{}
+	if err := ss.ReadKeyValues(context.Background(), store.ScopedName{Name: fakeSecretName, Scope: fakeSecretNamespace}, s); err != nil {
+		t.Fatalf("ss.ReadKeyValues(...): %v", err)
+	}
+	if diff := cmp.Diff(store.KeyValues(fakeKV()), s.Data); diff != "" {
+		t.Errorf("ss.ReadKeyValues(...): -want, +got:\n%s", diff)
+	}
+}
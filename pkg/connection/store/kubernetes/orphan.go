@@ -0,0 +1,118 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errOrphanSecret = "cannot orphan secret"
+)
+
+// ownerLabels are the labels setOwnerMetadata stamps on a secret.
+var ownerLabels = []string{
+	v1.LabelKeyOwnerUID,
+	LabelKeyOwnerGroup,
+	LabelKeyOwnerVersion,
+	LabelKeyOwnerKind,
+	LabelKeyOwnerNamespace,
+	LabelKeyOwnerName,
+	LabelKeyOwnerIdentity,
+}
+
+// ownerAnnotations are the annotations setOwnerMetadata stamps on a secret.
+var ownerAnnotations = []string{
+	AnnotationKeyOwnerKind,
+	AnnotationKeyOwnerName,
+}
+
+// OrphanKeyValues releases the supplied secret from the supplied secret's
+// owner, so that it is no longer deleted along with its owner, by owner
+// references or by CollectGarbage. Its owner labels and annotations and its
+// owner reference to the owner are removed. Its key values are left as is. A
+// secret that does not exist, or that is labelled as owned by another owner,
+// is left as is.
+func (ss *SecretStore) OrphanKeyValues(ctx context.Context, s *store.Secret) error {
+	if s.Owner == nil || s.Owner.UID == "" {
+		return nil
+	}
+
+	ks := &corev1.Secret{}
+	err := ss.client.Get(ctx, types.NamespacedName{Name: s.Name, Namespace: ss.namespaceForSecret(s.ScopedName)}, ks)
+	if kerrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(withUnauthenticated(withRBACHint(err)), errGetSecret)
+	}
+
+	if l, ok := ks.GetLabels()[v1.LabelKeyOwnerUID]; ok && l != string(s.Owner.UID) {
+		return nil
+	}
+
+	if !releaseOwner(ks, s.Owner.UID) {
+		return nil
+	}
+	return errors.Wrap(withUnauthenticated(withRBACHint(ss.client.Update(ctx, ks))), errOrphanSecret)
+}
+
+// releaseOwner removes the owner reference to the owner with the supplied UID
+// from the supplied secret. The secret's owner labels and annotations are
+// removed too, unless they describe another owner. It returns true if the
+// secret was changed.
+func releaseOwner(ks *corev1.Secret, uid types.UID) bool {
+	changed := false
+	if l, ok := ks.GetLabels()[v1.LabelKeyOwnerUID]; !ok || l == string(uid) {
+		labels := ks.GetLabels()
+		for _, k := range ownerLabels {
+			if _, ok := labels[k]; ok {
+				delete(labels, k)
+				changed = true
+			}
+		}
+		annotations := ks.GetAnnotations()
+		for _, k := range ownerAnnotations {
+			if _, ok := annotations[k]; ok {
+				delete(annotations, k)
+				changed = true
+			}
+		}
+		ks.SetLabels(labels)
+		ks.SetAnnotations(annotations)
+	}
+	refs := make([]metav1.OwnerReference, 0, len(ks.GetOwnerReferences()))
+	for _, ref := range ks.GetOwnerReferences() {
+		if ref.UID == uid {
+			changed = true
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	ks.SetOwnerReferences(refs)
+	return changed
+}
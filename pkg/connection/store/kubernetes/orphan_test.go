@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestSecretStoreOrphanKeyValues(t *testing.T) {
+	owner := &store.Owner{TypedReference: v1.TypedReference{UID: types.UID(fakeOwnerID)}}
+	ownedLabels := map[string]string{
+		v1.LabelKeyOwnerUID:  fakeOwnerID,
+		LabelKeyOwnerName:    "cool-owner",
+		"environment":        "prod",
+		LabelKeyOwnerKind:    "Cool",
+		LabelKeyOwnerVersion: "v1",
+	}
+
+	type want struct {
+		updated *corev1.Secret
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		owner  *store.Owner
+		get    test.MockGetFn
+		update error
+		want   want
+	}{
+		"Orphaned": {
+			reason: "We should remove the owner labels and the owner reference of the supplied owner, and leave the rest as is.",
+			owner:  owner,
+			get: test.NewMockGetFn(nil, func(obj client.Object) error {
+				s := fakeConnectionSecret(withData(fakeKV()), withLabels(ownedLabels))
+				s.SetOwnerReferences([]metav1.OwnerReference{{UID: types.UID(fakeOwnerID)}, {UID: "other"}})
+				*obj.(*corev1.Secret) = *s
+				return nil
+			}),
+			want: want{
+				updated: func() *corev1.Secret {
+					s := fakeConnectionSecret(withData(fakeKV()), withLabels(map[string]string{"environment": "prod"}))
+					s.SetOwnerReferences([]metav1.OwnerReference{{UID: "other"}})
+					return s
+				}(),
+			},
+		},
+		"ForeignOwner": {
+			reason: "We should leave a secret labelled as owned by another owner as is.",
+			owner:  owner,
+			get: test.NewMockGetFn(nil, func(obj client.Object) error {
+				*obj.(*corev1.Secret) = *fakeConnectionSecret(withLabels(map[string]string{v1.LabelKeyOwnerUID: "other"}), withOwnerID(fakeOwnerID))
+				return nil
+			}),
+		},
+		"AlreadyOrphaned": {
+			reason: "We should not update a secret that isn't owned by the supplied owner.",
+			owner:  owner,
+			get: test.NewMockGetFn(nil, func(obj client.Object) error {
+				*obj.(*corev1.Secret) = *fakeConnectionSecret(withData(fakeKV()))
+				return nil
+			}),
+		},
+		"NotFound": {
+			reason: "We should not return an error if the secret does not exist.",
+			owner:  owner,
+			get:    test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, fakeSecretName)),
+		},
+		"NoOwner": {
+			reason: "We should do nothing if the supplied secret has no owner.",
+		},
+		"CannotUpdate": {
+			reason: "We should return an error if the secret can't be updated.",
+			owner:  owner,
+			get: test.NewMockGetFn(nil, func(obj client.Object) error {
+				*obj.(*corev1.Secret) = *fakeConnectionSecret(withOwnerID(fakeOwnerID))
+				return nil
+			}),
+			update: errBoom,
+			want: want{
+				updated: fakeConnectionSecret(func(s *corev1.Secret) { s.OwnerReferences = []metav1.OwnerReference{} }),
+				err:     errors.Wrap(errBoom, errOrphanSecret),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var updated *corev1.Secret
+			ss := &SecretStore{
+				client: resource.ClientApplicator{
+					Client: &test.MockClient{
+						MockGet: tc.get,
+						MockUpdate: func(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+							updated = obj.(*corev1.Secret).DeepCopy()
+							return tc.update
+						},
+					},
+				},
+			}
+
+			err := ss.OrphanKeyValues(context.Background(), &store.Secret{ScopedName: store.ScopedName{Name: fakeSecretName, Scope: fakeSecretNamespace}, Owner: tc.owner})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nss.OrphanKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.updated, updated); diff != "" {
+				t.Errorf("\n%s\nss.OrphanKeyValues(...): -want updated, +got updated:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
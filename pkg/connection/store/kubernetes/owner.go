@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errFmtDeleteOwnedSecret  = "cannot delete secret %q in namespace %q"
+	errFmtReleaseOwnedSecret = "cannot release secret %q in namespace %q from its owner"
+)
+
+// DeleteAllForOwner deletes every secret owned by the supplied owner, whether
+// it is labelled with the owner's UID or references the owner. Secrets
+// labelled with the owner's UID are found in all namespaces, while secrets
+// that only reference a namespaced owner are found in the owner's namespace.
+// Only secrets the owner controls, or that have no other owners, are deleted.
+// Other secrets are released from the owner like OrphanKeyValues does, so that
+// their other owners keep them. Secrets that no longer exist are considered
+// deleted. Errors deleting or releasing individual secrets don't stop the
+// remaining secrets from being deleted; they are returned together.
+func (ss *SecretStore) DeleteAllForOwner(ctx context.Context, o *store.Owner) error {
+	owned := map[types.NamespacedName]*corev1.Secret{}
+	collect := func(l *corev1.SecretList) {
+		for i := range l.Items {
+			s := &l.Items[i]
+			if ownedBy(s, o.UID) {
+				owned[types.NamespacedName{Namespace: s.GetNamespace(), Name: s.GetName()}] = s.DeepCopy()
+			}
+		}
+	}
+
+	if err := listPages(ctx, ss.client, &corev1.SecretList{}, []client.ListOption{client.MatchingLabels{v1.LabelKeyOwnerUID: string(o.UID)}}, collect); err != nil {
+		return errors.Wrap(withUnauthenticated(withRBACHint(err)), errListSecrets)
+	}
+	if o.Namespace != "" {
+		if err := listPages(ctx, ss.client, &corev1.SecretList{}, []client.ListOption{client.InNamespace(o.Namespace)}, collect); err != nil {
+			return errors.Wrap(withUnauthenticated(withRBACHint(err)), errListSecrets)
+		}
+	}
+
+	// Delete in a deterministic order so errors are stable.
+	names := make([]types.NamespacedName, 0, len(owned))
+	for n := range owned {
+		names = append(names, n)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i].String() < names[j].String() })
+
+	errs := make([]error, 0, len(names))
+	for _, n := range names {
+		s := owned[n]
+		if !soleOwner(s, o.UID) {
+			if !releaseOwner(s, o.UID) {
+				continue
+			}
+			if err := ss.client.Update(ctx, s); err != nil && !kerrors.IsNotFound(err) {
+				errs = append(errs, errors.Wrapf(withUnauthenticated(withRBACHint(err)), errFmtReleaseOwnedSecret, n.Name, n.Namespace))
+			}
+			continue
+		}
+		if err := ss.client.Delete(ctx, s); err != nil && !kerrors.IsNotFound(err) {
+			errs = append(errs, errors.Wrapf(withUnauthenticated(withRBACHint(err)), errFmtDeleteOwnedSecret, n.Name, n.Namespace))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// soleOwner returns true if the owner with the supplied UID is the controller
+// of the supplied secret, or if the secret is neither labelled with nor
+// references another owner.
+func soleOwner(s *corev1.Secret, uid types.UID) bool {
+	for _, ref := range s.GetOwnerReferences() {
+		if ref.UID == uid && ptr.Deref(ref.Controller, false) {
+			return true
+		}
+	}
+	if l, ok := s.GetLabels()[v1.LabelKeyOwnerUID]; ok && l != string(uid) {
+		return false
+	}
+	for _, ref := range s.GetOwnerReferences() {
+		if ref.UID != uid {
+			return false
+		}
+	}
+	return true
+}
+
+func ownedBy(s *corev1.Secret, uid types.UID) bool {
+	if s.GetLabels()[v1.LabelKeyOwnerUID] == string(uid) {
+		return true
+	}
+	for _, ref := range s.GetOwnerReferences() {
+		if ref.UID == uid {
+			return true
+		}
+	}
+	return false
+}
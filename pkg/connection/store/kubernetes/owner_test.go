@@ -0,0 +1,163 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestSecretStoreDeleteAllForOwner(t *testing.T) {
+	owner := &store.Owner{
+		TypedReference: v1.TypedReference{APIVersion: "example.org/v1", Kind: "Cool", Name: "cool", UID: types.UID(fakeOwnerID)},
+		Namespace:      "owner-ns",
+	}
+
+	labelled := func(ns, name, uid string) corev1.Secret {
+		return corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name, Labels: map[string]string{v1.LabelKeyOwnerUID: uid}}}
+	}
+	referencing := func(ns, name, uid string) corev1.Secret {
+		return corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name, OwnerReferences: []metav1.OwnerReference{{UID: types.UID(uid)}}}}
+	}
+
+	shared := func(ns, name string, controller bool) corev1.Secret {
+		s := labelled(ns, name, fakeOwnerID)
+		s.OwnerReferences = []metav1.OwnerReference{
+			{UID: types.UID(fakeOwnerID), Controller: ptr.To(controller)},
+			{UID: "other-uid"},
+		}
+		return s
+	}
+
+	type want struct {
+		deleted  []string
+		released []corev1.Secret
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason    string
+		secrets   []corev1.Secret
+		deleteErr map[string]error
+		want      want
+	}{
+		"OnlyOwnersSecrets": {
+			reason: "We should delete only the secrets owned by the owner, by label or reference.",
+			secrets: []corev1.Secret{
+				labelled("other-ns", "labelled", fakeOwnerID),
+				referencing("owner-ns", "referencing", fakeOwnerID),
+				labelled("owner-ns", "someone-elses", "other-uid"),
+				referencing("owner-ns", "someone-elses-ref", "other-uid"),
+			},
+			want: want{
+				deleted: []string{"other-ns/labelled", "owner-ns/referencing"},
+			},
+		},
+		"SharedSecrets": {
+			reason: "We should delete shared secrets the owner controls, and release the owner from other shared secrets.",
+			secrets: []corev1.Secret{
+				shared("owner-ns", "controlled", true),
+				shared("owner-ns", "shared", false),
+				{ObjectMeta: metav1.ObjectMeta{Namespace: "owner-ns", Name: "someone-elses-label", Labels: map[string]string{v1.LabelKeyOwnerUID: "other-uid"}, OwnerReferences: []metav1.OwnerReference{{UID: types.UID(fakeOwnerID)}}}},
+			},
+			want: want{
+				deleted: []string{"owner-ns/controlled"},
+				released: []corev1.Secret{
+					{ObjectMeta: metav1.ObjectMeta{Namespace: "owner-ns", Name: "shared", Labels: map[string]string{}, OwnerReferences: []metav1.OwnerReference{{UID: "other-uid"}}}},
+					{ObjectMeta: metav1.ObjectMeta{Namespace: "owner-ns", Name: "someone-elses-label", Labels: map[string]string{v1.LabelKeyOwnerUID: "other-uid"}, OwnerReferences: []metav1.OwnerReference{}}},
+				},
+			},
+		},
+		"AlreadyAbsent": {
+			reason: "We should treat secrets that no longer exist as deleted.",
+			secrets: []corev1.Secret{
+				labelled("owner-ns", "gone", fakeOwnerID),
+			},
+			deleteErr: map[string]error{"gone": kerrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, "gone")},
+			want: want{
+				deleted: []string{"owner-ns/gone"},
+			},
+		},
+		"AggregateErrors": {
+			reason: "We should keep deleting secrets after an error, and return all errors.",
+			secrets: []corev1.Secret{
+				labelled("owner-ns", "a", fakeOwnerID),
+				labelled("owner-ns", "b", fakeOwnerID),
+				labelled("owner-ns", "c", fakeOwnerID),
+			},
+			deleteErr: map[string]error{"a": errBoom, "c": errBoom},
+			want: want{
+				deleted: []string{"owner-ns/a", "owner-ns/b", "owner-ns/c"},
+				err: errors.Join(
+					errors.Wrapf(errBoom, errFmtDeleteOwnedSecret, "a", "owner-ns"),
+					errors.Wrapf(errBoom, errFmtDeleteOwnedSecret, "c", "owner-ns"),
+				),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var deleted []string
+			var released []corev1.Secret
+			ss := &SecretStore{
+				client: resource.ClientApplicator{
+					Client: &test.MockClient{
+						MockList: func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+							obj.(*corev1.SecretList).Items = tc.secrets
+							return nil
+						},
+						MockDelete: func(_ context.Context, obj client.Object, _ ...client.DeleteOption) error {
+							deleted = append(deleted, obj.GetNamespace()+"/"+obj.GetName())
+							return tc.deleteErr[obj.GetName()]
+						},
+						MockUpdate: func(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+							released = append(released, *obj.(*corev1.Secret))
+							return nil
+						},
+					},
+				},
+			}
+
+			err := ss.DeleteAllForOwner(context.Background(), owner)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nss.DeleteAllForOwner(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.deleted, deleted); diff != "" {
+				t.Errorf("\n%s\nss.DeleteAllForOwner(...): -want deleted, +got deleted:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.released, released); diff != "" {
+				t.Errorf("\n%s\nss.DeleteAllForOwner(...): -want released, +got released:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DefaultOwnerReferenceWarningThreshold is the default number of owner
+// references a secret may have before a SecretStore warns about it.
+const DefaultOwnerReferenceWarningThreshold = 100
+
+// WithOwnerReferenceWarningThreshold configures a SecretStore to warn when it
+// writes a secret that has more than the supplied number of owner references.
+// Secrets that are co-owned by many resources accumulate owner references,
+// each of which must be scanned when the secret is written and garbage
+// collected. DefaultOwnerReferenceWarningThreshold is used by default. A
+// threshold of zero or less disables the warning.
+func WithOwnerReferenceWarningThreshold(n int) SecretStoreOption {
+	return func(ss *SecretStore) {
+		ss.ownerRefWarningThreshold = &n
+	}
+}
+
+// warnManyOwnerReferences is an ApplyOption that warns if the current secret
+// has more owner references than the SecretStore's warning threshold.
+func (ss *SecretStore) warnManyOwnerReferences(_ context.Context, current, _ runtime.Object) error {
+	threshold := DefaultOwnerReferenceWarningThreshold
+	if ss.ownerRefWarningThreshold != nil {
+		threshold = *ss.ownerRefWarningThreshold
+	}
+	cs := current.(*corev1.Secret) //nolint:forcetypeassert // Will always be a secret.
+	if n := len(cs.GetOwnerReferences()); threshold > 0 && n > threshold {
+		ss.log.Info("Secret has an unusually large number of owner references. Writing and garbage collecting it may be slow.", "secret-name", cs.GetName(), "secret-namespace", cs.GetNamespace(), "owner-references", n, "threshold", threshold)
+	}
+	return nil
+}
+
+// controllerUID returns the UID of the controller of the supplied owner
+// references, or the UID of the first owner reference if none is a controller.
+// It scans the owner references once.
+func controllerUID(refs []metav1.OwnerReference) (types.UID, bool) {
+	for i := range refs {
+		if c := refs[i].Controller; c != nil && *c {
+			return refs[i].UID, true
+		}
+	}
+	if len(refs) > 0 {
+		return refs[0].UID, true
+	}
+	return "", false
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// manyOwnerReferences returns n owner references that are not controllers.
+func manyOwnerReferences(apiVersion string, n int) []metav1.OwnerReference {
+	refs := make([]metav1.OwnerReference, n)
+	for i := range refs {
+		refs[i] = metav1.OwnerReference{
+			APIVersion: apiVersion,
+			Kind:       "Cool",
+			Name:       fmt.Sprintf("cool-%d", i),
+			UID:        types.UID(fmt.Sprintf("uid-%d", i)),
+		}
+	}
+	return refs
+}
+
+func TestSecretStoreWriteKeyValuesManyOwnerReferences(t *testing.T) {
+	const many = 50000
+
+	controller := metav1.OwnerReference{APIVersion: "example.org/v1", Kind: "Cool", Name: "owner", UID: types.UID(fakeOwnerID), Controller: ptr.To(true)}
+
+	type want struct {
+		owner    string
+		warnings int
+	}
+
+	cases := map[string]struct {
+		reason string
+		refs   []metav1.OwnerReference
+		o      []SecretStoreOption
+		want   want
+	}{
+		"ControllerLast": {
+			reason: "We should consider the controller of a secret with many owner references to be its owner, and warn about them.",
+			refs:   append(manyOwnerReferences("example.org/v1", many), controller),
+			want: want{
+				owner:    fakeOwnerID,
+				warnings: 1,
+			},
+		},
+		"NoController": {
+			reason: "We should consider the first owner of a secret without a controller to be its owner.",
+			refs:   manyOwnerReferences("example.org/v1", many),
+			want: want{
+				owner:    "uid-0",
+				warnings: 1,
+			},
+		},
+		"BelowThreshold": {
+			reason: "We should not warn about a secret with few owner references.",
+			refs:   append(manyOwnerReferences("example.org/v1", 3), controller),
+			want: want{
+				owner: fakeOwnerID,
+			},
+		},
+		"WarningDisabled": {
+			reason: "We should not warn about a secret with many owner references if the warning is disabled.",
+			refs:   append(manyOwnerReferences("example.org/v1", many), controller),
+			o:      []SecretStoreOption{WithOwnerReferenceWarningThreshold(0)},
+			want: want{
+				owner: fakeOwnerID,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			warnings := 0
+			ss := &SecretStore{
+				client: resource.ClientApplicator{
+					Applicator: resource.ApplyFn(func(ctx context.Context, obj client.Object, ao ...resource.ApplyOption) error {
+						current := fakeConnectionSecret(withData(fakeKV()))
+						current.SetOwnerReferences(tc.refs)
+						for _, fn := range ao {
+							if err := fn(ctx, current, obj); err != nil {
+								return err
+							}
+						}
+						return nil
+					}),
+				},
+				log: countingLogger{infos: &warnings},
+			}
+			for _, fn := range tc.o {
+				fn(ss)
+			}
+
+			var owner string
+			_, err := ss.WriteKeyValues(context.Background(), &store.Secret{
+				ScopedName: store.ScopedName{Name: fakeSecretName, Scope: fakeSecretNamespace},
+				Data:       store.KeyValues{"key": []byte("new")},
+			}, func(_ context.Context, current, _ *store.Secret) error {
+				owner = current.GetOwner()
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("\n%s\nss.WriteKeyValues(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.owner, owner); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want owner, +got owner:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.warnings, warnings); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want warnings, +got warnings:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestAdoptRenamedOwnersManyOwnerReferences(t *testing.T) {
+	// Adopting this many owner references takes minutes if each current
+	// reference is compared to each desired reference.
+	const many = 50000
+
+	ss := &SecretStore{}
+	WithAPIGroupRenames(map[string]string{"old.example.org": "new.example.org"})(ss)
+
+	current := fakeConnectionSecret()
+	current.SetOwnerReferences(manyOwnerReferences("old.example.org/v1", many))
+	desired := fakeConnectionSecret()
+	desired.SetOwnerReferences(manyOwnerReferences("new.example.org/v1", many))
+
+	if err := ss.adoptRenamedOwners(context.Background(), current, desired); err != nil {
+		t.Fatalf("ss.adoptRenamedOwners(...): %v", err)
+	}
+	if diff := cmp.Diff(desired.GetOwnerReferences(), current.GetOwnerReferences()); diff != "" {
+		t.Errorf("ss.adoptRenamedOwners(...): -want owner references, +got owner references:\n%s", diff)
+	}
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errFmtProtectedTarget = "refusing to write secret %q in namespace %q: it is a protected target"
+)
+
+// WithProtectedTargets configures a SecretStore to refuse writing the supplied
+// secrets, for example to prevent a provider from accidentally overwriting its
+// own credentials secret. A target with an empty name protects all secrets in
+// its namespace, for example the namespace the provider runs in. No targets
+// are protected by default.
+func WithProtectedTargets(targets ...types.NamespacedName) SecretStoreOption {
+	return func(ss *SecretStore) {
+		ss.protectedTargets = targets
+	}
+}
+
+// checkProtectedTarget returns an error if the supplied secret is a protected
+// target.
+func (ss *SecretStore) checkProtectedTarget(nn types.NamespacedName) error {
+	for _, t := range ss.protectedTargets {
+		if t.Namespace != nn.Namespace {
+			continue
+		}
+		if t.Name == "" || t.Name == nn.Name {
+			return errors.Errorf(errFmtProtectedTarget, nn.Name, nn.Namespace)
+		}
+	}
+	return nil
+}
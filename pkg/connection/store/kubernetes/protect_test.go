@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestSecretStoreWriteKeyValuesProtectedTargets(t *testing.T) {
+	type want struct {
+		changed bool
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		targets []types.NamespacedName
+		want    want
+	}{
+		"NoProtectedTargets": {
+			reason: "We should write secrets if no targets are protected.",
+			want: want{
+				changed: true,
+			},
+		},
+		"OtherTarget": {
+			reason: "We should write secrets that are not protected targets.",
+			targets: []types.NamespacedName{
+				{Namespace: fakeSecretNamespace, Name: "provider-creds"},
+				{Namespace: "crossplane-system"},
+			},
+			want: want{
+				changed: true,
+			},
+		},
+		"ProtectedSecret": {
+			reason:  "We should refuse to write a protected secret.",
+			targets: []types.NamespacedName{{Namespace: fakeSecretNamespace, Name: fakeSecretName}},
+			want: want{
+				err: errors.Errorf(errFmtProtectedTarget, fakeSecretName, fakeSecretNamespace),
+			},
+		},
+		"ProtectedNamespace": {
+			reason:  "We should refuse to write any secret in a protected namespace.",
+			targets: []types.NamespacedName{{Namespace: fakeSecretNamespace}},
+			want: want{
+				err: errors.Errorf(errFmtProtectedTarget, fakeSecretName, fakeSecretNamespace),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			applied := false
+			ss := &SecretStore{
+				client: resource.ClientApplicator{
+					Applicator: resource.ApplyFn(func(_ context.Context, _ client.Object, _ ...resource.ApplyOption) error {
+						applied = true
+						return nil
+					}),
+				},
+			}
+			WithProtectedTargets(tc.targets...)(ss)
+
+			changed, err := ss.WriteKeyValues(context.Background(), &store.Secret{
+				ScopedName: store.ScopedName{Name: fakeSecretName, Scope: fakeSecretNamespace},
+				Data:       fakeKV(),
+			})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.changed, changed); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want changed, +got changed:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.changed, applied); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want applied, +got applied:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
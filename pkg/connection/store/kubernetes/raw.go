@@ -0,0 +1,52 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errFmtSecretNotFound = "secret %q not found in namespace %q"
+)
+
+// GetSecret returns the Kubernetes Secret that backs the supplied secret,
+// including fields ReadKeyValues doesn't return, for consumers that need them.
+// The returned Secret is a copy, so it may be mutated. A NotFound error, per
+// store.IsNotFound, is returned if the Secret does not exist. Like
+// ReadKeyValues, the Secret is read using the client carried by the supplied
+// context, if any.
+func (ss *SecretStore) GetSecret(ctx context.Context, n store.ScopedName) (*corev1.Secret, error) {
+	nn := types.NamespacedName{Name: n.Name, Namespace: ss.namespaceForSecret(n)}
+	ks := &corev1.Secret{}
+	err := ss.reader(ctx).Get(ctx, nn, ks)
+	if kerrors.IsNotFound(err) {
+		return nil, store.NewNotFound(errors.Errorf(errFmtSecretNotFound, nn.Name, nn.Namespace).Error())
+	}
+	if err != nil {
+		return nil, errors.Wrap(withUnauthenticated(withRBACHint(err)), errGetSecret)
+	}
+	return ks.DeepCopy(), nil
+}
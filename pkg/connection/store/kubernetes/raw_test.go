@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestSecretStoreGetSecret(t *testing.T) {
+	n := store.ScopedName{Name: fakeSecretName, Scope: fakeSecretNamespace}
+
+	// cached is shared with the client, like an object in an informer cache.
+	cached := &corev1.Secret{
+		Type:      resource.SecretTypeConnection,
+		Immutable: ptr.To(true),
+		Data:      map[string][]byte{"key": []byte("value")},
+	}
+
+	type want struct {
+		secret   *corev1.Secret
+		notFound bool
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason string
+		get    test.MockGetFn
+		want   want
+	}{
+		"Found": {
+			reason: "We should return the full secret.",
+			get: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+				*obj.(*corev1.Secret) = *cached
+				return nil
+			},
+			want: want{
+				secret: cached.DeepCopy(),
+			},
+		},
+		"NotFound": {
+			reason: "We should return a NotFound error if the secret does not exist.",
+			get:    test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, fakeSecretName)),
+			want: want{
+				notFound: true,
+				err:      store.NewNotFound(errors.Errorf(errFmtSecretNotFound, fakeSecretName, fakeSecretNamespace).Error()),
+			},
+		},
+		"GetError": {
+			reason: "We should return other errors.",
+			get:    test.NewMockGetFn(errBoom),
+			want: want{
+				err: errors.Wrap(errBoom, errGetSecret),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ss := &SecretStore{client: resource.ClientApplicator{Client: &test.MockClient{MockGet: tc.get}}}
+			got, err := ss.GetSecret(context.Background(), n)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nss.GetSecret(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.notFound, store.IsNotFound(err)); diff != "" {
+				t.Errorf("\n%s\nstore.IsNotFound(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.secret, got); diff != "" {
+				t.Errorf("\n%s\nss.GetSecret(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if got == nil {
+				return
+			}
+
+			// Mutating the returned secret must not mutate the cached one.
+			got.Data["key"] = []byte("mutated")
+			if diff := cmp.Diff([]byte("value"), cached.Data["key"]); diff != "" {
+				t.Errorf("\n%s\nss.GetSecret(...): expected a copy, the cached secret changed: -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
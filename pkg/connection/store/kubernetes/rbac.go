@@ -0,0 +1,67 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"regexp"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	errFmtRBACHint            = "missing RBAC permission: grant a rule allowing verb %q on resource %q in API group %q in namespace %q"
+	errFmtRBACHintClusterwide = "missing RBAC permission: grant a cluster rule allowing verb %q on resource %q in API group %q"
+)
+
+// forbiddenRE matches the message of a forbidden error returned by the
+// Kubernetes API server's RBAC authorizer, e.g.:
+//
+//	secrets "cool" is forbidden: User "system:serviceaccount:crossplane-system:crossplane"
+//	cannot get resource "secrets" in API group "" in the namespace "default"
+var forbiddenRE = regexp.MustCompile(`cannot (\S+) resource "([^"]*)" in API group "([^"]*)"(?: in the namespace "([^"]*)")?`)
+
+// withRBACHint enriches a forbidden error with the RBAC rule that is needed to
+// allow the forbidden request. Other errors, and forbidden errors that can't
+// be parsed, are returned unchanged.
+func withRBACHint(err error) error {
+	if !kerrors.IsForbidden(err) {
+		return err
+	}
+	m := forbiddenRE.FindStringSubmatch(err.Error())
+	if m == nil {
+		return err
+	}
+	verb, resource, group, namespace := m[1], m[2], m[3], m[4]
+	if namespace == "" {
+		return errors.Wrapf(err, errFmtRBACHintClusterwide, verb, resource, group)
+	}
+	return errors.Wrapf(err, errFmtRBACHint, verb, resource, group, namespace)
+}
+
+// withUnauthenticated marks an unauthorized error as store.ErrUnauthenticated.
+// The Kubernetes API server returns an unauthorized error when the client's
+// credentials are invalid or have expired. Other errors are returned
+// unchanged.
+func withUnauthenticated(err error) error {
+	if !kerrors.IsUnauthorized(err) {
+		return err
+	}
+	return store.NewUnauthenticated(err)
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func forbidden(verb, namespace string) error {
+	msg := fmt.Sprintf(`User "system:serviceaccount:crossplane-system:crossplane" cannot %s resource "secrets" in API group ""`, verb)
+	if namespace != "" {
+		msg += fmt.Sprintf(` in the namespace %q`, namespace)
+	}
+	return kerrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "cool", errors.New(msg))
+}
+
+func TestWithRBACHint(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		err    error
+		want   error
+	}{
+		"NotForbidden": {
+			reason: "We should return errors that aren't forbidden errors unchanged.",
+			err:    errBoom,
+			want:   errBoom,
+		},
+		"Get": {
+			reason: "We should suggest a rule allowing the forbidden get.",
+			err:    forbidden("get", "ns"),
+			want:   errors.Wrapf(forbidden("get", "ns"), errFmtRBACHint, "get", "secrets", "", "ns"),
+		},
+		"Create": {
+			reason: "We should suggest a rule allowing the forbidden create.",
+			err:    forbidden("create", "ns"),
+			want:   errors.Wrapf(forbidden("create", "ns"), errFmtRBACHint, "create", "secrets", "", "ns"),
+		},
+		"Update": {
+			reason: "We should suggest a rule allowing the forbidden update.",
+			err:    forbidden("update", "ns"),
+			want:   errors.Wrapf(forbidden("update", "ns"), errFmtRBACHint, "update", "secrets", "", "ns"),
+		},
+		"Delete": {
+			reason: "We should suggest a rule allowing the forbidden delete.",
+			err:    forbidden("delete", "ns"),
+			want:   errors.Wrapf(forbidden("delete", "ns"), errFmtRBACHint, "delete", "secrets", "", "ns"),
+		},
+		"ClusterWide": {
+			reason: "We should suggest a cluster rule if the forbidden request was not namespaced.",
+			err:    forbidden("list", ""),
+			want:   errors.Wrapf(forbidden("list", ""), errFmtRBACHintClusterwide, "list", "secrets", ""),
+		},
+		"Unparseable": {
+			reason: "We should return forbidden errors we can't parse unchanged.",
+			err:    kerrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "cool", errBoom),
+			want:   kerrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "cool", errBoom),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := withRBACHint(tc.err)
+			if diff := cmp.Diff(tc.want, got, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nwithRBACHint(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if kerrors.IsForbidden(tc.err) && !kerrors.IsForbidden(got) {
+				t.Errorf("\n%s\nwithRBACHint(...): the enriched error should still be a forbidden error", tc.reason)
+			}
+		})
+	}
+}
+
+func TestSecretStoreReadKeyValuesUnauthenticated(t *testing.T) {
+	errUnauthorized := kerrors.NewUnauthorized("token has expired")
+
+	cases := map[string]struct {
+		reason string
+		err    error
+		want   error
+	}{
+		"Unauthorized": {
+			reason: "We should return ErrUnauthenticated if the API server rejects our credentials.",
+			err:    errUnauthorized,
+			want:   errors.Wrap(store.NewUnauthenticated(errUnauthorized), errGetSecret),
+		},
+		"Other": {
+			reason: "We should not return ErrUnauthenticated for other errors.",
+			err:    errBoom,
+			want:   errors.Wrap(errBoom, errGetSecret),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ss := &SecretStore{
+				client: resource.ClientApplicator{
+					Client: &test.MockClient{MockGet: test.NewMockGetFn(tc.err)},
+				},
+			}
+			err := ss.ReadKeyValues(context.Background(), store.ScopedName{Name: fakeSecretName, Scope: fakeSecretNamespace}, &store.Secret{})
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nss.ReadKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(errors.Is(tc.want, store.ErrUnauthenticated), errors.Is(err, store.ErrUnauthenticated)); diff != "" {
+				t.Errorf("\n%s\nss.ReadKeyValues(...): -want ErrUnauthenticated, +got ErrUnauthenticated:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSecretStoreUnauthenticated(t *testing.T) {
+	errUnauthorized := kerrors.NewUnauthorized("token has expired")
+	s := &store.Secret{
+		ScopedName: store.ScopedName{Name: fakeSecretName, Scope: fakeSecretNamespace},
+		Owner:      &store.Owner{TypedReference: v1.TypedReference{UID: "owner-uid"}},
+	}
+
+	cases := map[string]struct {
+		reason string
+		client resource.ClientApplicator
+		op     func(ss *SecretStore) error
+	}{
+		"Write": {
+			reason: "We should return ErrUnauthenticated if the API server rejects our credentials when applying a secret.",
+			client: resource.ClientApplicator{
+				Applicator: resource.ApplyFn(func(_ context.Context, _ client.Object, _ ...resource.ApplyOption) error {
+					return errUnauthorized
+				}),
+			},
+			op: func(ss *SecretStore) error {
+				_, err := ss.WriteKeyValues(context.Background(), s.DeepCopy())
+				return err
+			},
+		},
+		"Delete": {
+			reason: "We should return ErrUnauthenticated if the API server rejects our credentials when deleting a secret.",
+			client: resource.ClientApplicator{
+				Client: &test.MockClient{
+					MockGet:    test.NewMockGetFn(nil),
+					MockDelete: test.NewMockDeleteFn(errUnauthorized),
+				},
+			},
+			op: func(ss *SecretStore) error {
+				return ss.DeleteKeyValues(context.Background(), s.DeepCopy())
+			},
+		},
+		"Orphan": {
+			reason: "We should return ErrUnauthenticated if the API server rejects our credentials when orphaning a secret.",
+			client: resource.ClientApplicator{
+				Client: &test.MockClient{MockGet: test.NewMockGetFn(errUnauthorized)},
+			},
+			op: func(ss *SecretStore) error {
+				return ss.OrphanKeyValues(context.Background(), s.DeepCopy())
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.op(&SecretStore{client: tc.client})
+			if !errors.Is(err, store.ErrUnauthenticated) {
+				t.Errorf("\n%s\nwant ErrUnauthenticated, got %v", tc.reason, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// Error strings.
+const (
+	errNotSecret       = "cannot server-side apply an object that is not a secret"
+	errGetCurrent      = "cannot get current secret"
+	errServerSideApply = "cannot server-side apply secret"
+)
+
+// A ConflictPolicy determines what a SecretStore that writes secrets using
+// server-side apply does when a key it writes is managed by another field
+// manager.
+type ConflictPolicy string
+
+// Conflict policies.
+const (
+	// ConflictPolicyForce takes ownership of the key from other field
+	// managers, overwriting their value.
+	ConflictPolicyForce ConflictPolicy = "Force"
+
+	// ConflictPolicyYield leaves the key to the other field managers that
+	// manage it, keeping their value.
+	ConflictPolicyYield ConflictPolicy = "Yield"
+)
+
+// WithServerSideApply configures a SecretStore to write secrets using
+// server-side apply, as the supplied field manager. Keys managed by another
+// field manager are handled per their ConflictPolicy. Unlike the merge patch
+// used by default, server-side apply removes keys the SecretStore previously
+// wrote, but no longer writes, unless another field manager also manages them.
+func WithServerSideApply(fieldManager string) SecretStoreOption {
+	return func(ss *SecretStore) {
+		ss.fieldManager = fieldManager
+	}
+}
+
+// WithKeyConflictPolicy configures the ConflictPolicy of the supplied keys,
+// when writing secrets using server-side apply. Keys use ConflictPolicyForce
+// by default, so a secret can be partially owned by yielding some of its keys.
+func WithKeyConflictPolicy(p ConflictPolicy, keys ...string) SecretStoreOption {
+	return func(ss *SecretStore) {
+		if ss.conflictPolicies == nil {
+			ss.conflictPolicies = map[string]ConflictPolicy{}
+		}
+		for _, k := range keys {
+			ss.conflictPolicies[k] = p
+		}
+	}
+}
+
+// WithFieldManagerPerOwner configures a SecretStore that writes secrets using
+// server-side apply to write each secret as a field manager derived from the
+// identity of the secret's owner, per OwnerIdentity. The field manager is the
+// one supplied to WithServerSideApply, suffixed with the owner's identity.
+// This tracks the keys each of the owners sharing a secret writes separately,
+// so an owner that stops writing a key only releases its own keys. Secrets
+// without an owner are written as the unsuffixed field manager.
+func WithFieldManagerPerOwner() SecretStoreOption {
+	return func(ss *SecretStore) {
+		ss.fieldManagerPerOwner = true
+	}
+}
+
+type fieldManagerKey struct{}
+
+// withOwnerFieldManager returns a copy of the supplied context that carries
+// the field manager the supplied owner's secrets should be applied as, if the
+// SecretStore derives field managers from owners.
+func (ss *SecretStore) withOwnerFieldManager(ctx context.Context, o *store.Owner) context.Context {
+	id := OwnerIdentity(o)
+	if !ss.fieldManagerPerOwner || ss.fieldManager == "" || id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, fieldManagerKey{}, ss.fieldManager+"-"+id)
+}
+
+// A serverSideApplicator applies secrets using server-side apply.
+type serverSideApplicator struct {
+	client       client.Client
+	fieldManager string
+	policies     map[string]ConflictPolicy
+}
+
+// Apply the supplied secret, as the field manager carried by the supplied
+// context if any. The apply patch omits keys that are managed by another field
+// manager and that yield to it, and forces ownership of all other keys. Like
+// resource.APIPatchingApplicator, ApplyOptions are only called if the secret
+// already exists.
+func (a *serverSideApplicator) Apply(ctx context.Context, o client.Object, ao ...resource.ApplyOption) error {
+	current, ok := o.(*corev1.Secret)
+	if !ok {
+		return errors.New(errNotSecret)
+	}
+	desired := current.DeepCopy()
+	fm := a.fieldManager
+	if v, ok := ctx.Value(fieldManagerKey{}).(string); ok {
+		fm = v
+	}
+
+	err := a.client.Get(ctx, types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, current)
+	if resource.IgnoreNotFound(err) != nil {
+		return errors.Wrap(err, errGetCurrent)
+	}
+	if !kerrors.IsNotFound(err) {
+		for _, fn := range ao {
+			if err := fn(ctx, current, desired); err != nil {
+				return err
+			}
+		}
+		for k := range foreignKeys(current.ManagedFields, fm) {
+			if a.policies[k] == ConflictPolicyYield {
+				delete(desired.Data, k)
+			}
+		}
+	}
+
+	desired.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+	desired.ResourceVersion = ""
+	desired.ManagedFields = nil
+	if err := a.client.Patch(ctx, desired, client.Apply, client.FieldOwner(fm), client.ForceOwnership); err != nil {
+		return errors.Wrap(err, errServerSideApply)
+	}
+	desired.DeepCopyInto(current)
+	return nil
+}
+
+// foreignKeys returns the data keys managed by field managers other than the
+// supplied one.
+func foreignKeys(mf []metav1.ManagedFieldsEntry, fieldManager string) map[string]bool {
+	keys := map[string]bool{}
+	for _, e := range mf {
+		if e.Manager == fieldManager || e.FieldsV1 == nil {
+			continue
+		}
+		fields := map[string]map[string]any{}
+		if err := json.Unmarshal(e.FieldsV1.Raw, &fields); err != nil {
+			// A malformed entry manages no keys we can tell about.
+			continue
+		}
+		for f := range fields["f:data"] {
+			if k, ok := strings.CutPrefix(f, "f:"); ok {
+				keys[k] = true
+			}
+		}
+	}
+	return keys
+}
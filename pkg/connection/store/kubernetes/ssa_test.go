@@ -0,0 +1,234 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestSecretStoreWriteKeyValuesServerSideApply(t *testing.T) {
+	// The other manager manages the forced and yielded keys. Nobody manages
+	// the free key.
+	current := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fakeSecretName,
+			Namespace: fakeSecretNamespace,
+			ManagedFields: []metav1.ManagedFieldsEntry{{
+				Manager:  "other",
+				FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:data":{"f:forced":{},"f:yielded":{}}}`)},
+			}},
+		},
+		Data: map[string][]byte{"forced": []byte("theirs"), "yielded": []byte("theirs")},
+	}
+
+	type want struct {
+		data  map[string][]byte
+		force bool
+		owner string
+	}
+
+	cases := map[string]struct {
+		reason string
+		exists bool
+		want   want
+	}{
+		"ManagedByOthers": {
+			reason: "We should force ownership of forced keys, and omit yielded keys that are managed by another field manager.",
+			exists: true,
+			want: want{
+				data:  map[string][]byte{"forced": []byte("ours"), "free": []byte("ours")},
+				force: true,
+				owner: "crossplane",
+			},
+		},
+		"DoesNotExist": {
+			reason: "We should apply all keys if the secret does not exist yet.",
+			want: want{
+				data:  map[string][]byte{"forced": []byte("ours"), "yielded": []byte("ours"), "free": []byte("ours")},
+				force: true,
+				owner: "crossplane",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got *corev1.Secret
+			po := &client.PatchOptions{}
+			c := &test.MockClient{
+				MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+					if !tc.exists {
+						return kerrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, fakeSecretName)
+					}
+					current.DeepCopyInto(obj.(*corev1.Secret))
+					return nil
+				},
+				MockPatch: func(_ context.Context, obj client.Object, p client.Patch, opts ...client.PatchOption) error {
+					if p != client.Apply {
+						t.Errorf("\n%s\nss.WriteKeyValues(...): want an apply patch, got %q", tc.reason, p.Type())
+					}
+					got = obj.(*corev1.Secret).DeepCopy()
+					po.ApplyOptions(opts)
+					return nil
+				},
+			}
+
+			ss, err := NewSecretStore(context.Background(), c, nil, v1.SecretStoreConfig{DefaultScope: fakeSecretNamespace},
+				WithServerSideApply("crossplane"),
+				WithKeyConflictPolicy(ConflictPolicyYield, "yielded", "free"),
+			)
+			if err != nil {
+				t.Fatalf("NewSecretStore(...): %v", err)
+			}
+
+			_, err = ss.WriteKeyValues(context.Background(), &store.Secret{
+				ScopedName: store.ScopedName{Name: fakeSecretName, Scope: fakeSecretNamespace},
+				Data:       store.KeyValues{"forced": []byte("ours"), "yielded": []byte("ours"), "free": []byte("ours")},
+			})
+			if err != nil {
+				t.Fatalf("\n%s\nss.WriteKeyValues(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.data, got.Data); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want applied data, +got applied data:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.force, *po.Force); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want force, +got force:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.owner, po.FieldManager); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want field manager, +got field manager:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// ssaServer mimics how an API server tracks the data keys each field manager
+// applies to a secret.
+type ssaServer struct {
+	data     map[string][]byte
+	managers map[string]map[string]bool
+}
+
+func (s *ssaServer) get(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+	if s.data == nil {
+		return kerrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, fakeSecretName)
+	}
+	ks := obj.(*corev1.Secret)
+	ks.Data = s.data
+	ks.ManagedFields = nil
+	for m, keys := range s.managers {
+		fields := map[string]map[string]any{"f:data": {}}
+		for k := range keys {
+			fields["f:data"]["f:"+k] = map[string]any{}
+		}
+		raw, _ := json.Marshal(fields)
+		ks.ManagedFields = append(ks.ManagedFields, metav1.ManagedFieldsEntry{Manager: m, FieldsV1: &metav1.FieldsV1{Raw: raw}})
+	}
+	return nil
+}
+
+func (s *ssaServer) patch(_ context.Context, obj client.Object, _ client.Patch, opts ...client.PatchOption) error {
+	po := &client.PatchOptions{}
+	po.ApplyOptions(opts)
+	applied := map[string]bool{}
+	for k := range obj.(*corev1.Secret).Data {
+		applied[k] = true
+	}
+	if s.managers == nil {
+		s.managers = map[string]map[string]bool{}
+	}
+	s.managers[po.FieldManager] = applied
+
+	// A key is removed once no field manager manages it.
+	data := map[string][]byte{}
+	for k, v := range s.data {
+		for _, keys := range s.managers {
+			if keys[k] {
+				data[k] = v
+			}
+		}
+	}
+	for k, v := range obj.(*corev1.Secret).Data {
+		data[k] = v
+	}
+	s.data = data
+	return nil
+}
+
+func TestSecretStoreWriteKeyValuesFieldManagerPerOwner(t *testing.T) {
+	owner := func(uid string) *store.Owner {
+		return &store.Owner{
+			TypedReference: v1.TypedReference{APIVersion: "example.org/v1", Kind: "Cool", Name: uid, UID: types.UID(uid)},
+			Namespace:      fakeSecretNamespace,
+		}
+	}
+	a, b := owner("a"), owner("b")
+
+	srv := &ssaServer{}
+	c := &test.MockClient{MockGet: srv.get, MockPatch: srv.patch}
+	ss, err := NewSecretStore(context.Background(), c, nil, v1.SecretStoreConfig{DefaultScope: fakeSecretNamespace},
+		WithServerSideApply("crossplane"),
+		WithFieldManagerPerOwner(),
+		WithOwnerReferencePolicy(OwnerReferencePolicyLabelOnly),
+	)
+	if err != nil {
+		t.Fatalf("NewSecretStore(...): %v", err)
+	}
+
+	write := func(o *store.Owner, kv store.KeyValues) {
+		t.Helper()
+		if _, err := ss.WriteKeyValues(context.Background(), &store.Secret{
+			ScopedName: store.ScopedName{Name: fakeSecretName, Scope: fakeSecretNamespace},
+			Owner:      o,
+			Data:       kv,
+		}); err != nil {
+			t.Fatalf("ss.WriteKeyValues(...): %v", err)
+		}
+	}
+
+	write(a, store.KeyValues{"a": []byte("1"), "a-extra": []byte("2")})
+	write(b, store.KeyValues{"b": []byte("3")})
+
+	wantManagers := map[string]map[string]bool{
+		"crossplane-" + OwnerIdentity(a): {"a": true, "a-extra": true},
+		"crossplane-" + OwnerIdentity(b): {"b": true},
+	}
+	if diff := cmp.Diff(wantManagers, srv.managers); diff != "" {
+		t.Errorf("ss.WriteKeyValues(...): -want field managers, +got field managers:\n%s", diff)
+	}
+
+	// Owner a stops writing one of its keys, which releases only that key.
+	write(a, store.KeyValues{"a": []byte("1")})
+
+	want := map[string][]byte{"a": []byte("1"), "b": []byte("3")}
+	if diff := cmp.Diff(want, srv.data); diff != "" {
+		t.Errorf("ss.WriteKeyValues(...): -want data, +got data:\n%s", diff)
+	}
+}
@@ -20,20 +20,26 @@ package kubernetes
 import (
 	"context"
 	"crypto/tls"
+	"sync"
+	"time"
 
-	"github.com/google/go-cmp/cmp"
-	"github.com/google/go-cmp/cmp/cmpopts"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 )
 
@@ -47,6 +53,45 @@ const (
 	errExtractKubernetesAuthCreds = "cannot extract kubernetes auth credentials"
 	errBuildRestConfig            = "cannot build rest config kubeconfig"
 	errBuildClient                = "cannot build Kubernetes client"
+	errUnownedRemoteWrite         = "refusing to write a secret to a remote Kubernetes API server without an owner UID label"
+
+	errFmtCrossNamespaceOwner = "cannot make %s %q in namespace %q the owner of secret %q in namespace %q: namespaced owners must be in the same namespace as the secrets they own"
+)
+
+// Event reasons.
+const (
+	reasonUnownedRemoteWrite event.Reason = "UnownedRemoteWrite"
+
+	msgUnownedRemoteWrite = "wrote a secret to a remote Kubernetes API server without an owner UID label: its ownership is not protected"
+)
+
+// SecretFinalizer is the finalizer a SecretStore configured WithSecretFinalizer
+// adds to the secrets it writes.
+const SecretFinalizer = "finalizer.connection.crossplane.io"
+
+// An OwnerReferencePolicy determines whether a SecretStore makes the owner of
+// a secret its controller using an owner reference. Secrets are always labelled
+// with the UID of their owner, regardless of this policy.
+type OwnerReferencePolicy string
+
+// Owner reference policies.
+const (
+	// OwnerReferencePolicyRequireSameNamespace sets an owner reference to
+	// cluster scoped owners, and to namespaced owners in the same namespace
+	// as the secret. Writing a secret owned by a namespaced owner in another
+	// namespace returns an error, because Kubernetes does not support cross
+	// namespace owner references.
+	OwnerReferencePolicyRequireSameNamespace OwnerReferencePolicy = "RequireSameNamespace"
+
+	// OwnerReferencePolicyFallbackToLabel sets an owner reference to cluster
+	// scoped owners, and to namespaced owners in the same namespace as the
+	// secret. Secrets owned by a namespaced owner in another namespace are
+	// only labelled with the UID of their owner.
+	OwnerReferencePolicyFallbackToLabel OwnerReferencePolicy = "FallbackToLabel"
+
+	// OwnerReferencePolicyLabelOnly never sets owner references. Secrets are
+	// only labelled with the UID of their owner.
+	OwnerReferencePolicyLabelOnly OwnerReferencePolicy = "LabelOnly"
 )
 
 // SecretStore is a Kubernetes Secret Store.
@@ -54,25 +99,215 @@ type SecretStore struct {
 	client resource.ClientApplicator
 
 	defaultNamespace string
+
+	restMapper meta.RESTMapper
+
+	// remote is true if secrets are stored on a remote Kubernetes API server.
+	remote bool
+
+	// server and credentialsSource describe the remote Kubernetes API server,
+	// if any. They are used only to describe the SecretStore's configuration.
+	server            string
+	credentialsSource v1.CredentialsSource
+
+	requireOwnership bool
+	log              logging.Logger
+	recorder         event.Recorder
+	unowned          *remoteTargets
+
+	deleteBackoff   wait.Backoff
+	emptyDeletesAll bool
+	conflictBackoff wait.Backoff
+	writeBackoff    wait.Backoff
+
+	ownerRefs    OwnerReferencePolicy
+	groupRenames map[string]string
+	finalizer    bool
+	merge        bool
+	protected    map[string]bool
+	versioned    bool
+
+	// labelOwnership is true if ownership of secrets on the local
+	// Kubernetes API server is tracked using labels rather than owner
+	// references, like it always is on a remote Kubernetes API server.
+	labelOwnership bool
+
+	protectedTargets []types.NamespacedName
+
+	// caBundle and insecureSkipVerify configure how the certificate of a
+	// remote Kubernetes API server is verified.
+	caBundle           []byte
+	insecureSkipVerify bool
+
+	// fieldManager is the field manager secrets are server-side applied as,
+	// if any. Secrets are merge patched if it is empty.
+	fieldManager         string
+	fieldManagerPerOwner bool
+	conflictPolicies     map[string]ConflictPolicy
+
+	// ownerRefWarningThreshold is nil if the default threshold applies.
+	ownerRefWarningThreshold *int
+
+	// terminatingBackoff is used to wait for secrets that are being deleted
+	// to be deleted before they are written. Writes fail fast if it is nil.
+	terminatingBackoff *wait.Backoff
+
+	defaultLabels      map[string]string
+	defaultAnnotations map[string]string
+
+	// cache is used to read secrets from the local Kubernetes API server,
+	// if set.
+	cache client.Reader
+}
+
+// A SecretStoreOption configures a SecretStore.
+type SecretStoreOption func(*SecretStore)
+
+// WithRESTMapper configures the RESTMapper used by the client of a remote
+// Kubernetes API server. This is useful when the remote API server restricts
+// discovery, or when discovery would be slow. A dynamic, discovery based
+// RESTMapper is used by default.
+func WithRESTMapper(m meta.RESTMapper) SecretStoreOption {
+	return func(ss *SecretStore) {
+		ss.restMapper = m
+	}
+}
+
+// WithLogger configures the logger used by a SecretStore.
+func WithLogger(l logging.Logger) SecretStoreOption {
+	return func(ss *SecretStore) {
+		ss.log = l
+	}
+}
+
+// WithEventRecorder configures the event recorder used by a SecretStore. It
+// records a warning event on a secret the first time it is written to a remote
+// Kubernetes API server without an owner UID label. No events are recorded by
+// default.
+func WithEventRecorder(r event.Recorder) SecretStoreOption {
+	return func(ss *SecretStore) {
+		ss.recorder = r
+	}
+}
+
+// WithRequireOwnership configures a SecretStore that stores secrets on a
+// remote Kubernetes API server to refuse writing a secret that does not carry
+// an owner UID label. Without the label nothing prevents two owners from
+// writing the same secret. By default such writes are allowed, and a warning
+// is logged and recorded on the first of them to each secret.
+func WithRequireOwnership() SecretStoreOption {
+	return func(ss *SecretStore) {
+		ss.requireOwnership = true
+	}
+}
+
+// WithoutDeleteConflictRetry configures a SecretStore not to retry deleting
+// key values from a secret that was concurrently modified. Such deletes fail
+// fast with a conflict error instead. By default they are retried with
+// retry.DefaultRetry.
+func WithoutDeleteConflictRetry() SecretStoreOption {
+	return func(ss *SecretStore) {
+		ss.deleteBackoff = wait.Backoff{Steps: 1}
+	}
+}
+
+// WithEmptyKeySetDeletesAll configures a SecretStore to delete the whole secret
+// when asked to delete an empty, non-nil set of keys, like it does when asked
+// to delete a nil set of keys. By default deleting an empty set of keys is a
+// no-op.
+func WithEmptyKeySetDeletesAll() SecretStoreOption {
+	return func(ss *SecretStore) {
+		ss.emptyDeletesAll = true
+	}
+}
+
+// WithConflictBackoff configures the backoff a SecretStore uses to retry
+// writing a secret that was concurrently modified. Conflicts usually resolve as
+// soon as the write is retried, so they are retried independently of other
+// errors. retry.DefaultRetry is used by default.
+func WithConflictBackoff(b wait.Backoff) SecretStoreOption {
+	return func(ss *SecretStore) {
+		ss.conflictBackoff = b
+	}
+}
+
+// WithWriteBackoff configures the backoff a SecretStore uses to retry writing
+// a secret when the Kubernetes API server returns an error other than a
+// conflict. retry.DefaultRetry is used by default.
+func WithWriteBackoff(b wait.Backoff) SecretStoreOption {
+	return func(ss *SecretStore) {
+		ss.writeBackoff = b
+	}
+}
+
+// WithOwnerReferencePolicy configures how a SecretStore that stores secrets
+// on the local Kubernetes API server sets owner references to the owners of
+// the secrets it writes. OwnerReferencePolicyRequireSameNamespace is used by
+// default. Secrets written to a remote Kubernetes API server never have owner
+// references, because their owners live on another API server.
+func WithOwnerReferencePolicy(p OwnerReferencePolicy) SecretStoreOption {
+	return func(ss *SecretStore) {
+		ss.ownerRefs = p
+	}
+}
+
+// WithSecretFinalizer configures a SecretStore to add the SecretFinalizer to
+// the secrets it writes. This coordinates garbage collection with the cleanup
+// of the secrets' owners. Without it, deleting an owner in the foreground
+// makes the garbage collector delete its secrets before the owner's finalizer
+// has cleaned up the resources they describe. With it, the ordering is:
+//
+//  1. The owner is deleted, and the garbage collector deletes its secrets.
+//     The secrets persist, because they have the SecretFinalizer.
+//  2. The owner's finalizer cleans up, and may still read its secrets.
+//  3. The owner's finalizer deletes its secrets from the SecretStore, which
+//     removes the SecretFinalizer. Only now do the secrets go away.
+//  4. The owner's finalizer is removed, and the owner goes away.
+//
+// Deleting a secret always removes the SecretFinalizer, regardless of this
+// option, so secrets written while it was enabled can always be deleted.
+func WithSecretFinalizer() SecretStoreOption {
+	return func(ss *SecretStore) {
+		ss.finalizer = true
+	}
 }
 
 // NewSecretStore returns a new Kubernetes SecretStore.
-func NewSecretStore(ctx context.Context, local client.Client, _ *tls.Config, cfg v1.SecretStoreConfig) (*SecretStore, error) {
-	kube, err := buildClient(ctx, local, cfg)
+func NewSecretStore(ctx context.Context, local client.Client, _ *tls.Config, cfg v1.SecretStoreConfig, o ...SecretStoreOption) (*SecretStore, error) {
+	ss := &SecretStore{
+		defaultNamespace: cfg.DefaultScope,
+		remote:           cfg.Kubernetes != nil,
+		log:              logging.NewNopLogger(),
+		recorder:         event.NewNopRecorder(),
+		unowned:          unownedWrites,
+		deleteBackoff:    retry.DefaultRetry,
+		conflictBackoff:  retry.DefaultRetry,
+		writeBackoff:     retry.DefaultRetry,
+		ownerRefs:        OwnerReferencePolicyRequireSameNamespace,
+
+		defaultLabels:      cfg.DefaultLabels,
+		defaultAnnotations: cfg.DefaultAnnotations,
+	}
+	for _, fn := range o {
+		fn(ss)
+	}
+
+	kube, err := ss.buildClient(ctx, local, cfg)
 	if err != nil {
 		return nil, errors.Wrap(err, errBuildClient)
 	}
 
-	return &SecretStore{
-		client: resource.ClientApplicator{
-			Client:     kube,
-			Applicator: resource.NewApplicatorWithRetry(resource.NewAPIPatchingApplicator(kube), resource.IsAPIErrorWrapped, nil),
-		},
-		defaultNamespace: cfg.DefaultScope,
-	}, nil
+	ss.client = resource.ClientApplicator{
+		Client:     kube,
+		Applicator: resource.NewAPIPatchingApplicator(kube),
+	}
+	if ss.fieldManager != "" {
+		ss.client.Applicator = &serverSideApplicator{client: kube, fieldManager: ss.fieldManager, policies: ss.conflictPolicies}
+	}
+	return ss, nil
 }
 
-func buildClient(ctx context.Context, local client.Client, cfg v1.SecretStoreConfig) (client.Client, error) {
+func (ss *SecretStore) buildClient(ctx context.Context, local client.Client, cfg v1.SecretStoreConfig) (client.Client, error) {
 	if cfg.Kubernetes == nil {
 		// No KubernetesSecretStoreConfig provided, local API Server will be
 		// used as Secret Store.
@@ -83,30 +318,39 @@ func buildClient(ctx context.Context, local client.Client, cfg v1.SecretStoreCon
 	if err != nil {
 		return nil, errors.Wrap(err, errExtractKubernetesAuthCreds)
 	}
-	config, err := clientcmd.RESTConfigFromKubeConfig(kfg)
-	if err != nil {
-		return nil, errors.Wrap(err, errBuildRestConfig)
-	}
-	return client.New(config, client.Options{})
+	ss.credentialsSource = cfg.Kubernetes.Auth.Source
+	return ss.clientForKubeconfig(kfg)
 }
 
 // ReadKeyValues reads and returns key value pairs for a given Kubernetes Secret.
-func (ss *SecretStore) ReadKeyValues(ctx context.Context, n store.ScopedName, s *store.Secret) error {
+// The secret is read using the client carried by the supplied context, if any.
+// The consistency of reads is determined by the client, so any consistency
+// level requested by the context is ignored.
+func (ss *SecretStore) ReadKeyValues(ctx context.Context, n store.ScopedName, s *store.Secret, ro ...store.ReadOption) error {
 	ks := &corev1.Secret{}
-	if err := ss.client.Get(ctx, types.NamespacedName{Name: n.Name, Namespace: ss.namespaceForSecret(n)}, ks); resource.IgnoreNotFound(err) != nil {
-		return errors.Wrap(err, errGetSecret)
+	if err := ss.reader(ctx).Get(ctx, types.NamespacedName{Name: n.Name, Namespace: ss.namespaceForSecret(n)}, ks); resource.IgnoreNotFound(err) != nil {
+		return errors.Wrap(withUnauthenticated(withRBACHint(err)), errGetSecret)
 	}
+	s.ScopedName = n
 	s.Data = ks.Data
 	s.Metadata = &v1.ConnectionSecretMetadata{
 		Labels:      ks.Labels,
 		Annotations: ks.Annotations,
 		Type:        &ks.Type,
 	}
-	return nil
+	s.Immutable = ptr.Deref(ks.Immutable, false)
+	return store.ApplyReadOptions(ctx, s, ro...)
 }
 
 // WriteKeyValues writes key value pairs to a given Kubernetes Secret.
 func (ss *SecretStore) WriteKeyValues(ctx context.Context, s *store.Secret, wo ...store.WriteOption) (bool, error) {
+	if err := ss.checkOwnership(s); err != nil {
+		return false, err
+	}
+	if err := ss.checkProtectedTarget(types.NamespacedName{Name: s.Name, Namespace: ss.namespaceForSecret(s.ScopedName)}); err != nil {
+		return false, err
+	}
+
 	ks := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      s.Name,
@@ -124,29 +368,107 @@ func (ss *SecretStore) WriteKeyValues(ctx context.Context, s *store.Secret, wo .
 		}
 	}
 
-	ao := applyOptions(wo...)
-	ao = append(ao, resource.AllowUpdateIf(func(current, desired runtime.Object) bool {
-		// We consider the update to be a no-op and don't allow it if the
-		// current and existing secret data are identical.
-		return !cmp.Equal(current.(*corev1.Secret).Data, desired.(*corev1.Secret).Data, cmpopts.EquateEmpty()) //nolint:forcetypeassert // Will always be a secret.
-	}))
+	if err := ss.setOwnerReference(ks, s.Owner); err != nil {
+		return false, err
+	}
+	ss.setOwnerMetadata(ks, s.Owner)
+	ss.setDefaultMetadata(ks)
+	if ss.finalizer {
+		controllerutil.AddFinalizer(ks, SecretFinalizer)
+	}
+	if ss.upToDate(ctx, ks, wo...) {
+		return false, nil
+	}
+	ss.setInitialVersion(ks)
+
+	// Renamed owners must be adopted before any write options check whether
+	// the current secret is controllable.
+	ao := append([]resource.ApplyOption{refuseTerminating, ss.warnManyOwnerReferences, ss.adoptRenamedOwners, ss.refuseForeignOwner}, applyOptions(wo...)...)
+	ao = append(ao, ss.mergeData, ss.protectKeys, ss.incrementVersion, resource.AllowUpdateIf(ss.needsUpdate))
 
-	err := ss.client.Apply(ctx, ks, ao...)
+	ctx = ss.withOwnerFieldManager(ctx, s.Owner)
+	apply := func() error {
+		return ss.retryWrite(ctx, func() error {
+			// The applicator overwrites the object it applies with the
+			// current object, so each attempt must apply a fresh copy.
+			return ss.client.Apply(ctx, ks.DeepCopy(), ao...)
+		})
+	}
+	err := apply()
+	if errors.Is(err, ErrSecretTerminating) {
+		// Writing over a secret that is being deleted races with its
+		// deletion, so we wait for it to be deleted and recreate it.
+		if err := ss.waitForDeletion(ctx, types.NamespacedName{Name: ks.Name, Namespace: ks.Namespace}); err != nil {
+			return false, errors.Wrap(err, errApplySecret)
+		}
+		err = apply()
+	}
 	if resource.IsNotAllowed(err) {
 		// The update was not allowed because it was a no-op.
 		return false, nil
 	}
 	if err != nil {
-		return false, errors.Wrap(err, errApplySecret)
+		return false, errors.Wrap(withUnauthenticated(withRBACHint(err)), errApplySecret)
 	}
 	return true, nil
 }
 
 // DeleteKeyValues delete key value pairs from a given Kubernetes Secret.
-// If no kv specified, the whole secret instance is deleted.
-// If kv specified, those would be deleted and secret instance will be deleted
-// only if there is no data left.
+// If no kv specified, i.e. the supplied secret's data is nil, the whole secret
+// instance is deleted. If an empty, non-nil kv is specified no keys are
+// deleted, and the secret is left as is, unless the SecretStore was configured
+// WithEmptyKeySetDeletesAll. If kv specified, those would be deleted and
+// secret instance will be deleted only if there is no data left. Deleting key
+// values reads, modifies, and writes the secret, so it is retried if the
+// secret was concurrently modified, unless the SecretStore was configured
+// WithoutDeleteConflictRetry.
 func (ss *SecretStore) DeleteKeyValues(ctx context.Context, s *store.Secret, do ...store.DeleteOption) error {
+	if s.Data != nil && len(s.Data) == 0 && !ss.emptyDeletesAll {
+		return nil
+	}
+	b := ss.deleteBackoff
+	if b.Steps == 0 {
+		// A SecretStore that wasn't built by NewSecretStore.
+		b = wait.Backoff{Steps: 1}
+	}
+	return retry.OnError(b, kerrors.IsConflict, func() error {
+		return ss.deleteKeyValues(ctx, s, do...)
+	})
+}
+
+// retryWrite calls the supplied function until it succeeds. Conflicts are
+// retried per the SecretStore's conflict backoff, and other Kubernetes API
+// errors per its write backoff. The two backoffs are stepped independently.
+func (ss *SecretStore) retryWrite(ctx context.Context, fn func() error) error {
+	conflict, write := ss.conflictBackoff, ss.writeBackoff
+	for {
+		err := fn()
+		var b *wait.Backoff
+		switch {
+		case err == nil, resource.IsNotAllowed(err):
+			return err
+		case kerrors.IsConflict(err):
+			b = &conflict
+		case resource.IsAPIErrorWrapped(err):
+			b = &write
+		default:
+			return err
+		}
+		// A backoff with one or fewer steps allows no retries. This includes
+		// the zero backoff of a SecretStore that wasn't built by
+		// NewSecretStore.
+		if b.Steps <= 1 {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(b.Step()):
+		}
+	}
+}
+
+func (ss *SecretStore) deleteKeyValues(ctx context.Context, s *store.Secret, do ...store.DeleteOption) error {
 	// NOTE(turkenh): DeleteKeyValues method wouldn't need to do anything if we
 	// have used owner references similar to existing implementation. However,
 	// this wouldn't work if the K8s API is not the same as where ConnectionSecretOwner
@@ -155,6 +477,8 @@ func (ss *SecretStore) DeleteKeyValues(ctx context.Context, s *store.Secret, do
 	// collection in this specific case other than one less API call during
 	// deletion, I opted for unifying both instead of adding conditional logic
 	// like add owner references if not remote and not call delete etc.
+	// Secrets in a remote cluster that DeleteKeyValues no longer knows about
+	// are deleted by DeleteKeyValuesForOwner, using their owner labels.
 	ks := &corev1.Secret{}
 	err := ss.client.Get(ctx, types.NamespacedName{Name: s.Name, Namespace: ss.namespaceForSecret(s.ScopedName)}, ks)
 	if kerrors.IsNotFound(err) {
@@ -162,7 +486,7 @@ func (ss *SecretStore) DeleteKeyValues(ctx context.Context, s *store.Secret, do
 		return nil
 	}
 	if err != nil {
-		return errors.Wrap(err, errGetSecret)
+		return errors.Wrap(withUnauthenticated(withRBACHint(err)), errGetSecret)
 	}
 
 	for _, o := range do {
@@ -179,10 +503,112 @@ func (ss *SecretStore) DeleteKeyValues(ctx context.Context, s *store.Secret, do
 		// Secret is deleted only if:
 		// - No kv to delete specified as input
 		// - No data left in the secret
-		return errors.Wrapf(ss.client.Delete(ctx, ks), errDeleteSecret)
+		// Our finalizer is removed first, so that the secret goes away even
+		// if the garbage collector already deleted it.
+		if controllerutil.RemoveFinalizer(ks, SecretFinalizer) {
+			if err := ss.client.Update(ctx, ks); err != nil {
+				return errors.Wrap(withUnauthenticated(withRBACHint(err)), errUpdateSecret)
+			}
+		}
+		if ks.GetDeletionTimestamp() != nil {
+			// The secret is already being deleted.
+			return nil
+		}
+		return errors.Wrapf(withUnauthenticated(withRBACHint(ss.client.Delete(ctx, ks))), errDeleteSecret)
 	}
 	// If there are still keys left, update the secret with the remaining.
-	return errors.Wrapf(ss.client.Update(ctx, ks), errUpdateSecret)
+	return errors.Wrapf(withUnauthenticated(withRBACHint(ss.client.Update(ctx, ks))), errUpdateSecret)
+}
+
+// checkOwnership handles writes of secrets that don't carry an owner UID label
+// to a remote Kubernetes API server. Such secrets have no owner reference
+// either, because their owner lives on another API server, so their
+// controllability is not protected.
+func (ss *SecretStore) checkOwnership(s *store.Secret) error {
+	if !ss.remote || s.GetOwner() != "" {
+		return nil
+	}
+	if ss.requireOwnership {
+		return errors.New(errUnownedRemoteWrite)
+	}
+	ns := ss.namespaceForSecret(s.ScopedName)
+	if !ss.unowned.add(remoteTarget{server: ss.server, namespace: ns, name: s.Name}) {
+		return nil
+	}
+	ss.log.Info("Writing a secret to a remote Kubernetes API server without an owner UID label. Ownership of secrets written to this store is not protected.", "secret-name", s.Name, "secret-namespace", ns)
+	ss.recorder.Event(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: s.Name, Namespace: ns}}, event.Warning(reasonUnownedRemoteWrite, errors.New(msgUnownedRemoteWrite)))
+	return nil
+}
+
+// A remoteTarget is a secret on a remote Kubernetes API server.
+type remoteTarget struct {
+	server    string
+	namespace string
+	name      string
+}
+
+// remoteTargets is a set of secrets on remote Kubernetes API servers.
+type remoteTargets struct {
+	mx      sync.Mutex
+	targets map[remoteTarget]bool
+}
+
+func newRemoteTargets() *remoteTargets {
+	return &remoteTargets{targets: make(map[remoteTarget]bool)}
+}
+
+// add adds the supplied target, and returns false if it was already added.
+func (t *remoteTargets) add(rt remoteTarget) bool {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	if t.targets[rt] {
+		return false
+	}
+	t.targets[rt] = true
+	return true
+}
+
+// unownedWrites remembers the secrets SecretStores warned about unowned writes
+// to. A SecretStore is usually built for each operation, so warning only once
+// per SecretStore would warn about every write.
+var unownedWrites = newRemoteTargets() //nolint:gochecknoglobals // Must outlive the SecretStores that warn.
+
+// setOwnerReference makes the supplied owner the controller of the supplied
+// secret, per the SecretStore's OwnerReferencePolicy. Secrets of owners with
+// the Orphan deletion policy have no owner reference, so that like the
+// owner's external resource they outlive the owner and don't block its
+// deletion.
+func (ss *SecretStore) setOwnerReference(ks *corev1.Secret, o *store.Owner) error {
+	if ss.tracksOwnershipByLabel() || o == nil || o.Kind == "" || o.UID == "" {
+		return nil
+	}
+	if o.DeletionPolicy == v1.DeletionOrphan {
+		return nil
+	}
+	switch ss.ownerRefs {
+	case OwnerReferencePolicyLabelOnly, "":
+		return nil
+	case OwnerReferencePolicyFallbackToLabel:
+		if o.Namespace != "" && o.Namespace != ks.Namespace {
+			// The garbage collector won't delete the secret along with its
+			// owner, so it must be deleted explicitly.
+			ss.log.Debug("Owner is in another namespace than its secret, tracking ownership using a label only", "secret-name", ks.Name, "secret-namespace", ks.Namespace, "owner-kind", o.Kind, "owner-name", o.Name, "owner-namespace", o.Namespace)
+			return nil
+		}
+	case OwnerReferencePolicyRequireSameNamespace:
+		if o.Namespace != "" && o.Namespace != ks.Namespace {
+			return errors.Errorf(errFmtCrossNamespaceOwner, o.Kind, o.Name, o.Namespace, ks.Name, ks.Namespace)
+		}
+	}
+	ks.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion:         o.APIVersion,
+		Kind:               o.Kind,
+		Name:               o.Name,
+		UID:                o.UID,
+		Controller:         ptr.To(true),
+		BlockOwnerDeletion: ptr.To(true),
+	}}
+	return nil
 }
 
 func (ss *SecretStore) namespaceForSecret(n store.ScopedName) string {
@@ -221,8 +647,8 @@ func applyOptions(wo ...store.WriteOption) []resource.ApplyOption {
 			// converting store.WriteOption's to k8s resource.ApplyOption's, so that our generic store.WriteOptions
 			// checking secret owner could work as expected.
 			// Fixes: https://github.com/crossplane/crossplane/issues/3520
-			if len(currentSecret.GetOwnerReferences()) > 0 {
-				cs.Metadata.SetOwnerUID(currentSecret.GetOwnerReferences()[0].UID)
+			if uid, ok := controllerUID(currentSecret.GetOwnerReferences()); ok {
+				cs.Metadata.SetOwnerUID(uid)
 			}
 
 			ds := &store.Secret{
@@ -18,19 +18,31 @@ package kubernetes
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 )
@@ -70,10 +82,12 @@ func TestSecretStoreReadKeyValues(t *testing.T) {
 	type args struct {
 		client resource.ClientApplicator
 		n      store.ScopedName
+		ro     []store.ReadOption
 	}
 	type want struct {
-		result store.KeyValues
-		err    error
+		result    store.KeyValues
+		immutable bool
+		err       error
 	}
 
 	cases := map[string]struct {
@@ -122,6 +136,77 @@ func TestSecretStoreReadKeyValues(t *testing.T) {
 				result: store.KeyValues(fakeKV()),
 			},
 		},
+		"SuccessfulImmutableRead": {
+			reason: "Should report that the secret is immutable after reading an immutable secret",
+			args: args{
+				client: resource.ClientApplicator{
+					Client: &test.MockClient{
+						MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+							*obj.(*corev1.Secret) = corev1.Secret{
+								Data:      fakeKV(),
+								Immutable: ptr.To(true),
+							}
+							return nil
+						}),
+					},
+				},
+			},
+			want: want{
+				result:    store.KeyValues(fakeKV()),
+				immutable: true,
+			},
+		},
+		"ReadOptionFailed": {
+			reason: "Should return the error returned by a supplied read option",
+			args: args{
+				client: resource.ClientApplicator{
+					Client: &test.MockClient{
+						MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+							*obj.(*corev1.Secret) = corev1.Secret{
+								Data: fakeKV(),
+							}
+							return nil
+						}),
+					},
+				},
+				ro: []store.ReadOption{
+					func(_ context.Context, _ *store.Secret) error {
+						return errBoom
+					},
+				},
+			},
+			want: want{
+				result: store.KeyValues(fakeKV()),
+				err:    errBoom,
+			},
+		},
+		"SuccessfulReadOption": {
+			reason: "Should return key values as modified by supplied read options",
+			args: args{
+				client: resource.ClientApplicator{
+					Client: &test.MockClient{
+						MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+							*obj.(*corev1.Secret) = corev1.Secret{
+								Data: fakeKV(),
+							}
+							return nil
+						}),
+					},
+				},
+				ro: []store.ReadOption{
+					func(_ context.Context, s *store.Secret) error {
+						delete(s.Data, "key1")
+						return nil
+					},
+				},
+			},
+			want: want{
+				result: store.KeyValues{
+					"key2": []byte("value2"),
+					"key3": []byte("value3"),
+				},
+			},
+		},
 		"SecretNotFound": {
 			reason: "Should return nil as an error if secret is not found",
 			args: args{
@@ -144,7 +229,7 @@ func TestSecretStoreReadKeyValues(t *testing.T) {
 
 			s := &store.Secret{}
 			s.ScopedName = tc.args.n
-			err := ss.ReadKeyValues(context.Background(), tc.args.n, s)
+			err := ss.ReadKeyValues(context.Background(), tc.args.n, s, tc.args.ro...)
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\nss.ReadKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
 			}
@@ -152,6 +237,9 @@ func TestSecretStoreReadKeyValues(t *testing.T) {
 			if diff := cmp.Diff(tc.want.result, s.Data); diff != "" {
 				t.Errorf("\n%s\nss.ReadKeyValues(...): -want, +got:\n%s", tc.reason, diff)
 			}
+			if diff := cmp.Diff(tc.want.immutable, s.Immutable); diff != "" {
+				t.Errorf("\n%s\nss.ReadKeyValues(...): -want immutable, +got immutable:\n%s", tc.reason, diff)
+			}
 		})
 	}
 }
@@ -895,3 +983,811 @@ func fakeConnectionSecret(opts ...secretOption) *corev1.Secret {
 
 	return s
 }
+
+func TestNewSecretStoreWithRESTMapper(t *testing.T) {
+	var discovery, created bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api" || strings.HasPrefix(r.URL.Path, "/apis"):
+			// Simulate a remote API server with restricted discovery.
+			discovery = true
+			w.WriteHeader(http.StatusForbidden)
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/namespaces/"+fakeSecretNamespace+"/secrets/"+fakeSecretName:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(kerrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, fakeSecretName).ErrStatus)
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/namespaces/"+fakeSecretNamespace+"/secrets":
+			created = true
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(fakeConnectionSecret(withData(fakeKV())))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	kubeconfig := fmt.Sprintf(`
+apiVersion: v1
+clusters:
+- cluster:
+    server: %s
+  name: remote
+contexts:
+- context:
+    cluster: remote
+    user: remote
+  name: remote
+current-context: remote
+kind: Config
+users:
+- name: remote
+  user: {}
+`, srv.URL)
+
+	local := &test.MockClient{
+		MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+			*obj.(*corev1.Secret) = corev1.Secret{
+				Data: map[string][]byte{
+					"kubeconfig": []byte(kubeconfig),
+				},
+			}
+			return nil
+		}),
+	}
+	cfg := v1.SecretStoreConfig{
+		Type:         &storeTypeKubernetes,
+		DefaultScope: fakeSecretNamespace,
+		Kubernetes: &v1.KubernetesSecretStoreConfig{
+			Auth: v1.KubernetesAuthConfig{
+				Source: v1.CredentialsSourceSecret,
+				CommonCredentialSelectors: v1.CommonCredentialSelectors{
+					SecretRef: &v1.SecretKeySelector{
+						SecretReference: v1.SecretReference{
+							Name:      "kube-conn",
+							Namespace: fakeSecretNamespace,
+						},
+						Key: "kubeconfig",
+					},
+				},
+			},
+		},
+	}
+
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion})
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Secret"), meta.RESTScopeNamespace)
+
+	ss, err := NewSecretStore(context.Background(), local, nil, cfg, WithRESTMapper(mapper))
+	if err != nil {
+		t.Fatalf("NewSecretStore(...): %v", err)
+	}
+	if ss.client.RESTMapper() != meta.RESTMapper(mapper) {
+		t.Errorf("NewSecretStore(...): the supplied RESTMapper was not used by the remote client")
+	}
+
+	changed, err := ss.WriteKeyValues(context.Background(), &store.Secret{
+		ScopedName: store.ScopedName{Name: fakeSecretName},
+		Data:       store.KeyValues(fakeKV()),
+	})
+	if err != nil {
+		t.Fatalf("ss.WriteKeyValues(...): %v", err)
+	}
+	if !changed || !created {
+		t.Errorf("ss.WriteKeyValues(...): expected the secret to be created")
+	}
+	if discovery {
+		t.Errorf("ss.WriteKeyValues(...): expected no discovery requests when a RESTMapper is supplied")
+	}
+}
+
+// countingLogger counts the messages it logs at info level.
+type countingLogger struct {
+	infos *int
+}
+
+func (l countingLogger) Info(_ string, _ ...any)            { *l.infos++ }
+func (l countingLogger) Debug(_ string, _ ...any)           {}
+func (l countingLogger) WithValues(_ ...any) logging.Logger { return l }
+
+// countingRecorder records the events it is asked to record.
+type countingRecorder struct {
+	events []event.Event
+}
+
+func (r *countingRecorder) Event(_ runtime.Object, e event.Event)      { r.events = append(r.events, e) }
+func (r *countingRecorder) WithAnnotations(_ ...string) event.Recorder { return r }
+
+func TestSecretStoreWriteKeyValuesRemoteOwnership(t *testing.T) {
+	owned := &store.Secret{
+		ScopedName: store.ScopedName{Name: fakeSecretName, Scope: fakeSecretNamespace},
+		Metadata:   &v1.ConnectionSecretMetadata{Labels: map[string]string{v1.LabelKeyOwnerUID: fakeOwnerID}},
+		Data:       fakeKV(),
+	}
+	unowned := &store.Secret{
+		ScopedName: store.ScopedName{Name: fakeSecretName, Scope: fakeSecretNamespace},
+		Data:       fakeKV(),
+	}
+	unownedOther := &store.Secret{
+		ScopedName: store.ScopedName{Name: "other", Scope: fakeSecretNamespace},
+		Data:       fakeKV(),
+	}
+
+	type args struct {
+		remote  bool
+		o       []SecretStoreOption
+		secrets []*store.Secret
+	}
+	type want struct {
+		err      error
+		warnings int
+		events   int
+		applies  int
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"LocalUnowned": {
+			reason: "We should not warn about unowned writes to the local API server.",
+			args: args{
+				secrets: []*store.Secret{unowned},
+			},
+			want: want{
+				applies: 1,
+			},
+		},
+		"RemoteOwned": {
+			reason: "We should not warn about owned writes to a remote API server.",
+			args: args{
+				remote:  true,
+				secrets: []*store.Secret{owned},
+			},
+			want: want{
+				applies: 1,
+			},
+		},
+		"RemoteUnowned": {
+			reason: "We should warn once about unowned writes to a remote API server, even though each write uses a new SecretStore.",
+			args: args{
+				remote:  true,
+				secrets: []*store.Secret{unowned, unowned, owned},
+			},
+			want: want{
+				warnings: 1,
+				events:   1,
+				applies:  3,
+			},
+		},
+		"RemoteUnownedSecrets": {
+			reason: "We should warn once about unowned writes to each secret on a remote API server.",
+			args: args{
+				remote:  true,
+				secrets: []*store.Secret{unowned, unownedOther, unowned},
+			},
+			want: want{
+				warnings: 2,
+				events:   2,
+				applies:  3,
+			},
+		},
+		"RemoteUnownedRequireOwnership": {
+			reason: "We should refuse unowned writes to a remote API server if ownership is required.",
+			args: args{
+				remote:  true,
+				o:       []SecretStoreOption{WithRequireOwnership()},
+				secrets: []*store.Secret{unowned},
+			},
+			want: want{
+				err: errors.New(errUnownedRemoteWrite),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			warnings, applies := 0, 0
+			rec := &countingRecorder{}
+			unowned := newRemoteTargets()
+
+			var err error
+			for _, s := range tc.args.secrets {
+				// A SecretStore is usually built for each operation.
+				ss := &SecretStore{
+					client: resource.ClientApplicator{
+						Applicator: resource.ApplyFn(func(_ context.Context, _ client.Object, _ ...resource.ApplyOption) error {
+							applies++
+							return nil
+						}),
+					},
+					remote:   tc.args.remote,
+					log:      countingLogger{infos: &warnings},
+					recorder: rec,
+					unowned:  unowned,
+				}
+				for _, fn := range tc.args.o {
+					fn(ss)
+				}
+				if _, err = ss.WriteKeyValues(context.Background(), s); err != nil {
+					break
+				}
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.warnings, warnings); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want warnings, +got warnings:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.events, len(rec.events)); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want events, +got events:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.applies, applies); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want applies, +got applies:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSecretStoreDeleteKeyValuesConflictRetry(t *testing.T) {
+	errConflict := kerrors.NewConflict(schema.GroupResource{Resource: "secrets"}, fakeSecretName, errBoom)
+
+	type want struct {
+		err     error
+		updates int
+	}
+
+	cases := map[string]struct {
+		reason string
+		o      []SecretStoreOption
+		want   want
+	}{
+		"RetryEnabled": {
+			reason: "We should recover from a conflict by retrying the delete.",
+			want: want{
+				updates: 2,
+			},
+		},
+		"RetryDisabled": {
+			reason: "We should return a conflict immediately if retries are disabled.",
+			o:      []SecretStoreOption{WithoutDeleteConflictRetry()},
+			want: want{
+				err:     errors.Wrap(errConflict, errUpdateSecret),
+				updates: 1,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			updates := 0
+			ss := &SecretStore{
+				client: resource.ClientApplicator{
+					Client: &test.MockClient{
+						MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+							*obj.(*corev1.Secret) = corev1.Secret{Data: fakeKV()}
+							return nil
+						}),
+						MockUpdate: func(_ context.Context, _ client.Object, _ ...client.UpdateOption) error {
+							updates++
+							if updates == 1 {
+								return errConflict
+							}
+							return nil
+						},
+					},
+				},
+				deleteBackoff: retry.DefaultRetry,
+			}
+			for _, fn := range tc.o {
+				fn(ss)
+			}
+
+			err := ss.DeleteKeyValues(context.Background(), &store.Secret{
+				ScopedName: store.ScopedName{Name: fakeSecretName, Scope: fakeSecretNamespace},
+				Data:       store.KeyValues{"key1": []byte("val1")},
+			})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nss.DeleteKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.updates, updates); diff != "" {
+				t.Errorf("\n%s\nss.DeleteKeyValues(...): -want updates, +got updates:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSecretStoreDeleteKeyValuesEmptyKeySet(t *testing.T) {
+	type want struct {
+		deletes int
+		updates int
+	}
+
+	cases := map[string]struct {
+		reason string
+		data   store.KeyValues
+		o      []SecretStoreOption
+		want   want
+	}{
+		"EmptyIsNoOp": {
+			reason: "We should neither delete nor update the secret when asked to delete an empty set of keys.",
+			data:   store.KeyValues{},
+		},
+		"EmptyDeletesAll": {
+			reason: "We should delete the whole secret when asked to delete an empty set of keys, if configured to.",
+			data:   store.KeyValues{},
+			o:      []SecretStoreOption{WithEmptyKeySetDeletesAll()},
+			want: want{
+				deletes: 1,
+			},
+		},
+		"NilDeletesAll": {
+			reason: "We should delete the whole secret when asked to delete a nil set of keys.",
+			want: want{
+				deletes: 1,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			deletes, updates := 0, 0
+			ss := &SecretStore{
+				client: resource.ClientApplicator{
+					Client: &test.MockClient{
+						MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+							*obj.(*corev1.Secret) = corev1.Secret{Data: fakeKV()}
+							return nil
+						}),
+						MockUpdate: func(_ context.Context, _ client.Object, _ ...client.UpdateOption) error {
+							updates++
+							return nil
+						},
+						MockDelete: func(_ context.Context, _ client.Object, _ ...client.DeleteOption) error {
+							deletes++
+							return nil
+						},
+					},
+				},
+			}
+			for _, fn := range tc.o {
+				fn(ss)
+			}
+
+			err := ss.DeleteKeyValues(context.Background(), &store.Secret{
+				ScopedName: store.ScopedName{Name: fakeSecretName, Scope: fakeSecretNamespace},
+				Data:       tc.data,
+			})
+			if err != nil {
+				t.Fatalf("\n%s\nss.DeleteKeyValues(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, want{deletes: deletes, updates: updates}, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nss.DeleteKeyValues(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSecretStoreWriteKeyValuesRetry(t *testing.T) {
+	errConflict := kerrors.NewConflict(schema.GroupResource{Resource: "secrets"}, fakeSecretName, errBoom)
+	errUnavailable := kerrors.NewServiceUnavailable("boom")
+
+	type args struct {
+		err      error
+		failures int
+		o        []SecretStoreOption
+	}
+	type want struct {
+		err     error
+		applies int
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ConflictUsesConflictBackoff": {
+			reason: "We should retry conflicts per the conflict backoff, regardless of the write backoff.",
+			args: args{
+				err:      errConflict,
+				failures: 2,
+				o:        []SecretStoreOption{WithConflictBackoff(wait.Backoff{Steps: 3}), WithWriteBackoff(wait.Backoff{Steps: 1})},
+			},
+			want: want{
+				applies: 3,
+			},
+		},
+		"ConflictBackoffExhausted": {
+			reason: "We should return a conflict once the conflict backoff is exhausted.",
+			args: args{
+				err:      errConflict,
+				failures: 3,
+				o:        []SecretStoreOption{WithConflictBackoff(wait.Backoff{Steps: 2}), WithWriteBackoff(wait.Backoff{Steps: 5})},
+			},
+			want: want{
+				err:     errors.Wrap(errConflict, errApplySecret),
+				applies: 2,
+			},
+		},
+		"TransientErrorUsesWriteBackoff": {
+			reason: "We should retry other API errors per the write backoff, regardless of the conflict backoff.",
+			args: args{
+				err:      errUnavailable,
+				failures: 2,
+				o:        []SecretStoreOption{WithConflictBackoff(wait.Backoff{Steps: 1}), WithWriteBackoff(wait.Backoff{Steps: 3})},
+			},
+			want: want{
+				applies: 3,
+			},
+		},
+		"WriteBackoffExhausted": {
+			reason: "We should return other API errors once the write backoff is exhausted.",
+			args: args{
+				err:      errUnavailable,
+				failures: 3,
+				o:        []SecretStoreOption{WithConflictBackoff(wait.Backoff{Steps: 5}), WithWriteBackoff(wait.Backoff{Steps: 2})},
+			},
+			want: want{
+				err:     errors.Wrap(errUnavailable, errApplySecret),
+				applies: 2,
+			},
+		},
+		"NonAPIErrorNotRetried": {
+			reason: "We should not retry errors that were not returned by the API server.",
+			args: args{
+				err:      errBoom,
+				failures: 1,
+				o:        []SecretStoreOption{WithConflictBackoff(wait.Backoff{Steps: 5}), WithWriteBackoff(wait.Backoff{Steps: 5})},
+			},
+			want: want{
+				err:     errors.Wrap(errBoom, errApplySecret),
+				applies: 1,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			applies := 0
+			ss := &SecretStore{
+				client: resource.ClientApplicator{
+					Applicator: resource.ApplyFn(func(_ context.Context, _ client.Object, _ ...resource.ApplyOption) error {
+						applies++
+						if applies <= tc.args.failures {
+							return tc.args.err
+						}
+						return nil
+					}),
+				},
+			}
+			for _, fn := range tc.args.o {
+				fn(ss)
+			}
+
+			_, err := ss.WriteKeyValues(context.Background(), &store.Secret{
+				ScopedName: store.ScopedName{Name: fakeSecretName, Scope: fakeSecretNamespace},
+				Data:       fakeKV(),
+			})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.applies, applies); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want applies, +got applies:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSecretStoreWriteKeyValuesOwnerReferences(t *testing.T) {
+	owner := func(namespace string) *store.Owner {
+		return &store.Owner{
+			TypedReference: v1.TypedReference{APIVersion: "example.org/v1", Kind: "Cool", Name: "cool", UID: types.UID(fakeOwnerID)},
+			Namespace:      namespace,
+		}
+	}
+	ref := []metav1.OwnerReference{{
+		APIVersion:         "example.org/v1",
+		Kind:               "Cool",
+		Name:               "cool",
+		UID:                types.UID(fakeOwnerID),
+		Controller:         ptr.To(true),
+		BlockOwnerDeletion: ptr.To(true),
+	}}
+
+	type args struct {
+		remote bool
+		policy OwnerReferencePolicy
+		owner  *store.Owner
+	}
+	type want struct {
+		refs []metav1.OwnerReference
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ClusterScopedOwner": {
+			reason: "We should set an owner reference to a cluster scoped owner of a namespaced secret.",
+			args: args{
+				policy: OwnerReferencePolicyRequireSameNamespace,
+				owner:  owner(""),
+			},
+			want: want{
+				refs: ref,
+			},
+		},
+		"SameNamespaceOwner": {
+			reason: "We should set an owner reference to a namespaced owner in the same namespace.",
+			args: args{
+				policy: OwnerReferencePolicyRequireSameNamespace,
+				owner:  owner(fakeSecretNamespace),
+			},
+			want: want{
+				refs: ref,
+			},
+		},
+		"CrossNamespaceOwner": {
+			reason: "We should return an error if a namespaced owner is in another namespace.",
+			args: args{
+				policy: OwnerReferencePolicyRequireSameNamespace,
+				owner:  owner("other"),
+			},
+			want: want{
+				err: errors.Errorf(errFmtCrossNamespaceOwner, "Cool", "cool", "other", fakeSecretName, fakeSecretNamespace),
+			},
+		},
+		"CrossNamespaceOwnerFallback": {
+			reason: "We should only label the secret if a namespaced owner is in another namespace and we may fall back to labels.",
+			args: args{
+				policy: OwnerReferencePolicyFallbackToLabel,
+				owner:  owner("other"),
+			},
+		},
+		"LabelOnly": {
+			reason: "We should never set owner references if configured to only label secrets.",
+			args: args{
+				policy: OwnerReferencePolicyLabelOnly,
+				owner:  owner(""),
+			},
+		},
+		"Remote": {
+			reason: "We should never set owner references on a remote API server.",
+			args: args{
+				remote: true,
+				policy: OwnerReferencePolicyRequireSameNamespace,
+				owner:  owner(""),
+			},
+		},
+		"DeletePolicy": {
+			reason: "We should set an owner reference that blocks owner deletion if the owner uses the Delete deletion policy.",
+			args: args{
+				policy: OwnerReferencePolicyRequireSameNamespace,
+				owner: func() *store.Owner {
+					o := owner(fakeSecretNamespace)
+					o.DeletionPolicy = v1.DeletionDelete
+					return o
+				}(),
+			},
+			want: want{
+				refs: ref,
+			},
+		},
+		"OrphanPolicy": {
+			reason: "We should not set an owner reference if the owner uses the Orphan deletion policy.",
+			args: args{
+				policy: OwnerReferencePolicyRequireSameNamespace,
+				owner: func() *store.Owner {
+					o := owner(fakeSecretNamespace)
+					o.DeletionPolicy = v1.DeletionOrphan
+					return o
+				}(),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var refs []metav1.OwnerReference
+			ss := &SecretStore{
+				client: resource.ClientApplicator{
+					Applicator: resource.ApplyFn(func(_ context.Context, obj client.Object, _ ...resource.ApplyOption) error {
+						refs = obj.GetOwnerReferences()
+						return nil
+					}),
+				},
+				remote:    tc.args.remote,
+				ownerRefs: tc.args.policy,
+				log:       logging.NewNopLogger(),
+			}
+			_, err := ss.WriteKeyValues(context.Background(), &store.Secret{
+				ScopedName: store.ScopedName{Name: fakeSecretName, Scope: fakeSecretNamespace},
+				Metadata:   &v1.ConnectionSecretMetadata{Labels: map[string]string{v1.LabelKeyOwnerUID: fakeOwnerID}},
+				Owner:      tc.args.owner,
+			})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.refs, refs); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want owner references, +got owner references:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSecretStoreSecretFinalizer(t *testing.T) {
+	// current is the secret as stored by the API server, or nil if it does
+	// not exist. The API server only removes a deleted secret once it has no
+	// finalizers.
+	var current *corev1.Secret
+	remove := func(ks *corev1.Secret) {
+		if len(ks.GetFinalizers()) > 0 {
+			now := metav1.Now()
+			ks.SetDeletionTimestamp(&now)
+			current = ks
+			return
+		}
+		current = nil
+	}
+
+	ss := &SecretStore{
+		client: resource.ClientApplicator{
+			Client: &test.MockClient{
+				MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+					if current == nil {
+						return kerrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, fakeSecretName)
+					}
+					*obj.(*corev1.Secret) = *current.DeepCopy()
+					return nil
+				},
+				MockUpdate: func(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+					ks := obj.(*corev1.Secret).DeepCopy()
+					if ks.GetDeletionTimestamp() != nil {
+						remove(ks)
+						return nil
+					}
+					current = ks
+					return nil
+				},
+				MockDelete: func(_ context.Context, obj client.Object, _ ...client.DeleteOption) error {
+					remove(obj.(*corev1.Secret).DeepCopy())
+					return nil
+				},
+			},
+			Applicator: resource.ApplyFn(func(_ context.Context, obj client.Object, _ ...resource.ApplyOption) error {
+				current = obj.(*corev1.Secret).DeepCopy()
+				return nil
+			}),
+		},
+	}
+	WithSecretFinalizer()(ss)
+
+	n := store.ScopedName{Name: fakeSecretName, Scope: fakeSecretNamespace}
+	if _, err := ss.WriteKeyValues(context.Background(), &store.Secret{ScopedName: n, Data: fakeKV()}); err != nil {
+		t.Fatalf("ss.WriteKeyValues(...): %v", err)
+	}
+	if diff := cmp.Diff([]string{SecretFinalizer}, current.GetFinalizers()); diff != "" {
+		t.Errorf("ss.WriteKeyValues(...): -want finalizers, +got finalizers:\n%s", diff)
+	}
+
+	// The owner is deleted in the foreground, so the garbage collector deletes
+	// its secret before the owner's finalizer has cleaned up.
+	remove(current.DeepCopy())
+
+	// The secret should persist, and remain readable, while the owner's
+	// finalizer cleans up.
+	s := &store.Secret{}
+	if err := ss.ReadKeyValues(context.Background(), n, s); err != nil {
+		t.Fatalf("ss.ReadKeyValues(...): %v", err)
+	}
+	if diff := cmp.Diff(fakeKV(), map[string][]byte(s.Data)); diff != "" {
+		t.Errorf("ss.ReadKeyValues(...) during cleanup: -want, +got:\n%s", diff)
+	}
+
+	// Once cleanup is complete the owner's finalizer deletes the secret, which
+	// should remove our finalizer and let the secret go away.
+	if err := ss.DeleteKeyValues(context.Background(), &store.Secret{ScopedName: n}); err != nil {
+		t.Fatalf("ss.DeleteKeyValues(...): %v", err)
+	}
+	if current != nil {
+		t.Errorf("ss.DeleteKeyValues(...): want secret to be removed, got %v", current)
+	}
+}
+
+func TestSecretStoreWriteKeyValuesCrossNamespaceOwner(t *testing.T) {
+	owner := &store.Owner{
+		TypedReference: v1.TypedReference{APIVersion: "example.org/v1", Kind: "Cool", Name: "cool", UID: types.UID(fakeOwnerID)},
+		Namespace:      fakeSecretNamespace,
+	}
+
+	type args struct {
+		policy           OwnerReferencePolicy
+		scope            string
+		defaultNamespace string
+	}
+	type want struct {
+		refs int
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"SameNamespace": {
+			reason: "We should reference an owner in the same namespace as its secret.",
+			args: args{
+				policy: OwnerReferencePolicyRequireSameNamespace,
+				scope:  fakeSecretNamespace,
+			},
+			want: want{
+				refs: 1,
+			},
+		},
+		"CrossNamespaceReject": {
+			reason: "We should reject writing a secret to another namespace than its owner's.",
+			args: args{
+				policy: OwnerReferencePolicyRequireSameNamespace,
+				scope:  "other",
+			},
+			want: want{
+				err: errors.Errorf(errFmtCrossNamespaceOwner, "Cool", "cool", fakeSecretNamespace, fakeSecretName, "other"),
+			},
+		},
+		"CrossDefaultNamespaceReject": {
+			reason: "We should detect that an unscoped secret is written to a default namespace other than its owner's.",
+			args: args{
+				policy:           OwnerReferencePolicyRequireSameNamespace,
+				defaultNamespace: "other",
+			},
+			want: want{
+				err: errors.Errorf(errFmtCrossNamespaceOwner, "Cool", "cool", fakeSecretNamespace, fakeSecretName, "other"),
+			},
+		},
+		"CrossNamespaceLabel": {
+			reason: "We should track ownership of a secret in another namespace than its owner's using only a label.",
+			args: args{
+				policy: OwnerReferencePolicyFallbackToLabel,
+				scope:  "other",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var written *corev1.Secret
+			ss := &SecretStore{
+				client: resource.ClientApplicator{
+					Applicator: resource.ApplyFn(func(_ context.Context, obj client.Object, _ ...resource.ApplyOption) error {
+						written = obj.(*corev1.Secret)
+						return nil
+					}),
+				},
+				defaultNamespace: tc.args.defaultNamespace,
+				ownerRefs:        tc.args.policy,
+				log:              logging.NewNopLogger(),
+			}
+			_, err := ss.WriteKeyValues(context.Background(), &store.Secret{
+				ScopedName: store.ScopedName{Name: fakeSecretName, Scope: tc.args.scope},
+				Metadata:   &v1.ConnectionSecretMetadata{Labels: map[string]string{v1.LabelKeyOwnerUID: fakeOwnerID}},
+				Owner:      owner,
+			})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.refs, len(written.GetOwnerReferences())); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want owner references, +got owner references:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(fakeOwnerID, written.GetLabels()[v1.LabelKeyOwnerUID]); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want owner label, +got owner label:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
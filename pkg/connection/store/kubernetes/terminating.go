@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// ErrSecretTerminating is returned when writing a secret that is being
+// deleted. Writing over a secret that is being deleted would race with its
+// deletion.
+var ErrSecretTerminating = errors.New("secret is being deleted")
+
+// WithWaitForTerminating configures a SecretStore to wait for a secret that is
+// being deleted to be deleted before writing it, which recreates it. The
+// secret is checked per the supplied backoff, and ErrSecretTerminating is
+// returned if it is still being deleted when the backoff is exhausted. By
+// default ErrSecretTerminating is returned immediately, so that the write can
+// be retried later.
+func WithWaitForTerminating(b wait.Backoff) SecretStoreOption {
+	return func(ss *SecretStore) {
+		ss.terminatingBackoff = &b
+	}
+}
+
+// refuseTerminating is an ApplyOption that refuses to write over a current
+// secret that is being deleted.
+func refuseTerminating(_ context.Context, current, _ runtime.Object) error {
+	if current.(*corev1.Secret).GetDeletionTimestamp() != nil { //nolint:forcetypeassert // Will always be a secret.
+		return ErrSecretTerminating
+	}
+	return nil
+}
+
+// waitForDeletion waits for the supplied secret to be deleted, per the
+// SecretStore's terminating backoff. It returns ErrSecretTerminating if the
+// SecretStore doesn't wait for secrets to be deleted, or if the secret was
+// not deleted in time.
+func (ss *SecretStore) waitForDeletion(ctx context.Context, nn types.NamespacedName) error {
+	if ss.terminatingBackoff == nil {
+		return ErrSecretTerminating
+	}
+	err := wait.ExponentialBackoffWithContext(ctx, *ss.terminatingBackoff, func(ctx context.Context) (bool, error) {
+		ks := &corev1.Secret{}
+		err := ss.client.Get(ctx, nn, ks)
+		if kerrors.IsNotFound(err) {
+			return true, nil
+		}
+		if err != nil {
+			return false, errors.Wrap(withUnauthenticated(withRBACHint(err)), errGetSecret)
+		}
+		// The secret may have been recreated by someone else.
+		return ks.GetDeletionTimestamp() == nil, nil
+	})
+	if wait.Interrupted(err) {
+		return ErrSecretTerminating
+	}
+	return err
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestSecretStoreWriteKeyValuesTerminating(t *testing.T) {
+	terminating := func() *corev1.Secret {
+		now := metav1.Now()
+		s := fakeConnectionSecret(withData(map[string][]byte{"old": []byte("value")}))
+		s.SetDeletionTimestamp(&now)
+		s.SetFinalizers([]string{"example.org/finalizer"})
+		return s
+	}
+
+	type args struct {
+		o []SecretStoreOption
+
+		// deletedAfter is the number of gets after which the terminating
+		// secret is deleted.
+		deletedAfter int
+	}
+	type want struct {
+		changed bool
+		created map[string][]byte
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"FailFast": {
+			reason: "We should return ErrSecretTerminating by default if the secret is being deleted.",
+			args: args{
+				deletedAfter: 2,
+			},
+			want: want{
+				err: errors.Wrap(ErrSecretTerminating, errApplySecret),
+			},
+		},
+		"WaitAndRecreate": {
+			reason: "We should wait for a secret that is being deleted to be deleted, then recreate it.",
+			args: args{
+				o:            []SecretStoreOption{WithWaitForTerminating(wait.Backoff{Steps: 5})},
+				deletedAfter: 2,
+			},
+			want: want{
+				changed: true,
+				created: fakeKV(),
+			},
+		},
+		"WaitTimedOut": {
+			reason: "We should return ErrSecretTerminating if the secret is still being deleted once we're done waiting.",
+			args: args{
+				o:            []SecretStoreOption{WithWaitForTerminating(wait.Backoff{Steps: 2})},
+				deletedAfter: 10,
+			},
+			want: want{
+				err: errors.Wrap(ErrSecretTerminating, errApplySecret),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			gets := 0
+			var created map[string][]byte
+			kube := &test.MockClient{
+				MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+					gets++
+					if gets > tc.args.deletedAfter {
+						return kerrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, fakeSecretName)
+					}
+					*obj.(*corev1.Secret) = *terminating()
+					return nil
+				},
+				MockCreate: func(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+					created = obj.(*corev1.Secret).Data
+					return nil
+				},
+				MockPatch: test.NewMockPatchFn(errors.New("should not patch a secret that is being deleted")),
+			}
+			ss := &SecretStore{
+				client: resource.ClientApplicator{
+					Client:     kube,
+					Applicator: resource.NewAPIPatchingApplicator(kube),
+				},
+			}
+			for _, fn := range tc.args.o {
+				fn(ss)
+			}
+
+			changed, err := ss.WriteKeyValues(context.Background(), &store.Secret{
+				ScopedName: store.ScopedName{Name: fakeSecretName, Scope: fakeSecretNamespace},
+				Data:       fakeKV(),
+			})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if !errors.Is(err, ErrSecretTerminating) && tc.want.err != nil {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): want errors.Is(err, ErrSecretTerminating)", tc.reason)
+			}
+			if diff := cmp.Diff(tc.want.changed, changed); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want changed, +got changed:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.created, created); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want created, +got created:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// WithRemoteCABundle configures a SecretStore that stores secrets on a remote
+// Kubernetes API server to verify the API server's certificate using the
+// supplied PEM encoded CA bundle, rather than the CA of its kubeconfig. This is
+// useful for API servers with certificates signed by a self-signed CA.
+func WithRemoteCABundle(pem []byte) SecretStoreOption {
+	return func(ss *SecretStore) {
+		ss.caBundle = pem
+	}
+}
+
+// WithRemoteInsecureSkipVerify configures a SecretStore that stores secrets
+// on a remote Kubernetes API server not to verify the API server's
+// certificate. This makes connections to the API server vulnerable to man in
+// the middle attacks, so a warning is logged whenever a client is built with
+// it. It takes precedence over WithRemoteCABundle. The API server's
+// certificate is verified by default.
+func WithRemoteInsecureSkipVerify() SecretStoreOption {
+	return func(ss *SecretStore) {
+		ss.insecureSkipVerify = true
+	}
+}
+
+// clientForKubeconfig returns a client for the remote Kubernetes API server
+// described by the supplied kubeconfig.
+func (ss *SecretStore) clientForKubeconfig(kfg []byte) (client.Client, error) {
+	config, err := ss.restConfigForKubeconfig(kfg)
+	if err != nil {
+		return nil, err
+	}
+	return client.New(config, client.Options{Mapper: ss.restMapper})
+}
+
+// restConfigForKubeconfig returns a REST config for the remote Kubernetes API
+// server described by the supplied kubeconfig, with the SecretStore's TLS
+// configuration applied.
+func (ss *SecretStore) restConfigForKubeconfig(kfg []byte) (*rest.Config, error) {
+	config, err := clientcmd.RESTConfigFromKubeConfig(kfg)
+	if err != nil {
+		return nil, errors.Wrap(err, errBuildRestConfig)
+	}
+	ss.server = config.Host
+
+	switch {
+	case ss.insecureSkipVerify:
+		ss.log.Info("WARNING: TLS verification of the remote Kubernetes API server is disabled. Connections to it are vulnerable to man in the middle attacks, and secrets may be disclosed. Do not use this in production.", "server", config.Host)
+		// Client-go refuses to skip verification when a CA is configured.
+		config.Insecure = true
+		config.CAData, config.CAFile = nil, ""
+	case len(ss.caBundle) > 0:
+		config.CAData, config.CAFile = ss.caBundle, ""
+	}
+	return config, nil
+}
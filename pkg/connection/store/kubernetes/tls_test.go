@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// kubeconfig describes a remote API server whose certificate is signed by a
+// CA with the PEM encoded certificate "kubeconfig-ca".
+const kubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: remote
+  cluster:
+    server: https://remote.example.org
+    certificate-authority-data: a3ViZWNvbmZpZy1jYQ==
+contexts:
+- name: remote
+  context:
+    cluster: remote
+    user: remote
+current-context: remote
+users:
+- name: remote
+  user:
+    token: t0k3n
+`
+
+func TestRestConfigForKubeconfig(t *testing.T) {
+	type want struct {
+		caData   []byte
+		insecure bool
+		warnings int
+	}
+
+	cases := map[string]struct {
+		reason string
+		o      []SecretStoreOption
+		want   want
+	}{
+		"KubeconfigCA": {
+			reason: "We should verify the API server's certificate using the kubeconfig's CA by default.",
+			want: want{
+				caData: []byte("kubeconfig-ca"),
+			},
+		},
+		"CABundle": {
+			reason: "We should verify the API server's certificate using the supplied CA bundle.",
+			o:      []SecretStoreOption{WithRemoteCABundle([]byte("bundle-ca"))},
+			want: want{
+				caData: []byte("bundle-ca"),
+			},
+		},
+		"InsecureSkipVerify": {
+			reason: "We should skip verifying the API server's certificate, and log a warning, when asked to.",
+			o:      []SecretStoreOption{WithRemoteCABundle([]byte("bundle-ca")), WithRemoteInsecureSkipVerify()},
+			want: want{
+				insecure: true,
+				warnings: 1,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			warnings := 0
+			ss := &SecretStore{log: countingLogger{infos: &warnings}}
+			for _, fn := range tc.o {
+				fn(ss)
+			}
+			config, err := ss.restConfigForKubeconfig([]byte(kubeconfig))
+			if err != nil {
+				t.Fatalf("\n%s\nss.restConfigForKubeconfig(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.caData, config.CAData); diff != "" {
+				t.Errorf("\n%s\nss.restConfigForKubeconfig(...): -want CA data, +got CA data:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.insecure, config.Insecure); diff != "" {
+				t.Errorf("\n%s\nss.restConfigForKubeconfig(...): -want insecure, +got insecure:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.warnings, warnings); diff != "" {
+				t.Errorf("\n%s\nss.restConfigForKubeconfig(...): -want warnings, +got warnings:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff("https://remote.example.org", ss.server); diff != "" {
+				t.Errorf("\n%s\nss.restConfigForKubeconfig(...): -want server, +got server:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// AnnotationKeyVersion is the annotation a SecretStore configured
+// WithVersionCounter records the version of a secret's data in.
+const AnnotationKeyVersion = "secret.crossplane.io/version"
+
+// Error strings.
+const (
+	errFmtParseVersion = "cannot parse version %q of secret"
+)
+
+// WithVersionCounter configures a SecretStore to record a version counter in
+// the AnnotationKeyVersion annotation of the secrets it writes. The version of
+// a new secret is 1, and is incremented each time the secret's data changes.
+// Writes that don't change the secret's data, for example no-op writes, don't
+// increment its version. Consumers that cache a secret's values may use
+// Version to cheaply detect whether they changed.
+func WithVersionCounter() SecretStoreOption {
+	return func(ss *SecretStore) {
+		ss.versioned = true
+	}
+}
+
+// Version returns the version of the supplied secret, as recorded by a
+// SecretStore configured WithVersionCounter. It returns 0 if the secret does
+// not exist or has no version.
+func (ss *SecretStore) Version(ctx context.Context, n store.ScopedName) (int64, error) {
+	s := &store.Secret{}
+	if err := ss.ReadKeyValues(ctx, n, s); err != nil {
+		return 0, err
+	}
+	return parseVersion(s.Metadata.Annotations)
+}
+
+// setInitialVersion sets the version of the supplied secret to 1, which is
+// the version it will have if the write creates it.
+func (ss *SecretStore) setInitialVersion(ks *corev1.Secret) {
+	if !ss.versioned {
+		return
+	}
+	ks.SetAnnotations(withVersion(ks.GetAnnotations(), 1))
+}
+
+// incrementVersion is an ApplyOption that increments the version of the
+// current secret if the desired secret's data differs from its data, and
+// otherwise keeps it. It must run after any ApplyOptions that change the
+// desired secret's data.
+func (ss *SecretStore) incrementVersion(_ context.Context, current, desired runtime.Object) error {
+	if !ss.versioned {
+		return nil
+	}
+	cs := current.(*corev1.Secret) //nolint:forcetypeassert // Will always be a secret.
+	ds := desired.(*corev1.Secret) //nolint:forcetypeassert // Will always be a secret.
+
+	v, err := parseVersion(cs.GetAnnotations())
+	if err != nil {
+		return err
+	}
+	if !store.Equal(cs.Data, ds.Data) {
+		v++
+	}
+	ds.SetAnnotations(withVersion(ds.GetAnnotations(), v))
+	return nil
+}
+
+func parseVersion(annotations map[string]string) (int64, error) {
+	s, ok := annotations[AnnotationKeyVersion]
+	if !ok {
+		return 0, nil
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	return v, errors.Wrapf(err, errFmtParseVersion, s)
+}
+
+// withVersion returns a copy of the supplied annotations with the supplied
+// version, so that annotations shared with the secret's owner aren't mutated.
+func withVersion(annotations map[string]string, v int64) map[string]string {
+	out := make(map[string]string, len(annotations)+1)
+	for k, val := range annotations {
+		out[k] = val
+	}
+	out[AnnotationKeyVersion] = strconv.FormatInt(v, 10)
+	return out
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestSecretStoreVersionCounter(t *testing.T) {
+	// Our fake applicator persists the secrets it applies, much like an API
+	// server would.
+	var current *corev1.Secret
+	ss := &SecretStore{
+		client: resource.ClientApplicator{
+			Client: &test.MockClient{
+				MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+					if current != nil {
+						current.DeepCopyInto(obj.(*corev1.Secret))
+					}
+					return nil
+				},
+			},
+			Applicator: resource.ApplyFn(func(ctx context.Context, obj client.Object, ao ...resource.ApplyOption) error {
+				if current == nil {
+					// Apply options are not called when creating a secret.
+					current = obj.(*corev1.Secret).DeepCopy()
+					return nil
+				}
+				for _, fn := range ao {
+					if err := fn(ctx, current.DeepCopy(), obj); err != nil {
+						return err
+					}
+				}
+				current = obj.(*corev1.Secret).DeepCopy()
+				return nil
+			}),
+		},
+	}
+	WithVersionCounter()(ss)
+
+	n := store.ScopedName{Name: fakeSecretName, Scope: fakeSecretNamespace}
+	writes := []struct {
+		reason  string
+		data    store.KeyValues
+		changed bool
+		version int64
+	}{
+		{reason: "Creating a secret should set its version to 1.", data: store.KeyValues{"password": []byte("a")}, changed: true, version: 1},
+		{reason: "A no-op write should not increment the version.", data: store.KeyValues{"password": []byte("a")}, changed: false, version: 1},
+		{reason: "Changing the data should increment the version.", data: store.KeyValues{"password": []byte("b")}, changed: true, version: 2},
+		{reason: "Adding a key should increment the version.", data: store.KeyValues{"password": []byte("b"), "user": []byte("c")}, changed: true, version: 3},
+		{reason: "A no-op write should not increment the version.", data: store.KeyValues{"password": []byte("b"), "user": []byte("c")}, changed: false, version: 3},
+	}
+
+	for i, w := range writes {
+		changed, err := ss.WriteKeyValues(context.Background(), &store.Secret{ScopedName: n, Data: w.data})
+		if err != nil {
+			t.Fatalf("\n%s\nss.WriteKeyValues(...): write %d: %v", w.reason, i, err)
+		}
+		if diff := cmp.Diff(w.changed, changed); diff != "" {
+			t.Errorf("\n%s\nss.WriteKeyValues(...): write %d: -want changed, +got changed:\n%s", w.reason, i, diff)
+		}
+		v, err := ss.Version(context.Background(), n)
+		if err != nil {
+			t.Fatalf("\n%s\nss.Version(...): write %d: %v", w.reason, i, err)
+		}
+		if diff := cmp.Diff(w.version, v); diff != "" {
+			t.Errorf("\n%s\nss.Version(...): write %d: -want, +got:\n%s", w.reason, i, diff)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errFmtReservedLabel      = "label %q is reserved for use by Crossplane"
+	errFmtReservedAnnotation = "annotation %q is reserved for use by Crossplane"
+)
+
+// ReservedDomain is the domain of the label and annotation keys Crossplane
+// reserves for its own use, e.g. to track the ownership of secrets. Keys
+// prefixed by the domain, or by any of its subdomains, are reserved.
+const ReservedDomain = "crossplane.io"
+
+// ValidateMetadata returns an error for each label or annotation of the
+// supplied metadata whose key is reserved, per ReservedDomain, so that the
+// metadata a user configures can't override the metadata Crossplane relies
+// on. The owner UID label is allowed if its value is the supplied owner UID,
+// since this is the value Crossplane would set. Keys are validated in sorted
+// order.
+func ValidateMetadata(m *v1.ConnectionSecretMetadata, owner types.UID) error {
+	if m == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, k := range sortedKeys(m.Labels) {
+		if k == v1.LabelKeyOwnerUID && m.Labels[k] == string(owner) {
+			continue
+		}
+		if IsReservedKey(k) {
+			errs = append(errs, errors.Errorf(errFmtReservedLabel, k))
+		}
+	}
+	for _, k := range sortedKeys(m.Annotations) {
+		if IsReservedKey(k) {
+			errs = append(errs, errors.Errorf(errFmtReservedAnnotation, k))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// IsReservedKey returns true if the supplied label or annotation key is
+// reserved, per ReservedDomain.
+func IsReservedKey(k string) bool {
+	prefix, _, ok := strings.Cut(k, "/")
+	if !ok {
+		return false
+	}
+	return prefix == ReservedDomain || strings.HasSuffix(prefix, "."+ReservedDomain)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
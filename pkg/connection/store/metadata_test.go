@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/types"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestValidateMetadata(t *testing.T) {
+	const owner = types.UID("cool-uid")
+
+	cases := map[string]struct {
+		reason string
+		m      *v1.ConnectionSecretMetadata
+		want   error
+	}{
+		"NoMetadata": {
+			reason: "We should accept nil metadata.",
+		},
+		"Unreserved": {
+			reason: "We should accept labels and annotations whose keys aren't reserved.",
+			m: &v1.ConnectionSecretMetadata{
+				Labels:      map[string]string{"app": "cool", "example.org/team": "platform", "notcrossplane.io/key": "v"},
+				Annotations: map[string]string{"replicator.v1.mittwald.de/replicate-to": "ns"},
+			},
+		},
+		"OwnerUID": {
+			reason: "We should accept the owner UID label if its value is the owner's UID.",
+			m: &v1.ConnectionSecretMetadata{
+				Labels: map[string]string{v1.LabelKeyOwnerUID: string(owner)},
+			},
+		},
+		"Reserved": {
+			reason: "We should reject each label and annotation whose key is reserved.",
+			m: &v1.ConnectionSecretMetadata{
+				Labels:      map[string]string{v1.LabelKeyOwnerUID: "other-uid", "crossplane.io/provider-config": "default", "app": "cool"},
+				Annotations: map[string]string{"connection.crossplane.io/key-order": "a,b"},
+			},
+			want: errors.Join(
+				errors.Errorf(errFmtReservedLabel, "crossplane.io/provider-config"),
+				errors.Errorf(errFmtReservedLabel, v1.LabelKeyOwnerUID),
+				errors.Errorf(errFmtReservedAnnotation, "connection.crossplane.io/key-order"),
+			),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateMetadata(tc.m, owner)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nValidateMetadata(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
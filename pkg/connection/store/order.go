@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"encoding/json"
+	"sort"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// AnnotationKeyKeyOrder is the annotation used to record the logical order of
+// the keys of a secret, whose data is otherwise unordered.
+const AnnotationKeyKeyOrder = "connection.crossplane.io/key-order"
+
+// A KeyValue is a single key of a secret and its value.
+type KeyValue struct {
+	Key   string
+	Value []byte
+}
+
+// SetKeyOrder records the supplied logical order of the secret's keys as an
+// annotation, so that it is written along with the secret.
+func (s *Secret) SetKeyOrder(keys ...string) {
+	b, _ := json.Marshal(keys) //nolint:errchkjson // Marshalling a string slice can't fail.
+	if s.Metadata == nil {
+		s.Metadata = &v1.ConnectionSecretMetadata{}
+	}
+	if s.Metadata.Annotations == nil {
+		s.Metadata.Annotations = map[string]string{}
+	}
+	s.Metadata.Annotations[AnnotationKeyKeyOrder] = string(b)
+}
+
+// GetKeyOrder returns the logical order of the secret's keys recorded by
+// SetKeyOrder, if any.
+func (s *Secret) GetKeyOrder() []string {
+	if s.Metadata == nil {
+		return nil
+	}
+	a, ok := s.Metadata.Annotations[AnnotationKeyKeyOrder]
+	if !ok {
+		return nil
+	}
+	var keys []string
+	if err := json.Unmarshal([]byte(a), &keys); err != nil {
+		// An invalid annotation is treated as no recorded order.
+		return nil
+	}
+	return keys
+}
+
+// Ordered returns the secret's key values in the order recorded by
+// SetKeyOrder. Keys whose order was not recorded follow in lexical order.
+// Recorded keys that don't exist are omitted.
+func (s *Secret) Ordered() []KeyValue {
+	out := make([]KeyValue, 0, len(s.Data))
+	seen := make(map[string]bool, len(s.Data))
+	for _, k := range s.GetKeyOrder() {
+		v, ok := s.Data[k]
+		if !ok || seen[k] {
+			continue
+		}
+		seen[k] = true
+		out = append(out, KeyValue{Key: k, Value: v})
+	}
+
+	rest := make([]string, 0, len(s.Data)-len(seen))
+	for k := range s.Data {
+		if !seen[k] {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	for _, k := range rest {
+		out = append(out, KeyValue{Key: k, Value: s.Data[k]})
+	}
+	return out
+}
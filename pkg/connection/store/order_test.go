@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+func TestSecretKeyOrder(t *testing.T) {
+	data := KeyValues{
+		"root.crt":         []byte("root"),
+		"intermediate.crt": []byte("intermediate"),
+		"leaf.crt":         []byte("leaf"),
+		"extra":            []byte("extra"),
+	}
+
+	cases := map[string]struct {
+		reason string
+		secret func() *Secret
+		want   []KeyValue
+	}{
+		"RoundTrip": {
+			reason: "We should return keys in the recorded order once the annotation round trips, followed by unordered keys.",
+			secret: func() *Secret {
+				written := &Secret{Data: data}
+				written.SetKeyOrder("leaf.crt", "intermediate.crt", "missing.crt", "root.crt")
+				// Simulate reading back only the annotations that were written.
+				return &Secret{
+					Data:     data,
+					Metadata: &v1.ConnectionSecretMetadata{Annotations: written.Metadata.Annotations},
+				}
+			},
+			want: []KeyValue{
+				{Key: "leaf.crt", Value: []byte("leaf")},
+				{Key: "intermediate.crt", Value: []byte("intermediate")},
+				{Key: "root.crt", Value: []byte("root")},
+				{Key: "extra", Value: []byte("extra")},
+			},
+		},
+		"NoOrder": {
+			reason: "We should return keys in lexical order if no order was recorded.",
+			secret: func() *Secret { return &Secret{Data: data} },
+			want: []KeyValue{
+				{Key: "extra", Value: []byte("extra")},
+				{Key: "intermediate.crt", Value: []byte("intermediate")},
+				{Key: "leaf.crt", Value: []byte("leaf")},
+				{Key: "root.crt", Value: []byte("root")},
+			},
+		},
+		"InvalidOrder": {
+			reason: "We should ignore an invalid order annotation.",
+			secret: func() *Secret {
+				return &Secret{
+					Data:     KeyValues{"b": []byte("b"), "a": []byte("a")},
+					Metadata: &v1.ConnectionSecretMetadata{Annotations: map[string]string{AnnotationKeyKeyOrder: "b,a"}},
+				}
+			},
+			want: []KeyValue{
+				{Key: "a", Value: []byte("a")},
+				{Key: "b", Value: []byte("b")},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.secret().Ordered()
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\ns.Ordered(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
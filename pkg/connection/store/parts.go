@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errFmtMissingPart   = "cannot reassemble key %q: missing part %d of %d"
+	errFmtDuplicatePart = "cannot reassemble key %q: duplicate part %d"
+	errFmtPartCollision = "cannot reassemble key %q: the secret already has a value for it"
+)
+
+var partKey = regexp.MustCompile(`^(.+)\.part(\d+)$`)
+
+// JoinParts returns a ReadOption that reassembles values that were split into
+// multiple parts. Keys suffixed with .part0, .part1, etc. are concatenated in
+// order, and returned as their base key. For example the values of keys
+// ca.crt.part0 and ca.crt.part1 are returned as key ca.crt. It returns an
+// error if a part is missing from the sequence. Keys that are not part
+// suffixed are returned as is.
+func JoinParts() ReadOption {
+	return func(_ context.Context, s *Secret) error {
+		parts := map[string]map[int]string{}
+		for k := range s.Data {
+			m := partKey.FindStringSubmatch(k)
+			if m == nil {
+				continue
+			}
+			i, err := strconv.Atoi(m[2])
+			if err != nil {
+				continue
+			}
+			if parts[m[1]] == nil {
+				parts[m[1]] = map[int]string{}
+			}
+			if _, ok := parts[m[1]][i]; ok {
+				return errors.Errorf(errFmtDuplicatePart, m[1], i)
+			}
+			parts[m[1]][i] = k
+		}
+
+		// Iterate in a deterministic order so errors are stable.
+		bases := make([]string, 0, len(parts))
+		for b := range parts {
+			bases = append(bases, b)
+		}
+		sort.Strings(bases)
+
+		for _, b := range bases {
+			if _, ok := s.Data[b]; ok {
+				return errors.Errorf(errFmtPartCollision, b)
+			}
+			n := 0
+			for i := range parts[b] {
+				n = max(n, i+1)
+			}
+			var v []byte
+			for i := range n {
+				k, ok := parts[b][i]
+				if !ok {
+					return errors.Errorf(errFmtMissingPart, b, i, n)
+				}
+				v = append(v, s.Data[k]...)
+			}
+			for _, k := range parts[b] {
+				delete(s.Data, k)
+			}
+			s.Data[b] = v
+		}
+		return nil
+	}
+}
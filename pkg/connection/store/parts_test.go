@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestJoinParts(t *testing.T) {
+	type want struct {
+		data KeyValues
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		data   KeyValues
+		want   want
+	}{
+		"Reassembled": {
+			reason: "We should concatenate part suffixed keys in order into their base key.",
+			data: KeyValues{
+				"ca.crt.part2":  []byte("c"),
+				"ca.crt.part0":  []byte("a"),
+				"ca.crt.part1":  []byte("b"),
+				"ca.crt.part10": []byte("k"),
+				"ca.crt.part3":  []byte("d"),
+				"ca.crt.part4":  []byte("e"),
+				"ca.crt.part5":  []byte("f"),
+				"ca.crt.part6":  []byte("g"),
+				"ca.crt.part7":  []byte("h"),
+				"ca.crt.part8":  []byte("i"),
+				"ca.crt.part9":  []byte("j"),
+			},
+			want: want{
+				data: KeyValues{"ca.crt": []byte("abcdefghijk")},
+			},
+		},
+		"PassThrough": {
+			reason: "We should return keys that are not part suffixed as is.",
+			data: KeyValues{
+				"username":    []byte("admin"),
+				"token.part0": []byte("to"),
+				"token.part1": []byte("ken"),
+				"partial":     []byte("p"),
+			},
+			want: want{
+				data: KeyValues{"username": []byte("admin"), "token": []byte("token"), "partial": []byte("p")},
+			},
+		},
+		"Gap": {
+			reason: "We should return an error if a part is missing from the sequence.",
+			data: KeyValues{
+				"token.part0": []byte("to"),
+				"token.part2": []byte("en"),
+			},
+			want: want{
+				err: errors.Errorf(errFmtMissingPart, "token", 1, 3),
+			},
+		},
+		"Collision": {
+			reason: "We should return an error if the secret already has a value for a base key.",
+			data: KeyValues{
+				"token":       []byte("token"),
+				"token.part0": []byte("to"),
+			},
+			want: want{
+				err: errors.Errorf(errFmtPartCollision, "token"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := &Secret{Data: tc.data}
+			err := JoinParts()(context.Background(), s)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nJoinParts(): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.data, s.Data); diff != "" {
+				t.Errorf("\n%s\nJoinParts(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
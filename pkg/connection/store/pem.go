@@ -0,0 +1,67 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errFmtMissingPEMKey   = "secret is missing key %q"
+	errFmtMalformedPEM    = "value of key %q is not valid PEM"
+	errFmtCertKeyMismatch = "certificate %q and private key %q are not a valid pair"
+)
+
+// A CertificatePair identifies the keys of a secret that hold a PEM encoded
+// certificate and its private key.
+type CertificatePair struct {
+	// Certificate is the key holding the PEM encoded certificate, e.g.
+	// tls.crt.
+	Certificate string
+
+	// PrivateKey is the key holding the PEM encoded private key, e.g.
+	// tls.key.
+	PrivateKey string
+}
+
+// ValidateCertificates returns a ReadOption that returns an error unless each
+// of the supplied certificate pairs is present, PEM encoded, and consists of a
+// certificate and the private key that matches it.
+func ValidateCertificates(pairs ...CertificatePair) ReadOption {
+	return func(_ context.Context, s *Secret) error {
+		for _, p := range pairs {
+			for _, k := range []string{p.Certificate, p.PrivateKey} {
+				v, ok := s.Data[k]
+				if !ok {
+					return errors.Errorf(errFmtMissingPEMKey, k)
+				}
+				if b, _ := pem.Decode(v); b == nil {
+					return errors.Errorf(errFmtMalformedPEM, k)
+				}
+			}
+			if _, err := tls.X509KeyPair(s.Data[p.Certificate], s.Data[p.PrivateKey]); err != nil {
+				return errors.Wrapf(err, errFmtCertKeyMismatch, p.Certificate, p.PrivateKey)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+// certificate returns a PEM encoded self signed certificate and its PEM
+// encoded private key.
+func certificate(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey(...): %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "cool"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("x509.CreateCertificate(...): %v", err)
+	}
+	kder, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("x509.MarshalECPrivateKey(...): %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: kder})
+}
+
+func TestValidateCertificates(t *testing.T) {
+	cert, key := certificate(t)
+	_, otherKey := certificate(t)
+	_, errMismatch := tls.X509KeyPair(cert, otherKey)
+
+	pair := CertificatePair{Certificate: "tls.crt", PrivateKey: "tls.key"}
+
+	cases := map[string]struct {
+		reason string
+		data   KeyValues
+		want   error
+	}{
+		"ValidPair": {
+			reason: "We should return no error if the certificate and private key are a valid pair.",
+			data:   KeyValues{"tls.crt": cert, "tls.key": key},
+		},
+		"MismatchedKey": {
+			reason: "We should return an error if the private key doesn't match the certificate.",
+			data:   KeyValues{"tls.crt": cert, "tls.key": otherKey},
+			want:   errors.Wrapf(errMismatch, errFmtCertKeyMismatch, "tls.crt", "tls.key"),
+		},
+		"MalformedPEM": {
+			reason: "We should return an error if a value is not PEM encoded.",
+			data:   KeyValues{"tls.crt": []byte("not-pem"), "tls.key": key},
+			want:   errors.Errorf(errFmtMalformedPEM, "tls.crt"),
+		},
+		"MissingKey": {
+			reason: "We should return an error if the private key is missing.",
+			data:   KeyValues{"tls.crt": cert},
+			want:   errors.Errorf(errFmtMissingPEMKey, "tls.key"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateCertificates(pair)(context.Background(), &Secret{Data: tc.data})
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nValidateCertificates(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package conformance tests that an external secret store plugin behaves the
+// way the plugin SecretStore expects. Plugin authors may run it against their
+// server in their own tests:
+//
+//	func TestConformance(t *testing.T) {
+//		conn, _ := grpc.NewClient(endpoint, grpc.WithTransportCredentials(creds))
+//		conformance.Run(t, ess.NewExternalSecretStorePluginServiceClient(conn), cfg)
+//	}
+package conformance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	ess "github.com/crossplane/crossplane-runtime/apis/proto/v1alpha1"
+)
+
+// DefaultScopedName is the scoped name of the secret the conformance tests
+// write to, read from and delete, unless configured otherwise.
+const DefaultScopedName = "crossplane-conformance/secret"
+
+// DefaultTimeout is the timeout of each request, unless configured otherwise.
+const DefaultTimeout = 10 * time.Second
+
+// An Option configures the conformance tests.
+type Option func(*options)
+
+type options struct {
+	name     string
+	timeout  time.Duration
+	metadata bool
+}
+
+// WithScopedName configures the scoped name of the secret the conformance
+// tests write to, read from and delete. The secret is deleted both before and
+// after the tests run. DefaultScopedName is used by default.
+func WithScopedName(n string) Option {
+	return func(o *options) {
+		o.name = n
+	}
+}
+
+// WithTimeout configures the timeout of each request. DefaultTimeout is used
+// by default.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithoutMetadata skips testing that the metadata of a secret is stored, for
+// plugins backed by a store that doesn't support secret metadata.
+func WithoutMetadata() Option {
+	return func(o *options) {
+		o.metadata = false
+	}
+}
+
+// Run the conformance tests against the supplied plugin client, using the
+// supplied store config reference. Each test is run as a subtest of the
+// supplied test, in order, since each depends on the state the previous test
+// left the secret in.
+func Run(t *testing.T, c ess.ExternalSecretStorePluginServiceClient, cfg *ess.ConfigReference, o ...Option) {
+	t.Helper()
+
+	opts := &options{name: DefaultScopedName, timeout: DefaultTimeout, metadata: true}
+	for _, fn := range o {
+		fn(opts)
+	}
+	r := &runner{client: c, config: cfg, opts: opts}
+
+	if err := r.delete(); err != nil {
+		t.Fatalf("cannot delete secret %q before running conformance tests: %v", opts.name, err)
+	}
+	t.Cleanup(func() { _ = r.delete() })
+
+	data := map[string][]byte{"username": []byte("admin"), "password": []byte("s3cr3t")}
+	metadata := map[string]string{"environment": "conformance"}
+	if !opts.metadata {
+		metadata = nil
+	}
+
+	steps := []struct {
+		name string
+		fn   func(t *testing.T)
+	}{
+		{"GetMissingSecret", func(t *testing.T) {
+			r.wantSecret(t, nil, nil)
+		}},
+		{"DeleteMissingSecret", func(t *testing.T) {
+			if err := r.delete(); err != nil {
+				t.Errorf("DeleteKeys(...): deleting a secret that does not exist must succeed: %v", err)
+			}
+		}},
+		{"CreateSecret", func(t *testing.T) {
+			r.wantApply(t, data, metadata, true)
+			r.wantSecret(t, data, metadata)
+		}},
+		{"ApplyUnchangedSecret", func(t *testing.T) {
+			r.wantApply(t, data, metadata, false)
+			r.wantSecret(t, data, metadata)
+		}},
+		{"UpdateSecret", func(t *testing.T) {
+			data = map[string][]byte{"username": []byte("admin"), "password": []byte("n3w-s3cr3t")}
+			r.wantApply(t, data, metadata, true)
+			r.wantSecret(t, data, metadata)
+		}},
+		{"DeleteSecretKeys", func(t *testing.T) {
+			if err := r.delete("password"); err != nil {
+				t.Fatalf("DeleteKeys(...): %v", err)
+			}
+			r.wantSecret(t, map[string][]byte{"username": data["username"]}, metadata)
+		}},
+		{"DeleteSecret", func(t *testing.T) {
+			if err := r.delete(); err != nil {
+				t.Fatalf("DeleteKeys(...): %v", err)
+			}
+		}},
+		{"GetDeletedSecret", func(t *testing.T) {
+			r.wantSecret(t, nil, nil)
+		}},
+	}
+
+	for _, s := range steps {
+		if !t.Run(s.name, s.fn) {
+			// Later steps depend on earlier ones.
+			return
+		}
+	}
+}
+
+type runner struct {
+	client ess.ExternalSecretStorePluginServiceClient
+	config *ess.ConfigReference
+	opts   *options
+}
+
+func (r *runner) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), r.opts.timeout)
+}
+
+// delete the supplied keys of the secret, or the whole secret if no keys are
+// supplied. Keys to delete are sent as the keys of the secret's data.
+func (r *runner) delete(keys ...string) error {
+	var data map[string][]byte
+	if len(keys) > 0 {
+		data = make(map[string][]byte, len(keys))
+		for _, k := range keys {
+			data[k] = nil
+		}
+	}
+	ctx, cancel := r.context()
+	defer cancel()
+	_, err := r.client.DeleteKeys(ctx, &ess.DeleteKeysRequest{Config: r.config, Secret: &ess.Secret{ScopedName: r.opts.name, Data: data}})
+	return err
+}
+
+// wantApply applies the supplied secret, and fails the test unless the plugin
+// reports the expected change.
+func (r *runner) wantApply(t *testing.T, data map[string][]byte, metadata map[string]string, changed bool) {
+	t.Helper()
+	ctx, cancel := r.context()
+	defer cancel()
+	rsp, err := r.client.ApplySecret(ctx, &ess.ApplySecretRequest{Config: r.config, Secret: &ess.Secret{ScopedName: r.opts.name, Data: data, Metadata: metadata}})
+	if err != nil {
+		t.Fatalf("ApplySecret(...): %v", err)
+	}
+	if rsp.GetChanged() != changed {
+		t.Errorf("ApplySecret(...): want changed %t, got changed %t", changed, rsp.GetChanged())
+	}
+}
+
+// wantSecret gets the secret, and fails the test unless it has the supplied
+// data and metadata. A secret that does not exist may be returned either as
+// an empty secret, or as a NotFound error. Nil and empty data and metadata are
+// considered equal.
+func (r *runner) wantSecret(t *testing.T, data map[string][]byte, metadata map[string]string) {
+	t.Helper()
+	ctx, cancel := r.context()
+	defer cancel()
+	rsp, err := r.client.GetSecret(ctx, &ess.GetSecretRequest{Config: r.config, Secret: &ess.Secret{ScopedName: r.opts.name}})
+	if status.Code(err) == codes.NotFound && len(data) == 0 {
+		return
+	}
+	if err != nil {
+		t.Fatalf("GetSecret(...): %v", err)
+	}
+	if diff := cmp.Diff(data, rsp.GetSecret().GetData(), cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("GetSecret(...): -want data, +got data:\n%s", diff)
+	}
+	if !r.opts.metadata {
+		return
+	}
+	if diff := cmp.Diff(metadata, rsp.GetSecret().GetMetadata(), cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("GetSecret(...): -want metadata, +got metadata:\n%s", diff)
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"maps"
+	"net"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	ess "github.com/crossplane/crossplane-runtime/apis/proto/v1alpha1"
+)
+
+// A memoryPlugin is a plugin that stores secrets in memory.
+type memoryPlugin struct {
+	ess.UnimplementedExternalSecretStorePluginServiceServer
+
+	mx      sync.Mutex
+	secrets map[string]*ess.Secret
+}
+
+func (p *memoryPlugin) GetSecret(_ context.Context, req *ess.GetSecretRequest) (*ess.GetSecretResponse, error) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	return &ess.GetSecretResponse{Secret: p.secrets[req.GetSecret().GetScopedName()]}, nil
+}
+
+func (p *memoryPlugin) ApplySecret(_ context.Context, req *ess.ApplySecretRequest) (*ess.ApplySecretResponse, error) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	s := req.GetSecret()
+	cur, ok := p.secrets[s.GetScopedName()]
+	changed := !ok || !maps.EqualFunc(cur.GetData(), s.GetData(), bytes.Equal) || !maps.Equal(cur.GetMetadata(), s.GetMetadata())
+	p.secrets[s.GetScopedName()] = s
+	return &ess.ApplySecretResponse{Changed: changed}, nil
+}
+
+func (p *memoryPlugin) DeleteKeys(_ context.Context, req *ess.DeleteKeysRequest) (*ess.DeleteKeysResponse, error) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	name := req.GetSecret().GetScopedName()
+	s, ok := p.secrets[name]
+	if !ok || len(req.GetSecret().GetData()) == 0 {
+		delete(p.secrets, name)
+		return &ess.DeleteKeysResponse{}, nil
+	}
+	for k := range req.GetSecret().GetData() {
+		delete(s.Data, k)
+	}
+	if len(s.Data) == 0 {
+		delete(p.secrets, name)
+	}
+	return &ess.DeleteKeysResponse{}, nil
+}
+
+func TestRun(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	ess.RegisterExternalSecretStorePluginServiceServer(srv, &memoryPlugin{secrets: map[string]*ess.Secret{}})
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("grpc.NewClient(...): %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	Run(t, ess.NewExternalSecretStorePluginServiceClient(conn), &ess.ConfigReference{ApiVersion: "v1", Kind: "Config", Name: "cool"})
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// A RetryPredicate returns true if the supplied error returned by a plugin
+// is transient, and the call that returned it should be retried.
+type RetryPredicate func(err error) bool
+
+// RetryableCodes returns a RetryPredicate that considers errors with any of
+// the supplied gRPC status codes to be retryable.
+func RetryableCodes(c ...codes.Code) RetryPredicate {
+	return func(err error) bool {
+		s, ok := status.FromError(err)
+		if !ok {
+			return false
+		}
+		for _, code := range c {
+			if s.Code() == code {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// RetryableMessages returns a RetryPredicate that considers errors whose gRPC
+// status message contains any of the supplied substrings to be retryable. It
+// is useful for plugins that surface backend specific throttling errors, such
+// as an AWS ThrottlingException, without a dedicated status code.
+func RetryableMessages(substr ...string) RetryPredicate {
+	return func(err error) bool {
+		s, ok := status.FromError(err)
+		if !ok {
+			return false
+		}
+		for _, m := range substr {
+			if strings.Contains(s.Message(), m) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// A SecretStoreOption configures a SecretStore.
+type SecretStoreOption func(*SecretStore)
+
+// WithRetryPredicates configures a SecretStore to retry calls that fail with
+// an error any of the supplied predicates consider retryable. Calls are not
+// retried by default. To retry calls that fail because the plugin is
+// unavailable or is exhausting a resource, for example because it is being
+// throttled, supply RetryableCodes(codes.Unavailable, codes.ResourceExhausted).
+func WithRetryPredicates(p ...RetryPredicate) SecretStoreOption {
+	return func(ss *SecretStore) {
+		ss.retryable = append(ss.retryable, p...)
+	}
+}
+
+// WithBackoff configures the backoff a SecretStore retries calls with. Calls
+// that are retryable per WithRetryPredicates are retried per
+// retry.DefaultRetry by default.
+func WithBackoff(b wait.Backoff) SecretStoreOption {
+	return func(ss *SecretStore) {
+		ss.backoff = b
+	}
+}
+
+// retry calls the supplied function until it succeeds, fails with an error
+// that is not retryable, or the supplied context is done while waiting to
+// retry it. It returns the last error the function returned.
+func (ss *SecretStore) retry(ctx context.Context, fn func() error) error {
+	b := ss.backoff
+	if b.Steps == 0 {
+		// A SecretStore that wasn't built by NewSecretStore.
+		b = wait.Backoff{Steps: 1}
+	}
+	for {
+		err := fn()
+		if err == nil || !ss.isRetryable(err) || b.Steps <= 1 {
+			return err
+		}
+		t := time.NewTimer(b.Step())
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+	}
+}
+
+func (ss *SecretStore) isRetryable(err error) bool {
+	for _, p := range ss.retryable {
+		if p(err) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	ess "github.com/crossplane/crossplane-runtime/apis/proto/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store/plugin/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestWriteKeyValuesRetry(t *testing.T) {
+	errThrottled := status.Error(codes.Unknown, "ThrottlingException: Rate exceeded")
+	errUnavailable := status.Error(codes.Unavailable, "connection refused")
+
+	type args struct {
+		err error
+		o   []SecretStoreOption
+	}
+	type want struct {
+		calls int
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"DeclaredRetryable": {
+			reason: "We should retry errors the plugin declared retryable.",
+			args: args{
+				err: errThrottled,
+				o:   []SecretStoreOption{WithRetryPredicates(RetryableMessages("ThrottlingException"))},
+			},
+			want: want{
+				calls: 2,
+			},
+		},
+		"NotRetryable": {
+			reason: "We should not retry errors nobody declared retryable.",
+			args: args{
+				err: errThrottled,
+			},
+			want: want{
+				calls: 1,
+				err:   errors.Wrap(errThrottled, errApply),
+			},
+		},
+		"Unavailable": {
+			reason: "We should not retry errors returned because the plugin is unavailable unless asked to.",
+			args: args{
+				err: errUnavailable,
+			},
+			want: want{
+				calls: 1,
+				err:   errors.Wrap(errUnavailable, errApply),
+			},
+		},
+		"UnavailableRetryable": {
+			reason: "We should retry errors returned because the plugin is unavailable if asked to.",
+			args: args{
+				err: errUnavailable,
+				o:   []SecretStoreOption{WithRetryPredicates(RetryableCodes(codes.Unavailable, codes.ResourceExhausted))},
+			},
+			want: want{
+				calls: 2,
+			},
+		},
+		"NotAStatus": {
+			reason: "We should not retry errors that aren't gRPC statuses.",
+			args: args{
+				err: errBoom,
+				o:   []SecretStoreOption{WithRetryPredicates(RetryableMessages("boom"))},
+			},
+			want: want{
+				calls: 1,
+				err:   errors.Wrap(errBoom, errApply),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			calls := 0
+			ss := &SecretStore{
+				client: &fake.ExternalSecretStorePluginServiceClient{
+					ApplySecretFn: func(_ context.Context, _ *ess.ApplySecretRequest, _ ...grpc.CallOption) (*ess.ApplySecretResponse, error) {
+						calls++
+						if calls == 1 {
+							return nil, tc.args.err
+						}
+						return &ess.ApplySecretResponse{Changed: true}, nil
+					},
+				},
+				config:  &v1.Config{},
+				backoff: wait.Backoff{Steps: 3},
+			}
+			for _, fn := range tc.args.o {
+				fn(ss)
+			}
+
+			_, err := ss.WriteKeyValues(context.Background(), &store.Secret{ScopedName: store.ScopedName{Name: secretName}})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.calls, calls); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want calls, +got calls:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRetryContextDone(t *testing.T) {
+	errUnavailable := status.Error(codes.Unavailable, "connection refused")
+
+	calls := 0
+	ss := &SecretStore{
+		client: &fake.ExternalSecretStorePluginServiceClient{
+			ApplySecretFn: func(_ context.Context, _ *ess.ApplySecretRequest, _ ...grpc.CallOption) (*ess.ApplySecretResponse, error) {
+				calls++
+				return nil, errUnavailable
+			},
+		},
+		config:  &v1.Config{},
+		backoff: wait.Backoff{Steps: 3, Duration: time.Hour},
+	}
+	WithRetryPredicates(RetryableCodes(codes.Unavailable))(ss)
+
+	// We should stop waiting to retry once the context is done, rather than
+	// sleeping through the backoff.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := ss.WriteKeyValues(ctx, &store.Secret{ScopedName: store.ScopedName{Name: secretName}})
+	if diff := cmp.Diff(errors.Wrap(errUnavailable, errApply), err, test.EquateErrors()); diff != "" {
+		t.Errorf("ss.WriteKeyValues(...): -want error, +got error:\n%s", diff)
+	}
+	if calls != 1 {
+		t.Errorf("ss.WriteKeyValues(...): want 1 call, got %d", calls)
+	}
+}
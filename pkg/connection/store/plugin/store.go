@@ -23,7 +23,11 @@ import (
 	"path/filepath"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
@@ -47,37 +51,52 @@ type SecretStore struct {
 	kubeClient client.Client
 	config     *v1.Config
 
+	// retryable determines which errors returned by the plugin are retried,
+	// with backoff. None are by default.
+	retryable []RetryPredicate
+	backoff   wait.Backoff
+
 	defaultScope string
 }
 
 // NewSecretStore returns a new External SecretStore.
-func NewSecretStore(_ context.Context, kube client.Client, tcfg *tls.Config, cfg v1.SecretStoreConfig) (*SecretStore, error) {
+func NewSecretStore(_ context.Context, kube client.Client, tcfg *tls.Config, cfg v1.SecretStoreConfig, o ...SecretStoreOption) (*SecretStore, error) {
 	creds := credentials.NewTLS(tcfg)
 	conn, err := grpc.NewClient(cfg.Plugin.Endpoint, grpc.WithTransportCredentials(creds))
 	if err != nil {
 		return nil, errors.Wrapf(err, errFmtCannotDial, cfg.Plugin.Endpoint)
 	}
 
-	return &SecretStore{
+	ss := &SecretStore{
 		kubeClient:   kube,
 		client:       essproto.NewExternalSecretStorePluginServiceClient(conn),
 		config:       &cfg.Plugin.ConfigRef,
+		backoff:      retry.DefaultRetry,
 		defaultScope: cfg.DefaultScope,
-	}, nil
+	}
+	for _, fn := range o {
+		fn(ss)
+	}
+	return ss, nil
 }
 
 // ReadKeyValues reads and returns key value pairs for a given Secret.
-func (ss *SecretStore) ReadKeyValues(ctx context.Context, n store.ScopedName, s *store.Secret) error {
-	resp, err := ss.client.GetSecret(ctx, &essproto.GetSecretRequest{Secret: &essproto.Secret{ScopedName: ss.getScopedName(n)}, Config: ss.getConfigReference()})
+func (ss *SecretStore) ReadKeyValues(ctx context.Context, n store.ScopedName, s *store.Secret, ro ...store.ReadOption) error {
+	var resp *essproto.GetSecretResponse
+	err := ss.retry(ctx, func() error {
+		var err error
+		resp, err = ss.client.GetSecret(ctx, &essproto.GetSecretRequest{Secret: &essproto.Secret{ScopedName: ss.getScopedName(n)}, Config: ss.getConfigReference()})
+		return err
+	})
 	if err != nil {
-		return errors.Wrap(err, errGet)
+		return errors.Wrap(unauthenticated(err), errGet)
 	}
 
 	s.ScopedName = n
 
 	respSecret := resp.GetSecret()
 	if respSecret == nil {
-		return nil
+		return store.ApplyReadOptions(ctx, s, ro...)
 	}
 
 	respSecretData := respSecret.GetData()
@@ -95,7 +114,7 @@ func (ss *SecretStore) ReadKeyValues(ctx context.Context, n store.ScopedName, s
 		}
 	}
 
-	return nil
+	return store.ApplyReadOptions(ctx, s, ro...)
 }
 
 // WriteKeyValues writes key value pairs to a given Secret.
@@ -114,9 +133,13 @@ func (ss *SecretStore) WriteKeyValues(ctx context.Context, s *store.Secret, _ ..
 		}
 	}
 
-	resp, err := ss.client.ApplySecret(ctx, &essproto.ApplySecretRequest{Secret: sec, Config: ss.getConfigReference()})
+	var resp *essproto.ApplySecretResponse
+	err = ss.retry(ctx, func() error {
+		resp, err = ss.client.ApplySecret(ctx, &essproto.ApplySecretRequest{Secret: sec, Config: ss.getConfigReference()})
+		return err
+	})
 	if err != nil {
-		return false, errors.Wrap(err, errApply)
+		return false, errors.Wrap(unauthenticated(err), errApply)
 	}
 
 	return resp.GetChanged(), nil
@@ -124,9 +147,22 @@ func (ss *SecretStore) WriteKeyValues(ctx context.Context, s *store.Secret, _ ..
 
 // DeleteKeyValues delete key value pairs from a given Secret.
 func (ss *SecretStore) DeleteKeyValues(ctx context.Context, s *store.Secret, _ ...store.DeleteOption) error {
-	_, err := ss.client.DeleteKeys(ctx, &essproto.DeleteKeysRequest{Secret: &essproto.Secret{ScopedName: ss.getScopedName(s.ScopedName)}, Config: ss.getConfigReference()})
+	err := ss.retry(ctx, func() error {
+		_, err := ss.client.DeleteKeys(ctx, &essproto.DeleteKeysRequest{Secret: &essproto.Secret{ScopedName: ss.getScopedName(s.ScopedName)}, Config: ss.getConfigReference()})
+		return err
+	})
 
-	return errors.Wrap(err, errDelete)
+	return errors.Wrap(unauthenticated(err), errDelete)
+}
+
+// unauthenticated returns an error that wraps store.ErrUnauthenticated if the
+// supplied error was returned because the plugin rejected our credentials, so
+// that controllers may refresh them. Other errors are returned as is.
+func unauthenticated(err error) error {
+	if status.Code(err) == codes.Unauthenticated {
+		return store.NewUnauthenticated(err)
+	}
+	return err
 }
 
 func (ss *SecretStore) getConfigReference() *essproto.ConfigReference {
@@ -23,6 +23,8 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	ess "github.com/crossplane/crossplane-runtime/apis/proto/v1alpha1"
@@ -37,7 +39,10 @@ const (
 	secretName = "ess-test-secret"
 )
 
-var errBoom = errors.New("boom")
+var (
+	errBoom            = errors.New("boom")
+	errUnauthenticated = status.Error(codes.Unauthenticated, "token expired")
+)
 
 func TestReadKeyValues(t *testing.T) {
 	type args struct {
@@ -67,6 +72,20 @@ func TestReadKeyValues(t *testing.T) {
 				err: errors.Wrap(errBoom, errGet),
 			},
 		},
+		"Unauthenticated": {
+			reason: "Should return ErrUnauthenticated if the plugin rejects our credentials",
+			args: args{
+				client: &fake.ExternalSecretStorePluginServiceClient{
+					GetSecretFn: func(_ context.Context, _ *ess.GetSecretRequest, _ ...grpc.CallOption) (*ess.GetSecretResponse, error) {
+						return nil, errUnauthenticated
+					},
+				},
+			},
+			want: want{
+				out: &store.Secret{},
+				err: errors.Wrap(store.NewUnauthenticated(errUnauthenticated), errGet),
+			},
+		},
 		"SuccessfulGet": {
 			reason: "Should return key values from a secret with scope",
 			args: args{
@@ -137,6 +156,9 @@ func TestReadKeyValues(t *testing.T) {
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\nss.ReadKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
 			}
+			if diff := cmp.Diff(errors.Is(tc.want.err, store.ErrUnauthenticated), errors.Is(err, store.ErrUnauthenticated)); diff != "" {
+				t.Errorf("\n%s\nss.ReadKeyValues(...): -want ErrUnauthenticated, +got ErrUnauthenticated:\n%s", tc.reason, diff)
+			}
 
 			if diff := cmp.Diff(tc.want.out, s); diff != "" {
 				t.Errorf("\n%s\nss.ReadKeyValues(...): -want, +got:\n%s", tc.reason, diff)
@@ -172,6 +194,19 @@ func TestWriteKeyValues(t *testing.T) {
 				err:       errors.Wrap(errBoom, errApply),
 			},
 		},
+		"Unauthenticated": {
+			reason: "Should return ErrUnauthenticated if the plugin rejects our credentials",
+			args: args{
+				client: &fake.ExternalSecretStorePluginServiceClient{
+					ApplySecretFn: func(_ context.Context, _ *ess.ApplySecretRequest, _ ...grpc.CallOption) (*ess.ApplySecretResponse, error) {
+						return nil, errUnauthenticated
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(store.NewUnauthenticated(errUnauthenticated), errApply),
+			},
+		},
 		"SuccessfulWrite": {
 			reason: "Should return isChanged true",
 			args: args{
@@ -209,6 +244,9 @@ func TestWriteKeyValues(t *testing.T) {
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\nss.WriteKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
 			}
+			if diff := cmp.Diff(errors.Is(tc.want.err, store.ErrUnauthenticated), errors.Is(err, store.ErrUnauthenticated)); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want ErrUnauthenticated, +got ErrUnauthenticated:\n%s", tc.reason, diff)
+			}
 
 			if diff := cmp.Diff(tc.want.isChanged, isChanged); diff != "" {
 				t.Errorf("\n%s\nss.WriteKeyValues(...): -want, +got:\n%s", tc.reason, diff)
@@ -242,6 +280,19 @@ func TestDeleteKeyValues(t *testing.T) {
 				err: errors.Wrap(errBoom, errDelete),
 			},
 		},
+		"Unauthenticated": {
+			reason: "Should return ErrUnauthenticated if the plugin rejects our credentials",
+			args: args{
+				client: &fake.ExternalSecretStorePluginServiceClient{
+					DeleteKeysFn: func(_ context.Context, _ *ess.DeleteKeysRequest, _ ...grpc.CallOption) (*ess.DeleteKeysResponse, error) {
+						return nil, errUnauthenticated
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(store.NewUnauthenticated(errUnauthenticated), errDelete),
+			},
+		},
 		"SuccessfulDelete": {
 			reason: "Should not return error",
 			args: args{
@@ -274,6 +325,9 @@ func TestDeleteKeyValues(t *testing.T) {
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\nss.DeletKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
 			}
+			if diff := cmp.Diff(errors.Is(tc.want.err, store.ErrUnauthenticated), errors.Is(err, store.ErrUnauthenticated)); diff != "" {
+				t.Errorf("\n%s\nss.DeleteKeyValues(...): -want ErrUnauthenticated, +got ErrUnauthenticated:\n%s", tc.reason, diff)
+			}
 		})
 	}
 }
@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errFmtProvenanceCollision = "cannot merge annotation %q: key is also present in data"
+)
+
+// A Source is where the value of a key was read from.
+type Source string
+
+// Sources of values.
+const (
+	// SourceData values were read from the data of a secret. They are
+	// sensitive.
+	SourceData Source = "Data"
+
+	// SourceAnnotation values were read from the annotations of a secret.
+	// They are not sensitive.
+	SourceAnnotation Source = "Annotation"
+)
+
+// Provenance records the Source of each key of a secret.
+type Provenance map[string]Source
+
+// MergeAnnotations returns a ReadOption that returns the values of the
+// supplied annotation keys alongside the data keys of the secret, so that
+// non-sensitive values stored as annotations can be read together with
+// sensitive values stored as data. Annotations that are not present are
+// ignored. The Source of each returned key is recorded in the supplied
+// Provenance, if it is not nil. It returns an error if an annotation key is
+// also a data key, because it would be ambiguous which value to return.
+func MergeAnnotations(p Provenance, keys ...string) ReadOption {
+	return func(_ context.Context, s *Secret) error {
+		for k := range s.Data {
+			if p != nil {
+				p[k] = SourceData
+			}
+		}
+		if s.Metadata == nil {
+			return nil
+		}
+		for _, k := range keys {
+			v, ok := s.Metadata.Annotations[k]
+			if !ok {
+				continue
+			}
+			if _, ok := s.Data[k]; ok {
+				return errors.Errorf(errFmtProvenanceCollision, k)
+			}
+			if s.Data == nil {
+				s.Data = KeyValues{}
+			}
+			s.Data[k] = []byte(v)
+			if p != nil {
+				p[k] = SourceAnnotation
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestMergeAnnotations(t *testing.T) {
+	type args struct {
+		keys        []string
+		data        KeyValues
+		annotations map[string]string
+	}
+	type want struct {
+		data       KeyValues
+		provenance Provenance
+		err        error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"DataOnly": {
+			reason: "We should return data keys as is, recorded as read from data.",
+			args: args{
+				keys:        []string{"endpoint"},
+				data:        KeyValues{"password": []byte("hunter2")},
+				annotations: map[string]string{"unrelated": "value"},
+			},
+			want: want{
+				data:       KeyValues{"password": []byte("hunter2")},
+				provenance: Provenance{"password": SourceData},
+			},
+		},
+		"AnnotationOnly": {
+			reason: "We should return designated annotations, recorded as read from annotations.",
+			args: args{
+				keys:        []string{"endpoint", "port"},
+				annotations: map[string]string{"endpoint": "db.example.org", "port": "5432", "unrelated": "value"},
+			},
+			want: want{
+				data:       KeyValues{"endpoint": []byte("db.example.org"), "port": []byte("5432")},
+				provenance: Provenance{"endpoint": SourceAnnotation, "port": SourceAnnotation},
+			},
+		},
+		"Mixed": {
+			reason: "We should merge designated annotations with data keys, recording where each was read from.",
+			args: args{
+				keys:        []string{"endpoint", "missing"},
+				data:        KeyValues{"password": []byte("hunter2")},
+				annotations: map[string]string{"endpoint": "db.example.org"},
+			},
+			want: want{
+				data:       KeyValues{"password": []byte("hunter2"), "endpoint": []byte("db.example.org")},
+				provenance: Provenance{"password": SourceData, "endpoint": SourceAnnotation},
+			},
+		},
+		"Collision": {
+			reason: "We should return an error if a designated annotation is also a data key.",
+			args: args{
+				keys:        []string{"password"},
+				data:        KeyValues{"password": []byte("hunter2")},
+				annotations: map[string]string{"password": "hunter3"},
+			},
+			want: want{
+				data:       KeyValues{"password": []byte("hunter2")},
+				provenance: Provenance{"password": SourceData},
+				err:        errors.Errorf(errFmtProvenanceCollision, "password"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := &Secret{Data: tc.args.data, Metadata: &v1.ConnectionSecretMetadata{Annotations: tc.args.annotations}}
+			p := Provenance{}
+			err := MergeAnnotations(p, tc.args.keys...)(context.Background(), s)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nMergeAnnotations(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.data, s.Data); diff != "" {
+				t.Errorf("\n%s\nMergeAnnotations(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.provenance, p); diff != "" {
+				t.Errorf("\n%s\nMergeAnnotations(...): -want provenance, +got provenance:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"sort"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errFmtRenameCollision = "cannot rename keys: keys %q and %q would both be returned as %q"
+)
+
+// RenameKeys returns a ReadOption that returns the value of each key in the
+// supplied mapping of old to new key names under its new name. Keys that are
+// not mapped are returned as is. It returns an error if two keys would be
+// returned under the same name.
+func RenameKeys(mapping map[string]string) ReadOption {
+	return func(_ context.Context, s *Secret) error {
+		if len(mapping) == 0 || len(s.Data) == 0 {
+			return nil
+		}
+
+		// Iterate in a deterministic order so collision errors are stable.
+		keys := make([]string, 0, len(s.Data))
+		for k := range s.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		out := make(KeyValues, len(s.Data))
+		from := make(map[string]string, len(s.Data))
+		for _, k := range keys {
+			n := k
+			if r, ok := mapping[k]; ok {
+				n = r
+			}
+			if prev, ok := from[n]; ok {
+				return errors.Errorf(errFmtRenameCollision, prev, k, n)
+			}
+			from[n] = k
+			out[n] = s.Data[k]
+		}
+		s.Data = out
+		return nil
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestRenameKeys(t *testing.T) {
+	type args struct {
+		mapping map[string]string
+		data    KeyValues
+	}
+	type want struct {
+		data KeyValues
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Renamed": {
+			reason: "We should return mapped keys under their new names.",
+			args: args{
+				mapping: map[string]string{"user": "username", "pass": "password"},
+				data:    KeyValues{"user": []byte("admin"), "pass": []byte("hunter2")},
+			},
+			want: want{
+				data: KeyValues{"username": []byte("admin"), "password": []byte("hunter2")},
+			},
+		},
+		"PassedThrough": {
+			reason: "We should return unmapped keys, and ignore mappings of missing keys.",
+			args: args{
+				mapping: map[string]string{"user": "username", "missing": "other"},
+				data:    KeyValues{"user": []byte("admin"), "endpoint": []byte("db.example.org")},
+			},
+			want: want{
+				data: KeyValues{"username": []byte("admin"), "endpoint": []byte("db.example.org")},
+			},
+		},
+		"CollidesWithPassthrough": {
+			reason: "We should return an error if a renamed key collides with an unmapped key.",
+			args: args{
+				mapping: map[string]string{"user": "username"},
+				data:    KeyValues{"user": []byte("admin"), "username": []byte("root")},
+			},
+			want: want{
+				data: KeyValues{"user": []byte("admin"), "username": []byte("root")},
+				err:  errors.Errorf(errFmtRenameCollision, "user", "username", "username"),
+			},
+		},
+		"CollidesWithRenamed": {
+			reason: "We should return an error if two keys are renamed to the same name.",
+			args: args{
+				mapping: map[string]string{"user": "username", "login": "username"},
+				data:    KeyValues{"user": []byte("admin"), "login": []byte("root")},
+			},
+			want: want{
+				data: KeyValues{"user": []byte("admin"), "login": []byte("root")},
+				err:  errors.Errorf(errFmtRenameCollision, "login", "user", "username"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := &Secret{Data: tc.args.data}
+			err := RenameKeys(tc.args.mapping)(context.Background(), s)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRenameKeys(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.data, s.Data); diff != "" {
+				t.Errorf("\n%s\nRenameKeys(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
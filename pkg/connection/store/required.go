@@ -0,0 +1,59 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"sort"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errFmtMissingRequiredKeys = "secret is missing required keys %v"
+	errFmtEmptyRequiredKeys   = "secret has empty values for required keys %v"
+)
+
+// RequireNonEmpty returns a ReadOption that returns an error unless each of
+// the supplied keys is present and has a non-empty value. The error names all
+// missing keys and all keys with an empty value.
+func RequireNonEmpty(keys ...string) ReadOption {
+	return func(_ context.Context, s *Secret) error {
+		var missing, empty []string
+		for _, k := range keys {
+			v, ok := s.Data[k]
+			switch {
+			case !ok:
+				missing = append(missing, k)
+			case len(v) == 0:
+				empty = append(empty, k)
+			}
+		}
+		sort.Strings(missing)
+		sort.Strings(empty)
+
+		var errs []error
+		if len(missing) > 0 {
+			errs = append(errs, errors.Errorf(errFmtMissingRequiredKeys, missing))
+		}
+		if len(empty) > 0 {
+			errs = append(errs, errors.Errorf(errFmtEmptyRequiredKeys, empty))
+		}
+		return errors.Join(errs...)
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestRequireNonEmpty(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		data   KeyValues
+		keys   []string
+		want   error
+	}{
+		"AllPopulated": {
+			reason: "We should return no error if all required keys have a value.",
+			data:   KeyValues{"username": []byte("admin"), "password": []byte("secret"), "port": nil},
+			keys:   []string{"username", "password"},
+		},
+		"PresentButEmpty": {
+			reason: "We should name the required keys that are present but empty.",
+			data:   KeyValues{"username": []byte("admin"), "password": []byte{}, "token": nil},
+			keys:   []string{"username", "token", "password"},
+			want:   errors.Join(errors.Errorf(errFmtEmptyRequiredKeys, []string{"password", "token"})),
+		},
+		"Missing": {
+			reason: "We should name the required keys that are missing.",
+			data:   KeyValues{"username": []byte("admin")},
+			keys:   []string{"username", "password"},
+			want:   errors.Join(errors.Errorf(errFmtMissingRequiredKeys, []string{"password"})),
+		},
+		"MissingAndEmpty": {
+			reason: "We should name both the missing and the empty required keys.",
+			data:   KeyValues{"username": []byte{}},
+			keys:   []string{"username", "password"},
+			want: errors.Join(
+				errors.Errorf(errFmtMissingRequiredKeys, []string{"password"}),
+				errors.Errorf(errFmtEmptyRequiredKeys, []string{"username"}),
+			),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := RequireNonEmpty(tc.keys...)(context.Background(), &Secret{Data: tc.data})
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRequireNonEmpty(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
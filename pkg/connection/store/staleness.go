@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errFmtStale = "cached secret was observed %s ago, longer than the maximum staleness of %s"
+)
+
+type errStale struct{ error }
+
+func (e errStale) Stale() bool { return true }
+
+// IsStale returns true if the supplied error, or any error it wraps, was
+// returned by a MaxStaleness ReadOption because a cached secret is too stale.
+func IsStale(err error) bool {
+	var e interface{ Stale() bool }
+	return errors.As(err, &e) && e.Stale()
+}
+
+// MaxStaleness returns a ReadOption that bounds how stale a cached read may be.
+// It returns an error for which IsStale is true if it is called with a secret
+// that was served from a cache and observed longer ago than the supplied
+// duration. Stores that cache reads must then read through to their backend.
+// Secrets that weren't served from a cache are never stale.
+func MaxStaleness(d time.Duration) ReadOption {
+	return func(ctx context.Context, _ *Secret) error {
+		age, ok := ctx.Value(observedAgeKey{}).(time.Duration)
+		if !ok || age <= d {
+			return nil
+		}
+		return errStale{error: errors.Errorf(errFmtStale, age, d)}
+	}
+}
+
+type observedAgeKey struct{}
+
+// WithObservedAge returns a copy of the supplied context indicating that the
+// secret ReadOptions are called with was served from a cache, and was observed
+// the supplied duration ago. Stores that cache reads call ReadOptions with it.
+func WithObservedAge(ctx context.Context, age time.Duration) context.Context {
+	return context.WithValue(ctx, observedAgeKey{}, age)
+}
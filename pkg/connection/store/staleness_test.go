@@ -0,0 +1,58 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMaxStaleness(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		ctx    context.Context
+		want   bool
+	}{
+		"NotCached": {
+			reason: "A secret that wasn't served from a cache should never be stale.",
+			ctx:    context.Background(),
+			want:   false,
+		},
+		"Fresh": {
+			reason: "A cached secret observed within the maximum staleness should not be stale.",
+			ctx:    WithObservedAge(context.Background(), 5*time.Second),
+			want:   false,
+		},
+		"Stale": {
+			reason: "A cached secret observed longer ago than the maximum staleness should be stale.",
+			ctx:    WithObservedAge(context.Background(), 15*time.Second),
+			want:   true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := MaxStaleness(10*time.Second)(tc.ctx, &Secret{})
+			if diff := cmp.Diff(tc.want, IsStale(err)); diff != "" {
+				t.Errorf("\n%s\nMaxStaleness(...): -want stale, +got stale:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
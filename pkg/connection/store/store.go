@@ -21,6 +21,7 @@ import (
 	"context"
 
 	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 )
 
@@ -34,6 +35,21 @@ type SecretOwner interface {
 // KeyValues is a map with sensitive values.
 type KeyValues map[string][]byte
 
+// Lookup returns the value of the supplied key or, if it is absent, the value
+// of the first of the supplied aliases that is present. It returns false if
+// neither the key nor any of its aliases are present.
+func (kv KeyValues) Lookup(key string, aliases ...string) ([]byte, bool) {
+	if v, ok := kv[key]; ok {
+		return v, true
+	}
+	for _, a := range aliases {
+		if v, ok := kv[a]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
 // ScopedName is scoped name of a secret.
 type ScopedName struct {
 	Name  string
@@ -45,20 +61,55 @@ type Secret struct {
 	ScopedName
 	Metadata *v1.ConnectionSecretMetadata
 	Data     KeyValues
+
+	// Immutable is true if the secret cannot be changed in place. It is set
+	// by Stores that support immutable secrets when the secret is read.
+	Immutable bool
+
+	// Owner of the secret, if known. Stores that support owner references
+	// may use it to make the owner the controller of the secret.
+	Owner *Owner
+}
+
+// An Owner of a Secret.
+type Owner struct {
+	v1.TypedReference
+
+	// Namespace of the owner. Empty if the owner is cluster scoped.
+	Namespace string
+
+	// DeletionPolicy of the owner, if it has one. Stores that support owner
+	// references use it to determine whether the secret should be garbage
+	// collected along with its owner.
+	DeletionPolicy v1.DeletionPolicy
 }
 
 // NewSecret returns a new Secret owned by supplied SecretOwner and with
 // supplied data.
 func NewSecret(so SecretOwner, data KeyValues) *Secret {
-	if so.GetPublishConnectionDetailsTo() == nil {
+	return NewSecretTo(so, so.GetPublishConnectionDetailsTo(), data)
+}
+
+// NewSecretTo returns a new Secret owned by the supplied owner and with the
+// supplied data, to be published to the supplied target. It is used to publish
+// the secret of an owner that publishes to multiple targets.
+func NewSecretTo(so resource.Object, p *v1.PublishConnectionDetailsTo, data KeyValues) *Secret {
+	if p == nil {
 		return nil
 	}
-	p := so.GetPublishConnectionDetailsTo()
 	if p.Metadata == nil {
 		p.Metadata = &v1.ConnectionSecretMetadata{}
 	}
 	p.Metadata.SetOwnerUID(so.GetUID())
+	o := &Owner{
+		TypedReference: *meta.TypedReferenceTo(so, so.GetObjectKind().GroupVersionKind()),
+		Namespace:      so.GetNamespace(),
+	}
+	if or, ok := so.(resource.Orphanable); ok {
+		o.DeletionPolicy = or.GetDeletionPolicy()
+	}
 	return &Secret{
+		Owner: o,
 		ScopedName: ScopedName{
 			Name:  p.Name,
 			Scope: so.GetNamespace(),
@@ -68,6 +119,28 @@ func NewSecret(so SecretOwner, data KeyValues) *Secret {
 	}
 }
 
+// DeepCopy returns a deep copy of the secret.
+func (s *Secret) DeepCopy() *Secret {
+	if s == nil {
+		return nil
+	}
+	out := &Secret{ScopedName: s.ScopedName, Immutable: s.Immutable}
+	if s.Owner != nil {
+		o := *s.Owner
+		out.Owner = &o
+	}
+	if s.Metadata != nil {
+		out.Metadata = s.Metadata.DeepCopy()
+	}
+	if s.Data != nil {
+		out.Data = make(KeyValues, len(s.Data))
+		for k, v := range s.Data {
+			out.Data[k] = append([]byte(nil), v...)
+		}
+	}
+	return out
+}
+
 // GetOwner returns the UID of the owner of secret.
 func (s *Secret) GetOwner() string {
 	if s.Metadata == nil {
@@ -84,6 +157,21 @@ func (s *Secret) GetLabels() map[string]string {
 	return s.Metadata.Labels
 }
 
+// A ReadOption is called after reading the secret from the store and before
+// returning it to the caller.
+type ReadOption func(ctx context.Context, secret *Secret) error
+
+// ApplyReadOptions calls the supplied ReadOptions with the supplied secret, in
+// order. It returns the first error any of them returns.
+func ApplyReadOptions(ctx context.Context, s *Secret, ro ...ReadOption) error {
+	for _, o := range ro {
+		if err := o(ctx, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // A WriteOption is called before writing the desired secret over the
 // current object.
 type WriteOption func(ctx context.Context, current, desired *Secret) error
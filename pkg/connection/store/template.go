@@ -0,0 +1,176 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"bytes"
+	"sort"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errFmtParseTemplate  = "cannot parse template value of key %q"
+	errFmtRenderTemplate = "cannot render template value of key %q"
+	errFmtTemplateCycle  = "template value of key %q depends on itself"
+)
+
+// RenderTemplates returns a copy of the supplied key values in which the value
+// of each of the supplied templated keys is rendered as a Go template. A
+// template may reference the value of other keys as fields of dot, e.g.
+// "{{ .host }}:{{ .port }}". Templated keys may reference other templated
+// keys; they are rendered in dependency order. It returns an error if a
+// template references a key that does not exist, or if templated keys depend
+// on each other cyclically. Templated keys that don't exist are ignored.
+func RenderTemplates(kv KeyValues, templated ...string) (KeyValues, error) {
+	if len(templated) == 0 {
+		return kv, nil
+	}
+
+	tmpls := map[string]*template.Template{}
+	for _, k := range templated {
+		v, ok := kv[k]
+		if !ok {
+			continue
+		}
+		t, err := template.New(k).Option("missingkey=error").Parse(string(v))
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtParseTemplate, k)
+		}
+		tmpls[k] = t
+	}
+
+	order, err := renderOrder(tmpls)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]string, len(kv))
+	for k, v := range kv {
+		data[k] = string(v)
+	}
+	out := make(KeyValues, len(kv))
+	for k, v := range kv {
+		out[k] = v
+	}
+	for _, k := range order {
+		b := &bytes.Buffer{}
+		if err := tmpls[k].Execute(b, data); err != nil {
+			return nil, errors.Wrapf(err, errFmtRenderTemplate, k)
+		}
+		data[k] = b.String()
+		out[k] = b.Bytes()
+	}
+	return out, nil
+}
+
+// renderOrder returns the supplied templates' keys ordered such that each
+// template is rendered after the templates it references.
+func renderOrder(tmpls map[string]*template.Template) ([]string, error) {
+	keys := make([]string, 0, len(tmpls))
+	for k := range tmpls {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := map[string]int{}
+	order := make([]string, 0, len(keys))
+
+	var visit func(k string) error
+	visit = func(k string) error {
+		switch state[k] {
+		case visiting:
+			return errors.Errorf(errFmtTemplateCycle, k)
+		case visited:
+			return nil
+		}
+		state[k] = visiting
+		for _, ref := range references(tmpls[k].Tree.Root) {
+			if _, ok := tmpls[ref]; !ok {
+				// Not a templated key.
+				continue
+			}
+			if err := visit(ref); err != nil {
+				return err
+			}
+		}
+		state[k] = visited
+		order = append(order, k)
+		return nil
+	}
+
+	for _, k := range keys {
+		if err := visit(k); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// references returns the keys referenced as fields of dot by the supplied
+// template node.
+func references(n parse.Node) []string {
+	var refs []string
+	switch n := n.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, c := range n.Nodes {
+			refs = append(refs, references(c)...)
+		}
+	case *parse.ActionNode:
+		refs = append(refs, references(n.Pipe)...)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, c := range n.Cmds {
+			refs = append(refs, references(c)...)
+		}
+	case *parse.CommandNode:
+		for _, a := range n.Args {
+			refs = append(refs, references(a)...)
+		}
+	case *parse.FieldNode:
+		refs = append(refs, n.Ident[0])
+	case *parse.ChainNode:
+		refs = append(refs, references(n.Node)...)
+	case *parse.IfNode:
+		refs = append(refs, branch(&n.BranchNode)...)
+	case *parse.RangeNode:
+		refs = append(refs, branch(&n.BranchNode)...)
+	case *parse.WithNode:
+		refs = append(refs, branch(&n.BranchNode)...)
+	case *parse.TemplateNode:
+		refs = append(refs, references(n.Pipe)...)
+	}
+	return refs
+}
+
+func branch(n *parse.BranchNode) []string {
+	refs := references(n.Pipe)
+	refs = append(refs, references(n.List)...)
+	return append(refs, references(n.ElseList)...)
+}
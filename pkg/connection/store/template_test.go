@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestRenderTemplates(t *testing.T) {
+	type args struct {
+		kv        KeyValues
+		templated []string
+	}
+	type want struct {
+		kv  KeyValues
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoTemplates": {
+			reason: "We should return key values as is if no keys are templated.",
+			args: args{
+				kv: KeyValues{"url": []byte("{{ .host }}")},
+			},
+			want: want{
+				kv: KeyValues{"url": []byte("{{ .host }}")},
+			},
+		},
+		"SimpleDerivation": {
+			reason: "We should render a templated key from other keys.",
+			args: args{
+				kv: KeyValues{
+					"host": []byte("db.example.org"),
+					"port": []byte("5432"),
+					"url":  []byte("{{ .host }}:{{ .port }}"),
+				},
+				templated: []string{"url"},
+			},
+			want: want{
+				kv: KeyValues{
+					"host": []byte("db.example.org"),
+					"port": []byte("5432"),
+					"url":  []byte("db.example.org:5432"),
+				},
+			},
+		},
+		"Chain": {
+			reason: "We should render templated keys in dependency order.",
+			args: args{
+				kv: KeyValues{
+					"dsn":      []byte("postgres://{{ .username }}@{{ .endpoint }}"),
+					"endpoint": []byte("{{ .host }}:{{ .port }}"),
+					"host":     []byte("db.example.org"),
+					"port":     []byte("5432"),
+					"username": []byte("{{ if .user }}{{ .user }}{{ end }}"),
+					"user":     []byte("admin"),
+				},
+				templated: []string{"dsn", "endpoint", "username"},
+			},
+			want: want{
+				kv: KeyValues{
+					"dsn":      []byte("postgres://admin@db.example.org:5432"),
+					"endpoint": []byte("db.example.org:5432"),
+					"host":     []byte("db.example.org"),
+					"port":     []byte("5432"),
+					"username": []byte("admin"),
+					"user":     []byte("admin"),
+				},
+			},
+		},
+		"Cycle": {
+			reason: "We should return an error if templated keys depend on each other cyclically.",
+			args: args{
+				kv: KeyValues{
+					"a": []byte("{{ .b }}"),
+					"b": []byte("{{ .c }}"),
+					"c": []byte("{{ .a }}"),
+				},
+				templated: []string{"a", "b", "c"},
+			},
+			want: want{
+				err: errors.Errorf(errFmtTemplateCycle, "a"),
+			},
+		},
+		"MissingKey": {
+			reason: "We should return an error if a template references a key that does not exist.",
+			args: args{
+				kv:        KeyValues{"url": []byte("{{ .host }}")},
+				templated: []string{"url"},
+			},
+			want: want{
+				err: errors.Wrapf(errors.New(`template: url:1:3: executing "url" at <.host>: map has no entry for key "host"`), errFmtRenderTemplate, "url"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := RenderTemplates(tc.args.kv, tc.args.templated...)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRenderTemplates(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.kv, got); diff != "" {
+				t.Errorf("\n%s\nRenderTemplates(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
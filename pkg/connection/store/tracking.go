@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"sort"
+	"sync"
+)
+
+// TrackedKeyValues wraps KeyValues, recording which keys were accessed. It
+// can be used to detect connection details that are never consumed, for
+// example because of a typo in the name of the key that should consume them.
+type TrackedKeyValues struct {
+	kv KeyValues
+
+	mx       sync.Mutex
+	consumed map[string]bool
+}
+
+// NewTrackedKeyValues returns TrackedKeyValues that wrap the supplied
+// KeyValues.
+func NewTrackedKeyValues(kv KeyValues) *TrackedKeyValues {
+	return &TrackedKeyValues{kv: kv, consumed: make(map[string]bool)}
+}
+
+// Get returns the value of the supplied key, and whether the key exists. The
+// key is recorded as consumed, even if it does not exist.
+func (t *TrackedKeyValues) Get(key string) ([]byte, bool) {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	t.consumed[key] = true
+	v, ok := t.kv[key]
+	return v, ok
+}
+
+// Value returns the value of the supplied key, or nil if it does not exist.
+// The key is recorded as consumed.
+func (t *TrackedKeyValues) Value(key string) []byte {
+	v, _ := t.Get(key)
+	return v
+}
+
+// Keys returns the sorted keys of the wrapped KeyValues. Listing keys does
+// not record them as consumed.
+func (t *TrackedKeyValues) Keys() []string {
+	keys := make([]string, 0, len(t.kv))
+	for k := range t.kv {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Unused returns the sorted keys of the wrapped KeyValues that were never
+// consumed.
+func (t *TrackedKeyValues) Unused() []string {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	unused := make([]string, 0)
+	for k := range t.kv {
+		if !t.consumed[k] {
+			unused = append(unused, k)
+		}
+	}
+	sort.Strings(unused)
+	return unused
+}
+
+// Missing returns the sorted keys that were consumed but do not exist in the
+// wrapped KeyValues.
+func (t *TrackedKeyValues) Missing() []string {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	missing := make([]string, 0)
+	for k := range t.consumed {
+		if _, ok := t.kv[k]; !ok {
+			missing = append(missing, k)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTrackedKeyValues(t *testing.T) {
+	type want struct {
+		unused  []string
+		missing []string
+	}
+
+	cases := map[string]struct {
+		reason string
+		kv     KeyValues
+		access []string
+		want   want
+	}{
+		"NoneAccessed": {
+			reason: "All keys should be reported unused if none were accessed.",
+			kv:     KeyValues{"username": []byte("admin"), "password": []byte("hunter2")},
+			want: want{
+				unused:  []string{"password", "username"},
+				missing: []string{},
+			},
+		},
+		"SomeAccessed": {
+			reason: "Accessed keys should be marked consumed, and the rest reported unused.",
+			kv:     KeyValues{"username": []byte("admin"), "password": []byte("hunter2"), "port": []byte("5432")},
+			access: []string{"username", "port", "username"},
+			want: want{
+				unused:  []string{"password"},
+				missing: []string{},
+			},
+		},
+		"MissingAccessed": {
+			reason: "Accessed keys that don't exist should be reported missing.",
+			kv:     KeyValues{"username": []byte("admin")},
+			access: []string{"username", "pasword"},
+			want: want{
+				unused:  []string{},
+				missing: []string{"pasword"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tkv := NewTrackedKeyValues(tc.kv)
+			for _, k := range tc.access {
+				if diff := cmp.Diff(tc.kv[k], tkv.Value(k)); diff != "" {
+					t.Errorf("\n%s\ntkv.Value(%q): -want, +got:\n%s", tc.reason, k, diff)
+				}
+			}
+			if diff := cmp.Diff(tc.want.unused, tkv.Unused()); diff != "" {
+				t.Errorf("\n%s\ntkv.Unused(): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.missing, tkv.Missing()); diff != "" {
+				t.Errorf("\n%s\ntkv.Missing(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+)
+
+// TruncateKeys returns a ReadOption that truncates the value of each key in the
+// supplied mapping of keys to maximum sizes in bytes to that size, rather than
+// returning an error. Keys whose value was truncated are flagged in the
+// supplied map of truncated keys, if it is not nil, so that consumers can
+// tell a truncated value from a complete one. Keys that are not mapped are
+// never truncated.
+func TruncateKeys(limits map[string]int, truncated map[string]bool) ReadOption {
+	return func(_ context.Context, s *Secret) error {
+		for k, n := range limits {
+			v, ok := s.Data[k]
+			if !ok || n < 0 || len(v) <= n {
+				continue
+			}
+			s.Data[k] = v[:n:n]
+			if truncated != nil {
+				truncated[k] = true
+			}
+		}
+		return nil
+	}
+}
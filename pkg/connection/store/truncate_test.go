@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTruncateKeys(t *testing.T) {
+	type args struct {
+		max  map[string]int
+		data KeyValues
+	}
+	type want struct {
+		data      KeyValues
+		truncated map[string]bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoLimits": {
+			reason: "We should not truncate any values by default.",
+			args: args{
+				data: KeyValues{"cert": []byte("long value")},
+			},
+			want: want{
+				data:      KeyValues{"cert": []byte("long value")},
+				truncated: map[string]bool{},
+			},
+		},
+		"UnderLimit": {
+			reason: "We should pass values that don't exceed their limit through as is.",
+			args: args{
+				max:  map[string]int{"cert": 10, "missing": 1},
+				data: KeyValues{"cert": []byte("long value")},
+			},
+			want: want{
+				data:      KeyValues{"cert": []byte("long value")},
+				truncated: map[string]bool{},
+			},
+		},
+		"OverLimit": {
+			reason: "We should truncate values that exceed their limit, and flag them.",
+			args: args{
+				max:  map[string]int{"cert": 4},
+				data: KeyValues{"cert": []byte("long value"), "other": []byte("long value")},
+			},
+			want: want{
+				data:      KeyValues{"cert": []byte("long"), "other": []byte("long value")},
+				truncated: map[string]bool{"cert": true},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := &Secret{Data: tc.args.data}
+			truncated := map[string]bool{}
+			if err := TruncateKeys(tc.args.max, truncated)(context.Background(), s); err != nil {
+				t.Fatalf("\n%s\nTruncateKeys(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.data, s.Data); diff != "" {
+				t.Errorf("\n%s\nTruncateKeys(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.truncated, truncated); diff != "" {
+				t.Errorf("\n%s\nTruncateKeys(...): -want truncated, +got truncated:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
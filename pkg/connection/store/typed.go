@@ -0,0 +1,268 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"encoding"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errFmtNotStruct       = "cannot use %T as a typed secret: typed secrets must be structs"
+	errFmtUnsupportedType = "unsupported type %s"
+	errFmtDecodeField     = "cannot decode key %q into field %s"
+	errFmtEncodeField     = "cannot encode field %s into key %q"
+
+	errReadTyped = "cannot read typed secret"
+)
+
+// A ReadError is returned by a typed read when the store failed to read the
+// secret. Reading the secret again may succeed.
+type ReadError struct {
+	Err error
+}
+
+func (e *ReadError) Error() string {
+	return errReadTyped + ": " + e.Err.Error()
+}
+
+// Unwrap returns the error returned by the store.
+func (e *ReadError) Unwrap() error {
+	return e.Err
+}
+
+// A DecodeError is returned by a typed read when the value of a key could not
+// be decoded into its field, for example because it is malformed. Reading
+// the secret again won't succeed until its value is fixed.
+type DecodeError struct {
+	// Key whose value could not be decoded.
+	Key string
+
+	// Field the value could not be decoded into.
+	Field string
+
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return errors.Wrapf(e.Err, errFmtDecodeField, e.Key, e.Field).Error()
+}
+
+// Unwrap returns the error that caused the value not to be decoded.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// TagKey is the struct tag that maps a field of a typed secret to a key. The
+// tag value is the key, optionally followed by ",omitempty" to omit the key
+// when writing a field with a zero value. Fields without the tag, or tagged
+// "-", are ignored.
+const TagKey = "connection"
+
+// A ReadWriter reads and writes secrets. It is satisfied by a connection
+// Store.
+type ReadWriter interface {
+	ReadKeyValues(ctx context.Context, n ScopedName, s *Secret, ro ...ReadOption) error
+	WriteKeyValues(ctx context.Context, s *Secret, wo ...WriteOption) (bool, error)
+}
+
+// Typed reads and writes secrets as structs of type T, whose fields are mapped
+// to keys by TagKey struct tags. Fields may be strings, byte slices, booleans,
+// numbers, or implement encoding.TextMarshaler and encoding.TextUnmarshaler,
+// or be pointers to any of those. Nil pointers are omitted when writing.
+//
+//	type Database struct {
+//		Endpoint string `connection:"endpoint"`
+//		Port     int    `connection:"port"`
+//		Password []byte `connection:"password"`
+//	}
+type Typed[T any] struct {
+	store ReadWriter
+}
+
+// NewTyped returns a Typed wrapper over the supplied ReadWriter.
+func NewTyped[T any](s ReadWriter) *Typed[T] {
+	return &Typed[T]{store: s}
+}
+
+// Read the supplied secret into a T. Fields whose key is absent from the
+// secret are left at their zero value. A *ReadError is returned if the store
+// failed to read the secret, and a *DecodeError if a value could not be
+// decoded into its field.
+func (t *Typed[T]) Read(ctx context.Context, n ScopedName, ro ...ReadOption) (T, error) {
+	var out T
+	s := &Secret{}
+	if err := t.store.ReadKeyValues(ctx, n, s, ro...); err != nil {
+		return out, &ReadError{Err: err}
+	}
+	return out, Unmarshal(s.Data, &out)
+}
+
+// Write the supplied T to the supplied secret.
+func (t *Typed[T]) Write(ctx context.Context, n ScopedName, v T, wo ...WriteOption) error {
+	kv, err := Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = t.store.WriteKeyValues(ctx, &Secret{ScopedName: n, Data: kv}, wo...)
+	return err
+}
+
+// Marshal the supplied struct into KeyValues, per its TagKey struct tags.
+func Marshal(v any) (KeyValues, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil, errors.Errorf(errFmtNotStruct, v)
+	}
+	kv := KeyValues{}
+	for i := range rv.NumField() {
+		f := rv.Type().Field(i)
+		key, omitempty, ok := parseTag(f)
+		if !ok {
+			continue
+		}
+		fv := rv.Field(i)
+		if omitempty && fv.IsZero() {
+			continue
+		}
+		// A nil pointer has no value to encode, so it's omitted like an
+		// empty field.
+		if fv.Kind() == reflect.Pointer && fv.IsNil() {
+			continue
+		}
+		b, err := encodeField(fv)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtEncodeField, f.Name, key)
+		}
+		kv[key] = b
+	}
+	return kv, nil
+}
+
+// Unmarshal the supplied KeyValues into the supplied pointer to a struct, per
+// its TagKey struct tags. A *DecodeError is returned if a value could not be
+// decoded into its field.
+func Unmarshal(kv KeyValues, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return errors.Errorf(errFmtNotStruct, v)
+	}
+	rv = rv.Elem()
+	for i := range rv.NumField() {
+		f := rv.Type().Field(i)
+		key, _, ok := parseTag(f)
+		if !ok {
+			continue
+		}
+		b, ok := kv[key]
+		if !ok {
+			continue
+		}
+		if err := decodeField(rv.Field(i), b); err != nil {
+			return &DecodeError{Key: key, Field: f.Name, Err: err}
+		}
+	}
+	return nil
+}
+
+func parseTag(f reflect.StructField) (key string, omitempty, ok bool) {
+	tag, ok := f.Tag.Lookup(TagKey)
+	if !ok || tag == "-" || !f.IsExported() {
+		return "", false, false
+	}
+	key, opts, _ := strings.Cut(tag, ",")
+	if key == "" {
+		key = f.Name
+	}
+	return key, opts == "omitempty", true
+}
+
+func encodeField(v reflect.Value) ([]byte, error) {
+	if v.Kind() == reflect.Pointer {
+		return encodeField(v.Elem())
+	}
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		return m.MarshalText()
+	}
+	if v.CanAddr() {
+		if m, ok := v.Addr().Interface().(encoding.TextMarshaler); ok {
+			return m.MarshalText()
+		}
+	}
+	switch v.Kind() { //nolint:exhaustive // Other kinds are unsupported.
+	case reflect.String:
+		return []byte(v.String()), nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Bytes(), nil
+		}
+	case reflect.Bool:
+		return []byte(strconv.FormatBool(v.Bool())), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return []byte(strconv.FormatInt(v.Int(), 10)), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return []byte(strconv.FormatUint(v.Uint(), 10)), nil
+	case reflect.Float32, reflect.Float64:
+		return []byte(strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits())), nil
+	}
+	return nil, errors.Errorf(errFmtUnsupportedType, v.Type())
+}
+
+func decodeField(v reflect.Value, b []byte) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decodeField(v.Elem(), b)
+	}
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText(b)
+	}
+	switch v.Kind() { //nolint:exhaustive // Other kinds are unsupported.
+	case reflect.String:
+		v.SetString(string(b))
+		return nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes(append([]byte(nil), b...))
+			return nil
+		}
+	case reflect.Bool:
+		p, err := strconv.ParseBool(string(b))
+		v.SetBool(p)
+		return err
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		p, err := strconv.ParseInt(string(b), 10, v.Type().Bits())
+		v.SetInt(p)
+		return err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		p, err := strconv.ParseUint(string(b), 10, v.Type().Bits())
+		v.SetUint(p)
+		return err
+	case reflect.Float32, reflect.Float64:
+		p, err := strconv.ParseFloat(string(b), v.Type().Bits())
+		v.SetFloat(p)
+		return err
+	}
+	return errors.Errorf(errFmtUnsupportedType, v.Type())
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"net/netip"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+type database struct {
+	Endpoint string     `connection:"endpoint"`
+	Port     int        `connection:"port"`
+	Password []byte     `connection:"password"`
+	TLS      bool       `connection:"tls,omitempty"`
+	Address  netip.Addr `connection:"address"`
+	Ignored  string
+	Skipped  string `connection:"-"`
+}
+
+type memoryReadWriter map[ScopedName]KeyValues
+
+func (m memoryReadWriter) ReadKeyValues(_ context.Context, n ScopedName, s *Secret, _ ...ReadOption) error {
+	s.Data = m[n]
+	return nil
+}
+
+func (m memoryReadWriter) WriteKeyValues(_ context.Context, s *Secret, _ ...WriteOption) (bool, error) {
+	m[s.ScopedName] = s.Data
+	return true, nil
+}
+
+func TestTyped(t *testing.T) {
+	n := ScopedName{Name: "cool", Scope: "ns"}
+	db := database{
+		Endpoint: "db.example.org",
+		Port:     5432,
+		Password: []byte("s3cr3t"),
+		Address:  netip.MustParseAddr("10.0.0.1"),
+		Ignored:  "ignored",
+		Skipped:  "skipped",
+	}
+
+	m := memoryReadWriter{}
+	typed := NewTyped[database](m)
+	if err := typed.Write(context.Background(), n, db); err != nil {
+		t.Fatalf("typed.Write(...): %v", err)
+	}
+
+	wantKV := KeyValues{
+		"endpoint": []byte("db.example.org"),
+		"port":     []byte("5432"),
+		"password": []byte("s3cr3t"),
+		"address":  []byte("10.0.0.1"),
+	}
+	if diff := cmp.Diff(wantKV, m[n]); diff != "" {
+		t.Errorf("typed.Write(...): -want, +got:\n%s", diff)
+	}
+
+	got, err := typed.Read(context.Background(), n)
+	if err != nil {
+		t.Fatalf("typed.Read(...): %v", err)
+	}
+	want := db
+	want.Ignored, want.Skipped = "", ""
+	if diff := cmp.Diff(want, got, cmp.Comparer(func(a, b netip.Addr) bool { return a == b })); diff != "" {
+		t.Errorf("typed.Read(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestMarshalPointers(t *testing.T) {
+	type endpoint struct {
+		Address *netip.Addr `connection:"address"`
+		Port    *int        `connection:"port"`
+	}
+	addr := netip.MustParseAddr("10.0.0.1")
+	port := 5432
+
+	cases := map[string]struct {
+		reason string
+		v      endpoint
+		want   KeyValues
+	}{
+		"NilPointers": {
+			reason: "We should omit nil pointer fields, including those whose type is a TextMarshaler, rather than panic.",
+			v:      endpoint{},
+			want:   KeyValues{},
+		},
+		"Pointers": {
+			reason: "We should encode the values non-nil pointer fields point to.",
+			v:      endpoint{Address: &addr, Port: &port},
+			want:   KeyValues{"address": []byte("10.0.0.1"), "port": []byte("5432")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kv, err := Marshal(tc.v)
+			if err != nil {
+				t.Fatalf("\n%s\nMarshal(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, kv); diff != "" {
+				t.Errorf("\n%s\nMarshal(...): -want, +got:\n%s", tc.reason, diff)
+			}
+
+			got := endpoint{}
+			if err := Unmarshal(kv, &got); err != nil {
+				t.Fatalf("\n%s\nUnmarshal(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.v, got, cmp.Comparer(func(a, b netip.Addr) bool { return a == b })); diff != "" {
+				t.Errorf("\n%s\nUnmarshal(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	_, errParse := strconv.ParseInt("not-a-number", 10, 64)
+
+	cases := map[string]struct {
+		reason string
+		kv     KeyValues
+		v      any
+		want   error
+	}{
+		"NotAPointer": {
+			reason: "We should return an error if we're not supplied a pointer to a struct.",
+			v:      database{},
+			want:   errors.Errorf(errFmtNotStruct, database{}),
+		},
+		"InvalidValue": {
+			reason: "We should return an error if a value can't be decoded into its field.",
+			kv:     KeyValues{"port": []byte("not-a-number")},
+			v:      &database{},
+			want:   &DecodeError{Key: "port", Field: "Port", Err: errParse},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := Unmarshal(tc.kv, tc.v)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nUnmarshal(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+type failingReader struct{ err error }
+
+func (f failingReader) ReadKeyValues(_ context.Context, _ ScopedName, _ *Secret, _ ...ReadOption) error {
+	return f.err
+}
+
+func (f failingReader) WriteKeyValues(_ context.Context, _ *Secret, _ ...WriteOption) (bool, error) {
+	return false, f.err
+}
+
+func TestTypedReadErrors(t *testing.T) {
+	errBoom := errors.New("boom")
+	n := ScopedName{Name: "cool", Scope: "ns"}
+
+	t.Run("StoreFailure", func(t *testing.T) {
+		_, err := NewTyped[database](failingReader{err: errBoom}).Read(context.Background(), n)
+		re := &ReadError{}
+		if !errors.As(err, &re) || !errors.Is(err, errBoom) {
+			t.Errorf("typed.Read(...): want a *ReadError wrapping the store error, got %v", err)
+		}
+		de := &DecodeError{}
+		if errors.As(err, &de) {
+			t.Errorf("typed.Read(...): want a store failure not to be a *DecodeError, got %v", err)
+		}
+	})
+
+	t.Run("MalformedValue", func(t *testing.T) {
+		m := memoryReadWriter{n: KeyValues{"port": []byte("not-a-number")}}
+		_, err := NewTyped[database](m).Read(context.Background(), n)
+		de := &DecodeError{}
+		if !errors.As(err, &de) {
+			t.Fatalf("typed.Read(...): want a *DecodeError, got %v", err)
+		}
+		if diff := cmp.Diff("port", de.Key); diff != "" {
+			t.Errorf("typed.Read(...): -want key, +got key:\n%s", diff)
+		}
+		re := &ReadError{}
+		if errors.As(err, &re) {
+			t.Errorf("typed.Read(...): want a malformed value not to be a *ReadError, got %v", err)
+		}
+	})
+}
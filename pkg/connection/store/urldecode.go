@@ -0,0 +1,49 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errFmtURLDecodeKey = "cannot URL-decode value of key %q"
+)
+
+// URLDecodeKeys returns a ReadOption that decodes the URL-encoded values of the
+// supplied keys, as produced by url.QueryEscape. Keys that are not present in
+// the secret are ignored. Keys that are not supplied are returned as is.
+func URLDecodeKeys(keys ...string) ReadOption {
+	return func(_ context.Context, s *Secret) error {
+		for _, k := range keys {
+			v, ok := s.Data[k]
+			if !ok {
+				continue
+			}
+			d, err := url.QueryUnescape(string(v))
+			if err != nil {
+				return errors.Wrapf(err, errFmtURLDecodeKey, k)
+			}
+			s.Data[k] = []byte(d)
+		}
+		return nil
+	}
+}
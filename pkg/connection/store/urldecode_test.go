@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestURLDecodeKeys(t *testing.T) {
+	type want struct {
+		data KeyValues
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		data   KeyValues
+		keys   []string
+		want   want
+	}{
+		"Decoded": {
+			reason: "We should decode the values of the supplied keys.",
+			data:   KeyValues{"password": []byte("p%40ss+w%2Frd")},
+			keys:   []string{"password", "missing"},
+			want: want{
+				data: KeyValues{"password": []byte("p@ss w/rd")},
+			},
+		},
+		"PassThrough": {
+			reason: "We should return the values of keys that were not supplied as is.",
+			data:   KeyValues{"password": []byte("p%40ss"), "endpoint": []byte("a%40b")},
+			keys:   []string{"password"},
+			want: want{
+				data: KeyValues{"password": []byte("p@ss"), "endpoint": []byte("a%40b")},
+			},
+		},
+		"Malformed": {
+			reason: "We should return an error if a value is not validly URL-encoded.",
+			data:   KeyValues{"password": []byte("p%zzss")},
+			keys:   []string{"password"},
+			want: want{
+				err: errors.Wrapf(url.EscapeError("%zz"), errFmtURLDecodeKey, "password"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := &Secret{Data: tc.data}
+			err := URLDecodeKeys(tc.keys...)(context.Background(), s)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nURLDecodeKeys(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.data, s.Data); diff != "" {
+				t.Errorf("\n%s\nURLDecodeKeys(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
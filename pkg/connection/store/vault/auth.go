@@ -0,0 +1,237 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// Error strings.
+const (
+	errNoTokenConfig      = "token auth requires a token configuration"
+	errNoKubernetesConfig = "kubernetes auth requires a kubernetes configuration"
+	errExtractToken       = "cannot extract vault token"
+	errReadJWT            = "cannot read service account token"
+	errEncodeLogin        = "cannot encode vault login request"
+	errDecodeLogin        = "cannot decode vault login response"
+	errLogin              = "cannot log in to vault"
+	errNoClientToken      = "vault login response has no client token"
+
+	errFmtUnknownAuthMethod = "unknown vault auth method %q"
+)
+
+const (
+	defaultKubernetesMountPath = "kubernetes"
+
+	//nolint:gosec // This is the path of a token, not a token.
+	defaultServiceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+// An authenticator issues the tokens used to authenticate requests to Vault.
+type authenticator interface {
+	// token returns a token.
+	token(ctx context.Context) (string, error)
+
+	// invalidate the current token. It returns true if a different token
+	// may be issued next.
+	invalidate() bool
+}
+
+func newAuthenticator(ctx context.Context, kube client.Client, ss *SecretStore, cfg v1.VaultAuthConfig) (authenticator, error) {
+	switch cfg.Method {
+	case v1.VaultAuthToken:
+		if cfg.Token == nil {
+			return nil, errors.New(errNoTokenConfig)
+		}
+		t, err := resource.CommonCredentialExtractor(ctx, cfg.Token.Source, kube, cfg.Token.CommonCredentialSelectors)
+		if err != nil {
+			return nil, errors.Wrap(err, errExtractToken)
+		}
+		return staticToken(strings.TrimSpace(string(t))), nil
+	case v1.VaultAuthKubernetes:
+		if cfg.Kubernetes == nil {
+			return nil, errors.New(errNoKubernetesConfig)
+		}
+		a := &kubernetesAuth{
+			store: ss,
+			cache: logins,
+			login: login{
+				server:    ss.server,
+				mountPath: cfg.Kubernetes.MountPath,
+				role:      cfg.Kubernetes.Role,
+				tokenPath: cfg.Kubernetes.TokenPath,
+			},
+		}
+		if a.login.mountPath == "" {
+			a.login.mountPath = defaultKubernetesMountPath
+		}
+		if a.login.tokenPath == "" {
+			a.login.tokenPath = defaultServiceAccountTokenPath
+		}
+		return a, nil
+	}
+	return nil, errors.Errorf(errFmtUnknownAuthMethod, cfg.Method)
+}
+
+// A staticToken authenticates requests using a fixed token.
+type staticToken string
+
+func (t staticToken) token(_ context.Context) (string, error) {
+	return string(t), nil
+}
+
+func (t staticToken) invalidate() bool {
+	return false
+}
+
+// A login identifies the tokens issued by logging in to a Vault server.
+type login struct {
+	server    string
+	mountPath string
+	role      string
+	tokenPath string
+}
+
+type cachedToken struct {
+	token string
+
+	// renewAt is the time after which the token should no longer be used,
+	// or the zero time if the token doesn't expire.
+	renewAt time.Time
+}
+
+// A tokenCache caches the tokens issued by logging in to Vault. SecretStores
+// are typically built for each operation, so tokens are cached across
+// SecretStores to avoid logging in, and leaving an unused token behind, on
+// each operation. A token is cached until most of its lease has passed.
+type tokenCache struct {
+	clock store.Clock
+
+	mx     sync.Mutex
+	tokens map[login]cachedToken
+}
+
+func newTokenCache(c store.Clock) *tokenCache {
+	return &tokenCache{clock: c, tokens: make(map[login]cachedToken)}
+}
+
+// logins caches the tokens issued to all SecretStores.
+var logins = newTokenCache(store.RealClock) //nolint:gochecknoglobals // Tokens must outlive the SecretStores that use them.
+
+func (c *tokenCache) get(l login) (string, bool) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	t, ok := c.tokens[l]
+	if !ok {
+		return "", false
+	}
+	if !t.renewAt.IsZero() && !c.clock.Now().Before(t.renewAt) {
+		delete(c.tokens, l)
+		return "", false
+	}
+	return t.token, true
+}
+
+// put caches the supplied token, which is leased for the supplied duration.
+// A zero lease means the token doesn't expire.
+func (c *tokenCache) put(l login, token string, lease time.Duration) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	t := cachedToken{token: token}
+	if lease > 0 {
+		// Renew once 90% of the lease has passed, so that the token doesn't
+		// expire while a request is in flight.
+		t.renewAt = c.clock.Now().Add(lease * 9 / 10)
+	}
+	c.tokens[l] = t
+}
+
+func (c *tokenCache) invalidate(l login) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	delete(c.tokens, l)
+}
+
+// kubernetesAuth authenticates requests using a token issued by logging in to
+// Vault with a Kubernetes service account token. It logs in lazily, and logs
+// in again once its token is invalidated or its lease is about to expire.
+// Issued tokens are shared by all kubernetesAuths with the same login. The
+// service account token is read on each login, so that rotated tokens are
+// picked up.
+type kubernetesAuth struct {
+	store *SecretStore
+	cache *tokenCache
+	login login
+
+	// mx serializes logins.
+	mx sync.Mutex
+}
+
+func (a *kubernetesAuth) token(ctx context.Context) (string, error) {
+	a.mx.Lock()
+	defer a.mx.Unlock()
+	if t, ok := a.cache.get(a.login); ok {
+		return t, nil
+	}
+
+	jwt, err := os.ReadFile(a.login.tokenPath)
+	if err != nil {
+		return "", errors.Wrap(err, errReadJWT)
+	}
+	body, err := json.Marshal(map[string]string{"role": a.login.role, "jwt": strings.TrimSpace(string(jwt))})
+	if err != nil {
+		return "", errors.Wrap(err, errEncodeLogin)
+	}
+	rsp, err := a.store.request(ctx, http.MethodPost, path.Join("auth", a.login.mountPath, "login"), "", body)
+	if err != nil {
+		return "", errors.Wrap(err, errLogin)
+	}
+
+	r := &struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int64  `json:"lease_duration"`
+		} `json:"auth"`
+	}{}
+	if err := json.Unmarshal(rsp, r); err != nil {
+		return "", errors.Wrap(err, errDecodeLogin)
+	}
+	if r.Auth.ClientToken == "" {
+		return "", errors.New(errNoClientToken)
+	}
+	a.cache.put(a.login, r.Auth.ClientToken, time.Duration(r.Auth.LeaseDuration)*time.Second)
+	return r.Auth.ClientToken, nil
+}
+
+func (a *kubernetesAuth) invalidate() bool {
+	a.cache.invalidate(a.login)
+	return true
+}
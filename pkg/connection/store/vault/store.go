@@ -0,0 +1,416 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package vault implements a secret store backed by a HashiCorp Vault KV
+// secrets engine.
+//
+// Secrets are stored at <mount path>/<scope>/<name>. Both version 1 and
+// version 2 of the KV secrets engine are supported. Values are stored as
+// strings. Values that aren't valid UTF-8, like keystores or DER encoded
+// certificates, can't be represented as JSON strings, so they are stored base64
+// encoded, prefixed with EncodedValuePrefix. Version 2 stores the labels of a
+// secret as its custom metadata; version 1 doesn't store secret metadata.
+package vault
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// Error strings.
+const (
+	errNoConfig       = "no vault secret store config"
+	errBuildURL       = "cannot build vault URL"
+	errBuildRequest   = "cannot build vault request"
+	errEncodeSecret   = "cannot encode secret"
+	errDecodeResponse = "cannot decode vault response"
+	errGet            = "cannot get secret"
+	errApply          = "cannot apply secret"
+	errDelete         = "cannot delete secret"
+
+	errFmtDecodeValue = "cannot decode base64 encoded value of key %q"
+
+	errFmtStatus         = "vault responded with status %d: %s"
+	errFmtUnknownVersion = "unknown vault KV version %q"
+)
+
+// HeaderToken is the HTTP header carrying the Vault token of a request.
+const HeaderToken = "X-Vault-Token"
+
+// EncodedValuePrefix prefixes values that are stored base64 encoded, because
+// they aren't valid UTF-8 or because they start with EncodedValuePrefix.
+const EncodedValuePrefix = "crossplane-base64:"
+
+// A response to a read request, per the version of the KV secrets engine.
+type v1Response struct {
+	Data map[string]string `json:"data"`
+}
+
+type v2Response struct {
+	Data struct {
+		Data     map[string]string `json:"data"`
+		Metadata struct {
+			CustomMetadata map[string]string `json:"custom_metadata"`
+		} `json:"metadata"`
+	} `json:"data"`
+}
+
+// SecretStore is a secret store backed by a Vault KV secrets engine.
+type SecretStore struct {
+	client    *http.Client
+	server    string
+	mountPath string
+	version   v1.VaultKVVersion
+	auth      authenticator
+
+	defaultScope string
+}
+
+// A SecretStoreOption configures a SecretStore.
+type SecretStoreOption func(*SecretStore)
+
+// WithHTTPClient configures the HTTP client a SecretStore makes requests with.
+// By default it uses a client returned by store.NewHTTPClient for the TLS
+// config supplied to NewSecretStore.
+func WithHTTPClient(c *http.Client) SecretStoreOption {
+	return func(ss *SecretStore) {
+		ss.client = c
+	}
+}
+
+// NewSecretStore returns a new Vault SecretStore.
+func NewSecretStore(ctx context.Context, kube client.Client, tcfg *tls.Config, cfg v1.SecretStoreConfig, o ...SecretStoreOption) (*SecretStore, error) {
+	if cfg.Vault == nil {
+		return nil, errors.New(errNoConfig)
+	}
+	ss := &SecretStore{
+		client:       store.NewHTTPClient(tcfg),
+		server:       cfg.Vault.Server,
+		mountPath:    cfg.Vault.MountPath,
+		version:      v1.VaultKVVersionV2,
+		defaultScope: cfg.DefaultScope,
+	}
+	for _, fn := range o {
+		fn(ss)
+	}
+
+	if cfg.Vault.Version != nil {
+		ss.version = *cfg.Vault.Version
+	}
+	if ss.version != v1.VaultKVVersionV1 && ss.version != v1.VaultKVVersionV2 {
+		return nil, errors.Errorf(errFmtUnknownVersion, ss.version)
+	}
+
+	a, err := newAuthenticator(ctx, kube, ss, cfg.Vault.Auth)
+	if err != nil {
+		return nil, err
+	}
+	ss.auth = a
+
+	return ss, nil
+}
+
+// ReadKeyValues reads and returns key value pairs for a given Secret. Like the
+// Kubernetes store, it returns no key values if the secret doesn't exist.
+func (ss *SecretStore) ReadKeyValues(ctx context.Context, n store.ScopedName, s *store.Secret, ro ...store.ReadOption) error {
+	current, err := ss.get(ctx, n)
+	if store.IsNotFound(err) {
+		current, err = &store.Secret{}, nil
+	}
+	if err != nil {
+		return errors.Wrap(err, errGet)
+	}
+
+	s.ScopedName = n
+	s.Data = current.Data
+	s.Metadata = current.Metadata
+	return store.ApplyReadOptions(ctx, s, ro...)
+}
+
+// WriteKeyValues writes key value pairs to a given Secret. Like the Kubernetes
+// store, the supplied key values replace those the secret already has, so keys
+// that are no longer supplied are removed. The secret keeps its labels unless
+// the supplied secret has labels. Write options are only called if the secret
+// already exists. The secret is not written if it would not change.
+func (ss *SecretStore) WriteKeyValues(ctx context.Context, s *store.Secret, wo ...store.WriteOption) (bool, error) {
+	current, err := ss.get(ctx, s.ScopedName)
+	if resource.Ignore(store.IsNotFound, err) != nil {
+		return false, errors.Wrap(err, errGet)
+	}
+
+	desired := s.DeepCopy()
+	if current != nil {
+		for _, o := range wo {
+			if err := o(ctx, current, desired); err != nil {
+				return false, errors.Wrap(err, errApply)
+			}
+		}
+	}
+
+	data := store.KeyValues{}
+	var labels map[string]string
+	if current != nil {
+		labels = current.GetLabels()
+	}
+	maps.Copy(data, desired.Data)
+	if desired.Metadata != nil && desired.Metadata.Labels != nil {
+		labels = desired.Metadata.Labels
+	}
+
+	if current != nil && store.Equal(current.Data, data) && maps.Equal(current.GetLabels(), labels) {
+		return false, nil
+	}
+
+	if err := ss.put(ctx, s.ScopedName, data); err != nil {
+		return false, errors.Wrap(err, errApply)
+	}
+	if ss.version == v1.VaultKVVersionV2 && len(labels) > 0 {
+		if err := ss.putMetadata(ctx, s.ScopedName, labels); err != nil {
+			return false, errors.Wrap(err, errApply)
+		}
+	}
+	return true, nil
+}
+
+// DeleteKeyValues deletes key value pairs from a given Secret. If no kv
+// specified, i.e. the supplied secret's data is nil, the whole secret is
+// deleted. If an empty, non-nil kv is specified no keys are deleted. If kv
+// specified, those are deleted and the secret is deleted only if there is no
+// data left. With version 2 of the KV secrets engine deleting a whole secret
+// deletes all of its versions and its metadata. Deleting a secret that does
+// not exist is not an error.
+func (ss *SecretStore) DeleteKeyValues(ctx context.Context, s *store.Secret, do ...store.DeleteOption) error {
+	if s.Data != nil && len(s.Data) == 0 {
+		return nil
+	}
+
+	current, err := ss.get(ctx, s.ScopedName)
+	if store.IsNotFound(err) {
+		// Secret already deleted, nothing to do.
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, errGet)
+	}
+	for _, o := range do {
+		if err := o(ctx, current); err != nil {
+			return err
+		}
+	}
+
+	for k := range s.Data {
+		delete(current.Data, k)
+	}
+	if s.Data != nil && len(current.Data) > 0 {
+		return errors.Wrap(ss.put(ctx, s.ScopedName, current.Data), errDelete)
+	}
+
+	_, err = ss.do(ctx, http.MethodDelete, ss.deletePath(s.ScopedName), nil)
+	return errors.Wrap(resource.Ignore(store.IsNotFound, err), errDelete)
+}
+
+func (ss *SecretStore) get(ctx context.Context, n store.ScopedName) (*store.Secret, error) {
+	rsp, err := ss.do(ctx, http.MethodGet, ss.dataPath(n), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &store.Secret{ScopedName: n}
+	switch ss.version {
+	case v1.VaultKVVersionV1:
+		r := &v1Response{}
+		if err := json.Unmarshal(rsp, r); err != nil {
+			return nil, errors.Wrap(err, errDecodeResponse)
+		}
+		if s.Data, err = keyValues(r.Data); err != nil {
+			return nil, err
+		}
+	case v1.VaultKVVersionV2:
+		r := &v2Response{}
+		if err := json.Unmarshal(rsp, r); err != nil {
+			return nil, errors.Wrap(err, errDecodeResponse)
+		}
+		if s.Data, err = keyValues(r.Data.Data); err != nil {
+			return nil, err
+		}
+		if cm := r.Data.Metadata.CustomMetadata; len(cm) > 0 {
+			s.Metadata = &v1.ConnectionSecretMetadata{Labels: cm}
+		}
+	}
+	return s, nil
+}
+
+func (ss *SecretStore) put(ctx context.Context, n store.ScopedName, kv store.KeyValues) error {
+	data := make(map[string]string, len(kv))
+	for k, v := range kv {
+		data[k] = encodeValue(v)
+	}
+
+	var body any = data
+	if ss.version == v1.VaultKVVersionV2 {
+		body = map[string]any{"data": data}
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		return errors.Wrap(err, errEncodeSecret)
+	}
+	_, err = ss.do(ctx, http.MethodPost, ss.dataPath(n), b)
+	return err
+}
+
+func (ss *SecretStore) putMetadata(ctx context.Context, n store.ScopedName, labels map[string]string) error {
+	b, err := json.Marshal(map[string]any{"custom_metadata": labels})
+	if err != nil {
+		return errors.Wrap(err, errEncodeSecret)
+	}
+	_, err = ss.do(ctx, http.MethodPost, ss.path("metadata", n), b)
+	return err
+}
+
+// dataPath returns the API path at which the supplied secret's key values
+// are read and written.
+func (ss *SecretStore) dataPath(n store.ScopedName) string {
+	if ss.version == v1.VaultKVVersionV1 {
+		return ss.path("", n)
+	}
+	return ss.path("data", n)
+}
+
+// deletePath returns the API path at which the supplied secret is deleted.
+func (ss *SecretStore) deletePath(n store.ScopedName) string {
+	if ss.version == v1.VaultKVVersionV1 {
+		return ss.path("", n)
+	}
+	return ss.path("metadata", n)
+}
+
+func (ss *SecretStore) path(kind string, n store.ScopedName) string {
+	if n.Scope == "" {
+		n.Scope = ss.defaultScope
+	}
+	return path.Join(ss.mountPath, kind, n.Scope, n.Name)
+}
+
+// do sends the supplied request to Vault, and returns the body of a
+// successful response. A request that is denied is retried once with a fresh
+// token, if the authenticator can issue one.
+func (ss *SecretStore) do(ctx context.Context, method, p string, body []byte) ([]byte, error) {
+	out, err := ss.send(ctx, method, p, body)
+	if errors.Is(err, store.ErrUnauthenticated) && ss.auth.invalidate() {
+		out, err = ss.send(ctx, method, p, body)
+	}
+	return out, err
+}
+
+func (ss *SecretStore) send(ctx context.Context, method, p string, body []byte) ([]byte, error) {
+	token, err := ss.auth.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ss.request(ctx, method, p, token, body)
+}
+
+// request sends the supplied request to Vault using the supplied token, if
+// any, and returns the body of a successful response.
+func (ss *SecretStore) request(ctx context.Context, method, p, token string, body []byte) ([]byte, error) {
+	u, err := url.JoinPath(ss.server, "v1", p)
+	if err != nil {
+		return nil, errors.Wrap(err, errBuildURL)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, errBuildRequest)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set(HeaderToken, token)
+	}
+
+	rsp, err := ss.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close() //nolint:errcheck // Nothing useful to do with this error.
+
+	b, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if rsp.StatusCode >= 200 && rsp.StatusCode < 300 {
+		return b, nil
+	}
+	return nil, statusError(rsp.StatusCode, b)
+}
+
+// statusError maps an unsuccessful HTTP status code to an error.
+func statusError(code int, body []byte) error {
+	err := errors.Errorf(errFmtStatus, code, strings.TrimSpace(string(body)))
+	switch code {
+	case http.StatusNotFound:
+		return store.NewNotFound(err.Error())
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return store.NewUnauthenticated(err)
+	}
+	return err
+}
+
+// encodeValue returns the supplied value as a string that survives a round
+// trip through JSON.
+func encodeValue(v []byte) string {
+	if utf8.Valid(v) && !bytes.HasPrefix(v, []byte(EncodedValuePrefix)) {
+		return string(v)
+	}
+	return EncodedValuePrefix + base64.StdEncoding.EncodeToString(v)
+}
+
+func keyValues(data map[string]string) (store.KeyValues, error) {
+	if data == nil {
+		return nil, nil
+	}
+	kv := make(store.KeyValues, len(data))
+	for k, v := range data {
+		b64, ok := strings.CutPrefix(v, EncodedValuePrefix)
+		if !ok {
+			kv[k] = []byte(v)
+			continue
+		}
+		b, err := base64.StdEncoding.DecodeString(b64)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtDecodeValue, k)
+		}
+		kv[k] = b
+	}
+	return kv, nil
+}
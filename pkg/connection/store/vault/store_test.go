@@ -0,0 +1,612 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+const (
+	fakeScope      = "fake-scope"
+	fakeSecretName = "fake"
+	fakeMountPath  = "secret"
+	fakeToken      = "t0ken"
+	fakeRole       = "cool-role"
+	fakeJWT        = "j.w.t"
+	fakeSecretPath = fakeScope + "/" + fakeSecretName
+)
+
+// A server is a fake Vault server, serving a KV secrets engine of the
+// configured version mounted at fakeMountPath, and Kubernetes auth mounted at
+// its default path.
+type server struct {
+	version v1.VaultKVVersion
+
+	// lease is the lease duration of issued tokens, in seconds.
+	lease int
+
+	mx       sync.Mutex
+	tokens   map[string]bool
+	logins   int
+	secrets  map[string]map[string]string
+	metadata map[string]map[string]string
+	writes   int
+}
+
+func newServer(version v1.VaultKVVersion, secrets map[string]map[string]string) *server {
+	if secrets == nil {
+		secrets = map[string]map[string]string{}
+	}
+	return &server{
+		version:  version,
+		tokens:   map[string]bool{fakeToken: true},
+		secrets:  secrets,
+		metadata: map[string]map[string]string{},
+	}
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	b, _ := io.ReadAll(r.Body)
+	p := strings.TrimPrefix(r.URL.Path, "/v1/")
+
+	if p == "auth/kubernetes/login" {
+		in := map[string]string{}
+		_ = json.Unmarshal(b, &in)
+		if in["role"] != fakeRole || in["jwt"] != fakeJWT {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		s.logins++
+		token := "issued-" + string(rune('0'+s.logins))
+		s.tokens[token] = true
+		_ = json.NewEncoder(w).Encode(map[string]any{"auth": map[string]any{"client_token": token, "lease_duration": s.lease}})
+		return
+	}
+
+	if !s.tokens[r.Header.Get(HeaderToken)] {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+		return
+	}
+
+	p, ok := strings.CutPrefix(p, fakeMountPath+"/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	kind := ""
+	if s.version == v1.VaultKVVersionV2 {
+		kind, p, _ = strings.Cut(p, "/")
+	}
+
+	switch {
+	case r.Method == http.MethodGet:
+		data, ok := s.secrets[p]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+		if s.version == v1.VaultKVVersionV1 {
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"data": data, "metadata": map[string]any{"custom_metadata": s.metadata[p]}}})
+	case r.Method == http.MethodPost && kind == "metadata":
+		in := map[string]map[string]string{}
+		_ = json.Unmarshal(b, &in)
+		s.metadata[p] = in["custom_metadata"]
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPost:
+		s.writes++
+		if s.version == v1.VaultKVVersionV1 {
+			data := map[string]string{}
+			_ = json.Unmarshal(b, &data)
+			s.secrets[p] = data
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		in := map[string]map[string]string{}
+		_ = json.Unmarshal(b, &in)
+		s.secrets[p] = in["data"]
+		_, _ = w.Write([]byte(`{"data":{"version":1}}`))
+	case r.Method == http.MethodDelete:
+		delete(s.secrets, p)
+		delete(s.metadata, p)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func tokenAuth() v1.VaultAuthConfig {
+	return v1.VaultAuthConfig{
+		Method: v1.VaultAuthToken,
+		Token: &v1.VaultAuthTokenConfig{
+			Source: v1.CredentialsSourceSecret,
+			CommonCredentialSelectors: v1.CommonCredentialSelectors{
+				SecretRef: &v1.SecretKeySelector{
+					SecretReference: v1.SecretReference{Name: "vault", Namespace: "ns"},
+					Key:             "token",
+				},
+			},
+		},
+	}
+}
+
+func newStore(t *testing.T, srv *server, auth v1.VaultAuthConfig) *SecretStore {
+	t.Helper()
+	hs := httptest.NewServer(srv)
+	t.Cleanup(hs.Close)
+	return newStoreAt(t, hs.URL, srv.version, auth)
+}
+
+// newStoreAt returns a SecretStore for the Vault server at the supplied URL.
+func newStoreAt(t *testing.T, url string, v v1.VaultKVVersion, auth v1.VaultAuthConfig) *SecretStore {
+	t.Helper()
+	kube := &test.MockClient{
+		MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+			*obj.(*corev1.Secret) = corev1.Secret{Data: map[string][]byte{"token": []byte(fakeToken + "\n")}}
+			return nil
+		}),
+	}
+	ss, err := NewSecretStore(context.Background(), kube, nil, v1.SecretStoreConfig{
+		DefaultScope: fakeScope,
+		Vault: &v1.VaultSecretStoreConfig{
+			Server:    url,
+			MountPath: fakeMountPath,
+			Version:   &v,
+			Auth:      auth,
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewSecretStore(...): %v", err)
+	}
+	return ss
+}
+
+func TestNewSecretStore(t *testing.T) {
+	c := &http.Client{}
+
+	type want struct {
+		client *http.Client
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		cfg    v1.SecretStoreConfig
+		o      []SecretStoreOption
+		want   want
+	}{
+		"NoConfig": {
+			reason: "We should return an error rather than panic if the vault config is missing.",
+			want: want{
+				err: errors.New(errNoConfig),
+			},
+		},
+		"HTTPClient": {
+			reason: "We should make requests with the supplied HTTP client.",
+			cfg: v1.SecretStoreConfig{Vault: &v1.VaultSecretStoreConfig{
+				Server: "https://vault.example.org",
+				Auth:   tokenAuth(),
+			}},
+			o: []SecretStoreOption{WithHTTPClient(c)},
+			want: want{
+				client: c,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kube := &test.MockClient{
+				MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+					*obj.(*corev1.Secret) = corev1.Secret{Data: map[string][]byte{"token": []byte(fakeToken)}}
+					return nil
+				}),
+			}
+			ss, err := NewSecretStore(context.Background(), kube, nil, tc.cfg, tc.o...)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nNewSecretStore(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if ss.client != tc.want.client {
+				t.Errorf("\n%s\nNewSecretStore(...): want the supplied HTTP client", tc.reason)
+			}
+		})
+	}
+}
+
+func TestSecretStoreReadKeyValues(t *testing.T) {
+	type want struct {
+		secret          *store.Secret
+		unauthenticated bool
+	}
+
+	cases := map[string]struct {
+		reason   string
+		version  v1.VaultKVVersion
+		secrets  map[string]map[string]string
+		metadata map[string]string
+		token    string
+		want     want
+	}{
+		"V1": {
+			reason:  "We should read key values from a version 1 KV secrets engine.",
+			version: v1.VaultKVVersionV1,
+			secrets: map[string]map[string]string{fakeSecretPath: {"key": "value"}},
+			want: want{
+				secret: &store.Secret{
+					ScopedName: store.ScopedName{Name: fakeSecretName},
+					Data:       store.KeyValues{"key": []byte("value")},
+				},
+			},
+		},
+		"V2": {
+			reason:   "We should read key values and labels from a version 2 KV secrets engine.",
+			version:  v1.VaultKVVersionV2,
+			secrets:  map[string]map[string]string{fakeSecretPath: {"key": "value"}},
+			metadata: map[string]string{"environment": "prod"},
+			want: want{
+				secret: &store.Secret{
+					ScopedName: store.ScopedName{Name: fakeSecretName},
+					Data:       store.KeyValues{"key": []byte("value")},
+					Metadata:   &v1.ConnectionSecretMetadata{Labels: map[string]string{"environment": "prod"}},
+				},
+			},
+		},
+		"NotFound": {
+			reason:  "We should return an empty secret if the secret does not exist, like the Kubernetes store does.",
+			version: v1.VaultKVVersionV2,
+			want: want{
+				secret: &store.Secret{ScopedName: store.ScopedName{Name: fakeSecretName}},
+			},
+		},
+		"Unauthenticated": {
+			reason:  "We should return an Unauthenticated error if Vault denies our token.",
+			version: v1.VaultKVVersionV2,
+			token:   "revoked",
+			want: want{
+				unauthenticated: true,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := newServer(tc.version, tc.secrets)
+			if tc.metadata != nil {
+				srv.metadata[fakeSecretPath] = tc.metadata
+			}
+			ss := newStore(t, srv, tokenAuth())
+			if tc.token != "" {
+				ss.auth = staticToken(tc.token)
+			}
+
+			s := &store.Secret{}
+			err := ss.ReadKeyValues(context.Background(), store.ScopedName{Name: fakeSecretName}, s)
+			if diff := cmp.Diff(tc.want.unauthenticated, errors.Is(err, store.ErrUnauthenticated)); diff != "" {
+				t.Errorf("\n%s\nss.ReadKeyValues(...): -want unauthenticated, +got unauthenticated:\n%s", tc.reason, diff)
+			}
+			if tc.want.secret == nil {
+				return
+			}
+			if err != nil {
+				t.Fatalf("\n%s\nss.ReadKeyValues(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.secret, s); diff != "" {
+				t.Errorf("\n%s\nss.ReadKeyValues(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSecretStoreWriteKeyValues(t *testing.T) {
+	type want struct {
+		changed  bool
+		writes   int
+		secrets  map[string]map[string]string
+		metadata map[string]map[string]string
+	}
+
+	cases := map[string]struct {
+		reason  string
+		version v1.VaultKVVersion
+		secrets map[string]map[string]string
+		secret  *store.Secret
+		want    want
+	}{
+		"CreateV2": {
+			reason:  "We should create a secret, and store its labels as custom metadata, if it does not exist.",
+			version: v1.VaultKVVersionV2,
+			secret: &store.Secret{
+				ScopedName: store.ScopedName{Name: fakeSecretName},
+				Data:       store.KeyValues{"key": []byte("value")},
+				Metadata:   &v1.ConnectionSecretMetadata{Labels: map[string]string{"environment": "prod"}},
+			},
+			want: want{
+				changed:  true,
+				writes:   1,
+				secrets:  map[string]map[string]string{fakeSecretPath: {"key": "value"}},
+				metadata: map[string]map[string]string{fakeSecretPath: {"environment": "prod"}},
+			},
+		},
+		"ReplaceV1": {
+			reason:  "We should replace the key values the secret already has, so that keys that are no longer published are dropped.",
+			version: v1.VaultKVVersionV1,
+			secrets: map[string]map[string]string{fakeSecretPath: {"existing": "old", "key": "old"}},
+			secret: &store.Secret{
+				ScopedName: store.ScopedName{Name: fakeSecretName},
+				Data:       store.KeyValues{"key": []byte("new")},
+			},
+			want: want{
+				changed:  true,
+				writes:   1,
+				secrets:  map[string]map[string]string{fakeSecretPath: {"key": "new"}},
+				metadata: map[string]map[string]string{},
+			},
+		},
+		"EncodeV2": {
+			reason:  "We should store values that aren't valid UTF-8, or that start with the encoded value prefix, base64 encoded.",
+			version: v1.VaultKVVersionV2,
+			secret: &store.Secret{
+				ScopedName: store.ScopedName{Name: fakeSecretName},
+				Data: store.KeyValues{
+					"der":    {0x30, 0x82, 0xff},
+					"prefix": []byte(EncodedValuePrefix + "value"),
+					"text":   []byte("value"),
+				},
+			},
+			want: want{
+				changed: true,
+				writes:  1,
+				secrets: map[string]map[string]string{fakeSecretPath: {
+					"der":    EncodedValuePrefix + "MIL/",
+					"prefix": EncodedValuePrefix + "Y3Jvc3NwbGFuZS1iYXNlNjQ6dmFsdWU=",
+					"text":   "value",
+				}},
+				metadata: map[string]map[string]string{},
+			},
+		},
+		"Unchanged": {
+			reason:  "We should not write a secret that would not change.",
+			version: v1.VaultKVVersionV2,
+			secrets: map[string]map[string]string{fakeSecretPath: {"key": "value"}},
+			secret: &store.Secret{
+				ScopedName: store.ScopedName{Name: fakeSecretName},
+				Data:       store.KeyValues{"key": []byte("value")},
+			},
+			want: want{
+				secrets:  map[string]map[string]string{fakeSecretPath: {"key": "value"}},
+				metadata: map[string]map[string]string{},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := newServer(tc.version, tc.secrets)
+			ss := newStore(t, srv, tokenAuth())
+
+			changed, err := ss.WriteKeyValues(context.Background(), tc.secret)
+			if err != nil {
+				t.Fatalf("\n%s\nss.WriteKeyValues(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.changed, changed); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want changed, +got changed:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.writes, srv.writes); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want writes, +got writes:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.secrets, srv.secrets); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want secrets, +got secrets:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.metadata, srv.metadata); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want metadata, +got metadata:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSecretStoreDeleteKeyValues(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		version v1.VaultKVVersion
+		secrets map[string]map[string]string
+		data    store.KeyValues
+		want    map[string]map[string]string
+	}{
+		"SomeKeys": {
+			reason:  "We should delete only the supplied keys.",
+			version: v1.VaultKVVersionV2,
+			secrets: map[string]map[string]string{fakeSecretPath: {"a": "1", "b": "2"}},
+			data:    store.KeyValues{"a": nil},
+			want:    map[string]map[string]string{fakeSecretPath: {"b": "2"}},
+		},
+		"LastKeys": {
+			reason:  "We should delete the whole secret once no keys are left.",
+			version: v1.VaultKVVersionV1,
+			secrets: map[string]map[string]string{fakeSecretPath: {"a": "1"}},
+			data:    store.KeyValues{"a": nil},
+			want:    map[string]map[string]string{},
+		},
+		"WholeSecret": {
+			reason:  "We should delete the whole secret if no keys are supplied.",
+			version: v1.VaultKVVersionV2,
+			secrets: map[string]map[string]string{fakeSecretPath: {"a": "1", "b": "2"}},
+			want:    map[string]map[string]string{},
+		},
+		"EmptyKeySet": {
+			reason:  "We should delete nothing if an empty set of keys is supplied.",
+			version: v1.VaultKVVersionV2,
+			secrets: map[string]map[string]string{fakeSecretPath: {"a": "1"}},
+			data:    store.KeyValues{},
+			want:    map[string]map[string]string{fakeSecretPath: {"a": "1"}},
+		},
+		"NotFound": {
+			reason:  "We should not return an error if the secret does not exist.",
+			version: v1.VaultKVVersionV2,
+			data:    store.KeyValues{"a": nil},
+			want:    map[string]map[string]string{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := newServer(tc.version, tc.secrets)
+			ss := newStore(t, srv, tokenAuth())
+
+			err := ss.DeleteKeyValues(context.Background(), &store.Secret{ScopedName: store.ScopedName{Name: fakeSecretName}, Data: tc.data})
+			if err != nil {
+				t.Fatalf("\n%s\nss.DeleteKeyValues(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, srv.secrets); diff != "" {
+				t.Errorf("\n%s\nss.DeleteKeyValues(...): -want secrets, +got secrets:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestKubernetesAuth(t *testing.T) {
+	jwt := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(jwt, []byte(fakeJWT+"\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile(...): %v", err)
+	}
+
+	srv := newServer(v1.VaultKVVersionV2, map[string]map[string]string{fakeSecretPath: {"key": "value"}})
+	ss := newStore(t, srv, v1.VaultAuthConfig{
+		Method:     v1.VaultAuthKubernetes,
+		Kubernetes: &v1.VaultAuthKubernetesConfig{Role: fakeRole, TokenPath: jwt},
+	})
+
+	n := store.ScopedName{Name: fakeSecretName}
+	if err := ss.ReadKeyValues(context.Background(), n, &store.Secret{}); err != nil {
+		t.Fatalf("ss.ReadKeyValues(...): %v", err)
+	}
+	if err := ss.ReadKeyValues(context.Background(), n, &store.Secret{}); err != nil {
+		t.Fatalf("ss.ReadKeyValues(...): %v", err)
+	}
+	if srv.logins != 1 {
+		t.Errorf("ss.ReadKeyValues(...): want the issued token to be reused, got %d logins", srv.logins)
+	}
+
+	// Once the issued token is revoked we should log in again.
+	srv.tokens = map[string]bool{}
+	if err := ss.ReadKeyValues(context.Background(), n, &store.Secret{}); err != nil {
+		t.Fatalf("ss.ReadKeyValues(...): %v", err)
+	}
+	if srv.logins != 2 {
+		t.Errorf("ss.ReadKeyValues(...): want to log in again once the issued token is revoked, got %d logins", srv.logins)
+	}
+}
+
+func TestKubernetesAuthAcrossStores(t *testing.T) {
+	jwt := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(jwt, []byte(fakeJWT+"\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile(...): %v", err)
+	}
+
+	srv := newServer(v1.VaultKVVersionV2, map[string]map[string]string{fakeSecretPath: {"key": "value"}})
+	srv.lease = 60
+	hs := httptest.NewServer(srv)
+	t.Cleanup(hs.Close)
+
+	clock := fake.NewClock(time.Now())
+	cache := newTokenCache(clock)
+	auth := v1.VaultAuthConfig{
+		Method:     v1.VaultAuthKubernetes,
+		Kubernetes: &v1.VaultAuthKubernetesConfig{Role: fakeRole, TokenPath: jwt},
+	}
+
+	// read builds a new SecretStore, like the DetailsManager does for each
+	// operation, and reads a secret using it.
+	read := func() {
+		t.Helper()
+		ss := newStoreAt(t, hs.URL, srv.version, auth)
+		ss.auth.(*kubernetesAuth).cache = cache
+		if err := ss.ReadKeyValues(context.Background(), store.ScopedName{Name: fakeSecretName}, &store.Secret{}); err != nil {
+			t.Fatalf("ss.ReadKeyValues(...): %v", err)
+		}
+	}
+
+	read()
+	read()
+	if srv.logins != 1 {
+		t.Errorf("ss.ReadKeyValues(...): want the issued token to be reused across SecretStores, got %d logins", srv.logins)
+	}
+
+	// Once most of the token's lease has passed we should log in again.
+	clock.Step(55 * time.Second)
+	read()
+	if srv.logins != 2 {
+		t.Errorf("ss.ReadKeyValues(...): want to log in again once the issued token's lease is about to expire, got %d logins", srv.logins)
+	}
+}
+
+func TestSecretStoreEncodedValues(t *testing.T) {
+	kv := store.KeyValues{
+		"der":    {0x30, 0x82, 0xff},
+		"prefix": []byte(EncodedValuePrefix + "value"),
+		"text":   []byte("value"),
+	}
+	srv := newServer(v1.VaultKVVersionV1, nil)
+	ss := newStore(t, srv, tokenAuth())
+
+	n := store.ScopedName{Name: fakeSecretName}
+	if _, err := ss.WriteKeyValues(context.Background(), &store.Secret{ScopedName: n, Data: kv}); err != nil {
+		t.Fatalf("ss.WriteKeyValues(...): %v", err)
+	}
+	s := &store.Secret{}
+	if err := ss.ReadKeyValues(context.Background(), n, s); err != nil {
+		t.Fatalf("ss.ReadKeyValues(...): %v", err)
+	}
+	if diff := cmp.Diff(kv, s.Data); diff != "" {
+		t.Errorf("ss.ReadKeyValues(...): want values to survive a round trip through Vault: -want, +got:\n%s", diff)
+	}
+
+	srv.secrets[fakeSecretPath]["bad"] = EncodedValuePrefix + "!"
+	err := ss.ReadKeyValues(context.Background(), n, &store.Secret{})
+	want := errors.Wrap(errors.Wrapf(base64.CorruptInputError(0), errFmtDecodeValue, "bad"), errGet)
+	if diff := cmp.Diff(want, err, test.EquateErrors()); diff != "" {
+		t.Errorf("ss.ReadKeyValues(...): -want error, +got error:\n%s", diff)
+	}
+}
@@ -0,0 +1,340 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhook implements a secret store backed by an HTTP webhook.
+//
+// Secrets are addressed as <endpoint>/<scope>/<name>. A secret is read with a
+// GET, written with a POST and deleted with a DELETE request. Secrets are
+// encoded as JSON objects with the fields "data" (a map of keys to base64
+// encoded values) and "metadata" (labels, annotations and type). Keys to be
+// deleted from a secret are passed as repeated "key" query parameters; a
+// DELETE request without keys deletes the whole secret.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// Error strings.
+const (
+	errNoConfig          = "no webhook secret store config"
+	errExtractSigningKey = "cannot extract webhook signing key"
+	errBuildURL          = "cannot build webhook URL"
+	errBuildRequest      = "cannot build webhook request"
+	errEncodeSecret      = "cannot encode secret"
+	errDecodeSecret      = "cannot decode secret"
+	errDecodeResponse    = "cannot decode webhook response"
+	errGet               = "cannot get secret"
+	errApply             = "cannot apply secret"
+	errDelete            = "cannot delete secret"
+
+	errFmtStatus = "webhook responded with status %d: %s"
+)
+
+// HeaderSignature is the HTTP header carrying the signature of a request. Its
+// value is of the form "sha256=<hex encoded HMAC-SHA256>", computed over the
+// request method, the request URI and the request body, each separated by a
+// newline.
+const HeaderSignature = "X-Crossplane-Signature"
+
+const defaultRetries = 3
+
+// A secret as encoded in requests to and responses from a webhook.
+type secret struct {
+	Data     map[string][]byte            `json:"data,omitempty"`
+	Metadata *v1.ConnectionSecretMetadata `json:"metadata,omitempty"`
+}
+
+// A response to a write request. Writes that don't respond with a body are
+// considered to have changed the secret.
+type applyResponse struct {
+	Changed *bool `json:"changed,omitempty"`
+}
+
+type errTransient struct{ error }
+
+// SecretStore is a secret store backed by an HTTP webhook.
+type SecretStore struct {
+	client     *http.Client
+	endpoint   string
+	signingKey []byte
+	backoff    wait.Backoff
+
+	defaultScope string
+}
+
+// A SecretStoreOption configures a SecretStore.
+type SecretStoreOption func(*SecretStore)
+
+// WithHTTPClient configures the HTTP client a SecretStore makes requests with.
+// By default it uses a client returned by store.NewHTTPClient for the TLS
+// config supplied to NewSecretStore.
+func WithHTTPClient(c *http.Client) SecretStoreOption {
+	return func(ss *SecretStore) {
+		ss.client = c
+	}
+}
+
+// NewSecretStore returns a new webhook SecretStore.
+func NewSecretStore(ctx context.Context, kube client.Client, tcfg *tls.Config, cfg v1.SecretStoreConfig, o ...SecretStoreOption) (*SecretStore, error) {
+	if cfg.Webhook == nil {
+		return nil, errors.New(errNoConfig)
+	}
+	ss := &SecretStore{
+		client:       store.NewHTTPClient(tcfg),
+		endpoint:     cfg.Webhook.Endpoint,
+		backoff:      backoff(defaultRetries),
+		defaultScope: cfg.DefaultScope,
+	}
+	for _, fn := range o {
+		fn(ss)
+	}
+
+	if cfg.Webhook.Retries != nil {
+		ss.backoff = backoff(*cfg.Webhook.Retries)
+	}
+
+	if s := cfg.Webhook.Signing; s != nil {
+		k, err := resource.CommonCredentialExtractor(ctx, s.Source, kube, s.CommonCredentialSelectors)
+		if err != nil {
+			return nil, errors.Wrap(err, errExtractSigningKey)
+		}
+		ss.signingKey = k
+	}
+
+	return ss, nil
+}
+
+func backoff(retries int) wait.Backoff {
+	return wait.Backoff{
+		Steps:    retries + 1,
+		Duration: 100 * time.Millisecond,
+		Factor:   2.0,
+		Jitter:   0.1,
+	}
+}
+
+// ReadKeyValues reads and returns key value pairs for a given Secret. Like the
+// Kubernetes store, it returns no key values if the webhook responds that the
+// secret doesn't exist.
+func (ss *SecretStore) ReadKeyValues(ctx context.Context, n store.ScopedName, s *store.Secret, ro ...store.ReadOption) error {
+	current, err := ss.get(ctx, n)
+	if store.IsNotFound(err) {
+		current, err = &store.Secret{}, nil
+	}
+	if err != nil {
+		return errors.Wrap(err, errGet)
+	}
+
+	s.ScopedName = n
+	s.Data = current.Data
+	s.Metadata = current.Metadata
+
+	for _, o := range ro {
+		if err := o(ctx, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteKeyValues writes key value pairs to a given Secret. Write options are
+// only called if the secret already exists.
+func (ss *SecretStore) WriteKeyValues(ctx context.Context, s *store.Secret, wo ...store.WriteOption) (bool, error) {
+	desired := s.DeepCopy()
+
+	if len(wo) > 0 {
+		current, err := ss.get(ctx, s.ScopedName)
+		if resource.Ignore(store.IsNotFound, err) != nil {
+			return false, errors.Wrap(err, errGet)
+		}
+		for _, o := range wo {
+			if current == nil {
+				break
+			}
+			if err := o(ctx, current, desired); err != nil {
+				return false, errors.Wrap(err, errApply)
+			}
+		}
+	}
+
+	body, err := json.Marshal(&secret{Data: desired.Data, Metadata: desired.Metadata})
+	if err != nil {
+		return false, errors.Wrap(err, errEncodeSecret)
+	}
+
+	rsp, err := ss.do(ctx, http.MethodPost, s.ScopedName, nil, body)
+	if err != nil {
+		return false, errors.Wrap(err, errApply)
+	}
+
+	ar := &applyResponse{}
+	if len(rsp) == 0 {
+		return true, nil
+	}
+	if err := json.Unmarshal(rsp, ar); err != nil {
+		return false, errors.Wrap(err, errDecodeResponse)
+	}
+	return ar.Changed == nil || *ar.Changed, nil
+}
+
+// DeleteKeyValues deletes key value pairs from a given Secret. If no key
+// values are supplied the whole secret is deleted. Deleting a secret that does
+// not exist is not an error.
+func (ss *SecretStore) DeleteKeyValues(ctx context.Context, s *store.Secret, do ...store.DeleteOption) error {
+	if len(do) > 0 {
+		current, err := ss.get(ctx, s.ScopedName)
+		if store.IsNotFound(err) {
+			// Secret already deleted, nothing to do.
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, errGet)
+		}
+		for _, o := range do {
+			if err := o(ctx, current); err != nil {
+				return err
+			}
+		}
+	}
+
+	q := url.Values{}
+	for k := range s.Data {
+		q.Add("key", k)
+	}
+
+	_, err := ss.do(ctx, http.MethodDelete, s.ScopedName, q, nil)
+	return errors.Wrap(resource.Ignore(store.IsNotFound, err), errDelete)
+}
+
+func (ss *SecretStore) get(ctx context.Context, n store.ScopedName) (*store.Secret, error) {
+	rsp, err := ss.do(ctx, http.MethodGet, n, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	sec := &secret{}
+	if err := json.Unmarshal(rsp, sec); err != nil {
+		return nil, errors.Wrap(err, errDecodeSecret)
+	}
+	return &store.Secret{ScopedName: n, Data: sec.Data, Metadata: sec.Metadata}, nil
+}
+
+// do sends the supplied request to the webhook, retrying transient failures
+// until the supplied context is done, and returns the body of a successful
+// response.
+func (ss *SecretStore) do(ctx context.Context, method string, n store.ScopedName, q url.Values, body []byte) ([]byte, error) {
+	if n.Scope == "" {
+		n.Scope = ss.defaultScope
+	}
+	u, err := url.JoinPath(ss.endpoint, n.Scope, n.Name)
+	if err != nil {
+		return nil, errors.Wrap(err, errBuildURL)
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+
+	var out []byte
+	err = retry.OnError(ss.backoff, func(err error) bool {
+		// Don't wait to retry a request that can't succeed in time.
+		if ctx.Err() != nil {
+			return false
+		}
+		_, ok := err.(errTransient) //nolint:errorlint // We only ever return an unwrapped errTransient.
+		return ok
+	}, func() error {
+		out, err = ss.send(ctx, method, u, body)
+		return err
+	})
+	if t, ok := err.(errTransient); ok { //nolint:errorlint // We only ever return an unwrapped errTransient.
+		return nil, t.error
+	}
+	return out, err
+}
+
+func (ss *SecretStore) send(ctx context.Context, method, u string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, errBuildRequest)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if ss.signingKey != nil {
+		req.Header.Set(HeaderSignature, Sign(ss.signingKey, method, req.URL.RequestURI(), body))
+	}
+
+	rsp, err := ss.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return nil, errTransient{err}
+	}
+	defer rsp.Body.Close() //nolint:errcheck // Nothing useful to do with this error.
+
+	b, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, errTransient{err}
+	}
+	if rsp.StatusCode >= 200 && rsp.StatusCode < 300 {
+		return b, nil
+	}
+	return nil, statusError(rsp.StatusCode, b)
+}
+
+// statusError maps an unsuccessful HTTP status code to an error.
+func statusError(code int, body []byte) error {
+	msg := strings.TrimSpace(string(body))
+	switch {
+	case code == http.StatusNotFound:
+		return store.NewNotFound(errors.Errorf(errFmtStatus, code, msg).Error())
+	case code == http.StatusConflict || code == http.StatusPreconditionFailed:
+		return store.NewConflict(errors.Errorf(errFmtStatus, code, msg).Error())
+	case code == http.StatusTooManyRequests || code >= http.StatusInternalServerError:
+		return errTransient{errors.Errorf(errFmtStatus, code, msg)}
+	}
+	return errors.Errorf(errFmtStatus, code, msg)
+}
+
+// Sign returns the value of the signature header for the supplied request
+// method, request URI and body.
+func Sign(key []byte, method, uri string, body []byte) string {
+	m := hmac.New(sha256.New, key)
+	_, _ = m.Write([]byte(method + "\n" + uri + "\n"))
+	_, _ = m.Write(body)
+	return "sha256=" + hex.EncodeToString(m.Sum(nil))
+}
@@ -0,0 +1,447 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+const (
+	fakeScope      = "fake-scope"
+	fakeSecretName = "fake"
+	fakeSigningKey = "s3cr3t"
+	fakePath       = "/secrets/" + fakeScope + "/" + fakeSecretName
+)
+
+var errBoom = errors.New("boom")
+
+type request struct {
+	Method string
+	Path   string
+	Query  string
+	Body   string
+}
+
+// A recorder is a webhook that records the requests it receives and responds
+// with a canned status and body, optionally after a number of failures.
+type recorder struct {
+	t *testing.T
+
+	status   int
+	body     string
+	failures int
+
+	mx       sync.Mutex
+	requests []request
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	b, _ := io.ReadAll(req.Body)
+	if got, want := req.Header.Get(HeaderSignature), Sign([]byte(fakeSigningKey), req.Method, req.URL.RequestURI(), b); got != want {
+		r.t.Errorf("request signature: want %q, got %q", want, got)
+	}
+
+	r.mx.Lock()
+	r.requests = append(r.requests, request{Method: req.Method, Path: req.URL.Path, Query: req.URL.RawQuery, Body: string(b)})
+	fail := len(r.requests) <= r.failures
+	r.mx.Unlock()
+
+	if fail {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(r.status)
+	_, _ = w.Write([]byte(r.body))
+}
+
+func newStore(t *testing.T, h http.Handler) *SecretStore {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	kube := &test.MockClient{
+		MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+			*obj.(*corev1.Secret) = corev1.Secret{Data: map[string][]byte{"key": []byte(fakeSigningKey)}}
+			return nil
+		}),
+	}
+	retries := 2
+	ss, err := NewSecretStore(context.Background(), kube, nil, v1.SecretStoreConfig{
+		DefaultScope: fakeScope,
+		Webhook: &v1.WebhookSecretStoreConfig{
+			Endpoint: srv.URL + "/secrets",
+			Retries:  &retries,
+			Signing: &v1.WebhookSigningConfig{
+				Source: v1.CredentialsSourceSecret,
+				CommonCredentialSelectors: v1.CommonCredentialSelectors{
+					SecretRef: &v1.SecretKeySelector{
+						SecretReference: v1.SecretReference{Name: "signing", Namespace: "ns"},
+						Key:             "key",
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewSecretStore(...): %v", err)
+	}
+	ss.backoff.Duration = time.Millisecond
+	return ss
+}
+
+func TestNewSecretStore(t *testing.T) {
+	c := &http.Client{}
+
+	type want struct {
+		client *http.Client
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		cfg    v1.SecretStoreConfig
+		o      []SecretStoreOption
+		want   want
+	}{
+		"NoConfig": {
+			reason: "We should return an error rather than panic if the webhook config is missing.",
+			want: want{
+				err: errors.New(errNoConfig),
+			},
+		},
+		"HTTPClient": {
+			reason: "We should make requests with the supplied HTTP client.",
+			cfg:    v1.SecretStoreConfig{Webhook: &v1.WebhookSecretStoreConfig{Endpoint: "https://example.org"}},
+			o:      []SecretStoreOption{WithHTTPClient(c)},
+			want: want{
+				client: c,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ss, err := NewSecretStore(context.Background(), &test.MockClient{}, nil, tc.cfg, tc.o...)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nNewSecretStore(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if ss.client != tc.want.client {
+				t.Errorf("\n%s\nNewSecretStore(...): want the supplied HTTP client", tc.reason)
+			}
+		})
+	}
+}
+
+func TestSecretStoreReadKeyValues(t *testing.T) {
+	type want struct {
+		data     store.KeyValues
+		err      bool
+		requests int
+	}
+
+	cases := map[string]struct {
+		reason string
+		hook   *recorder
+		want   want
+	}{
+		"Success": {
+			reason: "We should decode the secret returned by the webhook.",
+			hook:   &recorder{status: http.StatusOK, body: `{"data":{"key":"dmFsdWU="}}`},
+			want: want{
+				data:     store.KeyValues{"key": []byte("value")},
+				requests: 1,
+			},
+		},
+		"NotFound": {
+			reason: "We should return no key values if the webhook responds with 404, like the Kubernetes store does.",
+			hook:   &recorder{status: http.StatusNotFound},
+			want: want{
+				requests: 1,
+			},
+		},
+		"RetriedTransientError": {
+			reason: "We should retry requests that failed with a transient error.",
+			hook:   &recorder{status: http.StatusOK, body: `{"data":{"key":"dmFsdWU="}}`, failures: 2},
+			want: want{
+				data:     store.KeyValues{"key": []byte("value")},
+				requests: 3,
+			},
+		},
+		"RetriesExhausted": {
+			reason: "We should return an error if a transient error persists after all retries.",
+			hook:   &recorder{status: http.StatusOK, failures: 5},
+			want: want{
+				err:      true,
+				requests: 3,
+			},
+		},
+		"BadRequest": {
+			reason: "We should not retry requests that failed with a non-transient error.",
+			hook:   &recorder{status: http.StatusBadRequest},
+			want: want{
+				err:      true,
+				requests: 1,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tc.hook.t = t
+			ss := newStore(t, tc.hook)
+
+			s := &store.Secret{}
+			err := ss.ReadKeyValues(context.Background(), store.ScopedName{Name: fakeSecretName}, s)
+			if (err != nil) != tc.want.err {
+				t.Errorf("\n%s\nss.ReadKeyValues(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if diff := cmp.Diff(tc.want.data, s.Data); diff != "" {
+				t.Errorf("\n%s\nss.ReadKeyValues(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.requests, len(tc.hook.requests)); diff != "" {
+				t.Errorf("\n%s\nss.ReadKeyValues(...): -want requests, +got requests:\n%s", tc.reason, diff)
+			}
+			for _, r := range tc.hook.requests {
+				if r.Method != http.MethodGet || r.Path != fakePath {
+					t.Errorf("\n%s\nss.ReadKeyValues(...): unexpected request %s %s", tc.reason, r.Method, r.Path)
+				}
+			}
+		})
+	}
+}
+
+func TestSecretStoreWriteKeyValues(t *testing.T) {
+	type args struct {
+		wo []store.WriteOption
+	}
+	type want struct {
+		changed  bool
+		conflict bool
+		err      bool
+		requests []request
+	}
+
+	cases := map[string]struct {
+		reason string
+		hook   *recorder
+		args   args
+		want   want
+	}{
+		"Success": {
+			reason: "We should POST the secret to the webhook.",
+			hook:   &recorder{status: http.StatusOK},
+			want: want{
+				changed: true,
+				requests: []request{
+					{Method: http.MethodPost, Path: fakePath, Body: `{"data":{"key":"dmFsdWU="}}`},
+				},
+			},
+		},
+		"Unchanged": {
+			reason: "We should honor a webhook reporting that the secret was unchanged.",
+			hook:   &recorder{status: http.StatusOK, body: `{"changed":false}`},
+			want: want{
+				requests: []request{
+					{Method: http.MethodPost, Path: fakePath, Body: `{"data":{"key":"dmFsdWU="}}`},
+				},
+			},
+		},
+		"Conflict": {
+			reason: "We should return a Conflict error if the webhook responds with 409.",
+			hook:   &recorder{status: http.StatusConflict},
+			want: want{
+				conflict: true,
+				err:      true,
+				requests: []request{
+					{Method: http.MethodPost, Path: fakePath, Body: `{"data":{"key":"dmFsdWU="}}`},
+				},
+			},
+		},
+		"WriteOptionFailed": {
+			reason: "We should not POST the secret if a write option fails.",
+			hook:   &recorder{status: http.StatusOK, body: `{"data":{"key":"b2xk"}}`},
+			args: args{
+				wo: []store.WriteOption{func(_ context.Context, _, _ *store.Secret) error { return errBoom }},
+			},
+			want: want{
+				err: true,
+				requests: []request{
+					{Method: http.MethodGet, Path: fakePath},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tc.hook.t = t
+			ss := newStore(t, tc.hook)
+
+			changed, err := ss.WriteKeyValues(context.Background(), &store.Secret{
+				ScopedName: store.ScopedName{Name: fakeSecretName},
+				Data:       store.KeyValues{"key": []byte("value")},
+			}, tc.args.wo...)
+			if (err != nil) != tc.want.err {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if diff := cmp.Diff(tc.want.conflict, store.IsConflict(err)); diff != "" {
+				t.Errorf("\n%s\nstore.IsConflict(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.changed, changed); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want changed, +got changed:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.requests, tc.hook.requests); diff != "" {
+				t.Errorf("\n%s\nss.WriteKeyValues(...): -want requests, +got requests:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSecretStoreDeleteKeyValues(t *testing.T) {
+	type args struct {
+		data store.KeyValues
+	}
+	type want struct {
+		err      bool
+		requests []request
+	}
+
+	cases := map[string]struct {
+		reason string
+		hook   *recorder
+		args   args
+		want   want
+	}{
+		"DeleteSecret": {
+			reason: "We should DELETE the whole secret if no keys are supplied.",
+			hook:   &recorder{status: http.StatusNoContent},
+			want: want{
+				requests: []request{{Method: http.MethodDelete, Path: fakePath}},
+			},
+		},
+		"DeleteKeys": {
+			reason: "We should DELETE only the supplied keys.",
+			hook:   &recorder{status: http.StatusNoContent},
+			args: args{
+				data: store.KeyValues{"key": nil},
+			},
+			want: want{
+				requests: []request{{Method: http.MethodDelete, Path: fakePath, Query: "key=key"}},
+			},
+		},
+		"AlreadyDeleted": {
+			reason: "We should not return an error if the secret does not exist.",
+			hook:   &recorder{status: http.StatusNotFound},
+			want: want{
+				requests: []request{{Method: http.MethodDelete, Path: fakePath}},
+			},
+		},
+		"Forbidden": {
+			reason: "We should return an error if the webhook responds with an unexpected status.",
+			hook:   &recorder{status: http.StatusForbidden},
+			want: want{
+				err:      true,
+				requests: []request{{Method: http.MethodDelete, Path: fakePath}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tc.hook.t = t
+			ss := newStore(t, tc.hook)
+
+			err := ss.DeleteKeyValues(context.Background(), &store.Secret{
+				ScopedName: store.ScopedName{Name: fakeSecretName},
+				Data:       tc.args.data,
+			})
+			if (err != nil) != tc.want.err {
+				t.Errorf("\n%s\nss.DeleteKeyValues(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if diff := cmp.Diff(tc.want.requests, tc.hook.requests); diff != "" {
+				t.Errorf("\n%s\nss.DeleteKeyValues(...): -want requests, +got requests:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSecretStoreRetryCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	requests := 0
+	ss := newStore(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		cancel()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	// We'd wait an hour to retry if we didn't notice the context was done.
+	ss.backoff.Duration = time.Hour
+
+	if err := ss.ReadKeyValues(ctx, store.ScopedName{Name: fakeSecretName}, &store.Secret{}); err == nil {
+		t.Errorf("ss.ReadKeyValues(...): want error, got nil")
+	}
+	if requests != 1 {
+		t.Errorf("ss.ReadKeyValues(...): want 1 request once the context is done, got %d", requests)
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	cases := map[string]struct {
+		code      int
+		notFound  bool
+		conflict  bool
+		transient bool
+	}{
+		"NotFound":            {code: http.StatusNotFound, notFound: true},
+		"Conflict":            {code: http.StatusConflict, conflict: true},
+		"PreconditionFailed":  {code: http.StatusPreconditionFailed, conflict: true},
+		"TooManyRequests":     {code: http.StatusTooManyRequests, transient: true},
+		"InternalServerError": {code: http.StatusInternalServerError, transient: true},
+		"BadRequest":          {code: http.StatusBadRequest},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := statusError(tc.code, nil)
+			_, transient := err.(errTransient) //nolint:errorlint // statusError never wraps errTransient.
+			got := map[string]bool{"notFound": store.IsNotFound(err), "conflict": store.IsConflict(err), "transient": transient}
+			want := map[string]bool{"notFound": tc.notFound, "conflict": tc.conflict, "transient": tc.transient}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("statusError(%d): -want, +got:\n%s", tc.code, diff)
+			}
+		})
+	}
+}
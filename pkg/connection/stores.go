@@ -25,23 +25,85 @@ import (
 	v1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/connection/store/kubernetes"
 	"github.com/crossplane/crossplane-runtime/pkg/connection/store/plugin"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store/vault"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store/webhook"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 )
 
 const (
 	errFmtUnknownSecretStore = "unknown secret store type: %q"
 )
 
+// A RuntimeStoreBuilderOption configures the Stores built by a StoreBuilderFn
+// returned by NewRuntimeStoreBuilder.
+type RuntimeStoreBuilderOption func(*runtimeStoreBuilder)
+
+// WithStoreLogger configures the logger used by the Stores that support one.
+func WithStoreLogger(l logging.Logger) RuntimeStoreBuilderOption {
+	return func(b *runtimeStoreBuilder) {
+		b.log = l
+	}
+}
+
+// WithStoreEventRecorder configures the event recorder used by the Stores that
+// support one.
+func WithStoreEventRecorder(r event.Recorder) RuntimeStoreBuilderOption {
+	return func(b *runtimeStoreBuilder) {
+		b.recorder = r
+	}
+}
+
+// WithPluginStoreOptions configures the plugin Stores built by the
+// StoreBuilderFn, for example to retry calls that fail with retryable errors
+// using plugin.WithRetryPredicates and plugin.WithBackoff.
+func WithPluginStoreOptions(o ...plugin.SecretStoreOption) RuntimeStoreBuilderOption {
+	return func(b *runtimeStoreBuilder) {
+		b.plugin = append(b.plugin, o...)
+	}
+}
+
+type runtimeStoreBuilder struct {
+	log      logging.Logger
+	recorder event.Recorder
+	plugin   []plugin.SecretStoreOption
+}
+
+// NewRuntimeStoreBuilder returns a StoreBuilderFn that builds Stores like
+// RuntimeStoreBuilder does, configured with the supplied options.
+func NewRuntimeStoreBuilder(o ...RuntimeStoreBuilderOption) StoreBuilderFn {
+	b := &runtimeStoreBuilder{
+		log:      logging.NewNopLogger(),
+		recorder: event.NewNopRecorder(),
+	}
+	for _, fn := range o {
+		fn(b)
+	}
+	return b.build
+}
+
 // RuntimeStoreBuilder builds and returns a Store for any supported Store type
-// in a given config.
+// in a given config. Its Stores don't log or record events; use
+// NewRuntimeStoreBuilder to configure them.
+func RuntimeStoreBuilder(ctx context.Context, local client.Client, tcfg *tls.Config, cfg v1.SecretStoreConfig) (Store, error) {
+	return NewRuntimeStoreBuilder()(ctx, local, tcfg, cfg)
+}
+
+// build builds and returns a Store for any supported Store type in a given
+// config.
 //
 // All in-tree connection Store implementations needs to be registered here.
-func RuntimeStoreBuilder(ctx context.Context, local client.Client, tcfg *tls.Config, cfg v1.SecretStoreConfig) (Store, error) {
+func (b *runtimeStoreBuilder) build(ctx context.Context, local client.Client, tcfg *tls.Config, cfg v1.SecretStoreConfig) (Store, error) {
 	switch *cfg.Type {
 	case v1.SecretStoreKubernetes:
-		return kubernetes.NewSecretStore(ctx, local, nil, cfg)
+		return kubernetes.NewSecretStore(ctx, local, nil, cfg, kubernetes.WithLogger(b.log), kubernetes.WithEventRecorder(b.recorder))
+	case v1.SecretStoreVault:
+		return vault.NewSecretStore(ctx, local, tcfg, cfg)
 	case v1.SecretStorePlugin:
-		return plugin.NewSecretStore(ctx, local, tcfg, cfg)
+		return plugin.NewSecretStore(ctx, local, tcfg, cfg, b.plugin...)
+	case v1.SecretStoreWebhook:
+		return webhook.NewSecretStore(ctx, local, tcfg, cfg)
 	}
 	return nil, errors.Errorf(errFmtUnknownSecretStore, *cfg.Type)
 }
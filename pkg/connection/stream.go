@@ -0,0 +1,55 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"sort"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+)
+
+// ReadKeyValuesStream calls the supplied function for each key value of the
+// supplied secret, in key order, and returns the first error it returns
+// without calling it for the remaining keys. Stores that are StreamReaders
+// read the secret one key value at a time. The secret is read using
+// ReadKeyValues from other Stores, so the whole secret is held in memory while
+// it is streamed.
+//
+// None of the in-tree Stores are StreamReaders yet. In particular the plugin
+// Store reads a secret in a single GetSecret call, because the plugin protocol
+// has no streaming RPC.
+func ReadKeyValuesStream(ctx context.Context, s Store, n store.ScopedName, fn func(key string, value []byte) error) error {
+	if sr, ok := s.(StreamReader); ok {
+		return sr.ReadKeyValuesStream(ctx, n, fn)
+	}
+	sec := &store.Secret{}
+	if err := s.ReadKeyValues(ctx, n, sec); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(sec.Data))
+	for k := range sec.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := fn(k, sec.Data[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
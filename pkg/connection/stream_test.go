@@ -0,0 +1,135 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+// A streamingStore is a Store that streams the key values of a secret
+// natively.
+type streamingStore struct {
+	fake.SecretStore
+
+	kv map[string]string
+}
+
+func (s *streamingStore) ReadKeyValuesStream(_ context.Context, _ store.ScopedName, fn func(key string, value []byte) error) error {
+	for _, k := range []string{"z", "y"} {
+		if err := fn(k, []byte(s.kv[k])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestReadKeyValuesStream(t *testing.T) {
+	errStop := errors.New("stop")
+	data := store.KeyValues{"c": []byte("3"), "a": []byte("1"), "b": []byte("2")}
+	reading := func(err error) *fake.SecretStore {
+		return &fake.SecretStore{
+			ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
+				s.Data = data
+				return err
+			},
+		}
+	}
+
+	type args struct {
+		s Store
+
+		// stopAfter is the number of keys after which the callback returns
+		// an error. The callback never returns an error if it is zero.
+		stopAfter int
+	}
+	type want struct {
+		keys []string
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"FullIteration": {
+			reason: "We should call the callback for each key value, in key order.",
+			args: args{
+				s: reading(nil),
+			},
+			want: want{
+				keys: []string{"a=1", "b=2", "c=3"},
+			},
+		},
+		"EarlyAbort": {
+			reason: "We should stop at, and return, the first error returned by the callback.",
+			args: args{
+				s:         reading(nil),
+				stopAfter: 2,
+			},
+			want: want{
+				keys: []string{"a=1", "b=2"},
+				err:  errStop,
+			},
+		},
+		"ReadError": {
+			reason: "We should return an error without calling the callback if the secret can't be read.",
+			args: args{
+				s: reading(errBoom),
+			},
+			want: want{
+				err: errBoom,
+			},
+		},
+		"NativeStream": {
+			reason: "We should stream natively from a Store that is a StreamReader.",
+			args: args{
+				s: &streamingStore{kv: map[string]string{"z": "26", "y": "25"}},
+			},
+			want: want{
+				keys: []string{"z=26", "y=25"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var keys []string
+			err := ReadKeyValuesStream(context.Background(), tc.args.s, store.ScopedName{Name: "cool"}, func(key string, value []byte) error {
+				keys = append(keys, key+"="+string(value))
+				if len(keys) == tc.args.stopAfter {
+					return errStop
+				}
+				return nil
+			})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nReadKeyValuesStream(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.keys, keys); diff != "" {
+				t.Errorf("\n%s\nReadKeyValuesStream(...): -want keys, +got keys:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
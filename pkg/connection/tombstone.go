@@ -0,0 +1,159 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+)
+
+// DefaultTombstoneTTL is the default duration for which a TombstoneStore
+// remembers that a secret was deleted.
+const DefaultTombstoneTTL = 24 * time.Hour
+
+// A TombstoneStoreOption configures a TombstoneStore.
+type TombstoneStoreOption func(*TombstoneStore)
+
+// WithTombstoneTTL configures the duration for which a TombstoneStore
+// remembers that a secret was deleted.
+func WithTombstoneTTL(ttl time.Duration) TombstoneStoreOption {
+	return func(t *TombstoneStore) {
+		t.ttl = ttl
+	}
+}
+
+// WithTombstoneClock configures the Clock a TombstoneStore uses to record when
+// secrets were deleted, and to expire their Tombstones. store.RealClock is
+// used by default.
+func WithTombstoneClock(c store.Clock) TombstoneStoreOption {
+	return func(t *TombstoneStore) {
+		t.clock = c
+	}
+}
+
+// A Tombstone records that a secret was deleted.
+type Tombstone struct {
+	store.ScopedName
+
+	// Deleted is when the secret was deleted.
+	Deleted time.Time
+}
+
+// A TombstoneStore is a Store that records a Tombstone when a secret is
+// deleted via it, so that a secret that was deleted can be told apart from a
+// secret that never existed, for example for auditing. Deleting a secret that
+// has a Tombstone is a no-op, so deletes are idempotent. Writing a secret
+// removes its Tombstone. Tombstones are held in memory, and expire after a
+// TTL.
+type TombstoneStore struct {
+	store Store
+	ttl   time.Duration
+	clock store.Clock
+
+	mx         sync.Mutex
+	tombstones map[store.ScopedName]Tombstone
+}
+
+// NewTombstoneStore returns a TombstoneStore that wraps the supplied Store.
+func NewTombstoneStore(s Store, o ...TombstoneStoreOption) *TombstoneStore {
+	t := &TombstoneStore{
+		store:      s,
+		ttl:        DefaultTombstoneTTL,
+		clock:      store.RealClock,
+		tombstones: make(map[store.ScopedName]Tombstone),
+	}
+	for _, fn := range o {
+		fn(t)
+	}
+	return t
+}
+
+// Unwrap returns the Store the TombstoneStore wraps.
+func (t *TombstoneStore) Unwrap() Store {
+	return t.store
+}
+
+// ReadKeyValues reads key values from the wrapped Store.
+func (t *TombstoneStore) ReadKeyValues(ctx context.Context, n store.ScopedName, s *store.Secret, ro ...store.ReadOption) error {
+	return t.store.ReadKeyValues(ctx, n, s, ro...)
+}
+
+// WriteKeyValues writes key values to the wrapped Store, and removes any
+// Tombstone of the secret once it is written.
+func (t *TombstoneStore) WriteKeyValues(ctx context.Context, s *store.Secret, wo ...store.WriteOption) (bool, error) {
+	changed, err := t.store.WriteKeyValues(ctx, s, wo...)
+	if err != nil {
+		return changed, err
+	}
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	delete(t.tombstones, s.ScopedName)
+	return changed, nil
+}
+
+// DeleteKeyValues deletes key values from the wrapped Store. Deleting a whole
+// secret records a Tombstone, unless the wrapped Store reports that the secret
+// does not exist. Deleting a whole secret that has a Tombstone is a no-op.
+// Deleting only some keys of a secret does not record a Tombstone.
+func (t *TombstoneStore) DeleteKeyValues(ctx context.Context, s *store.Secret, do ...store.DeleteOption) error {
+	if len(s.Data) > 0 {
+		return t.store.DeleteKeyValues(ctx, s, do...)
+	}
+	if _, ok := t.Tombstone(s.ScopedName); ok {
+		return nil
+	}
+
+	err := t.store.DeleteKeyValues(ctx, s, do...)
+	if store.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	now := t.clock.Now()
+	for n, ts := range t.tombstones {
+		// Expired Tombstones of secrets that are never looked up again would
+		// otherwise be held forever.
+		if now.Sub(ts.Deleted) > t.ttl {
+			delete(t.tombstones, n)
+		}
+	}
+	t.tombstones[s.ScopedName] = Tombstone{ScopedName: s.ScopedName, Deleted: now}
+	return nil
+}
+
+// Tombstone returns the Tombstone of the supplied secret, if it was deleted
+// via the TombstoneStore and its Tombstone has not yet expired.
+func (t *TombstoneStore) Tombstone(n store.ScopedName) (Tombstone, bool) {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	ts, ok := t.tombstones[n]
+	if !ok {
+		return Tombstone{}, false
+	}
+	if t.clock.Now().Sub(ts.Deleted) > t.ttl {
+		delete(t.tombstones, n)
+		return Tombstone{}, false
+	}
+	return ts, true
+}
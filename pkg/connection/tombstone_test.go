@@ -0,0 +1,146 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestTombstoneStoreDeleteKeyValues(t *testing.T) {
+	n := store.ScopedName{Name: "cool", Scope: "ns"}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type want struct {
+		deletes   int
+		tombstone *Tombstone
+		err       error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		deleted func(ts *TombstoneStore)
+		err     error
+		after   time.Duration
+		want    want
+	}{
+		"Deleted": {
+			reason: "We should record a tombstone when a secret is deleted.",
+			want: want{
+				deletes:   1,
+				tombstone: &Tombstone{ScopedName: n, Deleted: now},
+			},
+		},
+		"Redeleted": {
+			reason: "We should not delete a secret that has a tombstone again, nor replace its tombstone.",
+			deleted: func(ts *TombstoneStore) {
+				_ = ts.DeleteKeyValues(context.Background(), &store.Secret{ScopedName: n})
+			},
+			after: time.Minute,
+			want: want{
+				deletes:   1,
+				tombstone: &Tombstone{ScopedName: n, Deleted: now},
+			},
+		},
+		"RedeletedAfterExpiry": {
+			reason: "We should delete a secret again, and record a new tombstone, once its tombstone has expired.",
+			deleted: func(ts *TombstoneStore) {
+				_ = ts.DeleteKeyValues(context.Background(), &store.Secret{ScopedName: n})
+			},
+			after: 2 * time.Hour,
+			want: want{
+				deletes:   2,
+				tombstone: &Tombstone{ScopedName: n, Deleted: now.Add(2 * time.Hour)},
+			},
+		},
+		"RewrittenThenDeleted": {
+			reason: "We should delete a secret that was written again after it was deleted.",
+			deleted: func(ts *TombstoneStore) {
+				_ = ts.DeleteKeyValues(context.Background(), &store.Secret{ScopedName: n})
+				_, _ = ts.WriteKeyValues(context.Background(), &store.Secret{ScopedName: n})
+			},
+			after: time.Minute,
+			want: want{
+				deletes:   2,
+				tombstone: &Tombstone{ScopedName: n, Deleted: now.Add(time.Minute)},
+			},
+		},
+		"NeverExisted": {
+			reason: "We should not record a tombstone for a secret that never existed.",
+			err:    store.NewNotFound("not found"),
+			want: want{
+				deletes: 1,
+			},
+		},
+		"DeleteError": {
+			reason: "We should return an error, and not record a tombstone, if the delete fails.",
+			err:    errBoom,
+			want: want{
+				deletes: 1,
+				err:     errBoom,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			deletes := 0
+			clock := fake.NewClock(now)
+			ts := NewTombstoneStore(&fake.SecretStore{
+				WriteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.WriteOption) (bool, error) { return true, nil },
+				DeleteKeyValuesFn: func(_ context.Context, _ *store.Secret, _ ...store.DeleteOption) error {
+					deletes++
+					return nil
+				},
+			}, WithTombstoneTTL(time.Hour), WithTombstoneClock(clock))
+
+			if tc.deleted != nil {
+				tc.deleted(ts)
+			}
+			clock.Step(tc.after)
+			if tc.err != nil {
+				ts.store.(*fake.SecretStore).DeleteKeyValuesFn = func(_ context.Context, _ *store.Secret, _ ...store.DeleteOption) error {
+					deletes++
+					return tc.err
+				}
+			}
+
+			err := ts.DeleteKeyValues(context.Background(), &store.Secret{ScopedName: n})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nts.DeleteKeyValues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.deletes, deletes); diff != "" {
+				t.Errorf("\n%s\nts.DeleteKeyValues(...): -want deletes, +got deletes:\n%s", tc.reason, diff)
+			}
+
+			var got *Tombstone
+			if tomb, ok := ts.Tombstone(n); ok {
+				got = &tomb
+			}
+			if diff := cmp.Diff(tc.want.tombstone, got); diff != "" {
+				t.Errorf("\n%s\nts.Tombstone(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// Error strings.
+const (
+	errFmtMissingKeys = "secret is missing required keys %v"
+)
+
+// DefaultPollInterval is the default interval at which WaitForKeys polls.
+const DefaultPollInterval = 1 * time.Second
+
+// A WaitOption configures WaitForKeys.
+type WaitOption func(*waitOptions)
+
+type waitOptions struct {
+	interval time.Duration
+}
+
+// WithPollInterval configures the interval at which WaitForKeys polls.
+func WithPollInterval(d time.Duration) WaitOption {
+	return func(o *waitOptions) {
+		o.interval = d
+	}
+}
+
+// WaitForKeys reads the supplied secret from the supplied Store until all of
+// the supplied required keys are present, and returns its key values. Secrets
+// are often populated incrementally while the resource they belong to is
+// being provisioned. WaitForKeys returns an error naming the missing keys if
+// the context is cancelled or expires before all required keys are present.
+func WaitForKeys(ctx context.Context, s Store, n store.ScopedName, required []string, o ...WaitOption) (store.KeyValues, error) {
+	opts := &waitOptions{interval: DefaultPollInterval}
+	for _, fn := range o {
+		fn(opts)
+	}
+
+	var kv store.KeyValues
+	var missing []string
+	err := wait.PollUntilContextCancel(ctx, opts.interval, true, func(ctx context.Context) (bool, error) {
+		sec := &store.Secret{}
+		if err := s.ReadKeyValues(ctx, n, sec); err != nil {
+			return false, errors.Wrap(err, errReadStore)
+		}
+		kv, missing = sec.Data, missingKeys(sec.Data, required)
+		return len(missing) == 0, nil
+	})
+	if err != nil && len(missing) > 0 {
+		return nil, errors.Wrapf(err, errFmtMissingKeys, missing)
+	}
+	return kv, err
+}
+
+func missingKeys(kv store.KeyValues, required []string) []string {
+	var missing []string
+	for _, k := range required {
+		if _, ok := kv[k]; !ok {
+			missing = append(missing, k)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestWaitForKeys(t *testing.T) {
+	// Each poll reveals one more key.
+	polls := []store.KeyValues{
+		{},
+		{"host": []byte("example.org")},
+		{"host": []byte("example.org"), "port": []byte("5432")},
+		{"host": []byte("example.org"), "port": []byte("5432"), "password": []byte("hunter2")},
+	}
+
+	type args struct {
+		required []string
+		timeout  time.Duration
+		rErr     error
+	}
+	type want struct {
+		kv    store.KeyValues
+		polls int
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"KeysAppearIncrementally": {
+			reason: "We should poll until all required keys are present.",
+			args: args{
+				required: []string{"host", "port", "password"},
+				timeout:  10 * time.Second,
+			},
+			want: want{
+				kv:    polls[3],
+				polls: 4,
+			},
+		},
+		"SubsetOfKeys": {
+			reason: "We should stop polling as soon as all required keys are present.",
+			args: args{
+				required: []string{"host"},
+				timeout:  10 * time.Second,
+			},
+			want: want{
+				kv:    polls[1],
+				polls: 2,
+			},
+		},
+		"Timeout": {
+			reason: "We should return an error naming the missing keys if the context expires first.",
+			args: args{
+				required: []string{"host", "port", "password", "username"},
+				timeout:  50 * time.Millisecond,
+			},
+			want: want{
+				err: errors.Wrapf(context.DeadlineExceeded, errFmtMissingKeys, []string{"username"}),
+			},
+		},
+		"ReadError": {
+			reason: "We should return any error encountered reading the secret.",
+			args: args{
+				required: []string{"host"},
+				timeout:  10 * time.Second,
+				rErr:     errBoom,
+			},
+			want: want{
+				polls: 1,
+				err:   errors.Wrap(errBoom, errReadStore),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := 0
+			s := &fake.SecretStore{
+				ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
+					if tc.args.rErr != nil {
+						p++
+						return tc.args.rErr
+					}
+					s.Data = polls[min(p, len(polls)-1)]
+					p++
+					return nil
+				},
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), tc.args.timeout)
+			defer cancel()
+
+			kv, err := WaitForKeys(ctx, s, store.ScopedName{Name: "cool"}, tc.args.required, WithPollInterval(time.Millisecond))
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nWaitForKeys(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.kv, kv); diff != "" {
+				t.Errorf("\n%s\nWaitForKeys(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if tc.want.polls > 0 && tc.want.polls != p {
+				t.Errorf("\n%s\nWaitForKeys(...): want %d polls, got %d", tc.reason, tc.want.polls, p)
+			}
+		})
+	}
+}
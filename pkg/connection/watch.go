@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// A WatchOption configures Watch.
+type WatchOption func(*watchOptions)
+
+type watchOptions struct {
+	interval time.Duration
+	debounce time.Duration
+	clock    store.Clock
+}
+
+// WithWatchPollInterval configures the interval at which Watch polls.
+// DefaultPollInterval is used by default.
+func WithWatchPollInterval(d time.Duration) WatchOption {
+	return func(o *watchOptions) {
+		o.interval = d
+	}
+}
+
+// WithDebounce configures Watch to collapse bursts of changes into a single
+// notification. A notification is sent once the secret hasn't changed for the
+// supplied window, and carries its latest key values. This avoids notifying
+// consumers of every intermediate value of a secret that changes rapidly, for
+// example while it is being rotated. Every change is notified by default.
+func WithDebounce(window time.Duration) WatchOption {
+	return func(o *watchOptions) {
+		o.debounce = window
+	}
+}
+
+// WithWatchClock configures the Clock Watch uses to tell how long a secret
+// hasn't changed for when debouncing. store.RealClock is used by default.
+func WithWatchClock(c store.Clock) WatchOption {
+	return func(o *watchOptions) {
+		o.clock = c
+	}
+}
+
+// Watch polls the supplied secret from the supplied Store, and calls the
+// supplied function with its key values when they change. The function is
+// called with the key values first read, too. A secret that doesn't exist, or
+// that is deleted, is notified with empty key values. Watch blocks until the
+// context is done, in which case it returns nil, or until reading the secret
+// fails.
+func Watch(ctx context.Context, s Store, n store.ScopedName, fn func(kv store.KeyValues), o ...WatchOption) error {
+	opts := &watchOptions{interval: DefaultPollInterval, clock: store.RealClock}
+	for _, f := range o {
+		f(opts)
+	}
+
+	var (
+		seen    string
+		read    bool
+		unsent  bool
+		pending store.KeyValues
+		changed time.Time
+	)
+	err := wait.PollUntilContextCancel(ctx, opts.interval, true, func(ctx context.Context) (bool, error) {
+		sec := &store.Secret{}
+		if err := s.ReadKeyValues(ctx, n, sec); err != nil && !store.IsNotFound(err) {
+			return false, errors.Wrap(err, errReadStore)
+		}
+		if h := store.Hash(sec.Data); !read || h != seen {
+			seen, read, unsent, pending, changed = h, true, true, sec.Data, opts.clock.Now()
+		}
+		if unsent && opts.clock.Now().Sub(changed) >= opts.debounce {
+			fn(pending)
+			unsent, pending = false, nil
+		}
+		return false, nil
+	})
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
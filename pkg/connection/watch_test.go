@@ -0,0 +1,144 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connection
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+)
+
+func TestWatch(t *testing.T) {
+	const burst = 5
+
+	cases := map[string]struct {
+		reason string
+		o      []WatchOption
+		want   []string
+	}{
+		"EveryChange": {
+			reason: "We should notify every change by default.",
+			want:   []string{"v1", "v2", "v3", "v4", "v5"},
+		},
+		"Debounced": {
+			reason: "We should collapse a burst of changes into one notification carrying the latest values.",
+			o:      []WatchOption{WithDebounce(200 * time.Millisecond)},
+			want:   []string{"v5"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// The secret changes on each of the first reads, then settles. Each
+			// read takes 10ms on the clock, so the burst takes less time than
+			// the debounce window regardless of how fast we actually poll.
+			clock := fake.NewClock(time.Now())
+			reads := 0
+			s := &fake.SecretStore{
+				ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
+					clock.Step(10 * time.Millisecond)
+					reads++
+					s.Data = store.KeyValues{"token": []byte(fmt.Sprintf("v%d", min(reads, burst)))}
+					return nil
+				},
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			var got []string
+			err := Watch(ctx, s, store.ScopedName{Name: "cool"}, func(kv store.KeyValues) {
+				got = append(got, string(kv["token"]))
+				if string(kv["token"]) == fmt.Sprintf("v%d", burst) {
+					cancel()
+				}
+			}, append([]WatchOption{WithWatchPollInterval(time.Millisecond), WithWatchClock(clock)}, tc.o...)...)
+			if err != nil {
+				t.Fatalf("\n%s\nWatch(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nWatch(...): -want notifications, +got notifications:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWatchMissingSecret(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		reads  []store.KeyValues
+		err    error
+		want   []store.KeyValues
+	}{
+		"Missing": {
+			reason: "We should notify a secret that doesn't exist when it is first read.",
+			reads:  []store.KeyValues{nil},
+			want:   []store.KeyValues{nil},
+		},
+		"MissingNotFound": {
+			reason: "We should notify a secret that a store reports as not found when it is first read.",
+			reads:  []store.KeyValues{nil},
+			err:    store.NewNotFound("gone"),
+			want:   []store.KeyValues{nil},
+		},
+		"Deleted": {
+			reason: "We should notify a secret that is deleted.",
+			reads:  []store.KeyValues{{"token": []byte("v1")}, nil},
+			want:   []store.KeyValues{{"token": []byte("v1")}, nil},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			reads := 0
+			s := &fake.SecretStore{
+				ReadKeyValuesFn: func(_ context.Context, _ store.ScopedName, s *store.Secret, _ ...store.ReadOption) error {
+					kv := tc.reads[min(reads, len(tc.reads)-1)]
+					reads++
+					s.Data = kv
+					if kv == nil {
+						return tc.err
+					}
+					return nil
+				},
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			var got []store.KeyValues
+			err := Watch(ctx, s, store.ScopedName{Name: "cool"}, func(kv store.KeyValues) {
+				got = append(got, kv)
+				if len(got) == len(tc.want) {
+					cancel()
+				}
+			}, WithWatchPollInterval(time.Millisecond))
+			if err != nil {
+				t.Fatalf("\n%s\nWatch(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nWatch(...): -want notifications, +got notifications:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -103,6 +103,11 @@ func (a *APISecretPublisher) PublishConnection(ctx context.Context, o resource.C
 
 	s := resource.ConnectionSecretFor(o, resource.MustGetKind(o, a.typer))
 	s.Data = c
+
+	// The controllability check must be the first apply option, so that it
+	// is evaluated against the secret as it exists before any option mutates
+	// the desired secret. The applicator writes nothing if an option fails,
+	// so an existing secret we can't control is left untouched.
 	err := a.secret.Apply(ctx, s,
 		resource.ConnectionSecretMustBeControllableBy(o.GetUID()),
 		resource.AllowUpdateIf(func(current, desired runtime.Object) bool {
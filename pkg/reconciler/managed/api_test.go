@@ -21,10 +21,12 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
@@ -234,6 +236,54 @@ func TestAPISecretPublisher(t *testing.T) {
 	}
 }
 
+func TestAPISecretPublisherForeignOwnedSecret(t *testing.T) {
+	mg := &fake.Managed{
+		ObjectMeta: metav1.ObjectMeta{UID: "mine"},
+		ConnectionSecretWriterTo: fake.ConnectionSecretWriterTo{Ref: &xpv1.SecretReference{
+			Namespace: "coolnamespace",
+			Name:      "coolsecret",
+		}},
+	}
+
+	// The existing secret is controlled by another owner.
+	foreign := resource.ConnectionSecretFor(mg, fake.GVK(mg))
+	foreign.SetOwnerReferences([]metav1.OwnerReference{{UID: "theirs", Controller: ptr.To(true)}})
+	foreign.Data = map[string][]byte{"theirs": []byte("secret")}
+
+	mutations := 0
+	kube := &test.MockClient{
+		MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+			foreign.DeepCopyInto(obj.(*corev1.Secret))
+			return nil
+		},
+		MockCreate: func(_ context.Context, _ client.Object, _ ...client.CreateOption) error {
+			mutations++
+			return nil
+		},
+		MockUpdate: func(_ context.Context, _ client.Object, _ ...client.UpdateOption) error {
+			mutations++
+			return nil
+		},
+		MockPatch: func(_ context.Context, _ client.Object, _ client.Patch, _ ...client.PatchOption) error {
+			mutations++
+			return nil
+		},
+	}
+
+	a := NewAPISecretPublisher(kube, fake.SchemeWith(&fake.Managed{}))
+	published, err := a.PublishConnection(context.Background(), mg, ConnectionDetails{"mine": []byte("secret")})
+	var nc interface{ NotControllable() bool }
+	if !errors.As(err, &nc) {
+		t.Errorf("PublishConnection(...): want a not controllable error, got %v", err)
+	}
+	if published {
+		t.Errorf("PublishConnection(...): want not published")
+	}
+	if mutations != 0 {
+		t.Errorf("PublishConnection(...): want the foreign owned secret to be left untouched, got %d mutations", mutations)
+	}
+}
+
 type mockSimpleReferencer struct {
 	resource.Managed
 
@@ -18,12 +18,22 @@ package managed
 
 import (
 	"context"
+	"maps"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 )
 
-const errSecretStoreDisabled = "cannot publish to secret store, feature is not enabled"
+const (
+	errSecretStoreDisabled = "cannot publish to secret store, feature is not enabled"
+
+	errFmtPublishStatus = "cannot publish connection details to status field %q"
+)
 
 // A PublisherChain chains multiple ManagedPublishers.
 type PublisherChain []ConnectionPublisher
@@ -55,6 +65,107 @@ func (pc PublisherChain) UnpublishConnection(ctx context.Context, o resource.Con
 	return nil
 }
 
+// A ConditionalPublisher is a ConnectionPublisher that only publishes
+// connection details once a condition of a particular type is True.
+type ConditionalPublisher struct {
+	ConnectionPublisher
+
+	condition xpv1.ConditionType
+}
+
+// NewConditionalPublisher returns a ConnectionPublisher that publishes
+// connection details using the supplied ConnectionPublisher only when the
+// resource has a condition of the supplied type with status True, for example
+// a custom condition the ExternalClient sets once the connection details are
+// usable. Unpublishing connection details is never gated. Connection details
+// are published unconditionally by default; use WithConnectionPublishers to
+// supply a ConditionalPublisher.
+func NewConditionalPublisher(ct xpv1.ConditionType, p ConnectionPublisher) *ConditionalPublisher {
+	return &ConditionalPublisher{ConnectionPublisher: p, condition: ct}
+}
+
+// PublishConnection publishes the supplied connection details if the supplied
+// resource has a condition of the configured type with status True.
+func (p *ConditionalPublisher) PublishConnection(ctx context.Context, o resource.ConnectionSecretOwner, c ConnectionDetails) (bool, error) {
+	co, ok := o.(resource.Conditioned)
+	if !ok || co.GetCondition(p.condition).Status != corev1.ConditionTrue {
+		return false, nil
+	}
+	return p.ConnectionPublisher.PublishConnection(ctx, o, c)
+}
+
+// A StatusPublisher is a ConnectionPublisher that publishes non-sensitive
+// connection details to a status field of the resource, and all other
+// connection details using another ConnectionPublisher.
+type StatusPublisher struct {
+	ConnectionPublisher
+
+	path   string
+	public map[string]bool
+}
+
+// NewStatusPublisher returns a ConnectionPublisher that publishes the supplied
+// non-sensitive keys of the connection details to the status field at the
+// supplied field path, for example status.atProvider.connectionDetails, as a
+// map of strings. All other keys are published using the supplied
+// ConnectionPublisher. The status field is only set on the in-memory resource;
+// the Reconciler persists it when it updates the resource's status.
+func NewStatusPublisher(path string, keys []string, p ConnectionPublisher) *StatusPublisher {
+	public := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		public[k] = true
+	}
+	return &StatusPublisher{ConnectionPublisher: p, path: path, public: public}
+}
+
+// PublishConnection publishes the non-sensitive keys of the supplied
+// connection details to the configured status field, and the sensitive keys
+// using the wrapped ConnectionPublisher.
+func (p *StatusPublisher) PublishConnection(ctx context.Context, o resource.ConnectionSecretOwner, c ConnectionDetails) (bool, error) {
+	public, sensitive := p.split(c)
+
+	paved, err := fieldpath.PaveObject(o)
+	if err != nil {
+		return false, errors.Wrapf(err, errFmtPublishStatus, p.path)
+	}
+	current, err := paved.GetStringObject(p.path)
+	if err != nil && !fieldpath.IsNotFound(err) {
+		return false, errors.Wrapf(err, errFmtPublishStatus, p.path)
+	}
+	changed := !maps.Equal(current, public)
+	if changed {
+		if err := paved.SetValue(p.path, public); err != nil {
+			return false, errors.Wrapf(err, errFmtPublishStatus, p.path)
+		}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(paved.UnstructuredContent(), o); err != nil {
+			return false, errors.Wrapf(err, errFmtPublishStatus, p.path)
+		}
+	}
+
+	published, err := p.ConnectionPublisher.PublishConnection(ctx, o, sensitive)
+	return published || changed, err
+}
+
+// UnpublishConnection unpublishes the sensitive keys of the supplied
+// connection details using the wrapped ConnectionPublisher. The status field
+// goes away with the resource.
+func (p *StatusPublisher) UnpublishConnection(ctx context.Context, o resource.ConnectionSecretOwner, c ConnectionDetails) error {
+	_, sensitive := p.split(c)
+	return p.ConnectionPublisher.UnpublishConnection(ctx, o, sensitive)
+}
+
+func (p *StatusPublisher) split(c ConnectionDetails) (public map[string]string, sensitive ConnectionDetails) {
+	public, sensitive = map[string]string{}, ConnectionDetails{}
+	for k, v := range c {
+		if p.public[k] {
+			public[k] = string(v)
+			continue
+		}
+		sensitive[k] = v
+	}
+	return public, sensitive
+}
+
 // DisabledSecretStoreManager is a connection details manager that returns a proper
 // error when API used but feature not enabled.
 type DisabledSecretStoreManager struct{}
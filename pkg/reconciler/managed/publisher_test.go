@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
@@ -32,6 +33,8 @@ import (
 var (
 	_ ConnectionPublisher = &APISecretPublisher{}
 	_ ConnectionPublisher = PublisherChain{}
+	_ ConnectionPublisher = &ConditionalPublisher{}
+	_ ConnectionPublisher = &StatusPublisher{}
 )
 
 func TestPublisherChain(t *testing.T) {
@@ -116,6 +119,145 @@ func TestPublisherChain(t *testing.T) {
 	}
 }
 
+func TestConditionalPublisher(t *testing.T) {
+	ct := xpv1.ConditionType("CredentialsIssued")
+
+	type want struct {
+		published bool
+		calls     int
+	}
+
+	cases := map[string]struct {
+		reason string
+		status []corev1.ConditionStatus
+		want   want
+	}{
+		"ConditionMissing": {
+			reason: "We should not publish if the resource does not have the gating condition.",
+			status: []corev1.ConditionStatus{""},
+		},
+		"ConditionFalseThenTrue": {
+			reason: "We should only publish once the gating condition becomes True.",
+			status: []corev1.ConditionStatus{corev1.ConditionFalse, corev1.ConditionTrue},
+			want: want{
+				published: true,
+				calls:     1,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			calls := 0
+			p := NewConditionalPublisher(ct, ConnectionPublisherFns{
+				PublishConnectionFn: func(_ context.Context, _ resource.ConnectionSecretOwner, _ ConnectionDetails) (bool, error) {
+					calls++
+					return true, nil
+				},
+			})
+
+			mg := &fake.Managed{}
+			var published bool
+			for _, s := range tc.status {
+				if s != "" {
+					mg.SetConditions(xpv1.Condition{Type: ct, Status: s})
+				}
+				var err error
+				published, err = p.PublishConnection(context.Background(), mg, ConnectionDetails{})
+				if err != nil {
+					t.Fatalf("\n%s\nPublishConnection(...): %v", tc.reason, err)
+				}
+			}
+
+			if diff := cmp.Diff(tc.want.published, published); diff != "" {
+				t.Errorf("\n%s\nPublishConnection(...): -wantPublished, +gotPublished:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.calls, calls); diff != "" {
+				t.Errorf("\n%s\nPublishConnection(...): -wantCalls, +gotCalls:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// statusManaged is a managed resource with a status field that connection
+// details may be published to.
+type statusManaged struct {
+	fake.Managed
+
+	Status struct {
+		ConnectionDetails map[string]string `json:"connectionDetails,omitempty"`
+	} `json:"status"`
+}
+
+func TestStatusPublisher(t *testing.T) {
+	type want struct {
+		status    map[string]string
+		secret    ConnectionDetails
+		published bool
+	}
+
+	cases := map[string]struct {
+		reason  string
+		current map[string]string
+		c       ConnectionDetails
+		want    want
+	}{
+		"SplitKeys": {
+			reason: "We should publish non-sensitive keys to the status field, and sensitive keys using the wrapped publisher.",
+			c:      ConnectionDetails{"endpoint": []byte("db.example.org"), "port": []byte("5432"), "password": []byte("s3cr3t")},
+			want: want{
+				status:    map[string]string{"endpoint": "db.example.org", "port": "5432"},
+				secret:    ConnectionDetails{"password": []byte("s3cr3t")},
+				published: true,
+			},
+		},
+		"OnlySensitiveKeys": {
+			reason: "We should not set the status field if there are no non-sensitive keys.",
+			c:      ConnectionDetails{"password": []byte("s3cr3t")},
+			want: want{
+				secret: ConnectionDetails{"password": []byte("s3cr3t")},
+			},
+		},
+		"StatusUnchanged": {
+			reason:  "We should not report publishing if the status field is unchanged and the wrapped publisher published nothing.",
+			current: map[string]string{"endpoint": "db.example.org"},
+			c:       ConnectionDetails{"endpoint": []byte("db.example.org")},
+			want: want{
+				status: map[string]string{"endpoint": "db.example.org"},
+				secret: ConnectionDetails{},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var secret ConnectionDetails
+			p := NewStatusPublisher("status.connectionDetails", []string{"endpoint", "port"}, ConnectionPublisherFns{
+				PublishConnectionFn: func(_ context.Context, _ resource.ConnectionSecretOwner, c ConnectionDetails) (bool, error) {
+					secret = c
+					return false, nil
+				},
+			})
+
+			mg := &statusManaged{}
+			mg.Status.ConnectionDetails = tc.current
+			published, err := p.PublishConnection(context.Background(), mg, tc.c)
+			if err != nil {
+				t.Fatalf("\n%s\nPublishConnection(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.status, mg.Status.ConnectionDetails); diff != "" {
+				t.Errorf("\n%s\nPublishConnection(...): -wantStatus, +gotStatus:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.secret, secret); diff != "" {
+				t.Errorf("\n%s\nPublishConnection(...): -wantSecret, +gotSecret:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.published, published); diff != "" {
+				t.Errorf("\n%s\nPublishConnection(...): -wantPublished, +gotPublished:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestDisabledSecretStorePublish(t *testing.T) {
 	type args struct {
 		mg resource.Managed
@@ -134,7 +134,8 @@ func (m *ConnectionSecretWriterTo) GetWriteConnectionSecretToReference() *xpv1.S
 
 // ConnectionDetailsPublisherTo is a mock that implements ConnectionDetailsPublisherTo interface.
 type ConnectionDetailsPublisherTo struct {
-	To *xpv1.PublishConnectionDetailsTo
+	To    *xpv1.PublishConnectionDetailsTo
+	ToAll []xpv1.PublishConnectionDetailsTo
 }
 
 // SetPublishConnectionDetailsTo sets the PublishConnectionDetailsTo.
@@ -147,6 +148,18 @@ func (m *ConnectionDetailsPublisherTo) GetPublishConnectionDetailsTo() *xpv1.Pub
 	return m.To
 }
 
+// SetPublishConnectionDetailsToAll sets the PublishConnectionDetailsTo of
+// every target.
+func (m *ConnectionDetailsPublisherTo) SetPublishConnectionDetailsToAll(to []xpv1.PublishConnectionDetailsTo) {
+	m.ToAll = to
+}
+
+// GetPublishConnectionDetailsToAll gets the PublishConnectionDetailsTo of
+// every target.
+func (m *ConnectionDetailsPublisherTo) GetPublishConnectionDetailsToAll() []xpv1.PublishConnectionDetailsTo {
+	return m.ToAll
+}
+
 // Manageable implements the Manageable interface.
 type Manageable struct{ Policy xpv1.ManagementPolicies }
 
@@ -500,6 +513,7 @@ type MockConnectionSecretOwner struct {
 	metav1.ObjectMeta
 
 	To       *xpv1.PublishConnectionDetailsTo
+	ToAll    []xpv1.PublishConnectionDetailsTo
 	WriterTo *xpv1.SecretReference
 }
 
@@ -513,6 +527,18 @@ func (m *MockConnectionSecretOwner) SetPublishConnectionDetailsTo(t *xpv1.Publis
 	m.To = t
 }
 
+// GetPublishConnectionDetailsToAll returns the publish connection details to
+// references of every target.
+func (m *MockConnectionSecretOwner) GetPublishConnectionDetailsToAll() []xpv1.PublishConnectionDetailsTo {
+	return m.ToAll
+}
+
+// SetPublishConnectionDetailsToAll sets the publish connection details to
+// references of every target.
+func (m *MockConnectionSecretOwner) SetPublishConnectionDetailsToAll(t []xpv1.PublishConnectionDetailsTo) {
+	m.ToAll = t
+}
+
 // GetWriteConnectionSecretToReference returns the connection secret reference.
 func (m *MockConnectionSecretOwner) GetWriteConnectionSecretToReference() *xpv1.SecretReference {
 	return m.WriterTo
@@ -545,8 +571,9 @@ type MockLocalConnectionSecretOwner struct {
 	runtime.Object
 	metav1.ObjectMeta
 
-	Ref *xpv1.LocalSecretReference
-	To  *xpv1.PublishConnectionDetailsTo
+	Ref   *xpv1.LocalSecretReference
+	To    *xpv1.PublishConnectionDetailsTo
+	ToAll []xpv1.PublishConnectionDetailsTo
 }
 
 // GetWriteConnectionSecretToReference returns the connection secret reference.
@@ -569,6 +596,18 @@ func (m *MockLocalConnectionSecretOwner) GetPublishConnectionDetailsTo() *xpv1.P
 	return m.To
 }
 
+// GetPublishConnectionDetailsToAll returns the publish connection details to
+// references of every target.
+func (m *MockLocalConnectionSecretOwner) GetPublishConnectionDetailsToAll() []xpv1.PublishConnectionDetailsTo {
+	return m.ToAll
+}
+
+// SetPublishConnectionDetailsToAll sets the publish connection details to
+// references of every target.
+func (m *MockLocalConnectionSecretOwner) SetPublishConnectionDetailsToAll(t []xpv1.PublishConnectionDetailsTo) {
+	m.ToAll = t
+}
+
 // GetObjectKind returns schema.ObjectKind.
 func (m *MockLocalConnectionSecretOwner) GetObjectKind() schema.ObjectKind {
 	return schema.EmptyObjectKind
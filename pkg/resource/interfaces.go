@@ -61,11 +61,15 @@ type ConnectionSecretWriterTo interface {
 	GetWriteConnectionSecretToReference() *xpv1.SecretReference
 }
 
-// A ConnectionDetailsPublisherTo may write a connection details secret to a
-// secret store.
+// A ConnectionDetailsPublisherTo may write a connection details secret to one
+// or more secret stores, for example to a local Kubernetes secret for
+// applications and to Vault for auditing. The single target is used only if
+// there are no targets.
 type ConnectionDetailsPublisherTo interface {
 	SetPublishConnectionDetailsTo(r *xpv1.PublishConnectionDetailsTo)
 	GetPublishConnectionDetailsTo() *xpv1.PublishConnectionDetailsTo
+	SetPublishConnectionDetailsToAll(r []xpv1.PublishConnectionDetailsTo)
+	GetPublishConnectionDetailsToAll() []xpv1.PublishConnectionDetailsTo
 }
 
 // A Manageable resource may specify a ManagementPolicies.
@@ -117,6 +117,20 @@ func (cr *Unstructured) SetPublishConnectionDetailsTo(ref *xpv1.PublishConnectio
 	_ = fieldpath.Pave(cr.Object).SetValue("spec.publishConnectionDetailsTo", ref)
 }
 
+// GetPublishConnectionDetailsToAll of this Composed resource.
+func (cr *Unstructured) GetPublishConnectionDetailsToAll() []xpv1.PublishConnectionDetailsTo {
+	out := []xpv1.PublishConnectionDetailsTo{}
+	if err := fieldpath.Pave(cr.Object).GetValueInto("spec.publishConnectionDetailsToAll", &out); err != nil {
+		return nil
+	}
+	return out
+}
+
+// SetPublishConnectionDetailsToAll of this Composed resource.
+func (cr *Unstructured) SetPublishConnectionDetailsToAll(refs []xpv1.PublishConnectionDetailsTo) {
+	_ = fieldpath.Pave(cr.Object).SetValue("spec.publishConnectionDetailsToAll", refs)
+}
+
 // OwnedBy returns true if the supplied UID is an owner of the composed.
 func (cr *Unstructured) OwnedBy(u types.UID) bool {
 	for _, owner := range cr.GetOwnerReferences() {
@@ -214,6 +214,20 @@ func (c *Unstructured) SetPublishConnectionDetailsTo(ref *xpv1.PublishConnection
 	_ = fieldpath.Pave(c.Object).SetValue("spec.publishConnectionDetailsTo", ref)
 }
 
+// GetPublishConnectionDetailsToAll of this Composite resource.
+func (c *Unstructured) GetPublishConnectionDetailsToAll() []xpv1.PublishConnectionDetailsTo {
+	out := []xpv1.PublishConnectionDetailsTo{}
+	if err := fieldpath.Pave(c.Object).GetValueInto("spec.publishConnectionDetailsToAll", &out); err != nil {
+		return nil
+	}
+	return out
+}
+
+// SetPublishConnectionDetailsToAll of this Composite resource.
+func (c *Unstructured) SetPublishConnectionDetailsToAll(refs []xpv1.PublishConnectionDetailsTo) {
+	_ = fieldpath.Pave(c.Object).SetValue("spec.publishConnectionDetailsToAll", refs)
+}
+
 // GetCondition of this Composite resource.
 func (c *Unstructured) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
 	conditioned := xpv1.ConditionedStatus{}